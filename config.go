@@ -5,6 +5,7 @@ package coraza
 
 import (
 	"io/fs"
+	"time"
 
 	"github.com/corazawaf/coraza/v3/debuglog"
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
@@ -63,6 +64,22 @@ type WAFConfig interface {
 
 	// WithRootFS configures the root file system.
 	WithRootFS(fs fs.FS) WAFConfig
+
+	// WithAuditLog configures audit logging, it is equivalent to enabling
+	// SecAuditEngine and overrides the audit log directives.
+	WithAuditLog(config AuditLogConfig) WAFConfig
+
+	// WithGeoDB configures the database used by the geoLookup operator, it is
+	// equivalent to SecGeoLookupDb, which overrides it when used in the directives.
+	WithGeoDB(db plugintypes.GeoDatabase) WAFConfig
+
+	// WithDNSResolver sets the resolver used by the rbl operator, net.DefaultResolver
+	// is used by default.
+	WithDNSResolver(resolver plugintypes.DNSResolver) WAFConfig
+
+	// WithDNSTimeout sets the maximum time the DNS queries of an rbl evaluation can
+	// take, the operator does not match when it is exceeded. Defaults to 500ms.
+	WithDNSTimeout(timeout time.Duration) WAFConfig
 }
 
 // NewWAFConfig creates a new WAFConfig with the default settings.
@@ -77,6 +94,9 @@ type AuditLogConfig interface {
 
 	// WithParts configures the parts of the request/response to be logged.
 	WithParts(parts types.AuditLogParts) AuditLogConfig
+
+	// WithWriter configures the writer the audit logs are sent to.
+	WithWriter(writer plugintypes.AuditLogWriter) AuditLogConfig
 }
 
 // NewAuditLogConfig returns a new AuditLogConfig with the default settings.
@@ -105,6 +125,9 @@ type wafConfig struct {
 	debugLogger              debuglog.Logger
 	errorCallback            func(rule types.MatchedRule)
 	fsRoot                   fs.FS
+	geoDB                    plugintypes.GeoDatabase
+	dnsResolver              plugintypes.DNSResolver
+	dnsTimeout               time.Duration
 }
 
 func (c *wafConfig) WithRules(rules ...*corazawaf.Rule) WAFConfig {
@@ -161,6 +184,12 @@ func (c *wafConfig) WithRootFS(fs fs.FS) WAFConfig {
 	return ret
 }
 
+func (c *wafConfig) WithAuditLog(config AuditLogConfig) WAFConfig {
+	ret := c.clone()
+	ret.auditLog = config.(*auditLogConfig)
+	return ret
+}
+
 func (c *wafConfig) clone() *wafConfig {
 	ret := *c // copy
 	rules := make([]wafRule, len(c.rules))
@@ -169,6 +198,24 @@ func (c *wafConfig) clone() *wafConfig {
 	return &ret
 }
 
+func (c *wafConfig) WithGeoDB(db plugintypes.GeoDatabase) WAFConfig {
+	ret := c.clone()
+	ret.geoDB = db
+	return ret
+}
+
+func (c *wafConfig) WithDNSResolver(resolver plugintypes.DNSResolver) WAFConfig {
+	ret := c.clone()
+	ret.dnsResolver = resolver
+	return ret
+}
+
+func (c *wafConfig) WithDNSTimeout(timeout time.Duration) WAFConfig {
+	ret := c.clone()
+	ret.dnsTimeout = timeout
+	return ret
+}
+
 func (c *wafConfig) WithRequestBodyLimit(limit int) WAFConfig {
 	ret := c.clone()
 	ret.requestBodyLimit = &limit
@@ -211,6 +258,12 @@ func (c *auditLogConfig) WithParts(parts types.AuditLogParts) AuditLogConfig {
 	return ret
 }
 
+func (c *auditLogConfig) WithWriter(writer plugintypes.AuditLogWriter) AuditLogConfig {
+	ret := c.clone()
+	ret.writer = writer
+	return ret
+}
+
 func (c *auditLogConfig) clone() *auditLogConfig {
 	ret := *c // copy
 	return &ret
@@ -0,0 +1,65 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.geoLookup
+
+package coraza
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/corazawaf/coraza/v3/internal/geo"
+)
+
+func TestConfigGeoDBMatchesDirective(t *testing.T) {
+	data, err := os.ReadFile("internal/geo/testdata/GeoLite2-City-Test.mmdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := geo.FromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := `
+SecRuleEngine On
+SecRule REMOTE_ADDR "@geoLookup" "id:1,phase:1,pass,nolog,setvar:tx.country=%{geo.country_code},setvar:tx.city=%{geo.city}"
+`
+
+	withOption, err := NewWAF(NewWAFConfig().
+		WithGeoDB(db).
+		WithDirectives(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	withDirective, err := NewWAF(NewWAFConfig().
+		WithRootFS(fstest.MapFS{"GeoLite2-City-Test.mmdb": &fstest.MapFile{Data: data}}).
+		WithDirectives("SecGeoLookupDb GeoLite2-City-Test.mmdb\n" + rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, addr := range []string{"81.2.69.142", "2.125.160.216", "127.0.0.1"} {
+		o := withOption.(wafWrapper).waf.NewTransaction()
+		o.ProcessConnection(addr, 1234, "", 0)
+		o.ProcessRequestHeaders()
+		d := withDirective.(wafWrapper).waf.NewTransaction()
+		d.ProcessConnection(addr, 1234, "", 0)
+		d.ProcessRequestHeaders()
+		for _, key := range []string{"country", "city"} {
+			want := d.Variables().TX().Get(key)
+			have := o.Variables().TX().Get(key)
+			if len(want) != len(have) || (len(want) == 1 && want[0] != have[0]) {
+				t.Errorf("unexpected TX:%s for %s, want %q, have %q", key, addr, want, have)
+			}
+		}
+		if addr == "81.2.69.142" {
+			if have := o.Variables().TX().Get("country"); len(have) != 1 || have[0] != "GB" {
+				t.Errorf("unexpected TX:country for %s, have %q", addr, have)
+			}
+		}
+		_ = o.Close()
+		_ = d.Close()
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo && !coraza.disabled_operators.rbl
+
+package coraza
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+type listingResolver struct {
+	listed string
+}
+
+func (r listingResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if host == r.listed {
+		return []string{"127.0.0.2"}, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r listingResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func TestConfigDNSResolver(t *testing.T) {
+	waf, err := NewWAF(NewWAFConfig().
+		WithDNSResolver(listingResolver{listed: "4.3.2.1.dnsbl.example.org"}).
+		WithDNSTimeout(time.Second).
+		WithDirectives(`
+SecRuleEngine On
+SecRule REMOTE_ADDR "@rbl dnsbl.example.org" "id:1,phase:1,deny,log"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeout := waf.(wafWrapper).waf.DNSTimeout; timeout != time.Second {
+		t.Errorf("unexpected timeout %s", timeout)
+	}
+
+	for addr, denied := range map[string]bool{"1.2.3.4": true, "5.6.7.8": false} {
+		tx := waf.NewTransaction()
+		tx.ProcessConnection(addr, 1234, "", 0)
+		if it := tx.ProcessRequestHeaders(); (it != nil) != denied {
+			t.Errorf("unexpected interruption for %s: %v", addr, it)
+		}
+		_ = tx.Close()
+	}
+}
@@ -110,3 +110,69 @@ func TestConfigSetters(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigOptionsMatchDirectives(t *testing.T) {
+	cb := func(types.MatchedRule) {}
+	writer := &testAuditLogWriter{}
+	parts, err := types.ParseAuditLogParts("ABZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withOptions, err := NewWAF(NewWAFConfig().
+		WithRequestBodyAccess().
+		WithRequestBodyLimit(2048).
+		WithRequestBodyInMemoryLimit(1024).
+		WithResponseBodyAccess().
+		WithResponseBodyLimit(4096).
+		WithErrorCallback(cb).
+		WithAuditLog(NewAuditLogConfig().
+			LogRelevantOnly().
+			WithParts(parts).
+			WithWriter(writer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withDirectives, err := NewWAF(NewWAFConfig().
+		WithErrorCallback(cb).
+		WithDirectives(`
+		SecRequestBodyAccess On
+		SecRequestBodyLimit 2048
+		SecRequestBodyInMemoryLimit 1024
+		SecResponseBodyAccess On
+		SecResponseBodyLimit 4096
+		SecAuditEngine RelevantOnly
+		SecAuditLogParts ABZ
+		`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := withOptions.(wafWrapper).waf
+	d := withDirectives.(wafWrapper).waf
+	if want, have := d.RequestBodyAccess, o.RequestBodyAccess; want != have {
+		t.Errorf("unexpected RequestBodyAccess, want %t, have %t", want, have)
+	}
+	if want, have := d.RequestBodyLimit, o.RequestBodyLimit; want != have {
+		t.Errorf("unexpected RequestBodyLimit, want %d, have %d", want, have)
+	}
+	if want, have := *d.RequestBodyInMemoryLimit(), *o.RequestBodyInMemoryLimit(); want != have {
+		t.Errorf("unexpected RequestBodyInMemoryLimit, want %d, have %d", want, have)
+	}
+	if want, have := d.ResponseBodyAccess, o.ResponseBodyAccess; want != have {
+		t.Errorf("unexpected ResponseBodyAccess, want %t, have %t", want, have)
+	}
+	if want, have := d.ResponseBodyLimit, o.ResponseBodyLimit; want != have {
+		t.Errorf("unexpected ResponseBodyLimit, want %d, have %d", want, have)
+	}
+	if want, have := d.AuditEngine, o.AuditEngine; want != have {
+		t.Errorf("unexpected AuditEngine, want %v, have %v", want, have)
+	}
+	if want, have := string(d.AuditLogParts), string(o.AuditLogParts); want != have {
+		t.Errorf("unexpected AuditLogParts, want %q, have %q", want, have)
+	}
+	if o.AuditLogWriter() != writer {
+		t.Error("expected the configured audit log writer")
+	}
+}
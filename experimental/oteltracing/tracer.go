@@ -0,0 +1,50 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package oteltracing traces the transactions of a WAF with OpenTelemetry,
+// it is kept apart so only the users importing it depend on OpenTelemetry.
+package oteltracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+// NewTracer returns a tracer emitting the spans of the transactions with the
+// given OpenTelemetry tracer, to be set with experimental.WAFWithTracing.
+func NewTracer(tracer trace.Tracer) plugintypes.Tracer {
+	return otelTracer{tracer: tracer}
+}
+
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+func (t otelTracer) Start(ctx context.Context, name string) (context.Context, plugintypes.Span) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, otelSpan{span: span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetStringAttribute(key, value string) {
+	s.span.SetAttributes(attribute.String(key, value))
+}
+
+func (s otelSpan) SetIntAttribute(key string, value int) {
+	s.span.SetAttributes(attribute.Int(key, value))
+}
+
+func (s otelSpan) SetBoolAttribute(key string, value bool) {
+	s.span.SetAttributes(attribute.Bool(key, value))
+}
+
+func (s otelSpan) End() {
+	s.span.End()
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package oteltracing_test
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/corazawaf/coraza/v3"
+	"github.com/corazawaf/coraza/v3/experimental"
+	"github.com/corazawaf/coraza/v3/experimental/oteltracing"
+)
+
+func TestTracer(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+		WithDirectives(`SecRuleEngine On
+SecRule ARGS_GET:id "@eq 0" "id:1,phase:1,deny,status:403"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waf.(experimental.WAFWithTracing).SetTracer(oteltracing.NewTracer(provider.Tracer("coraza")))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	tx := waf.(experimental.WAFWithOptions).NewTransactionWithOptions(experimental.Options{
+		ID:      "abc",
+		Context: ctx,
+	})
+	tx.AddGetRequestArgument("id", "0")
+	if it := tx.ProcessRequestHeaders(); it == nil {
+		t.Fatal("expected the transaction to be interrupted")
+	}
+	if err := tx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, have %d", len(spans))
+	}
+	span := spans[0]
+	if want, have := "coraza.phase.request_headers", span.Name; want != have {
+		t.Errorf("unexpected span name, want %q, have %q", want, have)
+	}
+	if want, have := parent.SpanContext().SpanID(), span.Parent.SpanID(); want != have {
+		t.Errorf("unexpected parent span, want %s, have %s", want, have)
+	}
+
+	attrs := map[string]interface{}{}
+	for _, kv := range span.Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	for key, want := range map[string]interface{}{
+		"coraza.transaction.id":       "abc",
+		"coraza.phase":                int64(1),
+		"coraza.interrupted":          true,
+		"coraza.interruption.rule_id": int64(1),
+		"coraza.interruption.status":  int64(403),
+	} {
+		if have := attrs[key]; want != have {
+			t.Errorf("unexpected %s, want %v, have %v", key, want, have)
+		}
+	}
+}
@@ -17,3 +17,9 @@ type ActionFactory = func() plugintypes.Action
 func RegisterAction(name string, a ActionFactory) {
 	actions.Register(name, a)
 }
+
+// ListActions returns the sorted and lowercased names of the registered actions,
+// including the ones registered by plugins
+func ListActions() []string {
+	return actions.List()
+}
@@ -4,6 +4,7 @@
 package plugins_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins"
@@ -23,4 +24,16 @@ func TestAction(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 	})
+
+	t.Run("list actions", func(t *testing.T) {
+		plugins.RegisterAction("customListedAction", func() plugintypes.Action {
+			return nil
+		})
+		names := plugins.ListActions()
+		for _, name := range []string{"deny", "customlistedaction"} {
+			if !slices.Contains(names, name) {
+				t.Errorf("expected %q to be listed", name)
+			}
+		}
+	})
 }
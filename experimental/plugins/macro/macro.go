@@ -59,6 +59,8 @@ func (m *macro) Expand(tx plugintypes.TransactionState) string {
 		return expandToken(tx, m.tokens[0])
 	}
 	res := strings.Builder{}
+	// the expansion is usually as long as the original text
+	res.Grow(len(m.original))
 	for _, token := range m.tokens {
 		res.WriteString(expandToken(tx, token))
 	}
@@ -82,9 +84,10 @@ func expandToken(tx plugintypes.TransactionState, token macroToken) string {
 		}
 	}
 
-	// If the variable is known (e.g. TX) but the key is not found, we return the original text
-	tx.DebugLogger().Warn().Str("variable", token.variable.Name()).Str("key", token.key).Msg("key not found in collection, returning the original text")
-	return token.text
+	// If the variable is known (e.g. TX) but the key is not found, the macro expands to
+	// an empty string, as done by ModSecurity
+	tx.DebugLogger().Warn().Str("variable", token.variable.Name()).Str("key", token.key).Msg("key not found in collection, expanding to an empty string")
+	return ""
 }
 
 // compile is used to parse the input and generate the corresponding token
@@ -8,8 +8,16 @@ import (
 	"github.com/corazawaf/coraza/v3/internal/operators"
 )
 
-// RegisterOperator registers a new operator
-// If the operator already exists it will be overwritten
-func RegisterOperator(name string, op plugintypes.OperatorFactory) {
-	operators.Register(name, op)
+// RegisterOperator registers a new operator. Built-in operators can be replaced,
+// e.g. by faster implementations, but an error is returned if the name is empty,
+// the factory is nil or the name was already registered by a plugin.
+// It is safe for concurrent use.
+func RegisterOperator(name string, op plugintypes.OperatorFactory) error {
+	return operators.RegisterPlugin(name, op)
+}
+
+// ListOperators returns the sorted names of the registered operators,
+// including the ones registered by plugins
+func ListOperators() []string {
+	return operators.List()
 }
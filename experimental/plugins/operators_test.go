@@ -4,23 +4,145 @@
 package plugins_test
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/corazawaf/coraza/v3"
 	"github.com/corazawaf/coraza/v3/experimental/plugins"
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	"github.com/corazawaf/coraza/v3/internal/operators"
 )
 
+type hasPrefixOperator struct {
+	prefix string
+}
+
+func (o hasPrefixOperator) Evaluate(_ plugintypes.TransactionState, value string) bool {
+	return strings.HasPrefix(value, o.prefix)
+}
+
+func newHasPrefixOperator(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
+	return hasPrefixOperator{prefix: options.Arguments}, nil
+}
+
 func TestGetOperator(t *testing.T) {
 	t.Run("get existing operator", func(t *testing.T) {
 		operator := func(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
 			return nil, nil
 		}
 
-		plugins.RegisterOperator("custom_operator", operator)
+		if err := plugins.RegisterOperator("custom_operator", operator); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
 		_, err := operators.Get("custom_operator", plugintypes.OperatorOptions{})
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
 	})
+
+	t.Run("list operators", func(t *testing.T) {
+		if err := plugins.RegisterOperator("custom_listed_operator", newHasPrefixOperator); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		names := plugins.ListOperators()
+		for _, name := range []string{"rx", "custom_listed_operator"} {
+			if !slices.Contains(names, name) {
+				t.Errorf("expected %q to be listed", name)
+			}
+		}
+	})
+}
+
+func TestRegisterOperatorValidation(t *testing.T) {
+	if err := plugins.RegisterOperator("", newHasPrefixOperator); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if err := plugins.RegisterOperator("nil_operator", nil); err == nil {
+		t.Error("expected error for nil factory")
+	}
+
+	// built-in operators can be replaced once
+	if err := plugins.RegisterOperator("beginsWith", newHasPrefixOperator); err != nil {
+		t.Errorf("unexpected error replacing a built-in operator: %v", err)
+	}
+
+	if err := plugins.RegisterOperator("duplicated_operator", newHasPrefixOperator); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := plugins.RegisterOperator("duplicated_operator", newHasPrefixOperator); err == nil {
+		t.Error("expected error for duplicated name")
+	}
+}
+
+func TestRegisterOperatorUsedByParser(t *testing.T) {
+	if err := plugins.RegisterOperator("parsedHasPrefix", newHasPrefixOperator); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+		WithDirectives(`SecRule ARGS:id "@parsedHasPrefix admin" "id:1,phase:1,deny,status:403"`).
+		WithDirectives(`SecRuleEngine On`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.ProcessURI("/?id=administrator", "GET", "HTTP/1.1")
+	if it := tx.ProcessRequestHeaders(); it == nil || it.RuleID != 1 {
+		t.Errorf("expected interruption by the registered operator, have %v", it)
+	}
+}
+
+type inSetOperator struct {
+	values map[string]bool
+}
+
+func (o inSetOperator) Evaluate(_ plugintypes.TransactionState, value string) bool {
+	return o.values[value]
+}
+
+func TestOperatorOptionsFromWAF(t *testing.T) {
+	var options plugintypes.OperatorOptions
+	err := plugins.RegisterOperator("inDataset", func(opts plugintypes.OperatorOptions) (plugintypes.Operator, error) {
+		options = opts
+		// the dataset is read once, when the rule is parsed
+		values := map[string]bool{}
+		for _, v := range opts.Datasets[opts.Arguments] {
+			values[v] = true
+		}
+		return inSetOperator{values: values}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().WithDirectives(`
+SecRuleEngine On
+SecDataDir /var/lib/coraza
+SecDataset blocked_users ` + "`\nadmin\nroot\n`" + `
+SecRule ARGS:user "@inDataset blocked_users" "id:1,phase:1,deny,status:403"
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want, have := "blocked_users", options.Arguments; want != have {
+		t.Errorf("unexpected arguments, want %q, have %q", want, have)
+	}
+	if want, have := "/var/lib/coraza", options.DataDir; want != have {
+		t.Errorf("unexpected data dir, want %q, have %q", want, have)
+	}
+	if options.Root == nil {
+		t.Error("expected the root filesystem")
+	}
+
+	for user, blocked := range map[string]bool{"root": true, "guest": false} {
+		tx := waf.NewTransaction()
+		tx.ProcessURI("/?user="+user, "GET", "HTTP/1.1")
+		if it := tx.ProcessRequestHeaders(); (it != nil) != blocked {
+			t.Errorf("unexpected interruption for user %q, want %t, have %v", user, blocked, it)
+		}
+		tx.Close()
+	}
 }
@@ -0,0 +1,16 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins
+
+import (
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/persistence"
+)
+
+// RegisterPersistenceEngine registers a new persistence engine, selected with
+// SecPersistenceEngine. The factory gets the URL set in the directive, e.g. the
+// address of the database shared by the instances of the WAF.
+func RegisterPersistenceEngine(name string, engineFactory func(url string) (plugintypes.PersistenceEngine, error)) {
+	persistence.RegisterEngine(name, engineFactory)
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins_test
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/corazawaf/coraza/v3"
+	"github.com/corazawaf/coraza/v3/experimental/plugins"
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+// sharedEngine stands for a database shared by several instances, it ignores
+// the expirations
+type sharedEngine struct {
+	mu      sync.Mutex
+	records map[string]map[string]string
+}
+
+func (e *sharedEngine) Get(collection, record string) (map[string]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	res := map[string]string{}
+	for k, v := range e.records[collection+"/"+record] {
+		res[k] = v
+	}
+	return res, nil
+}
+
+func (e *sharedEngine) Set(collection, record, key, value string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.set(collection+"/"+record, key, value)
+	return nil
+}
+
+func (e *sharedEngine) Add(collection, record, key string, delta int) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	id := collection + "/" + record
+	n, _ := strconv.Atoi(e.records[id][key])
+	e.set(id, key, strconv.Itoa(n+delta))
+	return n + delta, nil
+}
+
+func (e *sharedEngine) Remove(collection, record, key string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.records[collection+"/"+record], key)
+	return nil
+}
+
+func (e *sharedEngine) Expire(string, string, string, time.Duration) error { return nil }
+
+func (e *sharedEngine) set(id, key, value string) {
+	if e.records[id] == nil {
+		e.records[id] = map[string]string{}
+	}
+	e.records[id][key] = value
+}
+
+// ExampleRegisterPersistenceEngine shows how to register a custom persistence
+// engine, shared by two WAFs counting the requests of a client.
+func ExampleRegisterPersistenceEngine() {
+	engine := &sharedEngine{records: map[string]map[string]string{}}
+	plugins.RegisterPersistenceEngine("shared", func(url string) (plugintypes.PersistenceEngine, error) {
+		return engine, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+			WithDirectives(`
+				SecPersistenceEngine shared db://localhost
+				SecAction "id:1,phase:1,pass,nolog,initcol:ip=%{REMOTE_ADDR},setvar:ip.requests=+1"
+			`))
+		if err != nil {
+			panic(err)
+		}
+
+		tx := waf.NewTransaction()
+		tx.ProcessConnection("10.0.0.1", 1234, "10.0.0.2", 80)
+		tx.ProcessRequestHeaders()
+		tx.Close()
+	}
+
+	vars, _ := engine.Get("IP", "10.0.0.1")
+	fmt.Println(vars["requests"])
+
+	// Output: 2
+}
@@ -0,0 +1,14 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package plugintypes
+
+import "context"
+
+// DNSResolver performs the DNS queries of operators such as @rbl, it is
+// implemented by *net.Resolver. It is shared by all the transactions, so it
+// must be safe for concurrent use.
+type DNSResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
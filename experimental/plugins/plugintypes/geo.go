@@ -0,0 +1,26 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package plugintypes
+
+import "net/netip"
+
+// GeoLocation is the location of an IP address. Fields missing in the
+// database are left empty.
+type GeoLocation struct {
+	CountryCode   string
+	CountryName   string
+	ContinentCode string
+	City          string
+	Latitude      float64
+	Longitude     float64
+}
+
+// GeoDatabase looks up the location of IP addresses, it is loaded once with the
+// configuration and shared by all the transactions, so it must be safe for
+// concurrent use.
+type GeoDatabase interface {
+	// Lookup returns the location of the address, false if the address is not
+	// in the database.
+	Lookup(ip netip.Addr) (GeoLocation, bool)
+}
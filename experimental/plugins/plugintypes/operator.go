@@ -3,9 +3,14 @@
 
 package plugintypes
 
-import "io/fs"
-
-// OperatorOptions is used to store the options for a rule operator
+import (
+	"io/fs"
+	"time"
+)
+
+// OperatorOptions is used to store the options for a rule operator. Besides the
+// operator arguments, it gives access to the WAF configuration parsed before the rule,
+// so operators can load their data when they are initialized.
 type OperatorOptions struct {
 	// Arguments is used to store the operator args
 	Arguments string
@@ -18,6 +23,26 @@ type OperatorOptions struct {
 
 	// Datasets contains input datasets or dictionaries
 	Datasets map[string][]string
+
+	// DataDir is the directory configured with SecDataDir to store persistent data
+	DataDir string
+
+	// TmpDir is the directory configured with SecTmpDir to store temporary files
+	TmpDir string
+
+	// UploadDir is the directory configured with SecUploadDir to store uploaded files
+	UploadDir string
+
+	// GeoDB is the database configured with SecGeoLookupDb, nil if none is configured
+	GeoDB GeoDatabase
+
+	// DNSResolver is the resolver of the operators querying the DNS, nil means
+	// net.DefaultResolver
+	DNSResolver DNSResolver
+
+	// DNSTimeout bounds the DNS queries of an evaluation, 0 means the default
+	// of the operator
+	DNSTimeout time.Duration
 }
 
 // Operator interface is used to define rule @operators
@@ -0,0 +1,33 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package plugintypes
+
+import "time"
+
+// PersistenceEngine stores the persistent collections (IP, GLOBAL, SESSION, USER
+// and RESOURCE) across transactions. A collection holds a record per key, e.g.
+// the record of the IP collection initialized with initcol:ip=%{REMOTE_ADDR} is
+// the client address. It is shared by all the transactions of a WAF, so it must
+// be safe for concurrent use.
+type PersistenceEngine interface {
+	// Get returns the variables of the record, expired variables are not
+	// returned. A missing record is returned empty.
+	Get(collection, record string) (map[string]string, error)
+
+	// Set sets a variable of the record, the expiration of an existing
+	// variable is kept.
+	Set(collection, record, key, value string) error
+
+	// Add adds delta to the integer value of a variable of the record and
+	// returns the result, a missing variable counts as 0 and the expiration
+	// of an existing one is kept. It must be atomic, so concurrent
+	// transactions incrementing the same variable do not lose updates.
+	Add(collection, record, key string, delta int) (int, error)
+
+	// Remove removes a variable of the record.
+	Remove(collection, record, key string) error
+
+	// Expire schedules the removal of a variable of the record after ttl.
+	Expire(collection, record, key string, ttl time.Duration) error
+}
@@ -0,0 +1,30 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package plugintypes
+
+import "context"
+
+// Tracer traces the evaluation of the transactions, it adapts a tracing
+// library so Coraza does not depend on it, e.g. experimental/oteltracing
+// adapts an OpenTelemetry tracer. It must be safe for concurrent use.
+type Tracer interface {
+	// Start starts a span as a child of the span of ctx, if any, and returns
+	// a context holding the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is an operation traced by a Tracer.
+type Span interface {
+	// SetStringAttribute sets an attribute with a string value.
+	SetStringAttribute(key, value string)
+
+	// SetIntAttribute sets an attribute with an integer value.
+	SetIntAttribute(key string, value int)
+
+	// SetBoolAttribute sets an attribute with a boolean value.
+	SetBoolAttribute(key string, value bool)
+
+	// End completes the span.
+	End()
+}
@@ -51,6 +51,9 @@ type TransactionVariables interface {
 	FilesCombinedSize() collection.Single
 	FullRequestLength() collection.Single
 	InboundDataError() collection.Single
+	ArgsLimitExceeded() collection.Single
+	RequestHeadersLimitExceeded() collection.Single
+	RequestCookiesLimitExceeded() collection.Single
 	MatchedVar() collection.Single
 	MatchedVarName() collection.Single
 	MultipartDataAfter() collection.Single
@@ -59,6 +62,8 @@ type TransactionVariables interface {
 	QueryString() collection.Single
 	RemoteAddr() collection.Single
 	RemoteHost() collection.Single
+	RemoteUser() collection.Single
+	AuthType() collection.Single
 	RemotePort() collection.Single
 	RequestBodyError() collection.Single
 	RequestBodyErrorMsg() collection.Single
@@ -67,6 +72,7 @@ type TransactionVariables interface {
 	RequestBodyProcessor() collection.Single
 	RequestBasename() collection.Single
 	RequestBody() collection.Single
+	RequestBodyRaw() collection.Single
 	RequestBodyLength() collection.Single
 	RequestFilename() collection.Single
 	RequestLine() collection.Single
@@ -75,6 +81,7 @@ type TransactionVariables interface {
 	RequestURI() collection.Single
 	RequestURIRaw() collection.Single
 	ResponseBody() collection.Single
+	ResponseBodyRaw() collection.Single
 	ResponseArgs() collection.Map
 	ResponseContentLength() collection.Single
 	ResponseProtocol() collection.Single
@@ -83,6 +90,7 @@ type TransactionVariables interface {
 	ServerAddr() collection.Single
 	ServerName() collection.Single
 	ServerPort() collection.Single
+	WebserverErrorLog() collection.Map
 	HighestSeverity() collection.Single
 	StatusLine() collection.Single
 	Env() collection.Map
@@ -95,10 +103,17 @@ type TransactionVariables interface {
 	ArgsPath() collection.Map
 	FilesTmpNames() collection.Map
 	Geo() collection.Map
+	IP() collection.Map
+	Global() collection.Map
+	Session() collection.Map
+	User() collection.Map
+	Resource() collection.Map
 	Files() collection.Map
 	RequestCookies() collection.Map
 	RequestHeaders() collection.Map
 	ResponseHeaders() collection.Map
+	RequestTrailers() collection.Map
+	ResponseTrailers() collection.Map
 	MultipartName() collection.Map
 	MatchedVarsNames() collection.Collection
 	MultipartFilename() collection.Map
@@ -116,4 +131,15 @@ type TransactionVariables interface {
 	ArgsGetNames() collection.Collection
 	ArgsPostNames() collection.Collection
 	MultipartStrictError() collection.Single
+	MultipartBoundaryQuoted() collection.Single
+	MultipartBoundaryWhitespace() collection.Single
+	MultipartCrlfLfLines() collection.Single
+	MultipartDataBefore() collection.Single
+	MultipartHeaderFolding() collection.Single
+	MultipartInvalidHeaderFolding() collection.Single
+	MultipartInvalidPart() collection.Single
+	MultipartInvalidQuoting() collection.Single
+	MultipartLfLine() collection.Single
+	MultipartMissingSemicolon() collection.Single
+	MultipartUnmatchedBoundary() collection.Single
 }
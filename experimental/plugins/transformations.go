@@ -8,8 +8,16 @@ import (
 	"github.com/corazawaf/coraza/v3/internal/transformations"
 )
 
-// RegisterTransformation registers a transformation by name
-// If the transformation is already registered, it will be overwritten
-func RegisterTransformation(name string, trans plugintypes.Transformation) {
-	transformations.Register(name, trans)
+// RegisterTransformation registers a transformation by name, names are
+// case-insensitive. Built-in transformations can be replaced, but an error is
+// returned if the name is empty, the transformation is nil or the name was
+// already registered by a plugin. It is safe for concurrent use.
+func RegisterTransformation(name string, trans plugintypes.Transformation) error {
+	return transformations.RegisterPlugin(name, trans)
+}
+
+// ListTransformations returns the sorted and lowercased names of the registered
+// transformations, including the ones registered by plugins
+func ListTransformations() []string {
+	return transformations.List()
 }
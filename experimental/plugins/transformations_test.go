@@ -4,22 +4,81 @@
 package plugins_test
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/corazawaf/coraza/v3"
 	"github.com/corazawaf/coraza/v3/experimental/plugins"
 	"github.com/corazawaf/coraza/v3/internal/transformations"
 )
 
+func reverse(input string) (string, bool, error) {
+	b := []byte(input)
+	slices.Reverse(b)
+	return string(b), len(b) > 1, nil
+}
+
 func TestTransformation(t *testing.T) {
 	t.Run("get existing transformation", func(t *testing.T) {
 		transformation := func(input string) (string, bool, error) {
 			return "", false, nil
 		}
 
-		plugins.RegisterTransformation("custom_transformation", transformation)
+		if err := plugins.RegisterTransformation("custom_transformation", transformation); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
 		_, err := transformations.GetTransformation("custom_transformation")
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
 	})
+
+	t.Run("list transformations", func(t *testing.T) {
+		if err := plugins.RegisterTransformation("customListedTransformation", reverse); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		names := plugins.ListTransformations()
+		for _, name := range []string{"lowercase", "customlistedtransformation"} {
+			if !slices.Contains(names, name) {
+				t.Errorf("expected %q to be listed", name)
+			}
+		}
+	})
+}
+
+func TestRegisterTransformationValidation(t *testing.T) {
+	if err := plugins.RegisterTransformation("", reverse); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if err := plugins.RegisterTransformation("nilTransformation", nil); err == nil {
+		t.Error("expected error for nil transformation")
+	}
+
+	if err := plugins.RegisterTransformation("duplicatedTransformation", reverse); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// names are case-insensitive
+	if err := plugins.RegisterTransformation(strings.ToUpper("duplicatedTransformation"), reverse); err == nil {
+		t.Error("expected error for duplicated name")
+	}
+}
+
+func TestRegisterTransformationUsedByParser(t *testing.T) {
+	if err := plugins.RegisterTransformation("parsedReverse", reverse); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+		WithDirectives(`SecRule ARGS:id "@streq nimda" "id:1,phase:1,t:parsedReverse,deny,status:403"`).
+		WithDirectives(`SecRuleEngine On`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.ProcessURI("/?id=admin", "GET", "HTTP/1.1")
+	if it := tx.ProcessRequestHeaders(); it == nil || it.RuleID != 1 {
+		t.Errorf("expected interruption after the registered transformation, have %v", it)
+	}
 }
@@ -0,0 +1,31 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package experimental
+
+import (
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/internal/seclang"
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+// FormatDirectives returns the SecLang directives in canonical form, to be
+// used by tools rewriting rule files. Every directive is written in a single
+// line with the chained rules indented, the variables and the operators are
+// normalized and the actions sorted: id, phase, the disruptive action, the
+// transformations, the logging actions and the metadata go first. Comments
+// are kept and the formatted directives are equivalent to the original ones.
+func FormatDirectives(directives string) (string, error) {
+	return seclang.Format(directives)
+}
+
+// FormatRule returns a rule parsed from SecLang, e.g. the one of a
+// types.MatchedRule, in the canonical form of FormatDirectives.
+func FormatRule(rule types.RuleMetadata) (string, error) {
+	formatted, err := seclang.Format(rule.Raw())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(formatted, "\n"), nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package experimental_test
+
+import (
+	"fmt"
+
+	"github.com/corazawaf/coraza/v3"
+	"github.com/corazawaf/coraza/v3/experimental"
+)
+
+func ExampleFormatDirectives() {
+	formatted, err := experimental.FormatDirectives(`
+SecRule args:id "\d+" "msg:'numeric id',t:lowercase,phase:1,id:1,deny,chain"
+SecRule REQUEST_METHOD "!@streq GET" "setvar:tx.score=+1"
+`)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(formatted)
+
+	// Output:
+	// SecRule ARGS:id "@rx \d+" "id:1,phase:1,deny,t:lowercase,msg:'numeric id',chain"
+	//     SecRule REQUEST_METHOD "!@streq GET" "setvar:'tx.score=+1'"
+}
+
+func ExampleFormatRule() {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+		WithDirectives(`SecRule ARGS:id "@eq 0" "log,pass,id:1,phase:1"`))
+	if err != nil {
+		panic(err)
+	}
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.AddGetRequestArgument("id", "0")
+	tx.ProcessRequestHeaders()
+
+	for _, mr := range tx.MatchedRules() {
+		formatted, err := experimental.FormatRule(mr.Rule())
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(formatted)
+	}
+
+	// Output:
+	// SecRule ARGS:id "@eq 0" "id:1,phase:1,pass,log"
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package experimental
+
+import (
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+// TransactionWithWebserverErrorLog is an interface that allows to feed the
+// web server error log of a transaction to the rules
+type TransactionWithWebserverErrorLog interface {
+	// AddWebserverErrorLog adds an entry of the web server error log related to the
+	// transaction, this will feed WEBSERVER_ERROR_LOG. Entries are expected to be
+	// added before calling ProcessLogging so they can be inspected by phase 5 rules.
+	AddWebserverErrorLog(line string)
+}
+
+// TransactionWithTrailers is an interface that allows to feed the trailer
+// fields of the request and the response of a transaction to the rules
+type TransactionWithTrailers interface {
+	// AddRequestTrailer adds a trailer field received after the request body, this will
+	// feed REQUEST_TRAILERS. Trailers are expected to be added before calling ProcessLogging
+	// so they can be inspected by phase 5 rules.
+	AddRequestTrailer(key string, value string)
+
+	// AddResponseTrailer adds a trailer field received after the response body, this will
+	// feed RESPONSE_TRAILERS. Trailers are expected to be added before calling ProcessLogging
+	// so they can be inspected by phase 5 rules.
+	AddResponseTrailer(key string, value string)
+}
+
+// TransactionWithEnvVariables is an interface that allows to read the
+// variables set by the setenv action of a transaction
+type TransactionWithEnvVariables interface {
+	// EnvVariables returns the variables set by the setenv action, so they
+	// can be propagated by the integration, e.g. as backend request headers.
+	EnvVariables() map[string]string
+}
+
+// TransactionWithEvaluationError is an interface that allows to know why the
+// evaluation of the rules of a transaction was aborted
+type TransactionWithEvaluationError interface {
+	// EvaluationError returns why the evaluation of a phase was aborted, the rules
+	// left in the phase were not evaluated: types.ErrRuleEvaluationTimeout when the
+	// phase exceeded SecRuleEvaluationTimeout, or the error of the context of the
+	// transaction. It returns nil if the evaluation of every phase completed.
+	EvaluationError() error
+}
+
+var (
+	_ TransactionWithWebserverErrorLog = (*corazawaf.Transaction)(nil)
+	_ TransactionWithTrailers          = (*corazawaf.Transaction)(nil)
+	_ TransactionWithEnvVariables      = (*corazawaf.Transaction)(nil)
+	_ TransactionWithEvaluationError   = (*corazawaf.Transaction)(nil)
+)
@@ -0,0 +1,65 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package experimental_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/corazawaf/coraza/v3"
+	"github.com/corazawaf/coraza/v3/experimental"
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+func ExampleTransactionWithEnvVariables_EnvVariables() {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+		WithDirectives(`SecRule REQUEST_HEADERS:X-Client "@rx ^(\w+)$" "id:1,phase:1,pass,nolog,capture,setenv:client=%{tx.1}"`))
+	if err != nil {
+		panic(err)
+	}
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.AddRequestHeader("X-Client", "mobile")
+	tx.ProcessRequestHeaders()
+
+	eTx, ok := tx.(experimental.TransactionWithEnvVariables)
+	if !ok {
+		panic("transaction does not implement TransactionWithEnvVariables")
+	}
+	fmt.Println(eTx.EnvVariables()["client"])
+
+	// Output:
+	// mobile
+}
+
+func ExampleTransactionWithEvaluationError_EvaluationError() {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+		WithDirectives(`
+SecRuleEngine DetectionOnly
+SecRule ARGS:id "@eq 0" "id:1,phase:1,deny,status:403"
+`))
+	if err != nil {
+		panic(err)
+	}
+
+	// the client hung up before the rules were evaluated
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tx := waf.(experimental.WAFWithOptions).NewTransactionWithOptions(experimental.Options{Context: ctx})
+	defer tx.Close()
+	tx.AddGetRequestArgument("id", "0")
+	tx.ProcessRequestHeaders()
+
+	eTx, ok := tx.(experimental.TransactionWithEvaluationError)
+	if !ok {
+		panic("transaction does not implement TransactionWithEvaluationError")
+	}
+	err = eTx.EvaluationError()
+	fmt.Println(errors.Is(err, context.Canceled), errors.Is(err, types.ErrRuleEvaluationTimeout))
+
+	// Output:
+	// true false
+}
@@ -4,6 +4,7 @@
 package experimental
 
 import (
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
 	"github.com/corazawaf/coraza/v3/types"
 )
@@ -15,3 +16,66 @@ type Options = corazawaf.Options
 type WAFWithOptions interface {
 	NewTransactionWithOptions(Options) types.Transaction
 }
+
+type MatchEvent = corazawaf.MatchEvent
+
+// WAFWithMatchEvents is an interface that allows to subscribe to the
+// rule matches of all the transactions of a WAF
+type WAFWithMatchEvents interface {
+	// Subscribe returns a channel receiving an event for every rule match,
+	// buffered up to size events. Events are dropped when the buffer is full
+	// so a slow consumer never blocks the transactions. The returned function
+	// unsubscribes and closes the channel.
+	Subscribe(size int) (<-chan MatchEvent, func())
+}
+
+// WAFWithRuleMatchCallback is an interface that allows to be notified
+// synchronously of the rule matches of all the transactions of a WAF
+type WAFWithRuleMatchCallback interface {
+	// SetRuleMatchCallback sets a function called every time a rule matches,
+	// during the evaluation of the phase. It must be set before creating
+	// transactions, the callback delays the transaction so it should return
+	// fast.
+	SetRuleMatchCallback(cb func(MatchEvent))
+}
+
+// WAFWithTracing is an interface that allows to trace the transactions of a
+// WAF, e.g. with OpenTelemetry using experimental/oteltracing
+type WAFWithTracing interface {
+	// SetTracer sets the tracer used to emit a span for every phase of the
+	// transactions, with the transaction id, the number of rules evaluated and
+	// whether the transaction was interrupted. The operators evaluated on large
+	// values get a child span. The spans are children of the span of the
+	// context set with Options.Context. Tracing is disabled by default, it
+	// must be set before creating transactions.
+	SetTracer(tracer plugintypes.Tracer)
+}
+
+// WAFWithRuleToggle is an interface that allows to enable and disable
+// rules at runtime, without reloading the WAF
+type WAFWithRuleToggle interface {
+	// SetRuleEnabled enables or disables the rule with the given id. Disabled
+	// rules are skipped by all the transactions, starting from their next
+	// phase. It returns an error if there is no rule with the given id.
+	SetRuleEnabled(id int, enabled bool) error
+	// IsRuleEnabled returns false if the rule with the given id is disabled.
+	IsRuleEnabled(id int) bool
+}
+
+// WAFWithRuleStats is an interface that allows to retrieve the number of
+// matches of the rules of a WAF
+type WAFWithRuleStats interface {
+	// RuleStats returns the number of times each rule matched, by rule id.
+	// Rules that never matched are included with a count of 0.
+	RuleStats() map[int]uint64
+}
+
+// WAFWithSampleSeed is an interface that allows to make the sampling
+// decisions of a WAF reproducible
+type WAFWithSampleSeed interface {
+	// SetSampleSeed seeds the random source consulted by the sampling
+	// decisions, like the evaluation of the rules using the sample action.
+	// By default it is seeded randomly. With a fixed seed the decisions are
+	// the same as long as the transactions are evaluated in the same order.
+	SetSampleSeed(seed int64)
+}
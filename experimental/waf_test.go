@@ -30,3 +30,133 @@ func ExampleWAFWithOptions_NewTransactionWithOptions() {
 	// Output:
 	// Transaction ID: abc123
 }
+
+func ExampleWAFWithMatchEvents_Subscribe() {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+		WithDirectives(`SecRule ARGS_GET:id "@eq 0" "id:1,phase:1,pass,nolog"`))
+	if err != nil {
+		panic(err)
+	}
+
+	eWAF, ok := waf.(experimental.WAFWithMatchEvents)
+	if !ok {
+		panic("WAF does not implement WAFWithMatchEvents")
+	}
+
+	events, unsubscribe := eWAF.Subscribe(10)
+	defer unsubscribe()
+
+	tx := waf.NewTransactionWithID("abc123")
+	tx.AddGetRequestArgument("id", "0")
+	tx.ProcessRequestHeaders()
+	_ = tx.Close()
+
+	e := <-events
+	fmt.Println(e.RuleID, e.TransactionID, e.MatchedVar)
+
+	// Output:
+	// 1 abc123 ARGS_GET:id
+}
+
+func ExampleWAFWithRuleMatchCallback_SetRuleMatchCallback() {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+		WithDirectives(`
+SecRuleEngine On
+SecRule ARGS_GET:id "@eq 0" "id:1,phase:1,pass,nolog"
+SecRule ARGS_GET:id "@eq 0" "id:2,phase:1,deny,status:403"
+`))
+	if err != nil {
+		panic(err)
+	}
+
+	cWAF, ok := waf.(experimental.WAFWithRuleMatchCallback)
+	if !ok {
+		panic("WAF does not implement WAFWithRuleMatchCallback")
+	}
+
+	cWAF.SetRuleMatchCallback(func(e experimental.MatchEvent) {
+		fmt.Println(e.RuleID, e.Phase, e.MatchedVar, e.Disruptive, e.Status)
+	})
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.AddGetRequestArgument("id", "0")
+	tx.ProcessRequestHeaders()
+
+	// Output:
+	// 1 1 ARGS_GET:id true 0
+	// 2 1 ARGS_GET:id true 403
+}
+
+func ExampleWAFWithRuleToggle_SetRuleEnabled() {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+		WithDirectives(`SecRule ARGS:id "@eq 0" "id:1,phase:1,deny,status:403"`))
+	if err != nil {
+		panic(err)
+	}
+
+	tWAF, ok := waf.(experimental.WAFWithRuleToggle)
+	if !ok {
+		panic("WAF does not implement WAFWithRuleToggle")
+	}
+
+	process := func() {
+		tx := waf.NewTransaction()
+		defer tx.Close()
+		tx.AddGetRequestArgument("id", "0")
+		fmt.Println(tx.ProcessRequestHeaders() != nil)
+	}
+
+	process()
+	if err := tWAF.SetRuleEnabled(1, false); err != nil {
+		panic(err)
+	}
+	process()
+	if err := tWAF.SetRuleEnabled(1, true); err != nil {
+		panic(err)
+	}
+	process()
+
+	// Output:
+	// true
+	// false
+	// true
+}
+
+func ExampleWAFWithSampleSeed_SetSampleSeed() {
+	sampled := func() string {
+		waf, err := coraza.NewWAF(coraza.NewWAFConfig().
+			WithDirectives(`SecRule ARGS:id "@eq 0" "id:1,phase:1,sample:50,pass,log"`))
+		if err != nil {
+			panic(err)
+		}
+
+		sWAF, ok := waf.(experimental.WAFWithSampleSeed)
+		if !ok {
+			panic("WAF does not implement WAFWithSampleSeed")
+		}
+		sWAF.SetSampleSeed(42)
+
+		res := ""
+		for i := 0; i < 20; i++ {
+			tx := waf.NewTransaction()
+			tx.AddGetRequestArgument("id", "0")
+			tx.ProcessRequestHeaders()
+			if len(tx.MatchedRules()) > 0 {
+				res += "x"
+			} else {
+				res += "."
+			}
+			tx.Close()
+		}
+		return res
+	}
+
+	// the same seed samples the same transactions
+	fmt.Println(sampled())
+	fmt.Println(sampled())
+
+	// Output:
+	// x...xx..xxx.xx...xxx
+	// x...xx..xxx.xx...xxx
+}
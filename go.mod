@@ -5,6 +5,7 @@ go 1.23.0
 // Testing dependencies:
 // - go-mockdns
 // - go-modsecurity (optional)
+// - opentelemetry-go sdk
 
 // Development dependencies:
 // - mage
@@ -15,6 +16,11 @@ go 1.23.0
 // - gjson
 // - binaryregexp
 // - ocsf-schema-golang
+// - maxminddb-golang
+// - gopher-lua
+
+// Optional dependencies, only for the importers of experimental/oteltracing:
+// - opentelemetry-go trace
 
 require (
 	github.com/anuraaga/go-modsecurity v0.0.0-20220824035035-b9a4099778df
@@ -24,9 +30,14 @@ require (
 	github.com/jcchavezs/mergefs v0.1.0
 	github.com/magefile/mage v1.15.1-0.20241126214340-bdc92f694516
 	github.com/mccutchen/go-httpbin/v2 v2.18.0
+	github.com/oschwald/maxminddb-golang v1.13.1
 	github.com/petar-dambovaliev/aho-corasick v0.0.0-20240411101913-e07a1f0e8eb4
 	github.com/tidwall/gjson v1.18.0
 	github.com/valllabh/ocsf-schema-golang v1.0.3
+	github.com/yuin/gopher-lua v1.1.1
+	go.opentelemetry.io/otel v1.34.0
+	go.opentelemetry.io/otel/sdk v1.34.0
+	go.opentelemetry.io/otel/trace v1.34.0
 	golang.org/x/net v0.38.0
 	golang.org/x/sync v0.12.0
 	rsc.io/binaryregexp v0.2.0
@@ -34,11 +45,15 @@ require (
 
 require (
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/miekg/dns v1.1.57 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.1 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.34.0 // indirect
 	golang.org/x/mod v0.18.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
 	golang.org/x/tools v0.22.0 // indirect
@@ -12,6 +12,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/corazawaf/coraza/v3/types"
 )
@@ -44,9 +45,10 @@ func (i *rwInterceptor) WriteHeader(statusCode int) {
 	i.statusCode = statusCode
 	if it := i.tx.ProcessResponseHeaders(statusCode, i.proto); it != nil {
 		i.cleanHeaders()
-		i.Header().Set("Content-Length", "0")
+		setInterruptionHeaders(i.Header(), it)
 		i.statusCode = obtainStatusCodeFromInterruptionOrDefault(it, i.statusCode)
 		i.flushWriteHeader()
+		_ = writeInterruptionBody(i.w, it)
 		return
 	}
 
@@ -102,10 +104,11 @@ func (i *rwInterceptor) Write(b []byte) (int, error) {
 		if it != nil {
 			// if there is an interruption we must clean the headers and override the status code
 			i.cleanHeaders()
-			i.Header().Set("Content-Length", "0")
+			setInterruptionHeaders(i.Header(), it)
 			i.overrideWriteHeader(obtainStatusCodeFromInterruptionOrDefault(it, i.statusCode))
 			// We only flush the status code after an interruption.
 			i.flushWriteHeader()
+			_ = writeInterruptionBody(i.w, it)
 			// We return the number of bytes as according to the interface io.Writer
 			// if we don't return an error, the number of bytes written is len(p).
 			// See https://pkg.go.dev/io#Writer
@@ -173,10 +176,10 @@ func wrap(w http.ResponseWriter, r *http.Request, tx types.Transaction) (
 			} else if it != nil {
 				// if there is an interruption we must clean the headers and override the status code
 				i.cleanHeaders()
-				i.Header().Set("Content-Length", "0")
+				setInterruptionHeaders(i.Header(), it)
 				i.overrideWriteHeader(obtainStatusCodeFromInterruptionOrDefault(it, i.statusCode))
 				i.flushWriteHeader()
-				return nil
+				return writeInterruptionBody(i.w, it)
 			}
 
 			// we release the buffer
@@ -187,6 +190,16 @@ func wrap(w http.ResponseWriter, r *http.Request, tx types.Transaction) (
 				return fmt.Errorf("failed to release the response body reader: %v", err)
 			}
 
+			// the rules may have modified the body, e.g. with the append action, hence
+			// the length set by the handler is replaced by the one of the buffered body.
+			if h := i.w.Header(); h.Get("Content-Length") != "" {
+				if l, ok := reader.(interface{ Len() int }); ok {
+					h.Set("Content-Length", strconv.Itoa(l.Len()))
+				} else {
+					h.Del("Content-Length")
+				}
+			}
+
 			// this is the last opportunity we have to report the resolved status code
 			// as next step is write into the response writer (triggering a 200 in the
 			// response status code.)
@@ -39,7 +39,13 @@ func processRequest(tx types.Transaction, req *http.Request) (*types.Interruptio
 	var in *types.Interruption
 	// There is no socket access in the request object, so we neither know the server client nor port.
 	tx.ProcessConnection(client, cport, "", 0)
-	tx.ProcessURI(req.URL.String(), req.Method, req.Proto)
+	// RequestURI is the unmodified request-target of the request line, URL.String() would
+	// escape some characters again. It is empty for requests not received by a server.
+	uri := req.RequestURI
+	if uri == "" {
+		uri = req.URL.String()
+	}
+	tx.ProcessURI(uri, req.Method, req.Proto)
 	for k, vr := range req.Header {
 		for _, v := range vr {
 			tx.AddRequestHeader(k, v)
@@ -139,7 +145,11 @@ func WrapHandler(waf coraza.WAF, h http.Handler) http.Handler {
 			tx.DebugLogger().Error().Err(err).Msg("Failed to process request")
 			return
 		} else if it != nil {
+			setInterruptionHeaders(w.Header(), it)
 			w.WriteHeader(obtainStatusCodeFromInterruptionOrDefault(it, http.StatusOK))
+			if err := writeInterruptionBody(w, it); err != nil {
+				tx.DebugLogger().Error().Err(err).Msg("Failed to write the interruption body")
+			}
 			return
 		}
 
@@ -170,3 +180,21 @@ func obtainStatusCodeFromInterruptionOrDefault(it *types.Interruption, defaultSt
 	}
 	return defaultStatusCode
 }
+
+// setInterruptionHeaders sets the headers for the block page carried by the
+// interruption (see SecDefaultBlockPage), if any.
+func setInterruptionHeaders(h http.Header, it *types.Interruption) {
+	h.Set("Content-Length", strconv.Itoa(len(it.Body)))
+	if it.Body != "" {
+		h.Set("Content-Type", it.ContentType)
+	}
+}
+
+// writeInterruptionBody writes the block page carried by the interruption, if any.
+func writeInterruptionBody(w io.Writer, it *types.Interruption) error {
+	if it.Body == "" {
+		return nil
+	}
+	_, err := io.WriteString(w, it.Body)
+	return err
+}
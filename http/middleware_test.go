@@ -109,6 +109,36 @@ SecRule &REQUEST_HEADERS:Transfer-Encoding "!@eq 0" "id:1,phase:1,deny"
 	}
 }
 
+func TestProcessRequestRequestLine(t *testing.T) {
+	waf, _ := coraza.NewWAF(coraza.NewWAFConfig().
+		WithDirectives(`
+SecRule REQUEST_LINE "@rx ^PATCH /api/<b>/1\?x=1 HTTP/1\.1$" "id:1,phase:1,pass,log"
+SecRule REQUEST_METHOD "@streq PATCH" "id:2,phase:1,pass,log"
+SecRule REQUEST_PROTOCOL "@streq HTTP/1.1" "id:3,phase:1,pass,log"
+SecRule REQUEST_URI_RAW "@streq /api/<b>/1?x=1" "id:4,phase:1,pass,log"
+`))
+	tx := waf.NewTransaction()
+
+	// the request-target is kept as received, URL.String() would escape it
+	req := httptest.NewRequest("PATCH", "/api/<b>/1?x=1", nil)
+
+	if _, err := processRequest(tx, req); err != nil {
+		t.Fatal(err)
+	}
+	matched := map[int]bool{}
+	for _, mr := range tx.MatchedRules() {
+		matched[mr.Rule().ID()] = true
+	}
+	for _, id := range []int{1, 2, 3, 4} {
+		if !matched[id] {
+			t.Errorf("expected rule %d to match", id)
+		}
+	}
+	if err := tx.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func createMultipartRequest(t *testing.T) *http.Request {
 	t.Helper()
 
@@ -643,3 +673,122 @@ func TestHandlerAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerDefaultBlockPage(t *testing.T) {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().WithDirectives(`
+SecRuleEngine On
+SecDefaultBlockPage html "<html><body>blocked</body></html>"
+SecDefaultBlockPage json '{"error":"blocked"}'
+SecRule ARGS:id "@eq 0" "id:1,phase:1,deny,status:403"
+`))
+	if err != nil {
+		t.Fatalf("unexpected error while creating the WAF: %s", err.Error())
+	}
+
+	testCases := map[string]struct {
+		accept              string
+		expectedContentType string
+		expectedBody        string
+	}{
+		"html": {
+			accept:              "text/html",
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<html><body>blocked</body></html>",
+		},
+		"json": {
+			accept:              "application/json",
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"blocked"}`,
+		},
+	}
+
+	srv := httptest.NewServer(WrapHandler(waf, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected call to the handler")
+	})))
+	defer srv.Close()
+
+	for name, tCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", srv.URL+"/?id=0", nil)
+			req.Header.Set("Accept", tCase.accept)
+			res, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error while performing the request: %s", err.Error())
+			}
+			defer res.Body.Close()
+
+			if want, have := 403, res.StatusCode; want != have {
+				t.Errorf("unexpected status code, want: %d, have: %d", want, have)
+			}
+			if want, have := tCase.expectedContentType, res.Header.Get("Content-Type"); want != have {
+				t.Errorf("unexpected content type, want: %q, have: %q", want, have)
+			}
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("unexpected error when reading the response body: %v", err)
+			}
+			if want, have := tCase.expectedBody, string(body); want != have {
+				t.Errorf("unexpected response body, want: %q, have: %q", want, have)
+			}
+		})
+	}
+}
+
+func TestHandlerModifiedResponseBody(t *testing.T) {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().WithDirectives(`
+SecRuleEngine On
+SecResponseBodyAccess On
+SecResponseBodyMimeType text/plain
+SecAction "id:1,phase:4,pass,nolog,append:' footer'"
+`))
+	if err != nil {
+		t.Fatalf("unexpected error while creating the WAF: %s", err.Error())
+	}
+
+	testCases := map[string]struct {
+		encoding     string
+		expectedBody string
+	}{
+		"plain": {
+			expectedBody: "body footer",
+		},
+		"encoded": {
+			encoding:     "gzip",
+			expectedBody: "body",
+		},
+	}
+
+	for name, tCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(WrapHandler(waf, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("Content-Length", "4")
+				if tCase.encoding != "" {
+					w.Header().Set("Content-Encoding", tCase.encoding)
+				}
+				_, _ = w.Write([]byte("body"))
+			})))
+			defer srv.Close()
+
+			req, _ := http.NewRequest("GET", srv.URL, nil)
+			// the transport would otherwise try to decompress the fake gzip body
+			req.Header.Set("Accept-Encoding", "identity")
+			res, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error while performing the request: %s", err.Error())
+			}
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("unexpected error while reading the body: %s", err.Error())
+			}
+			if want, have := tCase.expectedBody, string(body); want != have {
+				t.Errorf("unexpected body, want: %q, have: %q", want, have)
+			}
+			if want, have := strconv.Itoa(len(tCase.expectedBody)), res.Header.Get("Content-Length"); want != have {
+				t.Errorf("unexpected content length, want: %q, have: %q", want, have)
+			}
+		})
+	}
+}
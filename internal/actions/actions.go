@@ -6,6 +6,7 @@ package actions
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
@@ -34,6 +35,7 @@ func Register(name string, a func() plugintypes.Action) {
 
 func init() {
 	Register("allow", allow)
+	Register("append", appendAction)
 	Register("auditlog", auditlog)
 	Register("block", block)
 	Register("capture", capture)
@@ -53,9 +55,17 @@ func init() {
 	Register("noauditlog", noauditlog)
 	Register("nolog", nolog)
 	Register("pass", pass)
+	Register("pause", pause)
 	Register("phase", phase)
+	Register("prepend", prepend)
 	Register("redirect", redirect)
 	Register("rev", rev)
+	Register("sample", sample)
+	Register("sanitiseArg", sanitiseArg)
+	Register("sanitiseMatched", sanitiseMatched)
+	Register("sanitiseMatchedBytes", sanitiseMatchedBytes)
+	Register("sanitiseRequestHeader", sanitiseRequestHeader)
+	Register("sanitiseResponseHeader", sanitiseResponseHeader)
 	Register("setenv", setenv)
 	Register("setvar", setvar)
 	Register("severity", severity)
@@ -76,3 +86,14 @@ func Get(name string) (plugintypes.Action, error) {
 	}
 	return nil, fmt.Errorf("invalid action %q", name)
 }
+
+// List returns the sorted names of the registered actions.
+// Names are lowercased, as actions are case-insensitive.
+func List() []string {
+	names := make([]string, 0, len(actionmap))
+	for name := range actionmap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,25 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	names := List()
+	if len(names) != len(actionmap) {
+		t.Fatalf("unexpected number of actions, want %d, have %d", len(actionmap), len(names))
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("expected sorted names, have %v", names)
+	}
+	for _, name := range []string{"block", "id", "multimatch", "setvar", "t"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("expected %q to be listed", name)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 // (The logging phase is special; it is designed to be always execute.)
 // - Using with parameter `phase`: the engine will stop processing the current phase, and the other phases will continue.
 // - Using with parameter `request`: engine will stop processing the current phase, and the next phase to be processed will be phase `types.PhaseResponseHeaders`.
+// When used in a response phase, it only stops processing the current phase.
 //
 // Example:
 // ```
@@ -0,0 +1,92 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/macro"
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+// Action Group: Non-disruptive
+//
+// Description:
+// Appends text given as parameter to the end of the response body.
+// The parameter supports macro expansion. This action only works in phase 4
+// and requires the response body to be buffered (SecResponseBodyAccess On),
+// otherwise it is ignored. Encoded response bodies, e.g. with
+// `Content-Encoding: gzip`, are not modified.
+//
+// Example:
+// ```
+// SecRule RESPONSE_CONTENT_TYPE "^text/html" "nolog,id:99,pass,phase:4,append:'<hr>Footer'"
+// ```
+type appendFn struct {
+	data macro.Macro
+}
+
+func (a *appendFn) Init(_ plugintypes.RuleMetadata, data string) error {
+	if len(data) == 0 {
+		return ErrMissingArguments
+	}
+
+	m, err := macro.NewMacro(data)
+	if err != nil {
+		return err
+	}
+	a.data = m
+	return nil
+}
+
+func (a *appendFn) Evaluate(r plugintypes.RuleMetadata, txS plugintypes.TransactionState) {
+	tx := txS.(*corazawaf.Transaction)
+	modifyResponseBody(r, tx, "append", func() error {
+		return tx.AppendResponseBody([]byte(a.data.Expand(tx)))
+	})
+}
+
+func (a *appendFn) Type() plugintypes.ActionType {
+	return plugintypes.ActionTypeNondisruptive
+}
+
+func appendAction() plugintypes.Action {
+	return &appendFn{}
+}
+
+// modifyResponseBody runs modify, which adds data to the response body, if the
+// body can be modified in the current phase and it is not encoded, as the data
+// would corrupt it.
+func modifyResponseBody(r plugintypes.RuleMetadata, tx *corazawaf.Transaction, action string, modify func() error) {
+	if tx.LastPhase() != types.PhaseResponseBody || !tx.ResponseBodyAccess {
+		tx.DebugLogger().Warn().
+			Int("rule_id", r.ID()).
+			Str("action", action).
+			Msg("Response body can only be modified in phase 4 with response body access enabled")
+		return
+	}
+	for _, encoding := range tx.Variables().ResponseHeaders().Get("content-encoding") {
+		if e := strings.TrimSpace(encoding); e != "" && !strings.EqualFold(e, "identity") {
+			tx.DebugLogger().Warn().
+				Int("rule_id", r.ID()).
+				Str("action", action).
+				Str("content_encoding", encoding).
+				Msg("Skipping the modification of an encoded response body")
+			return
+		}
+	}
+	if err := modify(); err != nil {
+		tx.DebugLogger().Error().Err(err).
+			Int("rule_id", r.ID()).
+			Str("action", action).
+			Msg("Failed to modify the response body")
+	}
+}
+
+var (
+	_ plugintypes.Action = &appendFn{}
+	_ ruleActionWrapper  = appendAction
+)
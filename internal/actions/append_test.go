@@ -0,0 +1,122 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+func TestAppendInit(t *testing.T) {
+	t.Run("no arguments", func(t *testing.T) {
+		a := appendAction()
+		if err := a.Init(nil, ""); err != ErrMissingArguments {
+			t.Error("expected error ErrMissingArguments")
+		}
+	})
+
+	t.Run("invalid macro", func(t *testing.T) {
+		a := appendAction()
+		if err := a.Init(nil, "%{tx.missing"); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestAppendAndPrependResponseBody(t *testing.T) {
+	tests := map[string]struct {
+		phase        types.RulePhase
+		encoding     string
+		expectedBody string
+	}{
+		"phase 4": {
+			phase:        types.PhaseResponseBody,
+			expectedBody: "<b>header</b><p>body</p><i>footer 200</i>",
+		},
+		"phase 4 identity encoding": {
+			phase:        types.PhaseResponseBody,
+			encoding:     "identity",
+			expectedBody: "<b>header</b><p>body</p><i>footer 200</i>",
+		},
+		"phase 3 is ignored": {
+			phase:        types.PhaseResponseHeaders,
+			expectedBody: "<p>body</p>",
+		},
+		"encoded body is ignored": {
+			phase:        types.PhaseResponseBody,
+			encoding:     "gzip",
+			expectedBody: "<p>body</p>",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			waf.ResponseBodyAccess = true
+
+			r := corazawaf.NewRule()
+			r.ID_ = 1
+			r.Phase_ = tt.phase
+			pa := prepend()
+			if err := pa.Init(r, "<b>header</b>"); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.AddAction("prepend", pa); err != nil {
+				t.Fatal(err)
+			}
+			aa := appendAction()
+			if err := aa.Init(r, "<i>footer %{response_status}</i>"); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.AddAction("append", aa); err != nil {
+				t.Fatal(err)
+			}
+			if err := waf.Rules.Add(r); err != nil {
+				t.Fatal(err)
+			}
+
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.ProcessRequestHeaders()
+			if _, err := tx.ProcessRequestBody(); err != nil {
+				t.Fatal(err)
+			}
+			tx.AddResponseHeader("Content-Type", "text/html")
+			if tt.encoding != "" {
+				tx.AddResponseHeader("Content-Encoding", tt.encoding)
+			}
+			tx.ProcessResponseHeaders(200, "HTTP/1.1")
+			if _, _, err := tx.WriteResponseBody([]byte("<p>body</p>")); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tx.ProcessResponseBody(); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := tx.ResponseBodyReader()
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, have := tt.expectedBody, string(body); want != have {
+				t.Errorf("unexpected body, want %q, have %q", want, have)
+			}
+			if tt.expectedBody != "<p>body</p>" {
+				if want, have := "41", tx.Variables().ResponseContentLength().Get(); want != have {
+					t.Errorf("unexpected content length, want %q, have %q", want, have)
+				}
+			}
+			if strings.Contains(string(body), "%{") {
+				t.Error("unexpected non expanded macro")
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package actions
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -13,7 +14,7 @@ import (
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	"github.com/corazawaf/coraza/v3/internal/collections"
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
-	utils "github.com/corazawaf/coraza/v3/internal/strings"
+	"github.com/corazawaf/coraza/v3/internal/memoize"
 	"github.com/corazawaf/coraza/v3/types"
 	"github.com/corazawaf/coraza/v3/types/variables"
 )
@@ -74,6 +75,7 @@ const (
 // Here are some notes about the options:
 //
 //  1. Option `ruleRemoveTargetById`, `ruleRemoveTargetByMsg`, and `ruleRemoveTargetByTag`, users don't need to use the char ! before the target list.
+//     The target key can be a regular expression, e.g. `ARGS:/^json\.\d+\.password$/`, matched against the lowercased key.
 //
 //  2. Option `ruleRemoveById` is triggered at run time and should be specified before the rule in which it is disabling.
 //
@@ -99,6 +101,11 @@ const (
 //		SecRule REQUEST_URI "@beginsWith /index.php" "phase:1,t:none,pass,\
 //	 	nolog,ctl:ruleRemoveTargetById=981260;ARGS:user"
 //
+// # do not inspect the password parameter with the SQL injection rules
+//
+//		SecRule REQUEST_URI "@beginsWith /login" "phase:1,t:none,pass,\
+//	 	nolog,id:107,ctl:ruleRemoveTargetByTag=attack-sqli;ARGS:password"
+//
 // ```
 type ctlFn struct {
 	action     ctlFunctionType
@@ -142,11 +149,8 @@ func (a *ctlFn) Evaluate(_ plugintypes.RuleMetadata, txS plugintypes.Transaction
 			tx.RemoveRuleTargetByID(id, a.collection, a.colKey)
 		}
 	case ctlRuleRemoveTargetByTag:
-		rules := tx.WAF.Rules.GetRules()
-		for _, r := range rules {
-			if utils.InSlice(a.value, r.Tags_) {
-				tx.RemoveRuleTargetByID(r.ID(), a.collection, a.colKey)
-			}
+		for _, r := range tx.WAF.Rules.RulesByTag(a.value) {
+			tx.RemoveRuleTargetByID(r.ID(), a.collection, a.colKey)
 		}
 	case ctlRuleRemoveTargetByMsg:
 		rules := tx.WAF.Rules.GetRules()
@@ -233,6 +237,8 @@ func (a *ctlFn) Evaluate(_ plugintypes.RuleMetadata, txS plugintypes.Transaction
 		} else {
 			tx.DebugLogger().Warn().
 				Str("ctl", "RequestBodyProcessor").
+				Str("value", a.value).
+				Int("phase", int(tx.LastPhase())).
 				Msg("Cannot change request body processor after request headers phase")
 		}
 	case ctlRuleEngine:
@@ -280,11 +286,8 @@ func (a *ctlFn) Evaluate(_ plugintypes.RuleMetadata, txS plugintypes.Transaction
 			}
 		}
 	case ctlRuleRemoveByTag:
-		rules := tx.WAF.Rules.GetRules()
-		for _, r := range rules {
-			if utils.InSlice(a.value, r.Tags_) {
-				tx.RemoveRuleByID(r.ID_)
-			}
+		for _, r := range tx.WAF.Rules.RulesByTag(a.value) {
+			tx.RemoveRuleByID(r.ID_)
 		}
 
 	case ctlResponseBodyAccess:
@@ -348,8 +351,10 @@ func (a *ctlFn) Evaluate(_ plugintypes.RuleMetadata, txS plugintypes.Transaction
 			tx.Variables().ResponseBodyProcessor().(*collections.Single).Set(strings.ToUpper(a.value))
 		} else {
 			tx.DebugLogger().Warn().
-				Str("ctl", "ResponseBodyLimit").
-				Msg("Cannot change response body access after response headers phase")
+				Str("ctl", "ResponseBodyProcessor").
+				Str("value", a.value).
+				Int("phase", int(tx.LastPhase())).
+				Msg("Cannot change response body processor after response headers phase")
 			return
 		}
 	case ctlHashEngine:
@@ -386,7 +391,15 @@ func parseCtl(data string) (ctlFunctionType, string, variables.RuleVariable, str
 		colname, colkey, _ = strings.Cut(col, ":")
 	}
 	collection, _ := variables.Parse(strings.TrimSpace(colname))
-	colkey = strings.ToLower(colkey)
+	if len(colkey) > 2 && colkey[0] == '/' && colkey[len(colkey)-1] == '/' {
+		// regular expressions are not lowercased, e.g. \D would turn into \d
+		rx := colkey[1 : len(colkey)-1]
+		if _, err := memoize.DoBounded(rx, func() (interface{}, error) { return regexp.Compile(rx) }); err != nil {
+			return ctlUnknown, "", 0, "", fmt.Errorf("invalid target key %q: %s", colkey, err.Error())
+		}
+	} else {
+		colkey = strings.ToLower(colkey)
+	}
 	var act ctlFunctionType
 	switch action {
 	case "auditEngine":
@@ -111,6 +111,9 @@ func TestCtl(t *testing.T) {
 				if wantToContain, have := "[WARN] Cannot change request body processor after request headers phase", logEntry; !strings.Contains(have, wantToContain) {
 					t.Errorf("Failed to log entry, want to contain %q, have %q", wantToContain, have)
 				}
+				if have := tx.Variables().RequestBodyProcessor().Get(); have == "JSON" {
+					t.Errorf("Unexpected change of requestBodyProcessor after request headers phase, have %s", have)
+				}
 			},
 		},
 		"requestBodyProcessor successfully": {
@@ -289,7 +292,7 @@ func TestCtl(t *testing.T) {
 			},
 			input: "responseBodyProcessor=XML",
 			checkTX: func(t *testing.T, tx *corazawaf.Transaction, logEntry string) {
-				if wantToContain, have := "[WARN] Cannot change response body access after response headers phase", logEntry; !strings.Contains(have, wantToContain) {
+				if wantToContain, have := "[WARN] Cannot change response body processor after response headers phase", logEntry; !strings.Contains(have, wantToContain) {
 					t.Errorf("Failed to log entry, want to contain %q, have %q", wantToContain, have)
 				}
 			},
@@ -413,6 +416,8 @@ func TestParseCtl(t *testing.T) {
 		{"ruleRemoveByTag=MY_TAG", ctlRuleRemoveByTag, "MY_TAG", variables.Unknown, ""},
 		{"ruleRemoveTargetByMsg=MY_MSG;ARGS:user", ctlRuleRemoveTargetByMsg, "MY_MSG", variables.Args, "user"},
 		{"ruleRemoveTargetById=2;REQUEST_FILENAME:", ctlRuleRemoveTargetByID, "2", variables.RequestFilename, ""},
+		{"ruleRemoveTargetById=3;REQUEST_HEADERS:User-Agent", ctlRuleRemoveTargetByID, "3", variables.RequestHeaders, "user-agent"},
+		{"ruleRemoveTargetById=4;ARGS:/^Json\\.\\D/", ctlRuleRemoveTargetByID, "4", variables.Args, "/^Json\\.\\D/"},
 	}
 	for _, tCase := range tCases {
 		testName, _, _ := strings.Cut(tCase.input, "=")
@@ -437,6 +442,12 @@ func TestParseCtl(t *testing.T) {
 	}
 
 }
+func TestParseCtlInvalidTargetRegex(t *testing.T) {
+	if _, _, _, _, err := parseCtl("ruleRemoveTargetById=1;ARGS:/(/"); err == nil {
+		t.Error("expected error for invalid regular expression")
+	}
+}
+
 func TestCtlParseRange(t *testing.T) {
 	rules := []corazawaf.Rule{
 		{
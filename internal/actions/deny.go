@@ -37,7 +37,7 @@ func (a *denyFn) Evaluate(r plugintypes.RuleMetadata, tx plugintypes.Transaction
 	if rid == noID {
 		rid = r.ParentID()
 	}
-	status := r.Status()
+	status := ruleStatus(r, tx)
 	// deny action defaults to status 403
 	if status == noStatus {
 		status = http.StatusForbidden
@@ -41,7 +41,7 @@ func (a *dropFn) Evaluate(r plugintypes.RuleMetadata, tx plugintypes.Transaction
 		rid = r.ParentID()
 	}
 	tx.Interrupt(&types.Interruption{
-		Status: r.Status(),
+		Status: ruleStatus(r, tx),
 		RuleID: rid,
 		Action: "drop",
 	})
@@ -4,7 +4,15 @@
 package actions
 
 import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/macro"
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/types/variables"
 )
 
 // Action Group: Non-disruptive
@@ -13,6 +21,8 @@ import (
 // Configures a collection variable to expire after the given time period (in seconds).
 // You should use the `expirevar` with `setvar` action to keep the intended expiration time.
 // The expire time will be reset if they are used on their own (perhaps in a SecAction directive).
+// Only the variables of persistent collections initialized with initcol expire, `expirevar`
+// over a TX variable is a no-op as TX variables only live for the transaction.
 //
 // Example:
 // ```
@@ -22,16 +32,66 @@ import (
 //	SecRule REQUEST_URI "^/cgi-bin/script\.pl" "phase:2,id:115,t:none,t:lowercase,t:normalizePath,log,allow,\
 //		setvar:session.suspicious=1,expirevar:session.suspicious=3600,phase:1"
 //
+//	SecAction "phase:1,id:116,nolog,pass,initcol:ip=%{REMOTE_ADDR}"
+//	SecRule IP:BLOCKED "@eq 1" "phase:1,id:117,deny"
+//	SecRule ARGS:login "@streq failed" "phase:2,id:118,pass,setvar:ip.blocked=1,expirevar:ip.blocked=300"
+//
 // ```
-type expirevarFn struct{}
+type expirevarFn struct {
+	collection variables.RuleVariable
+	key        macro.Macro
+	ttl        macro.Macro
+}
 
 func (a *expirevarFn) Init(_ plugintypes.RuleMetadata, data string) error {
+	key, ttl, ok := strings.Cut(data, "=")
+	if !ok {
+		return ErrInvalidKVArguments
+	}
+	colKey, colVal, ok := strings.Cut(key, ".")
+	if !ok || strings.TrimSpace(colVal) == "" {
+		return errors.New("invalid arguments, expected syntax {collection}.{key}={seconds}")
+	}
+
+	var err error
+	if a.collection, err = variables.Parse(colKey); err != nil {
+		return err
+	}
+	if a.key, err = macro.NewMacro(colVal); err != nil {
+		return err
+	}
+	if a.ttl, err = macro.NewMacro(ttl); err != nil {
+		return err
+	}
 	return nil
 }
 
-func (a *expirevarFn) Evaluate(r plugintypes.RuleMetadata, tx plugintypes.TransactionState) {
-	// Not supported
-	tx.DebugLogger().Warn().Int("rule_id", r.ID()).Msg("Expirevar was used but it's not supported")
+func (a *expirevarFn) Evaluate(r plugintypes.RuleMetadata, txS plugintypes.TransactionState) {
+	if !corazawaf.IsPersistentCollection(a.collection) {
+		txS.DebugLogger().Warn().
+			Int("rule_id", r.ID()).
+			Str("collection", a.collection.Name()).
+			Msg("Expirevar has no effect over non persistent collections")
+		return
+	}
+
+	tx := txS.(*corazawaf.Transaction)
+	key := strings.ToLower(a.key.Expand(tx))
+	ttl, err := strconv.Atoi(a.ttl.Expand(tx))
+	if err != nil || ttl < 0 {
+		tx.DebugLogger().Error().
+			Int("rule_id", r.ID()).
+			Str("var_key", key).
+			Msg("Invalid expirevar seconds")
+		return
+	}
+	if err := tx.ExpireVariable(a.collection, key, time.Duration(ttl)*time.Second); err != nil {
+		tx.DebugLogger().Warn().
+			Int("rule_id", r.ID()).
+			Str("var_key", key).
+			Err(err).
+			Msg("Variable expiration not persisted")
+	}
 }
 
 func (a *expirevarFn) Type() plugintypes.ActionType {
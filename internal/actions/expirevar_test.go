@@ -0,0 +1,125 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corazawaf/coraza/v3/debuglog"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/internal/persistence"
+)
+
+func TestExpirevarInit(t *testing.T) {
+	tests := map[string]bool{
+		"":                              true,
+		"ip.blocked":                    true,
+		"ip=300":                        true,
+		"ip.=300":                       true,
+		"foo.blocked=300":               true,
+		"ip.blocked=300":                false,
+		"tx.blocked=300":                false,
+		"ip.blocked=%{tx.block_period}": false,
+	}
+	for data, expectErr := range tests {
+		err := expirevar().Init(&md{}, data)
+		if expectErr && err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+		if !expectErr && err != nil {
+			t.Errorf("unexpected error for %q: %s", data, err.Error())
+		}
+	}
+}
+
+func TestExpirevarEvaluate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	waf := corazawaf.NewWAF()
+	waf.Persistence = persistence.NewMemory(func() time.Time { return now })
+
+	evaluate := func(t *testing.T, tx *corazawaf.Transaction, actions ...string) {
+		t.Helper()
+		for _, action := range actions {
+			name, data, _ := strings.Cut(action, ":")
+			a, err := Get(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := a.Init(&md{}, data); err != nil {
+				t.Fatal(err)
+			}
+			a.Evaluate(&md{}, tx)
+		}
+	}
+	blocked := func(t *testing.T) []string {
+		t.Helper()
+		tx := waf.NewTransaction()
+		defer tx.Close()
+		evaluate(t, tx, "initcol:ip=1.2.3.4")
+		return tx.Variables().IP().Get("blocked")
+	}
+
+	tx := waf.NewTransaction()
+	evaluate(t, tx, "initcol:ip=1.2.3.4", "setvar:ip.blocked=1", "expirevar:ip.blocked=300")
+	tx.Close()
+
+	if have := blocked(t); len(have) != 1 || have[0] != "1" {
+		t.Errorf("expected IP:blocked to be persisted, have %q", have)
+	}
+
+	now = now.Add(299 * time.Second)
+	if have := blocked(t); len(have) != 1 || have[0] != "1" {
+		t.Errorf("expected IP:blocked before the expiration, have %q", have)
+	}
+
+	now = now.Add(time.Second)
+	if have := blocked(t); len(have) != 0 {
+		t.Errorf("expected IP:blocked to be expired, have %q", have)
+	}
+}
+
+func TestExpirevarNonPersistentCollection(t *testing.T) {
+	logsBuf := &bytes.Buffer{}
+	waf := corazawaf.NewWAF()
+	waf.Logger = debuglog.Default().WithLevel(debuglog.LevelWarn).WithOutput(logsBuf)
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.Variables().TX().Set("blocked", []string{"1"})
+
+	a := expirevar()
+	if err := a.Init(&md{}, "tx.blocked=300"); err != nil {
+		t.Fatal(err)
+	}
+	a.Evaluate(&md{}, tx)
+
+	if !strings.Contains(logsBuf.String(), "Expirevar has no effect over non persistent collections") {
+		t.Errorf("expected a warning, got %q", logsBuf.String())
+	}
+	if have := tx.Variables().TX().Get("blocked"); len(have) != 1 || have[0] != "1" {
+		t.Errorf("expected TX:blocked to be kept, have %q", have)
+	}
+}
+
+func TestSetvarNotInitializedCollection(t *testing.T) {
+	logsBuf := &bytes.Buffer{}
+	waf := corazawaf.NewWAF()
+	waf.Logger = debuglog.Default().WithLevel(debuglog.LevelWarn).WithOutput(logsBuf)
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+
+	a := setvar()
+	if err := a.Init(&md{}, "ip.blocked=1"); err != nil {
+		t.Fatal(err)
+	}
+	a.Evaluate(&md{}, tx)
+
+	if !strings.Contains(logsBuf.String(), "Variable not persisted") {
+		t.Errorf("expected a warning, got %q", logsBuf.String())
+	}
+}
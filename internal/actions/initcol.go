@@ -4,9 +4,13 @@
 package actions
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/corazawaf/coraza/v3/experimental/plugins/macro"
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/types/variables"
 )
 
 // Action Group: Non-disruptive
@@ -14,8 +18,9 @@ import (
 // Description:
 // Initializes a named persistent collection, either by loading data from storage or by creating a new collection in memory.
 // Collections are loaded into memory on-demand, when the initcol action is executed.
-// A collection will be persisted only if a change was made to it in the course of transaction processing.
-// See the `Persistent Storage` section for further details.
+// The variables set with setvar are written to the persistence engine as they change,
+// setvar and expirevar over a collection not initialized in the transaction are not persisted.
+// The supported collections are IP, GLOBAL, SESSION, USER and RESOURCE.
 //
 // Example:
 // ```
@@ -23,9 +28,8 @@ import (
 // SecAction "phase:1,id:116,nolog,pass,initcol:ip=%{REMOTE_ADDR}"
 // ```
 type initcolFn struct {
-	collection string
-	variable   byte
-	key        string
+	collection variables.RuleVariable
+	key        macro.Macro
 }
 
 func (a *initcolFn) Init(_ plugintypes.RuleMetadata, data string) error {
@@ -34,34 +38,34 @@ func (a *initcolFn) Init(_ plugintypes.RuleMetadata, data string) error {
 		return ErrInvalidKVArguments
 	}
 
-	a.collection = col
-	a.key = key
-	a.variable = 0x0
+	v, err := variables.Parse(col)
+	if err != nil {
+		return err
+	}
+	if !corazawaf.IsPersistentCollection(v) {
+		return fmt.Errorf("invalid collection %q, expected one of IP, GLOBAL, SESSION, USER or RESOURCE", col)
+	}
+	m, err := macro.NewMacro(key)
+	if err != nil {
+		return err
+	}
+
+	a.collection = v
+	a.key = m
 	return nil
 }
 
-func (a *initcolFn) Evaluate(_ plugintypes.RuleMetadata, _ plugintypes.TransactionState) {
-	// tx.DebugLogger().Error().Msg("initcol was used but it's not supported", zap.Int("rule", r.Id))
-	/*
-		key := tx.MacroExpansion(a.key)
-		data := tx.WAF.Persistence.Get(a.variable, key)
-		if data == nil {
-			ts := time.Now().UnixNano()
-			tss := strconv.FormatInt(ts, 10)
-			tsstimeout := strconv.FormatInt(ts+(int64(tx.WAF.CollectionTimeout)*1000), 10)
-			data = map[string][]string{
-				"CREATE_TIME":      {tss},
-				"IS_NEW":           {"1"},
-				"KEY":              {key},
-				"LAST_UPDATE_TIME": {tss},
-				"TIMEOUT":          {tsstimeout},
-				"UPDATE_COUNTER":   {"0"},
-				"UPDATE_RATE":      {"0"},
-			}
-		}
-		tx.GetCollection(a.variable).SetData(data)
-		tx.PersistentCollections[a.variable] = key
-	*/
+func (a *initcolFn) Evaluate(r plugintypes.RuleMetadata, txS plugintypes.TransactionState) {
+	tx := txS.(*corazawaf.Transaction)
+	key := a.key.Expand(tx)
+	if err := tx.InitCollection(a.collection, key); err != nil {
+		tx.DebugLogger().Error().
+			Int("rule_id", r.ID()).
+			Str("collection", a.collection.Name()).
+			Str("key", key).
+			Err(err).
+			Msg("Failed to initialize collection")
+	}
 }
 
 func (a *initcolFn) Type() plugintypes.ActionType {
@@ -16,9 +16,16 @@ func TestInitcolInit(t *testing.T) {
 
 	t.Run("passing argument", func(t *testing.T) {
 		initcol := initcol()
-		err := initcol.Init(nil, "foo=bar")
+		err := initcol.Init(nil, "ip=%{REMOTE_ADDR}")
 		if err != nil {
 			t.Errorf("unexpected error: %s", err.Error())
 		}
 	})
+
+	t.Run("non persistent collection", func(t *testing.T) {
+		initcol := initcol()
+		if err := initcol.Init(nil, "tx=bar"); err == nil {
+			t.Errorf("expected error")
+		}
+	})
 }
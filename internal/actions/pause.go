@@ -0,0 +1,68 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+// Action Group: Non-disruptive
+//
+// Description:
+// Pauses transaction processing for the specified number of milliseconds.
+// Coraza does not sleep while evaluating the rule, the requested delay is recorded
+// in the transaction and carried by the interruption (if any), so the integrator
+// can slow down the response accordingly.
+// The delay is only recorded when the rule engine is On.
+//
+// Example:
+// ```
+// SecRule REQUEST_HEADERS:User-Agent "nikto" "log,deny,id:107,msg:'Nikto Scanners Identified',pause:5000"
+// ```
+type pauseFn struct {
+	duration time.Duration
+}
+
+func (a *pauseFn) Init(_ plugintypes.RuleMetadata, data string) error {
+	if len(data) == 0 {
+		return ErrMissingArguments
+	}
+
+	ms, err := strconv.Atoi(data)
+	if err != nil {
+		return fmt.Errorf("invalid argument: %s", err.Error())
+	}
+	if ms < 0 {
+		return fmt.Errorf("invalid argument, %d must not be negative", ms)
+	}
+	a.duration = time.Duration(ms) * time.Millisecond
+	return nil
+}
+
+func (a *pauseFn) Evaluate(_ plugintypes.RuleMetadata, txS plugintypes.TransactionState) {
+	tx := txS.(*corazawaf.Transaction)
+	if tx.RuleEngine != types.RuleEngineOn {
+		return
+	}
+	tx.Pause = a.duration
+}
+
+func (a *pauseFn) Type() plugintypes.ActionType {
+	return plugintypes.ActionTypeNondisruptive
+}
+
+func pause() plugintypes.Action {
+	return &pauseFn{}
+}
+
+var (
+	_ plugintypes.Action = &pauseFn{}
+	_ ruleActionWrapper  = pause
+)
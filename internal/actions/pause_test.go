@@ -0,0 +1,86 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+func TestPauseInit(t *testing.T) {
+	t.Run("no arguments", func(t *testing.T) {
+		a := pause()
+		if err := a.Init(nil, ""); err != ErrMissingArguments {
+			t.Error("expected error ErrMissingArguments")
+		}
+	})
+
+	t.Run("invalid argument", func(t *testing.T) {
+		a := pause()
+		if err := a.Init(nil, "abc"); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("negative argument", func(t *testing.T) {
+		a := pause()
+		if err := a.Init(nil, "-1"); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("valid argument", func(t *testing.T) {
+		a := pause()
+		if err := a.Init(nil, "5000"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want, have := 5*time.Second, a.(*pauseFn).duration; want != have {
+			t.Errorf("unexpected duration, want %s, have %s", want, have)
+		}
+	})
+}
+
+func TestPauseEvaluate(t *testing.T) {
+	t.Run("recorded on transaction and interruption", func(t *testing.T) {
+		r := corazawaf.NewRule()
+		r.ID_ = 1
+		a := pause()
+		if err := a.Init(r, "5000"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tx := corazawaf.NewWAF().NewTransaction()
+		a.Evaluate(r, tx)
+		if want, have := 5*time.Second, tx.Pause; want != have {
+			t.Errorf("unexpected transaction pause, want %s, have %s", want, have)
+		}
+
+		deny().Evaluate(r, tx)
+		it := tx.Interruption()
+		if it == nil {
+			t.Fatal("expected interruption")
+		}
+		if want, have := 5*time.Second, it.Pause; want != have {
+			t.Errorf("unexpected interruption pause, want %s, have %s", want, have)
+		}
+	})
+
+	t.Run("not recorded in detection only", func(t *testing.T) {
+		r := corazawaf.NewRule()
+		a := pause()
+		if err := a.Init(r, "5000"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tx := corazawaf.NewWAF().NewTransaction()
+		tx.RuleEngine = types.RuleEngineDetectionOnly
+		a.Evaluate(r, tx)
+		if tx.Pause != 0 {
+			t.Errorf("unexpected transaction pause %s", tx.Pause)
+		}
+	})
+}
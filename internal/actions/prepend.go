@@ -0,0 +1,60 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"github.com/corazawaf/coraza/v3/experimental/plugins/macro"
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+// Action Group: Non-disruptive
+//
+// Description:
+// Prepends text given as parameter to the beginning of the response body.
+// The parameter supports macro expansion. This action only works in phase 4
+// and requires the response body to be buffered (SecResponseBodyAccess On),
+// otherwise it is ignored. Encoded response bodies, e.g. with
+// `Content-Encoding: gzip`, are not modified.
+//
+// Example:
+// ```
+// SecRule RESPONSE_CONTENT_TYPE "^text/html" "nolog,id:99,pass,phase:4,prepend:'Header<br>'"
+// ```
+type prependFn struct {
+	data macro.Macro
+}
+
+func (a *prependFn) Init(_ plugintypes.RuleMetadata, data string) error {
+	if len(data) == 0 {
+		return ErrMissingArguments
+	}
+
+	m, err := macro.NewMacro(data)
+	if err != nil {
+		return err
+	}
+	a.data = m
+	return nil
+}
+
+func (a *prependFn) Evaluate(r plugintypes.RuleMetadata, txS plugintypes.TransactionState) {
+	tx := txS.(*corazawaf.Transaction)
+	modifyResponseBody(r, tx, "prepend", func() error {
+		return tx.PrependResponseBody([]byte(a.data.Expand(tx)))
+	})
+}
+
+func (a *prependFn) Type() plugintypes.ActionType {
+	return plugintypes.ActionTypeNondisruptive
+}
+
+func prepend() plugintypes.Action {
+	return &prependFn{}
+}
+
+var (
+	_ plugintypes.Action = &prependFn{}
+	_ ruleActionWrapper  = prepend
+)
@@ -39,7 +39,7 @@ func (a *redirectFn) Evaluate(r plugintypes.RuleMetadata, tx plugintypes.Transac
 	if rid == noID {
 		rid = r.ParentID()
 	}
-	rstatus := r.Status()
+	rstatus := ruleStatus(r, tx)
 	if rstatus == 301 || rstatus == 302 || rstatus == 303 || rstatus == 307 {
 		status = rstatus
 	}
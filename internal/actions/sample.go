@@ -0,0 +1,58 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+// Action Group: Metadata
+//
+// Description:
+// Evaluates the rule only for the given percentage (1-100) of the times it is reached.
+// When the rule is not sampled it is skipped entirely: its operator is not evaluated
+// and none of its actions are executed. It is meant to roll out expensive or new rules
+// progressively. Sampling is decided before evaluating the rule, so it only applies to
+// the first rule of a chain.
+//
+// Example:
+// ```
+// # Evaluate the rule for 10% of the transactions
+// SecRule REQUEST_BODY "@rx expensive" "id:100,phase:2,sample:10,log,pass"
+// ```
+type sampleFn struct{}
+
+func (a *sampleFn) Init(r plugintypes.RuleMetadata, data string) error {
+	if len(data) == 0 {
+		return ErrMissingArguments
+	}
+	p, err := strconv.Atoi(data)
+	if err != nil {
+		return err
+	}
+	if p < 1 || p > 100 {
+		return fmt.Errorf("invalid argument, %d should be between 1 and 100", p)
+	}
+	r.(*corazawaf.Rule).SamplePercentage = p
+	return nil
+}
+
+func (a *sampleFn) Evaluate(_ plugintypes.RuleMetadata, _ plugintypes.TransactionState) {}
+
+func (a *sampleFn) Type() plugintypes.ActionType {
+	return plugintypes.ActionTypeMetadata
+}
+
+func sample() plugintypes.Action {
+	return &sampleFn{}
+}
+
+var (
+	_ plugintypes.Action = &sampleFn{}
+	_ ruleActionWrapper  = sample
+)
@@ -0,0 +1,45 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+func TestSampleInit(t *testing.T) {
+	for _, test := range []struct {
+		data               string
+		expectedError      bool
+		expectedPercentage int
+	}{
+		{"", true, 0},
+		{"abc", true, 0},
+		{"0", true, 0},
+		{"-10", true, 0},
+		{"101", true, 0},
+		{"1", false, 1},
+		{"10", false, 10},
+		{"100", false, 100},
+	} {
+		t.Run(test.data, func(t *testing.T) {
+			a := sample()
+			r := &corazawaf.Rule{}
+			err := a.Init(r, test.data)
+			if test.expectedError {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if want, have := test.expectedPercentage, r.SamplePercentage; want != have {
+				t.Errorf("unexpected sample percentage, want %d, have %d", want, have)
+			}
+		})
+	}
+}
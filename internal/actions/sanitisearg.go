@@ -0,0 +1,52 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+// Action Group: Non-disruptive
+//
+// Description:
+// Prevents sensitive request parameter data from being logged to the audit log.
+// The value of the named parameter(s) is replaced with `****`, both in the
+// arguments and in the query string or urlencoded request body. JSON and
+// multipart request bodies containing a sanitised parameter are logged as
+// their urlencoded parameters, e.g. `json.password=****`. JSON parameters also
+// match by their name without the `json.` prefix.
+//
+// Example:
+// ```
+// SecAction "nolog,phase:2,id:133,sanitiseArg:password,sanitiseArg:newPassword,sanitiseArg:oldPassword"
+// ```
+type sanitiseArgFn struct {
+	name string
+}
+
+func (a *sanitiseArgFn) Init(_ plugintypes.RuleMetadata, data string) error {
+	if len(data) == 0 {
+		return ErrMissingArguments
+	}
+	a.name = data
+	return nil
+}
+
+func (a *sanitiseArgFn) Evaluate(_ plugintypes.RuleMetadata, tx plugintypes.TransactionState) {
+	tx.(*corazawaf.Transaction).SanitiseArg(a.name)
+}
+
+func (a *sanitiseArgFn) Type() plugintypes.ActionType {
+	return plugintypes.ActionTypeNondisruptive
+}
+
+func sanitiseArg() plugintypes.Action {
+	return &sanitiseArgFn{}
+}
+
+var (
+	_ plugintypes.Action = &sanitiseArgFn{}
+	_ ruleActionWrapper  = sanitiseArg
+)
@@ -0,0 +1,60 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+// Action Group: Non-disruptive
+//
+// Description:
+// Prevents the matched variable from being logged to the audit log.
+// Its value is replaced with `****`. Only arguments (ARGS, ARGS_GET, ARGS_POST and ARGS_PATH)
+// and headers (REQUEST_HEADERS and RESPONSE_HEADERS) can be sanitised.
+//
+// Example:
+// ```
+// # Sanitise all arguments whose names contain "pass"
+// SecRule ARGS_NAMES "@rx pass" "id:137,phase:2,nolog,pass,chain"
+// SecRule ARGS:/pass/ "@unconditionalMatch" "sanitiseMatched"
+// ```
+type sanitiseMatchedFn struct{}
+
+func (a *sanitiseMatchedFn) Init(_ plugintypes.RuleMetadata, data string) error {
+	if len(data) > 0 {
+		return ErrUnexpectedArguments
+	}
+	return nil
+}
+
+func (a *sanitiseMatchedFn) Evaluate(r plugintypes.RuleMetadata, txS plugintypes.TransactionState) {
+	tx := txS.(*corazawaf.Transaction)
+	name, key, ok := strings.Cut(tx.Variables().MatchedVarName().Get(), ":")
+	if !ok {
+		tx.DebugLogger().Debug().Int("rule_id", r.ID()).Str("variable", name).Msg("Matched variable can't be sanitised")
+		return
+	}
+	v, err := variables.Parse(name)
+	if err != nil || !tx.SanitiseVariable(v, key) {
+		tx.DebugLogger().Debug().Int("rule_id", r.ID()).Str("variable", name).Msg("Matched variable can't be sanitised")
+	}
+}
+
+func (a *sanitiseMatchedFn) Type() plugintypes.ActionType {
+	return plugintypes.ActionTypeNondisruptive
+}
+
+func sanitiseMatched() plugintypes.Action {
+	return &sanitiseMatchedFn{}
+}
+
+var (
+	_ plugintypes.Action = &sanitiseMatchedFn{}
+	_ ruleActionWrapper  = sanitiseMatched
+)
@@ -0,0 +1,92 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+// Action Group: Non-disruptive
+//
+// Description:
+// Prevents the matched bytes of the matched variable from being logged to the audit log,
+// keeping the rest of the value. The matched bytes are taken from the capture TX:0,
+// hence the rule is expected to use the `capture` action. With `@rx`, the offsets of the
+// match are recorded so exactly the matched bytes are masked, even if the same bytes
+// appear before in the value. If there is no capture, or the
+// captured bytes can't be found in the raw value (e.g. due to transformations), the whole
+// value is masked. Optionally, N/M can be used to keep the first N and the last M matched
+// bytes. Only arguments and headers can be sanitised.
+//
+// Example:
+// ```
+// # Masks the credit card number but the last 4 digits
+// SecRule ARGS "@verifyCC \d{13,16}" "id:138,phase:2,nolog,pass,capture,sanitiseMatchedBytes:0/4"
+// ```
+type sanitiseMatchedBytesFn struct {
+	keepStart int
+	keepEnd   int
+}
+
+func (a *sanitiseMatchedBytesFn) Init(_ plugintypes.RuleMetadata, data string) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	start, end, ok := strings.Cut(data, "/")
+	if !ok {
+		return fmt.Errorf("invalid argument %q, expected syntax N/M", data)
+	}
+	var err error
+	if a.keepStart, err = strconv.Atoi(start); err != nil || a.keepStart < 0 {
+		return fmt.Errorf("invalid argument %q, expected syntax N/M", data)
+	}
+	if a.keepEnd, err = strconv.Atoi(end); err != nil || a.keepEnd < 0 {
+		return fmt.Errorf("invalid argument %q, expected syntax N/M", data)
+	}
+	return nil
+}
+
+func (a *sanitiseMatchedBytesFn) Evaluate(r plugintypes.RuleMetadata, txS plugintypes.TransactionState) {
+	tx := txS.(*corazawaf.Transaction)
+	name, key, ok := strings.Cut(tx.Variables().MatchedVarName().Get(), ":")
+	if !ok {
+		tx.DebugLogger().Debug().Int("rule_id", r.ID()).Str("variable", name).Msg("Matched variable can't be sanitised")
+		return
+	}
+	v, err := variables.Parse(name)
+	if err != nil {
+		tx.DebugLogger().Debug().Int("rule_id", r.ID()).Str("variable", name).Msg("Matched variable can't be sanitised")
+		return
+	}
+
+	var matched string
+	if tx.Capturing() {
+		if c := tx.Variables().TX().Get("0"); len(c) > 0 {
+			matched = c[0]
+		}
+	}
+	if !tx.SanitiseVariableBytes(v, key, matched, a.keepStart, a.keepEnd) {
+		tx.DebugLogger().Debug().Int("rule_id", r.ID()).Str("variable", name).Msg("Matched variable can't be sanitised")
+	}
+}
+
+func (a *sanitiseMatchedBytesFn) Type() plugintypes.ActionType {
+	return plugintypes.ActionTypeNondisruptive
+}
+
+func sanitiseMatchedBytes() plugintypes.Action {
+	return &sanitiseMatchedBytesFn{}
+}
+
+var (
+	_ plugintypes.Action = &sanitiseMatchedBytesFn{}
+	_ ruleActionWrapper  = sanitiseMatchedBytes
+)
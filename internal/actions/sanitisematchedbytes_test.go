@@ -0,0 +1,44 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import "testing"
+
+func TestSanitiseMatchedBytesInit(t *testing.T) {
+	tests := map[string]struct {
+		data              string
+		expectError       bool
+		expectedKeepStart int
+		expectedKeepEnd   int
+	}{
+		"no arguments":     {data: ""},
+		"keep bytes":       {data: "1/4", expectedKeepStart: 1, expectedKeepEnd: 4},
+		"missing slash":    {data: "4", expectError: true},
+		"invalid start":    {data: "a/4", expectError: true},
+		"invalid end":      {data: "1/b", expectError: true},
+		"negative numbers": {data: "-1/4", expectError: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := sanitiseMatchedBytes()
+			err := a.Init(nil, tt.data)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, have := tt.expectedKeepStart, a.(*sanitiseMatchedBytesFn).keepStart; want != have {
+				t.Errorf("unexpected keep start, want %d, have %d", want, have)
+			}
+			if want, have := tt.expectedKeepEnd, a.(*sanitiseMatchedBytesFn).keepEnd; want != have {
+				t.Errorf("unexpected keep end, want %d, have %d", want, have)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+// Action Group: Non-disruptive
+//
+// Description:
+// Prevents the named response header from being logged to the audit log.
+// Its value is replaced with `****`.
+//
+// Example:
+// ```
+// SecAction "nolog,phase:3,id:135,sanitiseResponseHeader:Set-Cookie"
+// ```
+type sanitiseResponseHeaderFn struct {
+	name string
+}
+
+func (a *sanitiseResponseHeaderFn) Init(_ plugintypes.RuleMetadata, data string) error {
+	if len(data) == 0 {
+		return ErrMissingArguments
+	}
+	a.name = data
+	return nil
+}
+
+func (a *sanitiseResponseHeaderFn) Evaluate(_ plugintypes.RuleMetadata, tx plugintypes.TransactionState) {
+	tx.(*corazawaf.Transaction).SanitiseResponseHeader(a.name)
+}
+
+func (a *sanitiseResponseHeaderFn) Type() plugintypes.ActionType {
+	return plugintypes.ActionTypeNondisruptive
+}
+
+func sanitiseResponseHeader() plugintypes.Action {
+	return &sanitiseResponseHeaderFn{}
+}
+
+var (
+	_ plugintypes.Action = &sanitiseResponseHeaderFn{}
+	_ ruleActionWrapper  = sanitiseResponseHeader
+)
@@ -5,7 +5,6 @@ package actions
 
 import (
 	"errors"
-	"os"
 	"strings"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/macro"
@@ -15,16 +14,17 @@ import (
 // Action Group: Non-disruptive
 //
 // Description:
-// Creates, removes, and updates environment variables that can be accessed by the implementation.
-// > In a trained rule, the action will be executed when an individual rule matches (not the entire chain).
+// Creates and updates environment variables that can be accessed by the implementation.
+// Unlike ModSecurity, the process environment is not modified: the variables are set in the
+// ENV collection of the transaction, where they can be inspected by later rules and read by
+// the integration through experimental.TransactionWithEnvVariables, e.g. to propagate them to
+// the backend as request headers.
+// > In a chained rule, the action will be executed when an individual rule matches (not the entire chain).
 //
 // Example:
 // ```
 // SecRule RESPONSE_HEADERS:/Set-Cookie2?/ "(?i:(j?sessionid|(php)?sessid|(asp|jserv|jw)?session[-_]?(id)?|cf(id|token)|sid))" "phase:3,t:none,pass,id:139,nolog,setvar:tx.sessionid=%{matched_var}"
 // SecRule TX:SESSIONID "!(?i:\;? ?httponly;?)" "phase:3,id:140,t:none,setenv:httponly_cookie=%{matched_var},pass,log,auditlog,msg:'AppDefect: Missing HttpOnly Cookie Flag.'"
-//
-// # In Apache
-// Header set Set-Cookie "%{httponly_cookie}e; HTTPOnly" env=httponly_cookie
 // ```
 type setenvFn struct {
 	key   string
@@ -58,19 +58,8 @@ func (a *setenvFn) Init(_ plugintypes.RuleMetadata, data string) error {
 	return nil
 }
 
-func (a *setenvFn) Evaluate(r plugintypes.RuleMetadata, tx plugintypes.TransactionState) {
-	v := a.value.Expand(tx)
-	// set env variable
-	if err := os.Setenv(a.key, v); err != nil {
-		tx.DebugLogger().
-			Error().
-			Int("rule_id", r.ID()).
-			Err(err).
-			Msg("Failed to set the env variable for rule")
-	}
-	// TODO is this ok?
-	tx.Variables().Env().Set(a.key, []string{v})
-
+func (a *setenvFn) Evaluate(_ plugintypes.RuleMetadata, tx plugintypes.TransactionState) {
+	tx.Variables().Env().Set(a.key, []string{a.value.Expand(tx)})
 }
 
 func (a *setenvFn) Type() plugintypes.ActionType {
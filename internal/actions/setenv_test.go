@@ -0,0 +1,49 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"os"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+func TestSetenvInit(t *testing.T) {
+	tests := map[string]string{
+		"no arguments": "",
+		"no value":     "foo",
+		"empty key":    "=bar",
+		"empty value":  "foo=",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := setenv().Init(&md{}, data); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSetenvEvaluate(t *testing.T) {
+	a := setenv()
+	if err := a.Init(&md{}, "coraza_setenv_test=%{tx.value}"); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := corazawaf.NewWAF().NewTransaction()
+	defer tx.Close()
+	tx.Variables().TX().Set("value", []string{"bar"})
+	a.Evaluate(&md{}, tx)
+
+	if want, have := "bar", tx.Variables().Env().Get("coraza_setenv_test"); len(have) != 1 || have[0] != want {
+		t.Errorf("unexpected ENV:coraza_setenv_test, want %q, have %v", want, have)
+	}
+	if want, have := "bar", tx.EnvVariables()["coraza_setenv_test"]; want != have {
+		t.Errorf("unexpected env variable, want %q, have %q", want, have)
+	}
+	if _, ok := os.LookupEnv("coraza_setenv_test"); ok {
+		t.Error("unexpected change of the process environment")
+	}
+}
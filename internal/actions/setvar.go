@@ -11,6 +11,7 @@ import (
 	"github.com/corazawaf/coraza/v3/collection"
 	"github.com/corazawaf/coraza/v3/experimental/plugins/macro"
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
 	"github.com/corazawaf/coraza/v3/types/variables"
 )
 
@@ -30,9 +31,17 @@ import (
 // # Remove a variable, prefix the name with an exclamation mark
 // `setvar:!TX.score`
 //
+// # Set a variable of a persistent collection initialized with initcol, it is kept across
+// # transactions and can be expired with expirevar
+// `setvar:IP.blocked=1`
+//
 // # Increase or decrease variable value, use + and - characters in front of a numerical value
 // `setvar:TX.score=+5`
 //
+// # In persistent collections the operation is applied by the persistence engine, so
+// # concurrent transactions don't lose updates
+// `setvar:IP.requests=+1`
+//
 // # Example from OWASP CRS:
 //
 //	SecRule REQUEST_FILENAME|ARGS_NAMES|ARGS|XML:/* "\bsys\.user_catalog\b" \
@@ -53,12 +62,20 @@ import (
 //	SecRule REQUEST_FILENAME "@streq test.php" "chain,id:7,phase:1,t:none,nolog"
 //		SecRule ARGS_POST:action "@streq login" "t:none,setvar:tx.auth_attempt=+1"
 //
+// # Captured values can be used as operands. As in ModSecurity, the leading integer of a
+// # non numeric operand is used, e.g. 12abc adds 12, and operands without one add 0.
+//
+//	SecRule ARGS:score "@rx ^(\S+)" "id:8,phase:2,capture,t:none,nolog,setvar:tx.score=+%{tx.1}"
+//
 // ```
 type setvarFn struct {
 	key        macro.Macro
 	value      macro.Macro
 	collection variables.RuleVariable
 	isRemove   bool
+	// macroOperand is true if the operand of an arithmetic operation is expanded
+	// from a macro, e.g. +%{tx.0}
+	macroOperand bool
 }
 
 func (a *setvarFn) Init(_ plugintypes.RuleMetadata, data string) error {
@@ -74,18 +91,14 @@ func (a *setvarFn) Init(_ plugintypes.RuleMetadata, data string) error {
 	var err error
 	key, val, valOk := strings.Cut(data, "=")
 	colKey, colVal, colOk := strings.Cut(key, ".")
-	// Right not it only makes sense to allow setting TX
-	// key is also required
-	if strings.ToUpper(colKey) != "TX" {
-		return errors.New("invalid arguments, expected collection TX")
+	// TX and the persistent collections can be set, key is also required
+	a.collection, err = variables.Parse(colKey)
+	if err != nil || (a.collection != variables.TX && !corazawaf.IsPersistentCollection(a.collection)) {
+		return errors.New("invalid arguments, expected collection TX, IP, GLOBAL, SESSION, USER or RESOURCE")
 	}
 	if strings.TrimSpace(colVal) == "" {
 		return errors.New("invalid arguments, expected syntax TX.{key}={value}")
 	}
-	a.collection, err = variables.Parse(colKey)
-	if err != nil {
-		return err
-	}
 	if colOk {
 		macro, err := macro.NewMacro(colVal)
 		if err != nil {
@@ -100,6 +113,7 @@ func (a *setvarFn) Init(_ plugintypes.RuleMetadata, data string) error {
 			return err
 		}
 		a.value = macro
+		a.macroOperand = len(val) > 1 && (val[0] == '+' || val[0] == '-') && strings.Contains(val[1:], "%{")
 	}
 	return nil
 }
@@ -112,14 +126,34 @@ func (a *setvarFn) Evaluate(r plugintypes.RuleMetadata, tx plugintypes.Transacti
 		Str("var_value", value).
 		Int("rule_id", r.ID()).
 		Msg("Action evaluated")
-	a.evaluateTxCollection(r, tx, strings.ToLower(key), value)
+	key = strings.ToLower(key)
+	delta, arithmetic := a.arithmeticOperand(r, tx, value)
+	a.evaluateTxCollection(r, tx, key, value, delta, arithmetic)
+	if corazawaf.IsPersistentCollection(a.collection) {
+		ptx := tx.(*corazawaf.Transaction)
+		var err error
+		if arithmetic && !a.isRemove {
+			// the engine applies the operation so concurrent transactions don't lose
+			// updates, its result replaces the value computed above
+			err = ptx.AddToVariable(a.collection, key, delta)
+		} else {
+			err = ptx.PersistVariable(a.collection, key)
+		}
+		if err != nil {
+			tx.DebugLogger().Warn().
+				Str("var_key", key).
+				Int("rule_id", r.ID()).
+				Err(err).
+				Msg("Variable not persisted")
+		}
+	}
 }
 
 func (a *setvarFn) Type() plugintypes.ActionType {
 	return plugintypes.ActionTypeNondisruptive
 }
 
-func (a *setvarFn) evaluateTxCollection(r plugintypes.RuleMetadata, tx plugintypes.TransactionState, key string, value string) {
+func (a *setvarFn) evaluateTxCollection(r plugintypes.RuleMetadata, tx plugintypes.TransactionState, key string, value string, delta int, arithmetic bool) {
 	var col collection.Map
 	if c, ok := tx.Collection(a.collection).(collection.Map); !ok {
 		tx.DebugLogger().Error().Msg("collection in setvar is not a map")
@@ -136,55 +170,74 @@ func (a *setvarFn) evaluateTxCollection(r plugintypes.RuleMetadata, tx plugintyp
 		col.Remove(key)
 		return
 	}
-	currentVal := ""
-	if r := col.Get(key); len(r) > 0 {
-		currentVal = r[0]
+	if !arithmetic {
+		col.Set(key, []string{value})
+		return
 	}
-	var err error
-	switch {
-	case len(value) == 0:
-		// if nothing to input
-		col.Set(key, []string{""})
-	// Check if this could be an arithemetic operation. If it is followed by a number, it will be treated as an arithmetic operation. Otherwise, it will be treated as a string.
-	case value[0] == '+', value[0] == '-':
-		val := 0
-		if len(value) > 1 {
-			val, err = strconv.Atoi(value[1:])
-			if err != nil {
-				// If the variable doesn't exist, we would need to raise an error. Otherwise, it should be the same value.
-				if strings.HasPrefix(value[1:], "tx.") {
-					tx.DebugLogger().Error().
-						Str("var_value", value).
-						Int("rule_id", r.ID()).
-						Err(err).
-						Msg(value)
-					return
-				}
-
-				col.Set(key, []string{value})
-				return
-			}
+	currentValInt := 0
+	if currentVal := col.Get(key); len(currentVal) > 0 && currentVal[0] != "" {
+		var err error
+		currentValInt, err = strconv.Atoi(currentVal[0])
+		if err != nil {
+			tx.DebugLogger().Error().
+				Str("var_key", currentVal[0]).
+				Int("rule_id", r.ID()).
+				Err(err).
+				Msg("Invalid value")
+			return
 		}
-		currentValInt := 0
-		if currentVal != "" {
-			currentValInt, err = strconv.Atoi(currentVal)
-			if err != nil {
-				tx.DebugLogger().Error().
-					Str("var_key", currentVal).
-					Int("rule_id", r.ID()).
-					Err(err).
-					Msg("Invalid value")
-				return
+	}
+	col.Set(key, []string{strconv.Itoa(currentValInt + delta)})
+}
+
+// arithmeticOperand returns the signed operand of value if it is an arithmetic
+// operation, e.g. +5. Values with a sign followed by something else than a number
+// are set as strings, unless the operand is expanded from a macro.
+func (a *setvarFn) arithmeticOperand(r plugintypes.RuleMetadata, tx plugintypes.TransactionState, value string) (int, bool) {
+	if len(value) == 0 || (value[0] != '+' && value[0] != '-') {
+		return 0, false
+	}
+	val := 0
+	if len(value) > 1 {
+		var err error
+		val, err = strconv.Atoi(value[1:])
+		if err != nil {
+			if !a.macroOperand {
+				return 0, false
 			}
+			// expanded operands, e.g. captures, are converted like ModSecurity does
+			val = leadingInt(value[1:])
+			tx.DebugLogger().Debug().
+				Str("var_value", value).
+				Int("rule_id", r.ID()).
+				Int("operand", val).
+				Msg("Non numeric operand in setvar arithmetic operation")
 		}
-		if value[0] == '+' {
-			col.Set(key, []string{strconv.Itoa(currentValInt + val)})
-		} else {
-			col.Set(key, []string{strconv.Itoa(currentValInt - val)})
-		}
-	default:
-		col.Set(key, []string{value})
 	}
+	if value[0] == '-' {
+		return -val, true
+	}
+	return val, true
+}
+
+// leadingInt mimics atoi(3): it skips leading whitespace, accepts an optional sign and
+// converts the digits that follow, returning 0 if there are none.
+func leadingInt(s string) int {
+	s = strings.TrimLeft(s, " \t\n\v\f\r")
+	end := 0
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		end++
+	}
+	start := end
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	if end == start {
+		return 0
+	}
+	// out of range values are clamped by Atoi
+	n, _ := strconv.Atoi(s[:end])
+	return n
 }
 
 func setvar() plugintypes.Action {
@@ -5,6 +5,7 @@ package actions
 
 import (
 	"bytes"
+	"math"
 	"strings"
 	"testing"
 
@@ -54,59 +55,84 @@ func TestSetvarInit(t *testing.T) {
 	})
 }
 
-var invalidSyntaxAtoiError = "invalid syntax"
 var warningKeyNotFoundInCollection = "key not found in collection"
 
 func TestSetvarEvaluate(t *testing.T) {
 	tests := []struct {
-		name                     string
-		init                     string
-		init2                    string
-		expectInvalidSyntaxError bool
-		expectNewVarValue        string
+		name                  string
+		init                  string
+		init2                 string
+		expectKeyNotFoundWarn bool
+		expectNewVarValue     string
 	}{
 		{
-			name:                     "Numerical operation + with existing variable",
-			init:                     "TX.var=5",
-			init2:                    "TX.newvar=+%{tx.var}",
-			expectInvalidSyntaxError: false,
-			expectNewVarValue:        "5",
+			name:                  "Numerical operation + with existing variable",
+			init:                  "TX.var=5",
+			init2:                 "TX.newvar=+%{tx.var}",
+			expectKeyNotFoundWarn: false,
+			expectNewVarValue:     "5",
 		},
 		{
-			name:                     "Numerical operation - with existing variable",
-			init:                     "TX.var=5",
-			init2:                    "TX.newvar=-%{tx.var}",
-			expectInvalidSyntaxError: false,
-			expectNewVarValue:        "-5",
+			name:                  "Numerical operation - with existing variable",
+			init:                  "TX.var=5",
+			init2:                 "TX.newvar=-%{tx.var}",
+			expectKeyNotFoundWarn: false,
+			expectNewVarValue:     "-5",
 		},
 		{
-			name:                     "Numerical operation - with existing negative variable",
-			init:                     "TX.newvar=-5",
-			init2:                    "TX.newvar=+5",
-			expectInvalidSyntaxError: false,
-			expectNewVarValue:        "0",
+			name:                  "Numerical operation - with existing negative variable",
+			init:                  "TX.newvar=-5",
+			init2:                 "TX.newvar=+5",
+			expectKeyNotFoundWarn: false,
+			expectNewVarValue:     "0",
 		},
 		{
-			name:                     "Numerical operation + with missing (or non-numerical) variable",
-			init:                     "TX.newvar=+%{tx.missingvar}",
-			expectInvalidSyntaxError: true,
+			name:                  "Numerical operation + with missing variable",
+			init:                  "TX.newvar=+%{tx.missingvar}",
+			expectKeyNotFoundWarn: true,
+			expectNewVarValue:     "0",
 		},
 		{
-			name:                     "Numerical operation - with missing (or non-numerical) variable",
-			init:                     "TX.newvar=-%{tx.missingvar}",
-			expectInvalidSyntaxError: true,
+			name:                  "Numerical operation - with missing variable",
+			init:                  "TX.newvar=-%{tx.missingvar}",
+			expectKeyNotFoundWarn: true,
+			expectNewVarValue:     "0",
 		},
 		{
-			name:                     "Non Numerical Operation - If the value starts with -",
-			init:                     "TX.newvar=----expected_value",
-			expectInvalidSyntaxError: false,
-			expectNewVarValue:        "----expected_value",
+			name:              "Numerical accumulation with the same variable",
+			init:              "TX.newvar=3",
+			init2:             "TX.newvar=+%{tx.newvar}",
+			expectNewVarValue: "6",
 		},
 		{
-			name:                     "Non Numerical Operation - If the value starts with +",
-			init:                     "TX.newvar=+++expected_value",
-			expectInvalidSyntaxError: false,
-			expectNewVarValue:        "+++expected_value",
+			name:                  "Non Numerical Operation - If the value starts with -",
+			init:                  "TX.newvar=----expected_value",
+			expectKeyNotFoundWarn: false,
+			expectNewVarValue:     "----expected_value",
+		},
+		{
+			name:                  "Non Numerical Operation - If the value starts with +",
+			init:                  "TX.newvar=+++expected_value",
+			expectKeyNotFoundWarn: false,
+			expectNewVarValue:     "+++expected_value",
+		},
+		{
+			name:              "Numerical operation + with non numerical expanded variable",
+			init:              "TX.0=abc",
+			init2:             "TX.newvar=+%{tx.0}",
+			expectNewVarValue: "0",
+		},
+		{
+			name:              "Numerical operation + with expanded variable starting with a number",
+			init:              "TX.0=12abc",
+			init2:             "TX.newvar=+%{tx.0}",
+			expectNewVarValue: "12",
+		},
+		{
+			name:              "Numerical operation - with expanded variable starting with spaces",
+			init:              "TX.0= 7 ",
+			init2:             "TX.newvar=-%{tx.0}",
+			expectNewVarValue: "-7",
 		},
 	}
 
@@ -129,16 +155,12 @@ func TestSetvarEvaluate(t *testing.T) {
 			tx := waf.NewTransaction()
 			a.Evaluate(metadata, tx)
 
-			if tt.expectInvalidSyntaxError {
+			if tt.expectKeyNotFoundWarn {
 				t.Log(logsBuf.String())
 				if logsBuf.Len() == 0 {
 					t.Fatal("expected logs")
 				}
 
-				if !strings.Contains(logsBuf.String(), invalidSyntaxAtoiError) {
-					t.Errorf("expected error log containing %q, got %q", invalidSyntaxAtoiError, logsBuf.String())
-				}
-
 				if !strings.Contains(logsBuf.String(), warningKeyNotFoundInCollection) {
 					t.Errorf("expected error log containing %q, got %q", warningKeyNotFoundInCollection, logsBuf.String())
 				}
@@ -151,7 +173,7 @@ func TestSetvarEvaluate(t *testing.T) {
 					t.Fatal("unexpected error during setvar init")
 				}
 				a.Evaluate(metadata, tx)
-				if logsBuf.Len() != 0 && !tt.expectInvalidSyntaxError {
+				if logsBuf.Len() != 0 && !tt.expectKeyNotFoundWarn {
 					t.Fatalf("unexpected error: %s", logsBuf.String())
 				}
 			}
@@ -162,6 +184,27 @@ func TestSetvarEvaluate(t *testing.T) {
 	}
 }
 
+func TestLeadingInt(t *testing.T) {
+	tests := map[string]int{
+		"":                     0,
+		"abc":                  0,
+		"12":                   12,
+		"12abc":                12,
+		"  5":                  5,
+		"-3x":                  -3,
+		"+4":                   4,
+		"-":                    0,
+		"1.5":                  1,
+		"a1":                   0,
+		"99999999999999999999": math.MaxInt,
+	}
+	for input, want := range tests {
+		if have := leadingInt(input); have != want {
+			t.Errorf("unexpected value for %q, want %d, have %d", input, want, have)
+		}
+	}
+}
+
 func checkCollectionValue(t *testing.T, a *setvarFn, tx plugintypes.TransactionState, key string, expected string) {
 	t.Helper()
 	var col collection.Map
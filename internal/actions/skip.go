@@ -18,6 +18,7 @@ import (
 // It only within the current processing phase and not necessarily in the order in which the rules appear in the configuration file.
 // If you place a phase 2 rule after a phase 1 rule that uses skip, it will not skip over the phase 2 rule,
 // it will skip over the next phase 1 rule that follows it in the phase.
+// A chain of rules counts as a single rule, while `SecMarker` directives are not counted.
 //
 // Example:
 // ```
@@ -45,12 +45,15 @@ type skipafterFn struct {
 	data string
 }
 
-func (a *skipafterFn) Init(_ plugintypes.RuleMetadata, data string) error {
+func (a *skipafterFn) Init(r plugintypes.RuleMetadata, data string) error {
 	data = utils.MaybeRemoveQuotes(data)
 	if len(data) == 0 {
 		return ErrMissingArguments
 	}
 	a.data = data
+	if rule, ok := r.(*corazawaf.Rule); ok {
+		rule.SkipAfter = data
+	}
 	return nil
 }
 
@@ -6,7 +6,9 @@ package actions
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/corazawaf/coraza/v3/experimental/plugins/macro"
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
 )
@@ -15,12 +17,17 @@ import (
 //
 // Description:
 // Specifies the response status code to use with actions deny and redirect.
+// It also applies to block, as it resolves to the disruptive action defined by SecDefaultAction.
 // If status is not set, deny action defaults to status 403.
+// The value can be a macro, which is expanded when the disruptive action is executed.
 //
 // Example:
 // ```
 // # Deny status 403
 // SecDefaultAction "phase:1,log,deny,id:145,status:403"
+//
+// # Deny with the status stored in a transaction variable
+// SecRule REQUEST_URI "@streq /admin" "phase:1,id:146,deny,status:%{tx.blocking_status}"
 // ```
 type statusFn struct{}
 
@@ -29,6 +36,15 @@ func (a *statusFn) Init(r plugintypes.RuleMetadata, data string) error {
 		return ErrMissingArguments
 	}
 
+	if strings.Contains(data, "%{") {
+		m, err := macro.NewMacro(data)
+		if err != nil {
+			return err
+		}
+		r.(*corazawaf.Rule).DisruptiveStatusMacro = m
+		return nil
+	}
+
 	// TODO(jcchavezs): Shall we validate valid status e.g. >200 && <600?
 	status, err := strconv.Atoi(data)
 	if err != nil {
@@ -48,6 +64,27 @@ func status() plugintypes.Action {
 	return &statusFn{}
 }
 
+// ruleStatus returns the status to be used by the disruptive actions of the rule,
+// expanding the status macro if needed. It returns noStatus if the expanded
+// value is not a valid number.
+func ruleStatus(r plugintypes.RuleMetadata, tx plugintypes.TransactionState) int {
+	rule, ok := r.(*corazawaf.Rule)
+	if !ok || rule.DisruptiveStatusMacro == nil {
+		return r.Status()
+	}
+
+	value := rule.DisruptiveStatusMacro.Expand(tx)
+	status, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		tx.DebugLogger().Warn().
+			Int("rule_id", r.ID()).
+			Str("value", value).
+			Msg("Invalid expanded status, ignoring it")
+		return noStatus
+	}
+	return status
+}
+
 var (
 	_ plugintypes.Action = &statusFn{}
 	_ ruleActionWrapper  = status
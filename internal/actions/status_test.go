@@ -0,0 +1,148 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+func TestStatusInit(t *testing.T) {
+	t.Run("no arguments", func(t *testing.T) {
+		a := status()
+		if err := a.Init(corazawaf.NewRule(), ""); err != ErrMissingArguments {
+			t.Error("expected error ErrMissingArguments")
+		}
+	})
+
+	t.Run("invalid argument", func(t *testing.T) {
+		a := status()
+		if err := a.Init(corazawaf.NewRule(), "abc"); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("invalid macro", func(t *testing.T) {
+		a := status()
+		if err := a.Init(corazawaf.NewRule(), "%{tx.status"); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("numeric argument", func(t *testing.T) {
+		a := status()
+		r := corazawaf.NewRule()
+		if err := a.Init(r, "418"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want, have := 418, r.DisruptiveStatus; want != have {
+			t.Errorf("unexpected status, want %d, have %d", want, have)
+		}
+	})
+
+	t.Run("macro argument", func(t *testing.T) {
+		a := status()
+		r := corazawaf.NewRule()
+		if err := a.Init(r, "%{tx.status}"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if r.DisruptiveStatusMacro == nil {
+			t.Error("expected status macro to be set")
+		}
+	})
+}
+
+func TestStatusPropagatesToInterruption(t *testing.T) {
+	tests := map[string]struct {
+		status         string
+		txStatus       string
+		action         string
+		actionArgs     string
+		expectedStatus int
+	}{
+		"deny": {
+			status:         "418",
+			action:         "deny",
+			expectedStatus: 418,
+		},
+		"deny without status": {
+			action:         "deny",
+			expectedStatus: 403,
+		},
+		"deny with macro": {
+			status:         "%{tx.status}",
+			txStatus:       "429",
+			action:         "deny",
+			expectedStatus: 429,
+		},
+		"deny with invalid expanded macro": {
+			status:         "%{tx.status}",
+			txStatus:       "abc",
+			action:         "deny",
+			expectedStatus: 403,
+		},
+		"redirect": {
+			status:         "307",
+			action:         "redirect",
+			actionArgs:     "https://www.example.com",
+			expectedStatus: 307,
+		},
+		"redirect with macro": {
+			status:         "%{tx.status}",
+			txStatus:       "301",
+			action:         "redirect",
+			actionArgs:     "https://www.example.com",
+			expectedStatus: 301,
+		},
+		"redirect with non redirect status": {
+			status:         "418",
+			action:         "redirect",
+			actionArgs:     "https://www.example.com",
+			expectedStatus: 302,
+		},
+		"drop": {
+			status:         "418",
+			action:         "drop",
+			expectedStatus: 418,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := corazawaf.NewRule()
+			r.ID_ = 1
+			if tt.status != "" {
+				if err := status().Init(r, tt.status); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			a, err := Get(tt.action)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := a.Init(r, tt.actionArgs); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tx := corazawaf.NewWAF().NewTransaction()
+			if tt.txStatus != "" {
+				tx.Variables().TX().Set("status", []string{tt.txStatus})
+			}
+			a.Evaluate(r, tx)
+
+			it := tx.Interruption()
+			if it == nil {
+				t.Fatal("expected interruption")
+			}
+			if want, have := tt.expectedStatus, it.Status; want != have {
+				t.Errorf("unexpected status, want %d, have %d", want, have)
+			}
+			if want, have := tt.action, it.Action; want != have {
+				t.Errorf("unexpected action, want %q, have %q", want, have)
+			}
+		})
+	}
+}
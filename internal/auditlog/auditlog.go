@@ -87,6 +87,9 @@ type Transaction struct {
 	Producer_        *TransactionProducer `json:"producer,omitempty"`
 	HighestSeverity_ string               `json:"highest_severity"`
 	IsInterrupted_   bool                 `json:"is_interrupted"`
+	// Interruption is the disruptive action taken, nil if the transaction
+	// was not interrupted
+	Interruption_ *TransactionInterruption `json:"interruption,omitempty"`
 }
 
 var _ plugintypes.AuditLogTransaction = Transaction{}
@@ -151,6 +154,47 @@ func (t Transaction) IsInterrupted() bool {
 	return t.IsInterrupted_
 }
 
+func (t Transaction) Interruption() *TransactionInterruption {
+	return t.Interruption_
+}
+
+// TransactionInterruption contains the disruptive
+// action that interrupted the transaction
+type TransactionInterruption struct {
+	RuleID_ int    `json:"rule_id"`
+	Action_ string `json:"action"`
+	Status_ int    `json:"status"`
+	Data_   string `json:"data,omitempty"`
+}
+
+func (ti *TransactionInterruption) RuleID() int {
+	if ti == nil {
+		return 0
+	}
+	return ti.RuleID_
+}
+
+func (ti *TransactionInterruption) Action() string {
+	if ti == nil {
+		return ""
+	}
+	return ti.Action_
+}
+
+func (ti *TransactionInterruption) Status() int {
+	if ti == nil {
+		return 0
+	}
+	return ti.Status_
+}
+
+func (ti *TransactionInterruption) Data() string {
+	if ti == nil {
+		return ""
+	}
+	return ti.Data_
+}
+
 // TransactionResponse contains response specific
 // information
 type TransactionResponse struct {
@@ -7,11 +7,15 @@
 package auditlog
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"log"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -40,10 +44,18 @@ func (cl *concurrentWriter) Init(c plugintypes.AuditLogConfig) error {
 	cl.formatter = c.Formatter
 	cl.mux = &sync.RWMutex{}
 
+	_, statErr := os.Stat(c.Target)
 	f, err := os.OpenFile(c.Target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, cl.logFileMode)
 	if err != nil {
 		return err
 	}
+	if errors.Is(statErr, fs.ErrNotExist) {
+		// the mode passed to OpenFile is restricted by the umask
+		if err := f.Chmod(cl.logFileMode); err != nil {
+			f.Close()
+			return err
+		}
+	}
 	cl.Closer = f
 
 	cl.log = log.New(f, "", 0)
@@ -65,39 +77,96 @@ func (cl concurrentWriter) Write(al plugintypes.AuditLog) error {
 	}
 
 	// 192.168.3.130 192.168.3.1 - - [22/Aug/2009:13:24:20 +0100] "GET / HTTP/1.1" 200 56 "-" "-" SojdH8AAQEAAAugAQAAAAAA "-" /20090822/20090822-1324/20090822-132420-SojdH8AAQEAAAugAQAAAAAA 0 1248
-	t := time.Unix(0, al.Transaction().UnixTimestamp())
+	tx := al.Transaction()
+	t := time.Unix(0, tx.UnixTimestamp())
 
 	ymd := t.Format("20060102")
 	ymdhm := ymd + t.Format("-1504")
-	filename := ymdhm + t.Format("05") + "-" + al.Transaction().ID()
+	filename := ymdhm + t.Format("05") + "-" + tx.ID()
 
-	logdir := path.Join(cl.logDir, ymd, ymdhm)
-	if err := os.MkdirAll(logdir, cl.logDirMode); err != nil {
+	// the index references the files relative to the storage directory
+	relpath := path.Join("/", ymd, ymdhm, filename)
+	if err := cl.mkdirAll(path.Join(cl.logDir, ymd, ymdhm)); err != nil {
 		return err
 	}
 
-	filepath := path.Join(logdir, filename)
-	if err = os.WriteFile(filepath, formattedAL, cl.logFileMode); err != nil {
+	if err := cl.writeFile(path.Join(cl.logDir, relpath), formattedAL); err != nil {
 		return err
 	}
 
+	requestLine, status := "-", "-"
+	if tx.HasRequest() {
+		protocol := tx.Request().Protocol()
+		if protocol == "" {
+			protocol = tx.Request().HTTPVersion()
+		}
+		requestLine = strconv.Quote(fmt.Sprintf("%s %s %s", tx.Request().Method(), tx.Request().URI(), protocol))
+	}
+	if tx.HasResponse() && tx.Response().Status() != 0 {
+		status = strconv.Itoa(tx.Response().Status())
+	}
+
+	// the whole entry is written at once, so the lines of concurrent writes
+	// are not interleaved
 	cl.mux.Lock()
 	defer cl.mux.Unlock()
+	cl.log.Printf("%s %s - - [%s] %s %s - %q %q %s \"-\" %s 0 %d",
+		tx.ClientIP(), tx.HostIP(), t.Format(nativeTimestampLayout), requestLine, status,
+		indexHeader(tx, "referer"), indexHeader(tx, "user-agent"), tx.ID(), relpath, len(formattedAL))
+
+	return nil
+}
 
-	cl.log.Printf("%s %s - - [%s]", al.Transaction().ClientIP(), al.Transaction().HostIP(), al.Transaction().Timestamp())
-	if al.Transaction().HasRequest() {
-		cl.log.Printf(
-			` "%s %s %s"`,
-			al.Transaction().Request().Method(),
-			al.Transaction().Request().URI(),
-			al.Transaction().Request().HTTPVersion())
+// indexHeader returns the first value of the request header for the index
+// line, "-" if it is missing or the request headers are not logged.
+func indexHeader(tx plugintypes.AuditLogTransaction, name string) string {
+	if !tx.HasRequest() {
+		return "-"
 	}
-	if al.Transaction().HasResponse() {
-		cl.log.Printf(` %d`, al.Transaction().Response().Status())
+	for k, v := range tx.Request().Headers() {
+		if strings.EqualFold(k, name) && len(v) > 0 {
+			return v[0]
+		}
 	}
-	cl.log.Printf("%s - %s\n", al.Transaction().ID(), filepath)
+	return "-"
+}
 
-	return nil
+// mkdirAll creates the directory and its missing parents with logDirMode. Unlike
+// os.MkdirAll, the mode of the created directories is not restricted by the umask.
+func (cl concurrentWriter) mkdirAll(dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if parent := path.Dir(dir); parent != dir {
+		if err := cl.mkdirAll(parent); err != nil {
+			return err
+		}
+	}
+	if err := os.Mkdir(dir, cl.logDirMode); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			// created meanwhile by another write
+			return nil
+		}
+		return err
+	}
+	return os.Chmod(dir, cl.logDirMode)
+}
+
+// writeFile writes the audit log file with logFileMode, regardless of the umask.
+func (cl concurrentWriter) writeFile(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, cl.logFileMode)
+	if err != nil {
+		return err
+	}
+	if err := f.Chmod(cl.logFileMode); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 var _ plugintypes.AuditLogWriter = (*concurrentWriter)(nil)
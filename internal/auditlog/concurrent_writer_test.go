@@ -14,6 +14,11 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -151,3 +156,141 @@ func TestConcurrentWriterSuccess(t *testing.T) {
 		t.Errorf("unexpected log entry, want:\n%s, have:\n%s", expectedLogStr, logData)
 	}
 }
+
+func TestConcurrentWriterModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on windows")
+	}
+
+	dir := t.TempDir()
+	config := plugintypes.AuditLogConfig{
+		Target: filepath.Join(dir, "audit.log"),
+		Dir:    dir,
+		// modes that a usual umask of 022 would restrict
+		FileMode:  fs.FileMode(0660),
+		DirMode:   fs.FileMode(0770),
+		Formatter: &jsonFormatter{},
+	}
+
+	writer := &concurrentWriter{}
+	if err := writer.Init(config); err != nil {
+		t.Fatal("failed to init concurrent logger", err)
+	}
+	defer writer.Close()
+
+	ts := time.Now()
+	if err := writer.Write(&Log{
+		Transaction_: Transaction{
+			UnixTimestamp_: ts.UnixNano(),
+			ID_:            "123",
+		},
+	}); err != nil {
+		t.Fatal("failed to write to logger: ", err)
+	}
+
+	ymdDir := filepath.Join(dir, ts.Format("20060102"))
+	ymdhmDir := filepath.Join(ymdDir, ts.Format("20060102-1504"))
+	logFile := filepath.Join(ymdhmDir, ts.Format("20060102-150405")+"-123")
+	for name, want := range map[string]fs.FileMode{
+		config.Target: 0660,
+		ymdDir:        fs.ModeDir | 0770,
+		ymdhmDir:      fs.ModeDir | 0770,
+		logFile:       0660,
+	} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := info.Mode(); have != want {
+			t.Errorf("unexpected mode for %s, want %s, have %s", name, want, have)
+		}
+	}
+}
+
+func TestConcurrentWriterIndex(t *testing.T) {
+	dir := t.TempDir()
+	config := plugintypes.AuditLogConfig{
+		Target:    filepath.Join(dir, "index"),
+		Dir:       filepath.Join(dir, "storage"),
+		FileMode:  fs.FileMode(0644),
+		DirMode:   fs.FileMode(0755),
+		Formatter: &jsonFormatter{},
+	}
+
+	writer := &concurrentWriter{}
+	if err := writer.Init(config); err != nil {
+		t.Fatal("failed to init concurrent logger", err)
+	}
+	defer writer.Close()
+
+	ts := time.Date(2009, 8, 22, 13, 24, 20, 0, time.Local)
+	const txs = 20
+	var wg sync.WaitGroup
+	for i := 0; i < txs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := writer.Write(&Log{
+				Transaction_: Transaction{
+					UnixTimestamp_: ts.UnixNano(),
+					ID_:            fmt.Sprintf("tx%d", i),
+					ClientIP_:      "192.168.3.130",
+					HostIP_:        "192.168.3.1",
+					Request_: &TransactionRequest{
+						Method_:   "GET",
+						URI_:      fmt.Sprintf("/?id=%d", i),
+						Protocol_: "HTTP/1.1",
+						Headers_:  map[string][]string{"user-agent": {"curl/8.0"}},
+					},
+					Response_: &TransactionResponse{
+						Status_: 200,
+					},
+				},
+			}); err != nil {
+				t.Error("failed to write to logger: ", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	index, err := os.ReadFile(config.Target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(index), "\n"), "\n")
+	if len(lines) != txs {
+		t.Fatalf("unexpected index entries, want %d, have %d:\n%s", txs, len(lines), index)
+	}
+
+	lineRx := regexp.MustCompile(`^192\.168\.3\.130 192\.168\.3\.1 - - \[22/Aug/2009:13:24:20 [+-]\d{4}\] "GET /\?id=(\d+) HTTP/1\.1" 200 - "-" "curl/8\.0" (tx\d+) "-" (/20090822/20090822-1324/20090822-132420-tx\d+) 0 (\d+)$`)
+	seen := map[string]bool{}
+	for _, line := range lines {
+		m := lineRx.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("unexpected index entry %q", line)
+		}
+		id, relpath := m[2], m[3]
+		if "tx"+m[1] != id || !strings.HasSuffix(relpath, "-"+id) {
+			t.Errorf("index entry references another transaction: %q", line)
+		}
+		seen[id] = true
+
+		data, err := os.ReadFile(filepath.Join(config.Dir, relpath))
+		if err != nil {
+			t.Fatalf("failed to read the file of %s: %s", id, err.Error())
+		}
+		if size, _ := strconv.Atoi(m[4]); size != len(data) {
+			t.Errorf("unexpected size of %s, want %d, have %d", id, len(data), size)
+		}
+		al := &Log{}
+		if err := json.Unmarshal(data, al); err != nil {
+			t.Fatal(err)
+		}
+		if have := al.Transaction().ID(); have != id {
+			t.Errorf("unexpected transaction in the file of %s, have %s", id, have)
+		}
+	}
+	if len(seen) != txs {
+		t.Errorf("unexpected logged transactions, want %d, have %d", txs, len(seen))
+	}
+}
@@ -21,10 +21,13 @@ package auditlog
 
 import (
 	"fmt"
+	"math/rand"
+	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
-	utils "github.com/corazawaf/coraza/v3/internal/strings"
 	"github.com/corazawaf/coraza/v3/types"
 )
 
@@ -32,96 +35,106 @@ type nativeFormatter struct{}
 
 type auditLogWithErrMesg interface{ ErrorMessage() string }
 
+// nativePartsOrder is the order in which ModSecurity writes the sections of
+// the native format, regardless of the order they are configured in
+// SecAuditLogParts.
+var nativePartsOrder = []types.AuditLogPart{
+	types.AuditLogPartRequestHeaders,
+	types.AuditLogPartRequestBody,
+	types.AuditLogPartResponseHeaders,
+	types.AuditLogPartIntermediaryResponseBody,
+	types.AuditLogPartAuditLogTrailer,
+	types.AuditLogPartRulesMatched,
+}
+
+// nativeTimestampLayout is the layout of the timestamp in the A section,
+// e.g. 27/Jul/2016:05:46:16 +0200
+const nativeTimestampLayout = "02/Jan/2006:15:04:05 -0700"
+
+// Format serializes the audit log using the ModSecurity native (serial)
+// format so existing ModSecurity log parsers can consume it. Every section
+// starts with a --<boundary>-<part>-- marker, the log always starts with
+// section A and ends with section Z.
 func (nativeFormatter) Format(al plugintypes.AuditLog) ([]byte, error) {
 	if len(al.Parts()) == 0 {
 		return nil, nil
 	}
 
-	boundaryPrefix := fmt.Sprintf("--%s-", utils.RandomString(10))
+	boundary := nativeBoundary()
+	tx := al.Transaction()
 
 	var res strings.Builder
 
-	for _, part := range al.Parts() {
-		res.WriteString(boundaryPrefix)
-		res.WriteByte(byte(part))
-		res.WriteString("--\n")
-		// [27/Jul/2016:05:46:16 +0200] V5guiH8AAQEAADTeJ2wAAAAK 192.168.3.1 50084 192.168.3.111 80
-		_, _ = fmt.Fprintf(&res, "[%s] %s %s %d %s %d", al.Transaction().Timestamp(), al.Transaction().ID(),
-			al.Transaction().ClientIP(), al.Transaction().ClientPort(), al.Transaction().HostIP(), al.Transaction().HostPort())
+	// [27/Jul/2016:05:46:16 +0200] V5guiH8AAQEAADTeJ2wAAAAK 192.168.3.1 50084 192.168.3.111 80
+	writeNativeSection(&res, boundary, 'A')
+	_, _ = fmt.Fprintf(&res, "[%s] %s %s %d %s %d\n", time.Unix(0, tx.UnixTimestamp()).Format(nativeTimestampLayout),
+		tx.ID(), tx.ClientIP(), tx.ClientPort(), tx.HostIP(), tx.HostPort())
+
+	for _, part := range nativePartsOrder {
+		if !slices.Contains(al.Parts(), part) {
+			continue
+		}
+		writeNativeSection(&res, boundary, part)
 		switch part {
 		case types.AuditLogPartRequestHeaders:
 			// GET /url HTTP/1.1
 			// Host: example.com
 			// User-Agent: Mozilla/5.0
-			// Accept: text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8
-			// Accept-Language: en-US,en;q=0.5
-			// Accept-Encoding: gzip, deflate
-			// Referer: http://example.com/index.html
-			// Connection: keep-alive
-			// Content-Type: application/x-www-form-urlencoded
-			// Content-Length: 6
-			_, _ = fmt.Fprintf(
-				&res,
-				"\n%s %s %s",
-				al.Transaction().Request().Method(),
-				al.Transaction().Request().URI(),
-				al.Transaction().Request().Protocol(),
-			)
-			for k, vv := range al.Transaction().Request().Headers() {
-				for _, v := range vv {
-					res.WriteByte('\n')
-					res.WriteString(k)
-					res.WriteString(": ")
-					res.WriteString(v)
-				}
-			}
+			_, _ = fmt.Fprintf(&res, "%s %s %s\n", tx.Request().Method(), tx.Request().URI(), tx.Request().Protocol())
+			writeNativeHeaders(&res, tx.Request().Headers())
+			res.WriteByte('\n')
 		case types.AuditLogPartRequestBody:
-			if body := al.Transaction().Request().Body(); body != "" {
-				res.WriteByte('\n')
+			if body := tx.Request().Body(); body != "" {
 				res.WriteString(body)
-			}
-		case types.AuditLogPartIntermediaryResponseBody:
-			if body := al.Transaction().Response().Body(); body != "" {
 				res.WriteByte('\n')
-				res.WriteString(al.Transaction().Response().Body())
 			}
 		case types.AuditLogPartResponseHeaders:
-			for k, vv := range al.Transaction().Response().Headers() {
-				for _, v := range vv {
-					res.WriteByte('\n')
-					res.WriteString(k)
-					res.WriteString(": ")
-					res.WriteString(v)
-				}
+			// HTTP/1.1 403 Forbidden
+			// Content-Type: text/html
+			if status := tx.Response().Status(); status != 0 {
+				_, _ = fmt.Fprintf(&res, "%s %d %s\n", tx.Response().Protocol(), status, http.StatusText(status))
+			}
+			writeNativeHeaders(&res, tx.Response().Headers())
+			res.WriteByte('\n')
+		case types.AuditLogPartIntermediaryResponseBody:
+			if body := tx.Response().Body(); body != "" {
+				res.WriteString(body)
+				res.WriteByte('\n')
 			}
 		case types.AuditLogPartAuditLogTrailer:
+			// Message: Access denied (phase 2). Matched Data: ...
 			// Stopwatch: 1470025005945403 1715 (- - -)
-			// Stopwatch2: 1470025005945403 1715; combined=26, p1=0, p2=0, p3=0, p4=0, p5=26, ↩
-			// sr=0, sw=0, l=0, gc=0
-			// Response-Body-Transformed: Dechunked
+			// Stopwatch2: 1470025005945403 1715; combined=26, p1=0, p2=0, p3=0, p4=0, p5=26
 			// Producer: ModSecurity for Apache/2.9.1 (http://www.modsecurity.org/).
 			// Server: Apache
 			// Engine-Mode: "ENABLED"
-
-			// AuditLogTrailer is also expected to contain the error message generated by the rule, if any
 			for _, alEntry := range al.Messages() {
 				alWithErrMsg, ok := alEntry.(auditLogWithErrMesg)
 				if ok && alWithErrMsg.ErrorMessage() != "" {
+					res.WriteString("Message: ")
+					res.WriteString(nativeMessage(alWithErrMsg.ErrorMessage()))
 					res.WriteByte('\n')
-					res.WriteString(alWithErrMsg.ErrorMessage())
 				}
 			}
-
-			_, _ = fmt.Fprintf(&res, "\nStopwatch: %s\nResponse-Body-Transformed: %s\nProducer: %s\nServer: %s", "", "", "", "")
+			if tx.IsInterrupted() {
+				res.WriteString("Action: Intercepted\n")
+			}
+			writeNativeProducer(&res, tx.Producer())
+			res.WriteByte('\n')
 		case types.AuditLogPartRulesMatched:
 			for _, alEntry := range al.Messages() {
-				res.WriteByte('\n')
+				if alEntry.Data() == nil {
+					continue
+				}
 				res.WriteString(alEntry.Data().Raw())
+				res.WriteByte('\n')
 			}
+			res.WriteByte('\n')
 		}
-		res.WriteByte('\n')
 	}
 
+	writeNativeSection(&res, boundary, 'Z')
+
 	return []byte(res.String()), nil
 }
 
@@ -129,6 +142,87 @@ func (nativeFormatter) MIME() string {
 	return "application/x-coraza-auditlog-native"
 }
 
+// nativeBoundary returns a random boundary of 8 hexadecimal characters,
+// as generated by ModSecurity.
+func nativeBoundary() string {
+	return fmt.Sprintf("%08x", rand.Uint32())
+}
+
+func writeNativeSection(res *strings.Builder, boundary string, part types.AuditLogPart) {
+	res.WriteString("--")
+	res.WriteString(boundary)
+	res.WriteByte('-')
+	res.WriteByte(byte(part))
+	res.WriteString("--\n")
+}
+
+// writeNativeHeaders writes the headers sorted by name, as the audit log
+// does not keep the order they were received in.
+func writeNativeHeaders(res *strings.Builder, headers map[string][]string) {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range headers[k] {
+			res.WriteString(k)
+			res.WriteString(": ")
+			res.WriteString(v)
+			res.WriteByte('\n')
+		}
+	}
+}
+
+// nativeMessage drops the error log prefix (e.g. `[client "1.2.3.4"] Coraza: `)
+// as ModSecurity does not include it in the H section messages.
+func nativeMessage(errorLog string) string {
+	if _, msg, ok := strings.Cut(errorLog, "Coraza: "); ok {
+		return msg
+	}
+	return errorLog
+}
+
+func writeNativeProducer(res *strings.Builder, p plugintypes.AuditLogTransactionProducer) {
+	if p == nil {
+		return
+	}
+
+	if sw := p.Stopwatch(); sw != "" {
+		// Stopwatch only carries the timestamp and duration, the per phase
+		// breakdown goes to Stopwatch2.
+		start, _, _ := strings.Cut(sw, ";")
+		_, _ = fmt.Fprintf(res, "Stopwatch: %s (- - -)\nStopwatch2: %s\n", start, sw)
+	}
+
+	producer := []string{"Coraza"}
+	if p.Connector() != "" {
+		producer[0] = strings.TrimSpace(p.Connector() + " " + p.Version())
+	}
+	producer = append(producer, p.Rulesets()...)
+	_, _ = fmt.Fprintf(res, "Producer: %s.\n", strings.Join(producer, "; "))
+
+	if p.Server() != "" {
+		_, _ = fmt.Fprintf(res, "Server: %s\n", p.Server())
+	}
+
+	_, _ = fmt.Fprintf(res, "Engine-Mode: %q\n", nativeEngineMode(p.RuleEngine()))
+}
+
+// nativeEngineMode maps the SecRuleEngine value to the ModSecurity
+// Engine-Mode values.
+func nativeEngineMode(ruleEngine string) string {
+	switch ruleEngine {
+	case types.RuleEngineOn.String():
+		return "ENABLED"
+	case types.RuleEngineDetectionOnly.String():
+		return "DETECTION_ONLY"
+	case types.RuleEngineOff.String():
+		return "DISABLED"
+	}
+	return strings.ToUpper(ruleEngine)
+}
+
 var (
 	_ plugintypes.AuditLogFormatter = (*nativeFormatter)(nil)
 )
@@ -7,8 +7,10 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	"github.com/corazawaf/coraza/v3/types"
@@ -56,10 +58,6 @@ func TestNativeFormatter(t *testing.T) {
 		if !strings.Contains(f.MIME(), "x-coraza-auditlog-native") {
 			t.Errorf("failed to match MIME, expected json and got %s", f.MIME())
 		}
-		// Log contains random strings, do a simple sanity check
-		if !bytes.Contains(data, []byte("[02/Jan/2006:15:04:20 -0700] 123  0  0")) {
-			t.Errorf("failed to match log, \ngot: %s\n", string(data))
-		}
 
 		scanner := bufio.NewScanner(bytes.NewReader(data))
 
@@ -68,26 +66,144 @@ func TestNativeFormatter(t *testing.T) {
 			lines = append(lines, scanner.Text())
 		}
 		separator := lines[0]
+		if !nativeSeparatorRx.MatchString(separator) {
+			t.Fatalf("unexpected separator %q", separator)
+		}
+
+		checkLine(t, lines, 1, fmt.Sprintf("[%s] 123  0  0", time.Unix(0, 0).Format(nativeTimestampLayout)))
+		checkLine(t, lines, 2, mutateSeparator(separator, 'B'))
+		checkLine(t, lines, 3, "GET /test.php HTTP/1.1")
+		checkLine(t, lines, 4, "some: request header")
+		checkLine(t, lines, 6, mutateSeparator(separator, 'C'))
+		checkLine(t, lines, 7, "some request body")
+		checkLine(t, lines, 8, mutateSeparator(separator, 'F'))
+		checkLine(t, lines, 9, " 200 OK")
+		checkLine(t, lines, 10, "some: response header")
+		checkLine(t, lines, 12, mutateSeparator(separator, 'E'))
+		checkLine(t, lines, 13, "some response body")
+		checkLine(t, lines, 14, mutateSeparator(separator, 'H'))
+		checkLine(t, lines, 15, "Message: error message")
+		checkLine(t, lines, 16, "Producer: some connector 1.2.3.")
+		checkLine(t, lines, 17, `Engine-Mode: ""`)
+		checkLine(t, lines, 19, mutateSeparator(separator, 'K'))
+		checkLine(t, lines, 20, `SecAction "id:100"`)
+		checkLine(t, lines, 22, mutateSeparator(separator, 'Z'))
+	})
+
+	t.Run("parts order", func(t *testing.T) {
+		al := createAuditLog()
+		al.Parts_ = types.AuditLogParts("KHEFCB")
+		data, err := f.Format(al)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-		checkLine(t, lines, 2, "GET /test.php HTTP/1.1")
-		checkLine(t, lines, 3, "some: request header")
-		checkLine(t, lines, 4, mutateSeparator(separator, 'C'))
-		checkLine(t, lines, 6, "some request body")
-		checkLine(t, lines, 7, mutateSeparator(separator, 'E'))
-		checkLine(t, lines, 9, "some response body")
-		checkLine(t, lines, 10, mutateSeparator(separator, 'F'))
-		checkLine(t, lines, 12, "some: response header")
-		checkLine(t, lines, 13, mutateSeparator(separator, 'H'))
-		checkLine(t, lines, 15, "error message")
-		checkLine(t, lines, 16, "Stopwatch: ")
-		checkLine(t, lines, 17, "Response-Body-Transformed: ")
-		checkLine(t, lines, 18, "Producer: ")
-		checkLine(t, lines, 19, "Server: ")
-		checkLine(t, lines, 20, mutateSeparator(separator, 'K'))
-		checkLine(t, lines, 22, `SecAction "id:100"`)
+		var parts []byte
+		for _, m := range nativeSeparatorRx.FindAllSubmatch(data, -1) {
+			parts = append(parts, m[1]...)
+		}
+		if want, have := "ABCFEHKZ", string(parts); want != have {
+			t.Errorf("unexpected parts order, want %q, have %q", want, have)
+		}
+	})
+
+	t.Run("modsecurity golden sample", func(t *testing.T) {
+		al := &Log{
+			Parts_: types.AuditLogParts("BFEHK"),
+			Transaction_: Transaction{
+				UnixTimestamp_: 1469591176000000000,
+				ID_:            "V5guiH8AAQEAADTeJ2wAAAAK",
+				ClientIP_:      "192.168.3.1",
+				ClientPort_:    50084,
+				HostIP_:        "192.168.3.111",
+				HostPort_:      80,
+				IsInterrupted_: true,
+				Request_: &TransactionRequest{
+					Method_:   "GET",
+					URI_:      "/?a=<script>",
+					Protocol_: "HTTP/1.1",
+					Headers_: map[string][]string{
+						"host":       {"192.168.3.111"},
+						"user-agent": {"curl/7.47.0"},
+						"accept":     {"*/*"},
+					},
+				},
+				Response_: &TransactionResponse{
+					Protocol_: "HTTP/1.1",
+					Status_:   403,
+					Headers_: map[string][]string{
+						"content-length": {"9"},
+						"content-type":   {"text/html"},
+					},
+					Body_: "Forbidden",
+				},
+				Producer_: &TransactionProducer{
+					Connector_:  "coraza-caddy",
+					Version_:    "2.0.0",
+					Server_:     "Caddy",
+					RuleEngine_: "On",
+					Stopwatch_:  "1469591176000000000 1715; combined=26, p1=0, p2=26, p3=0, p4=0, p5=0",
+					Rulesets_:   []string{"OWASP_CRS/4.0.0"},
+				},
+			},
+			Messages_: []plugintypes.AuditLogMessage{
+				&Message{
+					ErrorMessage_: `[client "192.168.3.1"] Coraza: Access denied (phase 2). XSS detected [file "rules.conf"] [line "1"] [id "100"]`,
+					Data_: &MessageData{
+						Raw_: `SecRule ARGS "@detectXSS" "id:100,phase:2,deny,msg:'XSS detected'"`,
+					},
+				},
+			},
+		}
+
+		data, err := f.Format(al)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		boundary := nativeSeparatorRx.FindSubmatch(data)[0][2 : 2+8]
+		have := strings.ReplaceAll(string(data), string(boundary), "a1b2c3d4")
+		have = strings.Replace(have, time.Unix(0, 1469591176000000000).Format(nativeTimestampLayout), "27/Jul/2016:05:46:16 +0200", 1)
+		if have != modsecurityNativeGolden {
+			t.Errorf("unexpected native audit log\nwant:\n%s\nhave:\n%s", modsecurityNativeGolden, have)
+		}
 	})
 }
 
+var nativeSeparatorRx = regexp.MustCompile(`--[0-9a-f]{8}-([A-Z])--`)
+
+// modsecurityNativeGolden is the native audit log ModSecurity writes for a
+// request denied in phase 2, with the Coraza producer details.
+const modsecurityNativeGolden = `--a1b2c3d4-A--
+[27/Jul/2016:05:46:16 +0200] V5guiH8AAQEAADTeJ2wAAAAK 192.168.3.1 50084 192.168.3.111 80
+--a1b2c3d4-B--
+GET /?a=<script> HTTP/1.1
+accept: */*
+host: 192.168.3.111
+user-agent: curl/7.47.0
+
+--a1b2c3d4-F--
+HTTP/1.1 403 Forbidden
+content-length: 9
+content-type: text/html
+
+--a1b2c3d4-E--
+Forbidden
+--a1b2c3d4-H--
+Message: Access denied (phase 2). XSS detected [file "rules.conf"] [line "1"] [id "100"]
+Action: Intercepted
+Stopwatch: 1469591176000000000 1715 (- - -)
+Stopwatch2: 1469591176000000000 1715; combined=26, p1=0, p2=26, p3=0, p4=0, p5=0
+Producer: coraza-caddy 2.0.0; OWASP_CRS/4.0.0.
+Server: Caddy
+Engine-Mode: "ENABLED"
+
+--a1b2c3d4-K--
+SecRule ARGS "@detectXSS" "id:100,phase:2,deny,msg:'XSS detected'"
+
+--a1b2c3d4-Z--
+`
+
 func createAuditLog() *Log {
 	return &Log{
 		Parts_: []types.AuditLogPart{
@@ -14,9 +14,9 @@ import (
 func NewConfig() plugintypes.AuditLogConfig {
 	return plugintypes.AuditLogConfig{
 		Target:    "",
-		FileMode:  0644,
+		FileMode:  0600,
 		Dir:       "",
-		DirMode:   0755,
+		DirMode:   0700,
 		Formatter: &nativeFormatter{},
 	}
 }
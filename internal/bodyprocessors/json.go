@@ -4,6 +4,7 @@
 package bodyprocessors
 
 import (
+	"errors"
 	"io"
 	"strconv"
 	"strings"
@@ -48,6 +49,10 @@ func readJSON(reader io.Reader) (map[string]string, error) {
 		return nil, err
 	}
 
+	if !gjson.Valid(s.String()) {
+		return nil, errors.New("invalid JSON")
+	}
+
 	json := gjson.Parse(s.String())
 	res := make(map[string]string)
 	key := []byte("json")
@@ -145,6 +145,14 @@ func TestReadJSON(t *testing.T) {
 	}
 }
 
+func TestReadInvalidJSON(t *testing.T) {
+	for _, body := range []string{`{"a":`, `{"a": 1}}`, `not json`} {
+		if _, err := readJSON(strings.NewReader(body)); err == nil {
+			t.Errorf("expected error for %q", body)
+		}
+	}
+}
+
 func BenchmarkReadJSON(b *testing.B) {
 	for _, tc := range jsonTests {
 		tt := tc
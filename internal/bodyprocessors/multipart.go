@@ -9,7 +9,6 @@ import (
 	"io"
 	"mime"
 	"mime/multipart"
-	"os"
 	"strings"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
@@ -30,7 +29,33 @@ func (mbp *multipartBodyProcessor) ProcessRequest(reader io.Reader, v plugintype
 	if !strings.HasPrefix(mediaType, "multipart/") {
 		return errors.New("not a multipart body")
 	}
-	mr := multipart.NewReader(reader, params["boundary"])
+
+	inspector := newMultipartInspector(reader, params["boundary"])
+	if err := checkBoundaryParam(mimeType, &inspector.flags); err != nil {
+		inspector.flags.set(v)
+		v.MultipartStrictError().(*collections.Single).Set("1")
+		return err
+	}
+
+	err = readMultipartParts(multipart.NewReader(inspector, params["boundary"]), v, storagePath)
+	// mime/multipart stops reading at the final boundary or at the first error,
+	// the rest of the body is consumed to look for data after the final boundary
+	// and for the anomalies of the parts that were not parsed.
+	if _, copyErr := io.Copy(io.Discard, inspector); err == nil {
+		err = copyErr
+	}
+	inspector.close()
+
+	inspector.flags.set(v)
+	if err != nil || inspector.flags.strict() {
+		v.MultipartStrictError().(*collections.Single).Set("1")
+	}
+	return err
+}
+
+// readMultipartParts reads all the parts of the multipart body populating the
+// files and post arguments.
+func readMultipartParts(mr *multipart.Reader, v plugintypes.TransactionVariables, storagePath string) error {
 	totalSize := int64(0)
 	filesCol := v.Files()
 	filesTmpNamesCol := v.FilesTmpNames()
@@ -45,7 +70,6 @@ func (mbp *multipartBodyProcessor) ProcessRequest(reader io.Reader, v plugintype
 			break
 		}
 		if err != nil {
-			v.MultipartStrictError().(*collections.Single).Set("1")
 			return err
 		}
 		partName := p.FormName()
@@ -60,23 +84,22 @@ func (mbp *multipartBodyProcessor) ProcessRequest(reader io.Reader, v plugintype
 			var size int64
 			if environment.HasAccessToFS {
 				// Only copy file to temp when not running in TinyGo
-				temp, err := os.CreateTemp(storagePath, "crzmp*")
+				temp, err := environment.CreateTemp(storagePath, "crzmp*")
 				if err != nil {
-					v.MultipartStrictError().(*collections.Single).Set("1")
 					return err
 				}
 				defer temp.Close()
+				// the file is tracked before being written, so it is removed when the
+				// transaction is closed even if the part can't be read
+				filesTmpNamesCol.Add("", temp.Name())
 				sz, err := io.Copy(temp, p)
 				if err != nil {
-					v.MultipartStrictError().(*collections.Single).Set("1")
 					return err
 				}
 				size = sz
-				filesTmpNamesCol.Add("", temp.Name())
 			} else {
 				sz, err := io.Copy(io.Discard, p)
 				if err != nil {
-					v.MultipartStrictError().(*collections.Single).Set("1")
 					return err
 				}
 				size = sz
@@ -89,7 +112,6 @@ func (mbp *multipartBodyProcessor) ProcessRequest(reader io.Reader, v plugintype
 			// if is a field
 			data, err := io.ReadAll(p)
 			if err != nil {
-				v.MultipartStrictError().(*collections.Single).Set("1")
 				return err
 			}
 			totalSize += int64(len(data))
@@ -0,0 +1,397 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bodyprocessors
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/collection"
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/collections"
+)
+
+// maxMultipartLineLength is the maximum number of bytes of a line kept by the
+// inspector. Boundaries and part headers are expected to be much shorter, longer
+// lines can only be part data.
+const maxMultipartLineLength = 8192
+
+// multipartFlags holds the anomalies found while inspecting a multipart body,
+// they mirror the MULTIPART_* variables populated by ModSecurity.
+type multipartFlags struct {
+	boundaryQuoted       bool
+	boundaryWhitespace   bool
+	crlfLine             bool
+	lfLine               bool
+	dataBefore           bool
+	dataAfter            bool
+	headerFolding        bool
+	invalidHeaderFolding bool
+	invalidPart          bool
+	invalidQuoting       bool
+	missingSemicolon     bool
+	unmatchedBoundary    bool
+}
+
+// strict returns whether any of the flags contributing to MULTIPART_STRICT_ERROR is set.
+// As in ModSecurity, unmatched boundaries and mixed line endings are reported on their
+// own but don't make the body fail strict validation.
+func (f *multipartFlags) strict() bool {
+	return f.boundaryQuoted || f.boundaryWhitespace || f.lfLine || f.dataBefore || f.dataAfter ||
+		f.headerFolding || f.invalidHeaderFolding || f.invalidPart || f.invalidQuoting || f.missingSemicolon
+}
+
+// set populates the MULTIPART_* variables with the flags
+func (f *multipartFlags) set(v plugintypes.TransactionVariables) {
+	for _, flag := range []struct {
+		col collection.Single
+		on  bool
+	}{
+		{v.MultipartBoundaryQuoted(), f.boundaryQuoted},
+		{v.MultipartBoundaryWhitespace(), f.boundaryWhitespace},
+		{v.MultipartCrlfLfLines(), f.crlfLine && f.lfLine},
+		{v.MultipartLfLine(), f.lfLine},
+		{v.MultipartDataBefore(), f.dataBefore},
+		{v.MultipartDataAfter(), f.dataAfter},
+		{v.MultipartHeaderFolding(), f.headerFolding},
+		{v.MultipartInvalidHeaderFolding(), f.invalidHeaderFolding},
+		{v.MultipartInvalidPart(), f.invalidPart},
+		{v.MultipartInvalidQuoting(), f.invalidQuoting},
+		{v.MultipartMissingSemicolon(), f.missingSemicolon},
+		{v.MultipartUnmatchedBoundary(), f.unmatchedBoundary},
+	} {
+		if flag.on {
+			flag.col.(*collections.Single).Set("1")
+		}
+	}
+}
+
+// checkBoundaryParam inspects the raw boundary parameter of the Content-Type header,
+// as mime.ParseMediaType hides quoting and whitespace.
+func checkBoundaryParam(contentType string, flags *multipartFlags) error {
+	var param string
+	found := false
+	_, params, _ := strings.Cut(contentType, ";")
+	for _, p := range strings.Split(params, ";") {
+		p = strings.TrimLeft(p, " \t")
+		if len(p) < len("boundary") || !strings.EqualFold(p[:len("boundary")], "boundary") {
+			continue
+		}
+		if rest := strings.TrimLeft(p[len("boundary"):], " \t"); !strings.HasPrefix(rest, "=") {
+			continue
+		}
+		if found {
+			return errors.New("multipart: multiple boundary parameters in content type")
+		}
+		found = true
+		param = p[len("boundary"):]
+	}
+	if !found {
+		return errors.New("multipart: boundary not found in content type")
+	}
+
+	trimmed := strings.TrimLeft(param, " \t")
+	if len(trimmed) != len(param) {
+		flags.boundaryWhitespace = true
+	}
+	value := trimmed[1:]
+	if trimmed := strings.TrimLeft(value, " \t"); len(trimmed) != len(value) {
+		flags.boundaryWhitespace = true
+		value = trimmed
+	}
+	if strings.HasPrefix(value, "\"") {
+		flags.boundaryQuoted = true
+		end := strings.IndexByte(value[1:], '"')
+		if end < 0 {
+			return errors.New("multipart: invalid boundary quoting in content type")
+		}
+		value = value[1 : end+1]
+	}
+	if strings.ContainsAny(value, " \t") {
+		flags.boundaryWhitespace = true
+	}
+	return validateBoundary(strings.TrimRight(value, " \t"))
+}
+
+// validateBoundary checks the boundary against the characters allowed by RFC 2046
+func validateBoundary(boundary string) error {
+	if len(boundary) == 0 || len(boundary) > 70 {
+		return errors.New("multipart: invalid boundary length")
+	}
+	for i := 0; i < len(boundary); i++ {
+		c := boundary[i]
+		switch {
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case strings.IndexByte("'()+_,-./:=? ", c) >= 0:
+		default:
+			return errors.New("multipart: invalid boundary characters")
+		}
+	}
+	return nil
+}
+
+type multipartState int
+
+const (
+	multipartStatePreamble multipartState = iota
+	multipartStateHeaders
+	multipartStateData
+	multipartStateEpilogue
+)
+
+// multipartInspector wraps a multipart body and inspects it line by line while it
+// is read by mime/multipart, flagging the anomalies that mime/multipart silently
+// tolerates.
+type multipartInspector struct {
+	r        io.Reader
+	boundary []byte
+	flags    multipartFlags
+
+	state multipartState
+	line  []byte
+	// lineTruncated is set when the current line went beyond maxMultipartLineLength
+	lineTruncated bool
+	// headers of the current part, folded lines are appended to the previous header
+	headers []string
+}
+
+func newMultipartInspector(r io.Reader, boundary string) *multipartInspector {
+	return &multipartInspector{
+		r:        r,
+		boundary: []byte("--" + boundary),
+	}
+}
+
+func (mi *multipartInspector) Read(p []byte) (int, error) {
+	n, err := mi.r.Read(p)
+	data := p[:n]
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			mi.appendLine(data)
+			break
+		}
+		mi.appendLine(data[:i])
+		mi.endLine(true)
+		data = data[i+1:]
+	}
+	return n, err
+}
+
+func (mi *multipartInspector) appendLine(data []byte) {
+	if room := maxMultipartLineLength - len(mi.line); len(data) > room {
+		data = data[:room]
+		mi.lineTruncated = true
+	}
+	mi.line = append(mi.line, data...)
+}
+
+// close inspects the last line, if not terminated, once the whole body has been read
+func (mi *multipartInspector) close() {
+	if len(mi.line) > 0 {
+		mi.endLine(false)
+	}
+	if mi.state == multipartStateHeaders {
+		mi.flags.invalidPart = true
+	}
+}
+
+func (mi *multipartInspector) endLine(terminated bool) {
+	line := mi.line
+	crlf := false
+	if terminated && len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+		crlf = true
+	}
+	truncated := mi.lineTruncated
+	mi.line = mi.line[:0]
+	mi.lineTruncated = false
+
+	isBoundary, isFinal := mi.matchBoundary(line)
+	if isBoundary || mi.state == multipartStateHeaders {
+		// line endings are only meaningful for boundaries and part headers
+		if terminated {
+			if crlf {
+				mi.flags.crlfLine = true
+			} else {
+				mi.flags.lfLine = true
+			}
+		}
+	}
+
+	switch {
+	case isBoundary:
+		if mi.state == multipartStateHeaders {
+			// boundary found before the end of the part headers
+			mi.flags.invalidPart = true
+		}
+		if mi.state == multipartStateEpilogue {
+			mi.flags.dataAfter = true
+		}
+		mi.headers = mi.headers[:0]
+		if isFinal {
+			mi.state = multipartStateEpilogue
+		} else {
+			mi.state = multipartStateHeaders
+		}
+		return
+	case !truncated && bytes.HasPrefix(line, []byte("--")) && bytes.Contains(line, mi.boundary[2:]):
+		mi.flags.unmatchedBoundary = true
+	}
+
+	switch mi.state {
+	case multipartStatePreamble:
+		mi.flags.dataBefore = true
+	case multipartStateEpilogue:
+		if len(line) > 0 {
+			mi.flags.dataAfter = true
+		}
+	case multipartStateHeaders:
+		if len(line) == 0 {
+			mi.checkPartHeaders()
+			mi.state = multipartStateData
+			return
+		}
+		mi.inspectHeaderLine(string(line))
+	}
+}
+
+// matchBoundary returns whether the line is a boundary and whether it is the final one.
+// Trailing whitespace is allowed after the boundary as per RFC 2046.
+func (mi *multipartInspector) matchBoundary(line []byte) (bool, bool) {
+	if !bytes.HasPrefix(line, mi.boundary) {
+		return false, false
+	}
+	rest := line[len(mi.boundary):]
+	final := bytes.HasPrefix(rest, []byte("--"))
+	if final {
+		rest = rest[2:]
+	}
+	if len(bytes.TrimLeft(rest, " \t")) != 0 {
+		return false, false
+	}
+	return true, final
+}
+
+func (mi *multipartInspector) inspectHeaderLine(line string) {
+	switch line[0] {
+	case ' ', '\t', '\v', '\f':
+		mi.flags.headerFolding = true
+		if line[0] != ' ' && line[0] != '\t' {
+			mi.flags.invalidHeaderFolding = true
+		}
+		if len(mi.headers) == 0 {
+			// there is no header to continue
+			mi.flags.invalidPart = true
+			return
+		}
+		mi.headers[len(mi.headers)-1] += " " + strings.TrimLeft(line, " \t\v\f")
+		return
+	}
+	if i := strings.IndexByte(line, ':'); i <= 0 {
+		// colon missing or empty header name
+		mi.flags.invalidPart = true
+		return
+	}
+	mi.headers = append(mi.headers, line)
+}
+
+// checkPartHeaders validates the headers of the current part once they have been read.
+// Parts without a Content-Disposition header are tolerated, as by mime/multipart,
+// they are not exposed in ARGS_POST nor FILES.
+func (mi *multipartInspector) checkPartHeaders() {
+	found := false
+	for _, h := range mi.headers {
+		name, value, _ := strings.Cut(h, ":")
+		if !strings.EqualFold(strings.TrimSpace(name), "content-disposition") {
+			continue
+		}
+		if found {
+			// duplicated Content-Disposition header
+			mi.flags.invalidPart = true
+		}
+		found = true
+		mi.checkContentDisposition(strings.TrimSpace(value))
+	}
+}
+
+// checkContentDisposition validates a Content-Disposition part header, expected to be
+// form-data followed by semicolon separated name and filename parameters.
+func (mi *multipartInspector) checkContentDisposition(value string) {
+	if len(value) < len("form-data") || !strings.EqualFold(value[:len("form-data")], "form-data") {
+		mi.flags.invalidPart = true
+		return
+	}
+	rest := value[len("form-data"):]
+	seen := map[string]bool{}
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			return
+		}
+		if rest[0] != ';' {
+			mi.flags.missingSemicolon = true
+		} else {
+			rest = strings.TrimLeft(rest[1:], " \t")
+			if rest == "" {
+				return
+			}
+		}
+
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			mi.flags.invalidPart = true
+			return
+		}
+		name := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		switch name {
+		case "name", "filename", "filename*":
+		default:
+			mi.flags.invalidPart = true
+		}
+		if seen[name] {
+			mi.flags.invalidPart = true
+		}
+		seen[name] = true
+
+		rest = strings.TrimLeft(rest[eq+1:], " \t")
+		switch {
+		case strings.HasPrefix(rest, "\""):
+			end := closingQuote(rest[1:])
+			if end < 0 {
+				mi.flags.invalidQuoting = true
+				return
+			}
+			rest = rest[end+2:]
+		case strings.HasPrefix(rest, "'"):
+			mi.flags.invalidQuoting = true
+			fallthrough
+		default:
+			end := strings.IndexAny(rest, "; \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			if strings.ContainsAny(rest[:end], "\"'") {
+				mi.flags.invalidQuoting = true
+			}
+			rest = rest[end:]
+		}
+	}
+}
+
+// closingQuote returns the index of the closing quote of a quoted-string, skipping
+// escaped characters, or -1 if the string is not terminated.
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
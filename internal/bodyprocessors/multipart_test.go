@@ -7,9 +7,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/corazawaf/coraza/v3/collection"
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	"github.com/corazawaf/coraza/v3/internal/bodyprocessors"
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/internal/variables"
 )
 
 func multipartProcessor(t *testing.T) plugintypes.BodyProcessor {
@@ -210,3 +212,159 @@ func TestMultipartUnmatchedBoundary(t *testing.T) {
 		}
 	}
 }
+
+func TestMultipartStrictFlags(t *testing.T) {
+	validPart := "Content-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n"
+	tests := map[string]struct {
+		mime          string
+		payload       string
+		expectedFlags []variables.RuleVariable
+		expectedError bool
+		// notStrict is set for the flags not contributing to MULTIPART_STRICT_ERROR
+		notStrict bool
+	}{
+		"valid body": {
+			payload: "--abc\r\n" + validPart + "--abc--\r\n",
+		},
+		"boundary containing the parameter name": {
+			mime:    "multipart/form-data; boundary=----FormBoundaryabc",
+			payload: "------FormBoundaryabc\r\n" + validPart + "------FormBoundaryabc--\r\n",
+		},
+		"boundary quoted": {
+			mime:          "multipart/form-data; boundary=\"abc\"",
+			payload:       "--abc\r\n" + validPart + "--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{variables.MultipartBoundaryQuoted},
+		},
+		"boundary whitespace": {
+			mime:          "multipart/form-data; boundary= abc",
+			payload:       "--abc\r\n" + validPart + "--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{variables.MultipartBoundaryWhitespace},
+		},
+		"invalid boundary characters": {
+			mime:          "multipart/form-data; boundary=\"a{c\"",
+			payload:       "--a{c\r\n" + validPart + "--a{c--\r\n",
+			expectedFlags: []variables.RuleVariable{variables.MultipartBoundaryQuoted},
+			expectedError: true,
+		},
+		"data before": {
+			payload:       "preamble\r\n--abc\r\n" + validPart + "--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{variables.MultipartDataBefore},
+		},
+		"data after": {
+			payload:       "--abc\r\n" + validPart + "--abc--\r\nepilogue",
+			expectedFlags: []variables.RuleVariable{variables.MultipartDataAfter},
+		},
+		"header folding": {
+			payload:       "--abc\r\nContent-Disposition: form-data;\r\n name=\"a\"\r\n\r\n1\r\n--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{variables.MultipartHeaderFolding},
+		},
+		"invalid header folding": {
+			payload: "--abc\r\nContent-Disposition: form-data;\r\n\vname=\"a\"\r\n\r\n1\r\n--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{
+				variables.MultipartHeaderFolding,
+				variables.MultipartInvalidHeaderFolding,
+			},
+		},
+		"header without colon": {
+			payload:       "--abc\r\nContent-Disposition form-data; name=\"a\"\r\n\r\n1\r\n--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{variables.MultipartInvalidPart},
+		},
+		"missing content disposition": {
+			payload: "--abc\r\nContent-Type: text/plain\r\n\r\n1\r\n--abc--\r\n",
+		},
+		"invalid quoting": {
+			payload:       "--abc\r\nContent-Disposition: form-data; name='a'\r\n\r\n1\r\n--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{variables.MultipartInvalidQuoting},
+		},
+		"missing semicolon": {
+			payload:       "--abc\r\nContent-Disposition: form-data name=\"a\"\r\n\r\n1\r\n--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{variables.MultipartMissingSemicolon},
+		},
+		"lf lines": {
+			payload:       "--abc\nContent-Disposition: form-data; name=\"a\"\n\n1\n--abc--\n",
+			expectedFlags: []variables.RuleVariable{variables.MultipartLfLine},
+		},
+		"crlf and lf lines": {
+			payload: "--abc\r\nContent-Disposition: form-data; name=\"a\"\n\r\n1\r\n--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{
+				variables.MultipartLfLine,
+				variables.MultipartCrlfLfLines,
+			},
+		},
+		"anomalies after a parsing error": {
+			// mime/multipart fails on the change of line endings, before buffering the last part
+			payload: "--abc\nContent-Disposition: form-data; name=\"a\"\n\n1\n--abc\r\n" + validPart +
+				strings.Repeat("x", 8192) + "\r\n--abc\r\nContent-Disposition: form-data; name='b'\r\n\r\n2\r\n--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{
+				variables.MultipartLfLine,
+				variables.MultipartCrlfLfLines,
+				variables.MultipartInvalidQuoting,
+			},
+			expectedError: true,
+		},
+		"unmatched boundary": {
+			payload:       "--abc\r\n" + validPart + "--xabc\r\n--abc--\r\n",
+			expectedFlags: []variables.RuleVariable{variables.MultipartUnmatchedBoundary},
+			notStrict:     true,
+		},
+	}
+
+	strictFlags := []variables.RuleVariable{
+		variables.MultipartBoundaryQuoted,
+		variables.MultipartBoundaryWhitespace,
+		variables.MultipartCrlfLfLines,
+		variables.MultipartDataAfter,
+		variables.MultipartDataBefore,
+		variables.MultipartHeaderFolding,
+		variables.MultipartInvalidHeaderFolding,
+		variables.MultipartInvalidPart,
+		variables.MultipartInvalidQuoting,
+		variables.MultipartLfLine,
+		variables.MultipartMissingSemicolon,
+		variables.MultipartUnmatchedBoundary,
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mime := tt.mime
+			if mime == "" {
+				mime = "multipart/form-data; boundary=abc"
+			}
+			mp := multipartProcessor(t)
+			v := corazawaf.NewTransactionVariables()
+			err := mp.ProcessRequest(strings.NewReader(tt.payload), v, plugintypes.BodyProcessorOptions{
+				Mime: mime,
+			})
+			if tt.expectedError && err == nil {
+				t.Error("expected error")
+			}
+
+			values := map[variables.RuleVariable]string{}
+			v.All(func(rv variables.RuleVariable, c collection.Collection) bool {
+				if s, ok := c.(collection.Single); ok {
+					values[rv] = s.Get()
+				}
+				return true
+			})
+			for _, flag := range strictFlags {
+				want := ""
+				for _, expected := range tt.expectedFlags {
+					if flag == expected {
+						want = "1"
+					}
+				}
+				if have := values[flag]; want != have {
+					t.Errorf("unexpected %s, want %q, have %q", flag.Name(), want, have)
+				}
+			}
+
+			wantStrict := ""
+			if err != nil || (len(tt.expectedFlags) > 0 && !tt.notStrict) {
+				wantStrict = "1"
+			}
+			if have := values[variables.MultipartStrictError]; wantStrict != have {
+				t.Errorf("unexpected MULTIPART_STRICT_ERROR, want %q, have %q (err: %v)", wantStrict, have, err)
+			}
+		})
+	}
+}
@@ -5,23 +5,39 @@ package bodyprocessors
 
 import (
 	"encoding/xml"
+	"fmt"
 	"io"
 	"strings"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/collections"
 )
 
+// maxXMLDepth is the maximum nesting depth of the elements of a document, as
+// enforced by libxml2 for ModSecurity
+const maxXMLDepth = 256
+
+// xmlBodyProcessor parses XML request bodies. The text contents and attribute
+// values are available in XML:/* and XML://@*, the document can also be queried
+// with a subset of XPath, e.g. XML:/order/item/text().
+//
+// Entity expansion attacks such as the billion laughs are not possible: the
+// document type declaration is ignored, so neither internal nor external
+// entities are resolved, only the predefined XML and HTML entities are decoded.
 type xmlBodyProcessor struct {
 }
 
 func (*xmlBodyProcessor) ProcessRequest(reader io.Reader, v plugintypes.TransactionVariables, options plugintypes.BodyProcessorOptions) error {
-	values, contents, err := readXML(reader)
+	values, contents, root, err := readXML(reader)
 	if err != nil {
 		return err
 	}
 	col := v.RequestXML()
 	col.Set("//@*", values)
 	col.Set("/*", contents)
+	if doc, ok := col.(*collections.XML); ok && root != nil {
+		doc.SetDocument(root)
+	}
 	return nil
 }
 
@@ -29,9 +45,12 @@ func (*xmlBodyProcessor) ProcessResponse(reader io.Reader, v plugintypes.Transac
 	return nil
 }
 
-func readXML(reader io.Reader) ([]string, []string, error) {
+func readXML(reader io.Reader) ([]string, []string, *collections.XMLNode, error) {
 	var attrs []string
 	var content []string
+	var root *collections.XMLNode
+	// stack holds the open elements, the last one is the current element
+	var stack []*collections.XMLNode
 	dec := xml.NewDecoder(reader)
 	dec.Strict = false
 	dec.AutoClose = xml.HTMLAutoClose
@@ -39,23 +58,47 @@ func readXML(reader io.Reader) ([]string, []string, error) {
 	for {
 		token, err := dec.Token()
 		if err != nil && err != io.EOF {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		if token == nil {
 			break
 		}
 		switch tok := token.(type) {
 		case xml.StartElement:
+			if len(stack) == maxXMLDepth {
+				return nil, nil, nil, fmt.Errorf("XML document exceeds the maximum depth of %d", maxXMLDepth)
+			}
+			node := &collections.XMLNode{Name: tok.Name.Local}
 			for _, attr := range tok.Attr {
 				attrs = append(attrs, attr.Value)
+				node.Attrs = append(node.Attrs, collections.XMLAttr{Name: attr.Name.Local, Value: attr.Value})
+			}
+			switch {
+			case len(stack) > 0:
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			case root == nil:
+				root = node
+			}
+			// elements after the root one are not part of the document
+			if len(stack) > 0 || root == node {
+				stack = append(stack, node)
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
 			}
 		case xml.CharData:
 			if c := strings.TrimSpace(string(tok)); c != "" {
 				content = append(content, c)
 			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, &collections.XMLNode{Text: string(tok)})
+			}
 		}
 	}
-	return attrs, content, nil
+	return attrs, content, root, nil
 }
 
 var (
@@ -5,6 +5,7 @@ package bodyprocessors
 
 import (
 	"bytes"
+	stdstrings "strings"
 	"testing"
 
 	"github.com/corazawaf/coraza/v3/internal/strings"
@@ -24,7 +25,7 @@ func TestXMLAttribures(t *testing.T) {
 </book>
 
 </bookstore>`
-	attrs, contents, err := readXML(bytes.NewReader([]byte(xmldoc)))
+	attrs, contents, _, err := readXML(bytes.NewReader([]byte(xmldoc)))
 	if err != nil {
 		t.Error(err)
 	}
@@ -55,7 +56,7 @@ func TestXMLPayloadFlexibility(t *testing.T) {
 			<heading>Reminder</heading>
 			<body>Don't forget me this weekend!
 		</note>`
-	_, contents, err := readXML(bytes.NewReader([]byte(xmldoc)))
+	_, contents, _, err := readXML(bytes.NewReader([]byte(xmldoc)))
 	if err != nil {
 		t.Error(err)
 	}
@@ -68,3 +69,56 @@ func TestXMLPayloadFlexibility(t *testing.T) {
 		t.Errorf("Expected 4 contents, got %d", len(contents))
 	}
 }
+
+func TestXMLDocument(t *testing.T) {
+	xmldoc := `<?xml version="1.0"?>
+<order id="1"><item>apple</item><item>ban<b>an</b>a</item></order>
+<ignored>after the root element</ignored>`
+	_, _, root, err := readXML(bytes.NewReader([]byte(xmldoc)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root == nil || root.Name != "order" {
+		t.Fatalf("unexpected root element: %v", root)
+	}
+	if len(root.Attrs) != 1 || root.Attrs[0].Name != "id" || root.Attrs[0].Value != "1" {
+		t.Errorf("unexpected root attributes: %v", root.Attrs)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("unexpected number of children, want 2, have %d", len(root.Children))
+	}
+	if item := root.Children[1]; len(item.Children) != 3 || item.Children[1].Name != "b" {
+		t.Errorf("unexpected children of the second item: %v", item.Children)
+	}
+}
+
+func TestXMLMaxDepth(t *testing.T) {
+	doc := func(depth int) []byte {
+		return []byte(stdstrings.Repeat("<a>", depth) + "x" + stdstrings.Repeat("</a>", depth))
+	}
+	if _, _, _, err := readXML(bytes.NewReader(doc(maxXMLDepth))); err != nil {
+		t.Errorf("unexpected error at the maximum depth: %v", err)
+	}
+	if _, _, _, err := readXML(bytes.NewReader(doc(maxXMLDepth + 1))); err == nil {
+		t.Error("expected an error beyond the maximum depth")
+	}
+}
+
+func TestXMLEntitiesAreNotExpanded(t *testing.T) {
+	xmldoc := `<?xml version="1.0"?>
+<!DOCTYPE lolz [
+ <!ENTITY lol "lol">
+ <!ENTITY lol1 "&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;">
+ <!ENTITY lol2 "&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;">
+ <!ENTITY lol3 "&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;">
+ <!ENTITY xxe SYSTEM "file:///etc/passwd">
+]>
+<lolz>&lol3;&xxe;</lolz>`
+	_, contents, _, err := readXML(bytes.NewReader([]byte(xmldoc)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contents) != 1 || contents[0] != "&lol3;&xxe;" {
+		t.Errorf("unexpected contents, entities must not be expanded: %q", contents)
+	}
+}
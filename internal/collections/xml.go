@@ -0,0 +1,278 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package collections
+
+import (
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/collection"
+	"github.com/corazawaf/coraza/v3/internal/corazarules"
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+// XMLNode is a node of a parsed XML document, either an element or a text node.
+type XMLNode struct {
+	// Name is the local name of the element, it is empty for text nodes
+	Name string
+	// Text is the content of a text node
+	Text string
+	// Attrs are the attributes of the element
+	Attrs []XMLAttr
+	// Children are the element and text nodes of the element, in document order
+	Children []*XMLNode
+}
+
+// XMLAttr is an attribute of an XMLNode
+type XMLAttr struct {
+	Name  string
+	Value string
+}
+
+// XML is a collection.Map holding a parsed XML document. Keys not set in the
+// map are evaluated as XPath expressions against the document, e.g.
+// XML:/order/item/text(). The supported subset is made of absolute location
+// paths with child (/) and descendant (//) steps selecting elements by name or
+// with *, attributes with @name or @* and text nodes with text(). Elements
+// evaluate to their string value, the concatenation of their descendant text.
+// Other expressions, e.g. with predicates or axes, select nothing.
+type XML struct {
+	*Map
+	document *XMLNode
+}
+
+var _ collection.Map = &XML{}
+
+// NewXML creates a new XML collection.
+func NewXML(variable variables.RuleVariable) *XML {
+	return &XML{
+		Map: NewMap(variable),
+	}
+}
+
+// SetDocument sets the root element of the document evaluated by XPath keys.
+func (c *XML) SetDocument(root *XMLNode) {
+	c.document = &XMLNode{Children: []*XMLNode{root}}
+}
+
+// Get returns the values of a key set in the map, or the values selected by
+// the key as an XPath expression.
+func (c *XML) Get(key string) []string {
+	if values := c.Map.Get(key); values != nil {
+		return values
+	}
+	return c.evaluate(key)
+}
+
+// FindString returns the elements of a key set in the map, or the values
+// selected by the key as an XPath expression.
+func (c *XML) FindString(key string) []types.MatchData {
+	if result := c.Map.FindString(key); result != nil || key == "" {
+		return result
+	}
+	values := c.evaluate(key)
+	if len(values) == 0 {
+		return nil
+	}
+	result := make([]types.MatchData, 0, len(values))
+	for _, v := range values {
+		result = append(result, &corazarules.MatchData{
+			Variable_: c.variable,
+			Key_:      key,
+			Value_:    v,
+		})
+	}
+	return result
+}
+
+// Reset removes all key/value pairs from the map and the document.
+func (c *XML) Reset() {
+	c.Map.Reset()
+	c.document = nil
+}
+
+func (c *XML) evaluate(expr string) []string {
+	if c.document == nil {
+		return nil
+	}
+	steps, ok := parseXPath(expr)
+	if !ok {
+		return nil
+	}
+
+	nodes := []*XMLNode{c.document}
+	for i, s := range steps {
+		if s.descendant {
+			nodes = descendantsOrSelf(nodes)
+		}
+		if s.kind != xpathElement {
+			if i != len(steps)-1 {
+				// attributes and text nodes have no children
+				return nil
+			}
+			return s.values(nodes)
+		}
+		var next []*XMLNode
+		for _, n := range nodes {
+			for _, child := range n.Children {
+				if child.Name != "" && s.matches(child.Name) {
+					next = append(next, child)
+				}
+			}
+		}
+		nodes = next
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		values = append(values, n.stringValue())
+	}
+	return values
+}
+
+// stringValue returns the concatenation of the descendant text nodes
+func (n *XMLNode) stringValue() string {
+	if n.Name == "" {
+		return n.Text
+	}
+	var sb strings.Builder
+	var walk func(*XMLNode)
+	walk = func(n *XMLNode) {
+		for _, child := range n.Children {
+			if child.Name == "" {
+				sb.WriteString(child.Text)
+			} else {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// descendantsOrSelf returns the nodes and their descendant elements without
+// duplicates, as the descendants of a node could already be in nodes.
+func descendantsOrSelf(nodes []*XMLNode) []*XMLNode {
+	seen := map[*XMLNode]struct{}{}
+	var res []*XMLNode
+	var walk func(*XMLNode)
+	walk = func(n *XMLNode) {
+		if _, ok := seen[n]; ok {
+			return
+		}
+		seen[n] = struct{}{}
+		res = append(res, n)
+		for _, child := range n.Children {
+			if child.Name != "" {
+				walk(child)
+			}
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return res
+}
+
+type xpathStepKind int
+
+const (
+	xpathElement xpathStepKind = iota
+	xpathAttribute
+	xpathText
+)
+
+type xpathStep struct {
+	// descendant is true for steps preceded by //
+	descendant bool
+	kind       xpathStepKind
+	// name is the element or attribute name, * matches any
+	name string
+}
+
+func (s xpathStep) matches(name string) bool {
+	return s.name == "*" || s.name == name
+}
+
+// values returns the attribute or text values selected by the step in nodes
+func (s xpathStep) values(nodes []*XMLNode) []string {
+	var values []string
+	for _, n := range nodes {
+		if s.kind == xpathAttribute {
+			for _, a := range n.Attrs {
+				if s.matches(a.Name) {
+					values = append(values, a.Value)
+				}
+			}
+			continue
+		}
+		for _, child := range n.Children {
+			if child.Name == "" {
+				values = append(values, child.Text)
+			}
+		}
+	}
+	return values
+}
+
+// parseXPath parses the supported subset of XPath, it returns false for
+// anything else.
+func parseXPath(expr string) ([]xpathStep, bool) {
+	if len(expr) < 2 || expr[0] != '/' {
+		return nil, false
+	}
+	var steps []xpathStep
+	for rest := expr; rest != ""; {
+		var s xpathStep
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			s.descendant = true
+			rest = rest[2:]
+		case rest[0] == '/':
+			rest = rest[1:]
+		default:
+			return nil, false
+		}
+		var name string
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			name, rest = rest[:i], rest[i:]
+		} else {
+			name, rest = rest, ""
+		}
+		switch {
+		case name == "text()":
+			s.kind = xpathText
+		case strings.HasPrefix(name, "@"):
+			s.kind = xpathAttribute
+			name = name[1:]
+		}
+		if s.kind != xpathText {
+			if !isXPathName(name) {
+				return nil, false
+			}
+			s.name = name
+		}
+		steps = append(steps, s)
+	}
+	return steps, true
+}
+
+func isXPathName(name string) bool {
+	if name == "*" {
+		return true
+	}
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch c {
+		case '/', '[', ']', '(', ')', '@', '*', ':', '=', '\'', '"', ' ', '|':
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package collections
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+func newTestXMLDocument() *XMLNode {
+	text := func(s string) *XMLNode { return &XMLNode{Text: s} }
+	return &XMLNode{
+		Name:  "order",
+		Attrs: []XMLAttr{{Name: "id", Value: "42"}},
+		Children: []*XMLNode{
+			{Name: "item", Attrs: []XMLAttr{{Name: "sku", Value: "a1"}}, Children: []*XMLNode{text("apple")}},
+			{Name: "item", Attrs: []XMLAttr{{Name: "sku", Value: "b2"}}, Children: []*XMLNode{
+				text("ban"),
+				{Name: "b", Children: []*XMLNode{text("an")}},
+				text("a"),
+			}},
+			{Name: "note", Children: []*XMLNode{
+				{Name: "item", Children: []*XMLNode{text("nested")}},
+			}},
+		},
+	}
+}
+
+func TestXMLXPath(t *testing.T) {
+	c := NewXML(variables.RequestXML)
+	c.Set("/*", []string{"legacy"})
+	c.SetDocument(newTestXMLDocument())
+
+	tests := map[string][]string{
+		"/*":                   {"legacy"},
+		"/order/item":          {"apple", "banana"},
+		"/order/item/text()":   {"apple", "ban", "a"},
+		"/order/item/b":        {"an"},
+		"/order/@id":           {"42"},
+		"/order/item/@sku":     {"a1", "b2"},
+		"/order/item/@*":       {"a1", "b2"},
+		"//item":               {"apple", "banana", "nested"},
+		"//item/text()":        {"apple", "ban", "a", "nested"},
+		"/order//item":         {"apple", "banana", "nested"},
+		"//@sku":               {"a1", "b2"},
+		"/order/*/item":        {"nested"},
+		"/order/missing":       nil,
+		"/Order/item":          nil,
+		"/order/item[1]":       nil,
+		"/order/item/text()/a": nil,
+		"order/item":           nil,
+		"/order/@id/text()":    nil,
+		"/order/child::item":   nil,
+	}
+	for expr, want := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if have := c.Get(expr); !reflect.DeepEqual(want, have) {
+				t.Errorf("unexpected values, want %q, have %q", want, have)
+			}
+			matches := c.FindString(expr)
+			if len(matches) != len(want) {
+				t.Fatalf("unexpected number of matches, want %d, have %d", len(want), len(matches))
+			}
+			for i, m := range matches {
+				if m.Value() != want[i] {
+					t.Errorf("unexpected value, want %q, have %q", want[i], m.Value())
+				}
+				if m.Key() != expr {
+					t.Errorf("unexpected key, want %q, have %q", expr, m.Key())
+				}
+			}
+		})
+	}
+}
+
+func TestXMLReset(t *testing.T) {
+	c := NewXML(variables.RequestXML)
+	c.SetDocument(newTestXMLDocument())
+	c.Reset()
+	if have := c.Get("/order/item"); have != nil {
+		t.Errorf("unexpected values after reset: %q", have)
+	}
+	if have := c.FindString("/order/item"); have != nil {
+		t.Errorf("unexpected matches after reset: %v", have)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+// AnomalyScoreThreshold interrupts the transaction at the end of the request
+// body and response body phases if the score accumulated in the TX variable
+// reaches the threshold.
+type AnomalyScoreThreshold struct {
+	// Variable is the TX variable holding the score, e.g. anomaly_score
+	Variable string
+	// Threshold is the minimum score triggering the interruption
+	Threshold int
+}
+
+// checkAnomalyScoreThresholds interrupts the transaction with a deny action if
+// any anomaly score reaches its threshold.
+func (tx *Transaction) checkAnomalyScoreThresholds() {
+	for _, t := range tx.WAF.AnomalyScoreThresholds {
+		values := tx.variables.tx.Get(strings.ToLower(t.Variable))
+		if len(values) == 0 {
+			continue
+		}
+		score, err := strconv.Atoi(values[0])
+		if err != nil {
+			tx.debugLogger.Warn().
+				Str("variable", t.Variable).
+				Str("value", values[0]).
+				Msg("Invalid anomaly score")
+			continue
+		}
+		if score < t.Threshold {
+			continue
+		}
+		tx.debugLogger.Info().
+			Str("variable", t.Variable).
+			Int("score", score).
+			Int("threshold", t.Threshold).
+			Msg("Anomaly score threshold reached")
+		tx.Interrupt(&types.Interruption{
+			Action: "deny",
+			Status: 403,
+		})
+		return
+	}
+}
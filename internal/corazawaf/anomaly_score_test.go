@@ -0,0 +1,60 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+func TestAnomalyScoreThresholds(t *testing.T) {
+	tests := map[string]struct {
+		phase       types.RulePhase
+		score       string
+		interrupted bool
+	}{
+		"below threshold": {
+			phase: types.PhaseRequestBody,
+			score: "4",
+		},
+		"at threshold in request body phase": {
+			phase:       types.PhaseRequestBody,
+			score:       "5",
+			interrupted: true,
+		},
+		"above threshold in response body phase": {
+			phase:       types.PhaseResponseBody,
+			score:       "10",
+			interrupted: true,
+		},
+		"headers phases are not checked": {
+			phase: types.PhaseRequestHeaders,
+			score: "10",
+		},
+		"invalid score": {
+			phase: types.PhaseRequestBody,
+			score: "abc",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			waf := NewWAF()
+			waf.AnomalyScoreThresholds = []AnomalyScoreThreshold{{Variable: "anomaly_score", Threshold: 5}}
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.variables.tx.Set("anomaly_score", []string{tc.score})
+
+			if have := waf.Rules.Eval(tc.phase, tx); have != tc.interrupted {
+				t.Fatalf("unexpected interruption, want %t, have %t", tc.interrupted, have)
+			}
+			if tc.interrupted {
+				if it := tx.Interruption(); it.Action != "deny" || it.Status != 403 {
+					t.Errorf("unexpected interruption %+v", it)
+				}
+			}
+		})
+	}
+}
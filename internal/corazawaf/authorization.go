@@ -0,0 +1,28 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// parseAuthorization returns the authentication scheme of an Authorization
+// header, e.g. Basic or Bearer, and the username if the scheme is Basic.
+// Malformed Basic credentials return an empty username.
+func parseAuthorization(value string) (authType string, user string) {
+	authType, credentials, _ := strings.Cut(strings.TrimSpace(value), " ")
+	if !strings.EqualFold(authType, "basic") {
+		return authType, ""
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+	if err != nil {
+		return authType, ""
+	}
+	user, _, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return authType, ""
+	}
+	return authType, user
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/collection"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+func TestParseAuthorization(t *testing.T) {
+	tests := []struct {
+		value    string
+		authType string
+		user     string
+	}{
+		// admin:secret
+		{"Basic YWRtaW46c2VjcmV0", "Basic", "admin"},
+		{"basic YWRtaW46c2VjcmV0", "basic", "admin"},
+		// admin:se:cret
+		{"Basic YWRtaW46c2U6Y3JldA==", "Basic", "admin"},
+		// :secret
+		{"Basic OnNlY3JldA==", "Basic", ""},
+		// admin, without the password separator
+		{"Basic YWRtaW4=", "Basic", ""},
+		{"Basic not-base64!", "Basic", ""},
+		{"Bearer eyJhbGciOiJIUzI1NiJ9.e30.signature", "Bearer", ""},
+		{"Bearer", "Bearer", ""},
+		{"", "", ""},
+	}
+	for _, tc := range tests {
+		authType, user := parseAuthorization(tc.value)
+		if authType != tc.authType || user != tc.user {
+			t.Errorf("unexpected result for %q, want (%q, %q), have (%q, %q)", tc.value, tc.authType, tc.user, authType, user)
+		}
+	}
+}
+
+func TestAuthorizationVariables(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		authType      string
+		remoteUser    string
+	}{
+		{"basic", "Basic YWRtaW46c2VjcmV0", "Basic", "admin"},
+		{"bearer", "Bearer eyJhbGciOiJIUzI1NiJ9.e30.signature", "Bearer", ""},
+		{"none", "", "", ""},
+	}
+	waf := NewWAF()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			if tc.authorization != "" {
+				tx.AddRequestHeader("Authorization", tc.authorization)
+			}
+			if have := tx.variables.authType.Get(); have != tc.authType {
+				t.Errorf("unexpected AUTH_TYPE, want %q, have %q", tc.authType, have)
+			}
+			if have := tx.variables.remoteUser.Get(); have != tc.remoteUser {
+				t.Errorf("unexpected REMOTE_USER, want %q, have %q", tc.remoteUser, have)
+			}
+			tx.variables.All(func(_ variables.RuleVariable, col collection.Collection) bool {
+				if col.Name() == "REQUEST_HEADERS" || col.Name() == "REQUEST_HEADERS_NAMES" {
+					return true
+				}
+				for _, md := range col.FindAll() {
+					if strings.Contains(md.Value(), "secret") {
+						t.Errorf("unexpected password in %s", col.Name())
+					}
+				}
+				return true
+			})
+		})
+	}
+}
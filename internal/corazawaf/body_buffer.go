@@ -34,15 +34,9 @@ func (br *BodyBuffer) WriteTo(w io.Writer) (int64, error) {
 		return br.buffer.WriteTo(w)
 	}
 
-	b := make([]byte, br.length)
-
-	n, err := br.writer.Read(b)
-	if err != nil {
-		return 0, err
-	}
-
-	n, err = w.Write(b[:n])
-	return int64(n), err
+	// the file offset is at the end of the written data, hence the body is read
+	// from the beginning without moving it.
+	return io.Copy(w, io.NewSectionReader(br.writer, 0, br.length))
 }
 
 // Write appends data to the body buffer by chunks
@@ -60,6 +54,12 @@ func (br *BodyBuffer) Write(data []byte) (n int, err error) {
 		// that have to perform limit checks before calling Write()
 		return 0, errors.New("limit reached while writing")
 	}
+	return br.write(data)
+}
+
+// write appends data to the body buffer without checking the overall limit,
+// spilling over to a temporary file once the memory limit is reached.
+func (br *BodyBuffer) write(data []byte) (n int, err error) {
 	targetLen := br.length + int64(len(data))
 
 	// Check if memory limits are reached
@@ -75,15 +75,9 @@ func (br *BodyBuffer) Write(data []byte) (n int, err error) {
 			return 0, errors.New("memoryLimit reached while writing")
 		} else {
 			if br.writer == nil {
-				br.writer, err = os.CreateTemp(br.options.TmpPath, "body*")
-				if err != nil {
-					return 0, err
-				}
-				// we dump the previous buffer
-				if _, err := br.writer.Write(br.buffer.Bytes()); err != nil {
+				if err := br.spillOver(); err != nil {
 					return 0, err
 				}
-				br.buffer.Reset()
 			}
 			br.length = targetLen
 			return br.writer.Write(data)
@@ -94,6 +88,22 @@ func (br *BodyBuffer) Write(data []byte) (n int, err error) {
 	return br.buffer.Write(data)
 }
 
+// spillOver moves the body buffered in memory to a temporary file in TmpPath,
+// the file is removed on Reset.
+func (br *BodyBuffer) spillOver() error {
+	w, err := environment.CreateTemp(br.options.TmpPath, "body*")
+	if err != nil {
+		return err
+	}
+	// we dump the previous buffer
+	if _, err := w.Write(br.buffer.Bytes()); err != nil {
+		return errors.Join(err, w.Close(), os.Remove(w.Name()))
+	}
+	br.writer = w
+	br.buffer.Reset()
+	return nil
+}
+
 type bodyBufferReader struct {
 	pos int
 	br  *BodyBuffer
@@ -126,6 +136,15 @@ func (b *bodyBufferReader) Read(p []byte) (n int, err error) {
 	return
 }
 
+// Len returns the number of bytes of the body buffer not read yet, like
+// bytes.Reader, so the size of the body is known before reading it.
+func (b *bodyBufferReader) Len() int {
+	if b.br == nil {
+		return 0
+	}
+	return int(b.br.length) - b.pos
+}
+
 // Close closes the reader
 func (b *bodyBufferReader) Close() {
 	b.br = nil
@@ -141,6 +160,55 @@ func (br *BodyBuffer) Reader() (io.Reader, error) {
 	return r, nil
 }
 
+// Append writes data at the end of the body buffer. Unlike Write, it does not
+// enforce the overall limit as it is used to modify an already buffered body.
+func (br *BodyBuffer) Append(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	_, err := br.write(data)
+	return err
+}
+
+// Prepend writes data at the beginning of the body buffer. Unlike Write, it does
+// not enforce the overall limit as it is used to modify an already buffered body.
+func (br *BodyBuffer) Prepend(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	if !environment.HasAccessToFS || br.writer == nil {
+		buf := make([]byte, 0, len(data)+br.buffer.Len())
+		buf = append(buf, data...)
+		buf = append(buf, br.buffer.Bytes()...)
+		br.buffer.Reset()
+		br.length = 0
+		_, err := br.write(buf)
+		return err
+	}
+
+	// The body has been spilled over to a file, hence we create a new one
+	// starting with data and followed by the current content.
+	old := br.writer
+	oldLength := br.length
+	w, err := environment.CreateTemp(br.options.TmpPath, "body*")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return errors.Join(err, w.Close(), os.Remove(w.Name()))
+	}
+	if _, err := io.Copy(w, io.NewSectionReader(old, 0, oldLength)); err != nil {
+		return errors.Join(err, w.Close(), os.Remove(w.Name()))
+	}
+	br.writer = w
+	br.length = oldLength + int64(len(data))
+	if err := old.Close(); err != nil {
+		return err
+	}
+	return os.Remove(old.Name())
+}
+
 // Size returns the current size of the body buffer
 func (br *BodyBuffer) Size() int64 {
 	return br.length
@@ -162,10 +230,8 @@ func (br *BodyBuffer) Reset() error {
 	if environment.HasAccessToFS && br.writer != nil {
 		w := br.writer
 		br.writer = nil
-		if err := w.Close(); err != nil {
-			return err
-		}
-		return os.Remove(w.Name())
+		// the file is removed even if closing it fails, so it is not left behind
+		return errors.Join(w.Close(), os.Remove(w.Name()))
 	}
 
 	return nil
@@ -174,3 +174,90 @@ func TestBodyBufferResetAndReadTheReader(t *testing.T) {
 		t.Fatalf("unexpected number of bytes read, want: %d, have: %d", 5, nCopied)
 	}
 }
+
+func TestBodyBufferAppendAndPrepend(t *testing.T) {
+	testCases := map[string]struct {
+		memoryLimit int64
+		requiresFS  bool
+	}{
+		"memory": {memoryLimit: 100},
+		"file":   {memoryLimit: 1, requiresFS: true},
+	}
+
+	for name, tCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if tCase.requiresFS && !environment.HasAccessToFS {
+				return // t.Skip doesn't work on TinyGo
+			}
+
+			br := NewBodyBuffer(types.BodyBufferOptions{
+				TmpPath:     t.TempDir(),
+				MemoryLimit: tCase.memoryLimit,
+				Limit:       4,
+			})
+			if _, err := br.Write([]byte("body")); err != nil {
+				t.Fatal(err)
+			}
+			// Neither append nor prepend are bound to the overall limit
+			if err := br.Append([]byte("-suffix")); err != nil {
+				t.Fatal(err)
+			}
+			if err := br.Prepend([]byte("prefix-")); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := br.Reader()
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := new(strings.Builder)
+			if _, err := io.Copy(buf, reader); err != nil {
+				t.Fatal(err)
+			}
+			if want, have := "prefix-body-suffix", buf.String(); want != have {
+				t.Errorf("unexpected body, want %q, have %q", want, have)
+			}
+			if want, have := int64(len("prefix-body-suffix")), br.Size(); want != have {
+				t.Errorf("unexpected size, want %d, have %d", want, have)
+			}
+			if err := br.Reset(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestBodyBufferWriteToFile(t *testing.T) {
+	if !environment.HasAccessToFS {
+		return // t.Skip doesn't work on TinyGo
+	}
+
+	br := NewBodyBuffer(types.BodyBufferOptions{
+		TmpPath:     t.TempDir(),
+		MemoryLimit: 4,
+		Limit:       100,
+	})
+	for _, chunk := range []string{"spilled ", "over ", "body"} {
+		if _, err := br.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if br.writer == nil {
+		t.Fatal("expected the body to be spilled over to a file")
+	}
+
+	buf := new(strings.Builder)
+	n, err := br.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "spilled over body", buf.String(); want != have {
+		t.Errorf("unexpected body, want %q, have %q", want, have)
+	}
+	if want, have := int64(len("spilled over body")), n; want != have {
+		t.Errorf("unexpected number of bytes written, want %d, have %d", want, have)
+	}
+	if err := br.Reset(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var errDecompressedBodyLimit = errors.New("decompressed response body exceeds the response body limit")
+
+// decompressResponseBody returns the response body decoded according to its
+// Content-Encoding, so the rules and the body processors inspect the content
+// and not the compressed bytes. The decompressed body is limited to the
+// response body limit, RES_BODY_ERROR is set if it exceeds it or if it cannot
+// be decoded, the rules inspect the content decoded so far.
+func (tx *Transaction) decompressResponseBody(reader io.Reader, codings []string) (io.Reader, error) {
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	body, err = decompressBody(body, codings, tx.ResponseBodyLimit)
+	if err != nil {
+		tx.debugLogger.Warn().Err(err).Msg("Failed to decompress response body")
+		tx.variables.resBodyError.Set("1")
+		tx.variables.resBodyErrorMsg.Set(err.Error())
+	}
+	return bytes.NewReader(body), nil
+}
+
+// contentCodings returns the content codings listed by the Content-Encoding
+// headers in the order they were applied, identity is left out.
+func contentCodings(headers []string) []string {
+	var codings []string
+	for _, h := range headers {
+		for _, c := range strings.Split(h, ",") {
+			c = strings.ToLower(strings.TrimSpace(c))
+			if c == "" || c == "identity" {
+				continue
+			}
+			codings = append(codings, c)
+		}
+	}
+	return codings
+}
+
+// decompressBody decodes the content codings of a body, the last one applied
+// is decoded first. gzip (or x-gzip) and deflate are supported, both the zlib
+// format of the specification and the raw deflate sent by some servers. The
+// decoded body is truncated to limit bytes, errDecompressedBodyLimit is
+// returned along with it if there was more. An error is returned for
+// unsupported codings and invalid data, with the data decoded so far.
+func decompressBody(body []byte, codings []string, limit int64) ([]byte, error) {
+	for i := len(codings) - 1; i >= 0; i-- {
+		var r io.Reader
+		var err error
+		switch codings[i] {
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(bytes.NewReader(body))
+		case "deflate":
+			r, err = zlib.NewReader(bytes.NewReader(body))
+			if errors.Is(err, zlib.ErrHeader) {
+				r, err = flate.NewReader(bytes.NewReader(body)), nil
+			}
+		default:
+			return body, fmt.Errorf("unsupported content encoding %q", codings[i])
+		}
+		if err != nil {
+			return body, fmt.Errorf("invalid %s content: %w", codings[i], err)
+		}
+		// one more byte than the limit tells whether the body exceeds it
+		var buf bytes.Buffer
+		_, err = io.Copy(&buf, io.LimitReader(r, limit+1))
+		body = buf.Bytes()
+		if err != nil {
+			return body, fmt.Errorf("invalid %s content: %w", codings[i], err)
+		}
+		if int64(len(body)) > limit {
+			return body[:limit], errDecompressedBodyLimit
+		}
+	}
+	return body, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func compress(t *testing.T, coding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch coding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestContentCodings(t *testing.T) {
+	tests := map[string]struct {
+		headers []string
+		want    []string
+	}{
+		"none":     {nil, nil},
+		"identity": {[]string{"identity"}, nil},
+		"single":   {[]string{"GZIP"}, []string{"gzip"}},
+		"list":     {[]string{"deflate, gzip"}, []string{"deflate", "gzip"}},
+		"headers":  {[]string{"deflate", " gzip ,identity"}, []string{"deflate", "gzip"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if have := contentCodings(tc.headers); !slices.Equal(tc.want, have) {
+				t.Errorf("unexpected codings, want %q, have %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestDecompressBody(t *testing.T) {
+	content := []byte("<html><body>" + strings.Repeat("hello ", 100) + "</body></html>")
+	stacked := compress(t, "gzip", compress(t, "deflate", content))
+	tests := map[string]struct {
+		body    []byte
+		codings []string
+		limit   int64
+		want    []byte
+		err     error
+	}{
+		"gzip":        {compress(t, "gzip", content), []string{"gzip"}, 1024, content, nil},
+		"x-gzip":      {compress(t, "gzip", content), []string{"x-gzip"}, 1024, content, nil},
+		"deflate":     {compress(t, "deflate", content), []string{"deflate"}, 1024, content, nil},
+		"raw deflate": {compress(t, "raw-deflate", content), []string{"deflate"}, 1024, content, nil},
+		"stacked":     {stacked, []string{"deflate", "gzip"}, 1024, content, nil},
+		"exact limit": {compress(t, "gzip", content), []string{"gzip"}, int64(len(content)), content, nil},
+		"over limit":  {compress(t, "gzip", content), []string{"gzip"}, 10, content[:10], errDecompressedBodyLimit},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have, err := decompressBody(tc.body, tc.codings, tc.limit)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error, want %v, have %v", tc.err, err)
+			}
+			if !bytes.Equal(tc.want, have) {
+				t.Errorf("unexpected body, want %q, have %q", tc.want, have)
+			}
+		})
+	}
+
+	for name, codings := range map[string][]string{
+		"invalid gzip":         {"gzip"},
+		"invalid deflate":      {"deflate"},
+		"unsupported encoding": {"br"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := decompressBody(content, codings, 1024); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
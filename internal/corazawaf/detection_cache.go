@@ -0,0 +1,35 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+// detectionCacheSize bounds the number of results cached by a transaction,
+// results are not cached once it is reached.
+const detectionCacheSize = 1024
+
+type detectionKey struct {
+	operator string
+	value    string
+}
+
+type detectionValue struct {
+	match       bool
+	fingerprint string
+}
+
+// CachedDetection returns the result cached with CacheDetection for the
+// operator and value, ok is false if there is none.
+func (tx *Transaction) CachedDetection(operator string, value string) (match bool, fingerprint string, ok bool) {
+	v, ok := tx.detectionCache[detectionKey{operator: operator, value: value}]
+	return v.match, v.fingerprint, ok
+}
+
+// CacheDetection caches the result of a detection operator, e.g. @detectSQLi,
+// over a value, so rules evaluating the same value in the transaction do not
+// run the detection again.
+func (tx *Transaction) CacheDetection(operator string, value string, match bool, fingerprint string) {
+	if tx.detectionCache == nil || len(tx.detectionCache) >= detectionCacheSize {
+		return
+	}
+	tx.detectionCache[detectionKey{operator: operator, value: value}] = detectionValue{match: match, fingerprint: fingerprint}
+}
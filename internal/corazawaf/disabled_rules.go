@@ -0,0 +1,72 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// disabledRules holds the ids of the rules disabled at runtime. The set is
+// replaced on every change, so transactions read it without locking.
+type disabledRules struct {
+	// mu serializes the writers
+	mu  sync.Mutex
+	ids atomic.Pointer[map[int]struct{}]
+}
+
+func (d *disabledRules) set(id int, enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var current map[int]struct{}
+	if p := d.ids.Load(); p != nil {
+		current = *p
+	}
+	if _, disabled := current[id]; disabled != enabled {
+		// nothing to change
+		return
+	}
+
+	next := make(map[int]struct{}, len(current)+1)
+	for k := range current {
+		next[k] = struct{}{}
+	}
+	if enabled {
+		delete(next, id)
+	} else {
+		next[id] = struct{}{}
+	}
+	d.ids.Store(&next)
+}
+
+// load returns the current set of disabled rules, nil if there are none
+func (d *disabledRules) load() map[int]struct{} {
+	if p := d.ids.Load(); p != nil && len(*p) > 0 {
+		return *p
+	}
+	return nil
+}
+
+// SetRuleEnabled enables or disables the rule with the given id at runtime,
+// without reloading the WAF. Disabled rules, including their chained rules,
+// are skipped by the transactions as if removed with ctl:ruleRemoveById.
+// It is safe to call while transactions are being processed, transactions
+// in progress see the change from their next phase.
+func (w *WAF) SetRuleEnabled(id int, enabled bool) error {
+	// SecMarkers have no id, they can't be disabled
+	if id == 0 || w.Rules.FindByID(id) == nil {
+		return fmt.Errorf("rule %d not found", id)
+	}
+	w.disabledRules.set(id, enabled)
+	return nil
+}
+
+// IsRuleEnabled returns false if the rule with the given id was disabled
+// with SetRuleEnabled.
+func (w *WAF) IsRuleEnabled(id int) bool {
+	_, disabled := w.disabledRules.load()[id]
+	return !disabled
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+func newDisabledRulesWAF(t *testing.T) *WAF {
+	t.Helper()
+	waf := NewWAF()
+	for _, id := range []int{1, 2} {
+		rule := NewRule()
+		rule.ID_ = id
+		rule.Phase_ = types.PhaseRequestHeaders
+		if err := rule.AddVariable(variables.ArgsGet, "q", false); err != nil {
+			t.Fatal(err)
+		}
+		rule.SetOperator(&dummyEqOperator{}, "@eq", "0")
+		if err := waf.Rules.Add(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return waf
+}
+
+func matchedRuleIDs(waf *WAF) []int {
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.AddGetRequestArgument("q", "0")
+	tx.ProcessRequestHeaders()
+	var ids []int
+	for _, mr := range tx.MatchedRules() {
+		ids = append(ids, mr.Rule().ID())
+	}
+	return ids
+}
+
+func TestSetRuleEnabled(t *testing.T) {
+	waf := newDisabledRulesWAF(t)
+
+	if want, have := []int{1, 2}, matchedRuleIDs(waf); len(have) != 2 {
+		t.Fatalf("unexpected matched rules, want %v, have %v", want, have)
+	}
+
+	if err := waf.SetRuleEnabled(1, false); err != nil {
+		t.Fatal(err)
+	}
+	if waf.IsRuleEnabled(1) {
+		t.Error("expected rule 1 to be disabled")
+	}
+	if !waf.IsRuleEnabled(2) {
+		t.Error("expected rule 2 to be enabled")
+	}
+	if want, have := []int{2}, matchedRuleIDs(waf); len(have) != 1 || have[0] != 2 {
+		t.Fatalf("unexpected matched rules, want %v, have %v", want, have)
+	}
+
+	if err := waf.SetRuleEnabled(1, true); err != nil {
+		t.Fatal(err)
+	}
+	if !waf.IsRuleEnabled(1) {
+		t.Error("expected rule 1 to be enabled")
+	}
+	if want, have := []int{1, 2}, matchedRuleIDs(waf); len(have) != 2 {
+		t.Fatalf("unexpected matched rules, want %v, have %v", want, have)
+	}
+}
+
+func TestSetRuleEnabledUnknownRule(t *testing.T) {
+	waf := newDisabledRulesWAF(t)
+	if err := waf.SetRuleEnabled(3, false); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+	if err := waf.SetRuleEnabled(0, false); err == nil {
+		t.Error("expected an error for rule id 0")
+	}
+}
+
+func TestSetRuleEnabledConcurrent(t *testing.T) {
+	waf := newDisabledRulesWAF(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				matchedRuleIDs(waf)
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		if err := waf.SetRuleEnabled(1, j%2 == 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+const (
+	// evaluationAbortedStatus is the status of the interruptions of the aborted
+	// evaluations
+	evaluationAbortedStatus = 503
+	// evaluationAbortedKey is the TX key set to the reason of the aborted
+	// evaluations, so the rules of the logging phase can detect them
+	evaluationAbortedKey = "rule_evaluation_aborted"
+	// evaluationCheckInterval is the number of values of a rule evaluated
+	// between two checks of the budget, which are too costly for every value
+	evaluationCheckInterval = 16
+)
+
+// abortedEvaluation is a phase whose evaluation was aborted.
+type abortedEvaluation struct {
+	phase types.RulePhase
+	err   error
+}
+
+// startEvaluationBudget starts the budget of the evaluation of a phase.
+func (tx *Transaction) startEvaluationBudget(phase types.RulePhase) {
+	tx.evaluationErr = nil
+	tx.phaseDeadline = time.Time{}
+	if tx.WAF.RuleEvaluationTimeout > 0 && phase != types.PhaseLogging {
+		tx.phaseDeadline = time.Now().Add(tx.WAF.RuleEvaluationTimeout)
+	}
+}
+
+// evaluationAborted reports whether the evaluation of the current phase must
+// stop because the context of the transaction is done or the phase exceeded
+// its budget. It is checked between rules and every evaluationCheckInterval
+// values evaluated by a rule. The logging phase is never aborted so the transaction is always
+// logged.
+func (tx *Transaction) evaluationAborted() bool {
+	if tx.evaluationErr != nil {
+		return true
+	}
+	if tx.lastPhase == types.PhaseLogging {
+		return false
+	}
+	if tx.context != nil {
+		tx.evaluationErr = tx.context.Err()
+	}
+	if tx.evaluationErr == nil && !tx.phaseDeadline.IsZero() && time.Now().After(tx.phaseDeadline) {
+		tx.evaluationErr = types.ErrRuleEvaluationTimeout
+	}
+	return tx.evaluationErr != nil
+}
+
+// evaluationContext returns the context of the transaction bounded by the
+// budget of the current phase, for the evaluations which can block like the
+// rule scripts.
+func (tx *Transaction) evaluationContext() (context.Context, context.CancelFunc) {
+	ctx := tx.context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if tx.phaseDeadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, tx.phaseDeadline)
+}
+
+// abortEvaluation records that the evaluation of a phase was aborted, in the
+// audit log and in TX:rule_evaluation_aborted, and interrupts the transaction
+// with status 503 and the reason in the data of the interruption. When the rule
+// engine is DetectionOnly the rules left are skipped but the transaction is not
+// interrupted.
+func (tx *Transaction) abortEvaluation(phase types.RulePhase) {
+	tx.debugLogger.Warn().
+		Int("phase", int(phase)).
+		Err(tx.evaluationErr).
+		Msg("Aborting the evaluation of the phase")
+	tx.abortedEvaluations = append(tx.abortedEvaluations, abortedEvaluation{phase: phase, err: tx.evaluationErr})
+	tx.variables.tx.Set(evaluationAbortedKey, []string{tx.evaluationErr.Error()})
+	tx.audit = true
+	if tx.interruption != nil || tx.RuleEngine != types.RuleEngineOn {
+		return
+	}
+	tx.interruption = &types.Interruption{
+		Action: "deny",
+		Status: evaluationAbortedStatus,
+		Data:   tx.evaluationErr.Error(),
+	}
+}
+
+// EvaluationError returns why the evaluation of the first aborted phase was
+// aborted: types.ErrRuleEvaluationTimeout, context.Canceled or
+// context.DeadlineExceeded. It returns nil if no evaluation was aborted.
+func (tx *Transaction) EvaluationError() error {
+	if len(tx.abortedEvaluations) == 0 {
+		return nil
+	}
+	return tx.abortedEvaluations[0].err
+}
+
+// message is the audit log message of an aborted evaluation.
+func (a abortedEvaluation) message() string {
+	return fmt.Sprintf("Evaluation of phase %d aborted: %s", a.phase, a.err)
+}
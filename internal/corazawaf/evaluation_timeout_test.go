@@ -0,0 +1,237 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+// slowOperator takes longer than the timeouts of the tests for every value
+type slowOperator struct {
+	calls int
+}
+
+func (o *slowOperator) Evaluate(_ plugintypes.TransactionState, _ string) bool {
+	o.calls++
+	time.Sleep(20 * time.Millisecond)
+	return false
+}
+
+// newSlowWAF returns a WAF with a slow rule evaluated before a denying rule,
+// and an action in the logging phase, which is evaluated in that phase with
+// multiphase evaluation too
+func newSlowWAF(t *testing.T) (*WAF, *slowOperator) {
+	t.Helper()
+	waf := NewWAF()
+	slow := &slowOperator{}
+	for _, id := range []int{1, 2, 3} {
+		rule := NewRule()
+		rule.ID_ = id
+		rule.Phase_ = types.PhaseRequestHeaders
+		if id != 3 {
+			if err := rule.AddVariable(variables.ArgsGet, "", false); err != nil {
+				t.Fatal(err)
+			}
+		}
+		switch id {
+		case 1:
+			rule.SetOperator(slow, "@slow", "")
+		case 2:
+			rule.SetOperator(&dummyEqOperator{}, "@eq", "0")
+			rule.DisruptiveStatus = 403
+			if err := rule.AddAction("deny", &dummyDenyAction{}); err != nil {
+				t.Fatal(err)
+			}
+		case 3:
+			rule.Phase_ = types.PhaseLogging
+		}
+		if err := waf.Rules.Add(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return waf, slow
+}
+
+func evaluateSlowTransaction(t *testing.T, tx *Transaction) *types.Interruption {
+	t.Helper()
+	tx.AddGetRequestArgument("a", "1")
+	tx.AddGetRequestArgument("b", "2")
+	tx.AddGetRequestArgument("c", "0")
+	it := tx.ProcessRequestHeaders()
+	tx.ProcessLogging()
+	return it
+}
+
+func txMatchedRuleIDs(tx *Transaction) []int {
+	var ids []int
+	for _, mr := range tx.MatchedRules() {
+		ids = append(ids, mr.Rule().ID())
+	}
+	return ids
+}
+
+func TestEvaluationContextTimeout(t *testing.T) {
+	waf, slow := newSlowWAF(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	tx := waf.NewTransactionWithOptions(Options{Context: ctx})
+	defer tx.Close()
+	tx.RuleEngine = types.RuleEngineOn
+	it := evaluateSlowTransaction(t, tx)
+	if it == nil {
+		t.Fatal("expected the transaction to be interrupted")
+	}
+	if want, have := 503, it.Status; want != have {
+		t.Errorf("unexpected status, want %d, have %d", want, have)
+	}
+	if want, have := context.DeadlineExceeded.Error(), it.Data; want != have {
+		t.Errorf("unexpected data, want %q, have %q", want, have)
+	}
+	if it.RuleID != 0 {
+		t.Errorf("unexpected rule id %d", it.RuleID)
+	}
+	// the budget is checked every evaluationCheckInterval values, so the three
+	// values of the slow rule are evaluated but not the rules left
+	if want, have := 3, slow.calls; want != have {
+		t.Errorf("unexpected operator calls, want %d, have %d", want, have)
+	}
+	// the logging phase is evaluated anyway
+	if ids := txMatchedRuleIDs(tx); len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("unexpected matched rules %v", ids)
+	}
+}
+
+func TestEvaluationContextCanceled(t *testing.T) {
+	waf, slow := newSlowWAF(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tx := waf.NewTransactionWithOptions(Options{Context: ctx})
+	defer tx.Close()
+	tx.RuleEngine = types.RuleEngineOn
+	it := evaluateSlowTransaction(t, tx)
+	if it == nil {
+		t.Fatal("expected the transaction to be interrupted")
+	}
+	if want, have := context.Canceled.Error(), it.Data; want != have {
+		t.Errorf("unexpected data, want %q, have %q", want, have)
+	}
+	if slow.calls != 0 {
+		t.Errorf("unexpected operator calls %d", slow.calls)
+	}
+}
+
+func TestRuleEvaluationTimeout(t *testing.T) {
+	for _, engine := range []types.RuleEngineStatus{types.RuleEngineOn, types.RuleEngineDetectionOnly} {
+		t.Run(engine.String(), func(t *testing.T) {
+			waf, slow := newSlowWAF(t)
+			waf.RuleEvaluationTimeout = 5 * time.Millisecond
+
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.RuleEngine = engine
+			it := evaluateSlowTransaction(t, tx)
+			if engine == types.RuleEngineOn {
+				if it == nil {
+					t.Fatal("expected the transaction to be interrupted")
+				}
+				if want, have := types.ErrRuleEvaluationTimeout.Error(), it.Data; want != have {
+					t.Errorf("unexpected data, want %q, have %q", want, have)
+				}
+			} else if it != nil {
+				t.Errorf("unexpected interruption %v", it)
+			}
+			// the aborted evaluation is visible even if the transaction is not interrupted
+			if err := tx.EvaluationError(); !errors.Is(err, types.ErrRuleEvaluationTimeout) {
+				t.Errorf("unexpected evaluation error %v", err)
+			}
+			if want, have := types.ErrRuleEvaluationTimeout.Error(), tx.variables.tx.Get(evaluationAbortedKey); len(have) != 1 || want != have[0] {
+				t.Errorf("unexpected TX:%s, want %q, have %q", evaluationAbortedKey, want, have)
+			}
+			var messages []string
+			for _, m := range tx.AuditLog().Messages() {
+				messages = append(messages, m.Message())
+			}
+			if want := "Evaluation of phase 1 aborted: rule evaluation timeout"; len(messages) != 1 || messages[0] != want {
+				t.Errorf("unexpected audit log messages, want %q, have %q", want, messages)
+			}
+			if want, have := 3, slow.calls; want != have {
+				t.Errorf("unexpected operator calls, want %d, have %d", want, have)
+			}
+			if ids := txMatchedRuleIDs(tx); len(ids) != 1 || ids[0] != 3 {
+				t.Errorf("unexpected matched rules %v", ids)
+			}
+		})
+	}
+}
+
+func TestRuleEvaluationWithinTimeout(t *testing.T) {
+	waf, slow := newSlowWAF(t)
+	waf.RuleEvaluationTimeout = time.Minute
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.RuleEngine = types.RuleEngineOn
+	it := evaluateSlowTransaction(t, tx)
+	if it == nil || it.RuleID != 2 {
+		t.Fatalf("expected the transaction to be interrupted by rule 2, have %v", it)
+	}
+	if want, have := 3, slow.calls; want != have {
+		t.Errorf("unexpected operator calls, want %d, have %d", want, have)
+	}
+	if err := tx.EvaluationError(); err != nil {
+		t.Errorf("unexpected evaluation error %v", err)
+	}
+}
+
+// cancelingOperator cancels the context of the transaction on its first call
+type cancelingOperator struct {
+	cancel context.CancelFunc
+	calls  int
+}
+
+func (o *cancelingOperator) Evaluate(_ plugintypes.TransactionState, _ string) bool {
+	o.calls++
+	o.cancel()
+	return false
+}
+
+func TestEvaluationCheckInterval(t *testing.T) {
+	waf := NewWAF()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	op := &cancelingOperator{cancel: cancel}
+	rule := NewRule()
+	rule.ID_ = 1
+	rule.Phase_ = types.PhaseRequestHeaders
+	if err := rule.AddVariable(variables.ArgsGet, "", false); err != nil {
+		t.Fatal(err)
+	}
+	rule.SetOperator(op, "@cancel", "")
+	if err := waf.Rules.Add(rule); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := waf.NewTransactionWithOptions(Options{Context: ctx})
+	defer tx.Close()
+	for i := 0; i < 3*evaluationCheckInterval; i++ {
+		tx.AddGetRequestArgument(strconv.Itoa(i), "1")
+	}
+	tx.ProcessRequestHeaders()
+	if want, have := evaluationCheckInterval, op.calls; want != have {
+		t.Errorf("unexpected operator calls, want %d, have %d", want, have)
+	}
+	if err := tx.EvaluationError(); !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected evaluation error %v", err)
+	}
+}
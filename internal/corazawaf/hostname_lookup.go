@@ -0,0 +1,153 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostnameResolver resolves the hostnames of an IP address and the addresses of
+// a hostname, it is implemented by *net.Resolver.
+type HostnameResolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+const (
+	// defaultHostnameLookupTimeout bounds the time a transaction waits for
+	// the reverse DNS of the client address.
+	defaultHostnameLookupTimeout = 500 * time.Millisecond
+
+	// hostnameCacheSize is the number of addresses kept in the cache, it is
+	// reset once full so the memory used by it stays bounded.
+	hostnameCacheSize = 4096
+
+	// hostnameCacheTTL is how long a resolved hostname, or the absence of
+	// one, is cached, so changes of the DNS records are eventually seen.
+	hostnameCacheTTL = 5 * time.Minute
+)
+
+type hostnameCacheEntry struct {
+	host    string
+	expires time.Time
+}
+
+// hostnameLookup resolves the REMOTE_HOST of the transactions when
+// SecHostnameLookups is On. It is shared by all the transactions of a WAF,
+// the hostnames and the definitive failures (NXDOMAIN) are cached per address
+// for hostnameCacheTTL. Timeouts and other transient failures are not cached,
+// the address is resolved again by the next transaction.
+type hostnameLookup struct {
+	mu       sync.Mutex
+	resolver HostnameResolver
+	timeout  time.Duration
+	cache    map[string]hostnameCacheEntry
+	// now is the clock of the cache, time.Now is used if it is nil
+	now func() time.Time
+}
+
+// lookup returns the hostname of addr, or addr itself if it cannot be
+// resolved within the timeout. The reverse DNS is controlled by the owner of
+// the address, so a hostname is only used if it resolves back to addr
+// (forward-confirmed reverse DNS).
+func (h *hostnameLookup) lookup(ctx context.Context, addr string) string {
+	h.mu.Lock()
+	now := time.Now
+	if h.now != nil {
+		now = h.now
+	}
+	entry, ok := h.cache[addr]
+	resolver, timeout := h.resolver, h.timeout
+	h.mu.Unlock()
+	if ok && now().Before(entry.expires) {
+		return entry.host
+	}
+
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	if timeout <= 0 {
+		timeout = defaultHostnameLookupTimeout
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	host, final := confirmedHostname(ctx, resolver, addr)
+	if !final || ctx.Err() != nil {
+		return host
+	}
+
+	h.mu.Lock()
+	if h.cache == nil || len(h.cache) >= hostnameCacheSize {
+		h.cache = map[string]hostnameCacheEntry{}
+	}
+	h.cache[addr] = hostnameCacheEntry{host: host, expires: now().Add(hostnameCacheTTL)}
+	h.mu.Unlock()
+
+	return host
+}
+
+// confirmedHostname returns the first hostname of addr resolving back to it,
+// or addr if there is none. final is false if the result may change on retry
+// because a lookup failed for another reason than a missing record, e.g. a
+// timeout.
+func confirmedHostname(ctx context.Context, resolver HostnameResolver, addr string) (host string, final bool) {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return addr, true
+	}
+	names, err := resolver.LookupAddr(ctx, addr)
+	if err != nil {
+		return addr, isNotFound(err)
+	}
+	final = true
+	for _, name := range names {
+		addrs, err := resolver.LookupHost(ctx, name)
+		if err != nil {
+			final = final && isNotFound(err)
+			continue
+		}
+		for _, a := range addrs {
+			if ip.Equal(net.ParseIP(a)) {
+				return strings.TrimSuffix(name, "."), true
+			}
+		}
+	}
+	return addr, final
+}
+
+// isNotFound reports whether err is the definitive absence of a DNS record
+// (NXDOMAIN), as opposed to a timeout or a failure of the server.
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// SetHostnameResolver sets the resolver used to populate REMOTE_HOST when
+// SecHostnameLookups is On, net.DefaultResolver is used by default. The
+// cached hostnames are discarded.
+func (w *WAF) SetHostnameResolver(r HostnameResolver) {
+	w.hostnameLookup.mu.Lock()
+	defer w.hostnameLookup.mu.Unlock()
+	w.hostnameLookup.resolver = r
+	w.hostnameLookup.cache = nil
+}
+
+// SetHostnameLookupTimeout sets the maximum time a transaction waits for the
+// reverse DNS of the client address, the client address is used as
+// REMOTE_HOST when it is exceeded.
+func (w *WAF) SetHostnameLookupTimeout(timeout time.Duration) {
+	w.hostnameLookup.mu.Lock()
+	defer w.hostnameLookup.mu.Unlock()
+	w.hostnameLookup.timeout = timeout
+}
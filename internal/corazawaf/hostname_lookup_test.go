@@ -0,0 +1,203 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+type stubResolver struct {
+	names map[string][]string
+	addrs map[string][]string
+	delay time.Duration
+	// failures are the addresses whose lookup fails with a server error
+	failures map[string]bool
+	calls    int
+}
+
+func (r *stubResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	r.calls++
+	if r.delay > 0 {
+		select {
+		case <-time.After(r.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if r.failures[addr] {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: addr, IsTemporary: true}
+	}
+	names, ok := r.names[addr]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+	}
+	return names, nil
+}
+
+func (r *stubResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	addrs, ok := r.addrs[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func TestRemoteHostDisabledByDefault(t *testing.T) {
+	waf := NewWAF()
+	resolver := &stubResolver{names: map[string][]string{"10.0.0.1": {"client.example.com."}}}
+	waf.SetHostnameResolver(resolver)
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.ProcessConnection("10.0.0.1", 1234, "10.0.0.2", 80)
+
+	if want, have := "10.0.0.1", tx.variables.remoteHost.Get(); want != have {
+		t.Errorf("unexpected REMOTE_HOST, want %q, have %q", want, have)
+	}
+	if resolver.calls != 0 {
+		t.Errorf("unexpected lookups, want 0, have %d", resolver.calls)
+	}
+}
+
+func TestRemoteHostLookup(t *testing.T) {
+	waf := NewWAF()
+	waf.HostnameLookups = true
+	resolver := &stubResolver{
+		names: map[string][]string{
+			"10.0.0.1":    {"client.example.com."},
+			"10.0.0.4":    {"spoofed.example.com.", "client4.attacker.com."},
+			"10.0.0.5":    {"other.example.com."},
+			"2001:db8::1": {"client6.example.com."},
+		},
+		addrs: map[string][]string{
+			"client.example.com.":   {"10.0.0.1"},
+			"spoofed.example.com.":  {"10.0.0.1"},
+			"client4.attacker.com.": {"10.0.0.4"},
+			"client6.example.com.":  {"2001:0db8:0000:0000:0000:0000:0000:0001"},
+		},
+	}
+	waf.SetHostnameResolver(resolver)
+
+	tests := []struct {
+		client     string
+		remoteHost string
+	}{
+		{"10.0.0.1", "client.example.com"},
+		{"10.0.0.1", "client.example.com"},
+		{"10.0.0.3", "10.0.0.3"},
+		{"10.0.0.3", "10.0.0.3"},
+		// only the hostnames resolving back to the client address are used
+		{"10.0.0.4", "client4.attacker.com"},
+		{"10.0.0.5", "10.0.0.5"},
+		{"2001:db8::1", "client6.example.com"},
+	}
+	for _, tc := range tests {
+		tx := waf.NewTransaction()
+		tx.ProcessConnection(tc.client, 1234, "10.0.0.2", 80)
+		if have := tx.variables.remoteHost.Get(); have != tc.remoteHost {
+			t.Errorf("unexpected REMOTE_HOST for %s, want %q, have %q", tc.client, tc.remoteHost, have)
+		}
+		tx.Close()
+	}
+
+	// Resolved and not found addresses are both cached
+	if resolver.calls != 5 {
+		t.Errorf("unexpected lookups, want 5, have %d", resolver.calls)
+	}
+}
+
+func TestRemoteHostLookupTimeout(t *testing.T) {
+	waf := NewWAF()
+	waf.HostnameLookups = true
+	waf.SetHostnameResolver(&stubResolver{
+		names: map[string][]string{"10.0.0.1": {"client.example.com."}},
+		delay: time.Second,
+	})
+	waf.SetHostnameLookupTimeout(10 * time.Millisecond)
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+
+	start := time.Now()
+	tx.ProcessConnection("10.0.0.1", 1234, "10.0.0.2", 80)
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("lookup did not time out, took %s", elapsed)
+	}
+	if want, have := "10.0.0.1", tx.variables.remoteHost.Get(); want != have {
+		t.Errorf("unexpected REMOTE_HOST, want %q, have %q", want, have)
+	}
+}
+
+func TestRemoteHostLookupTimeoutIsNotCached(t *testing.T) {
+	waf := NewWAF()
+	waf.HostnameLookups = true
+	resolver := &stubResolver{
+		names: map[string][]string{"10.0.0.1": {"client.example.com."}},
+		addrs: map[string][]string{"client.example.com.": {"10.0.0.1"}},
+		delay: time.Second,
+	}
+	waf.SetHostnameResolver(resolver)
+	waf.SetHostnameLookupTimeout(10 * time.Millisecond)
+
+	tx := waf.NewTransaction()
+	tx.ProcessConnection("10.0.0.1", 1234, "10.0.0.2", 80)
+	tx.Close()
+
+	// once the DNS server answers in time, the hostname is resolved
+	resolver.delay = 0
+	tx = waf.NewTransaction()
+	defer tx.Close()
+	tx.ProcessConnection("10.0.0.1", 1234, "10.0.0.2", 80)
+	if want, have := "client.example.com", tx.variables.remoteHost.Get(); want != have {
+		t.Errorf("unexpected REMOTE_HOST, want %q, have %q", want, have)
+	}
+	if want, have := 2, resolver.calls; want != have {
+		t.Errorf("unexpected lookups, want %d, have %d", want, have)
+	}
+}
+
+func TestRemoteHostLookupCache(t *testing.T) {
+	waf := NewWAF()
+	waf.HostnameLookups = true
+	resolver := &stubResolver{
+		names:    map[string][]string{"10.0.0.1": {"client.example.com."}},
+		addrs:    map[string][]string{"client.example.com.": {"10.0.0.1"}},
+		failures: map[string]bool{"10.0.0.2": true},
+	}
+	waf.SetHostnameResolver(resolver)
+	now := time.Now()
+	waf.hostnameLookup.now = func() time.Time { return now }
+
+	lookup := func(client string) string {
+		tx := waf.NewTransaction()
+		defer tx.Close()
+		tx.ProcessConnection(client, 1234, "10.0.0.3", 80)
+		return tx.variables.remoteHost.Get()
+	}
+
+	// server failures are not cached
+	lookup("10.0.0.2")
+	lookup("10.0.0.2")
+	if want, have := 2, resolver.calls; want != have {
+		t.Errorf("unexpected lookups, want %d, have %d", want, have)
+	}
+
+	resolver.calls = 0
+	lookup("10.0.0.1")
+	resolver.names["10.0.0.1"] = []string{"renamed.example.com."}
+	resolver.addrs["renamed.example.com."] = []string{"10.0.0.1"}
+	if want, have := "client.example.com", lookup("10.0.0.1"); want != have {
+		t.Errorf("unexpected cached REMOTE_HOST, want %q, have %q", want, have)
+	}
+	now = now.Add(hostnameCacheTTL)
+	if want, have := "renamed.example.com", lookup("10.0.0.1"); want != have {
+		t.Errorf("unexpected REMOTE_HOST after the TTL, want %q, have %q", want, have)
+	}
+	if want, have := 2, resolver.calls; want != have {
+		t.Errorf("unexpected lookups, want %d, have %d", want, have)
+	}
+}
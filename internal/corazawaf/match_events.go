@@ -0,0 +1,106 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+// defaultMatchEventBuffer is the buffer size used by subscriptions not
+// requesting a specific one
+const defaultMatchEventBuffer = 64
+
+// MatchEvent is emitted to the subscribers of a WAF every time a rule matches
+type MatchEvent struct {
+	// RuleID is the id of the matched rule, for chains the id of the parent rule
+	RuleID int
+	// Phase is the phase being evaluated when the rule matched
+	Phase types.RulePhase
+	// TransactionID is the id of the transaction the rule matched in
+	TransactionID string
+	// MatchedVar is the first matched variable in MATCHED_VAR_NAME format,
+	// e.g. ARGS:id, it is empty for rules without variables like SecAction
+	MatchedVar string
+	// MatchedValue is the value of MatchedVar
+	MatchedValue string
+	// Disruptive is true if the rule has a disruptive action, including
+	// pass, and the rule engine is On, as types.MatchedRule.Disruptive
+	Disruptive bool
+	// Status is the status of the interruption if the rule interrupted the
+	// transaction, 0 otherwise
+	Status int
+}
+
+// matchEventBus fans out match events to the subscribers of a WAF. Publishing
+// never blocks: events are dropped for subscribers whose buffer is full, so a
+// slow consumer does not slow down the transactions.
+type matchEventBus struct {
+	mu   sync.RWMutex
+	subs map[chan MatchEvent]struct{}
+	// count allows skipping the lock when there are no subscribers
+	count atomic.Int32
+}
+
+func (b *matchEventBus) subscribe(size int) (<-chan MatchEvent, func()) {
+	if size <= 0 {
+		size = defaultMatchEventBuffer
+	}
+	ch := make(chan MatchEvent, size)
+
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = map[chan MatchEvent]struct{}{}
+	}
+	b.subs[ch] = struct{}{}
+	b.count.Add(1)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.count.Add(-1)
+			// closed under the lock so publish never sends to a closed channel
+			close(ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+func (b *matchEventBus) enabled() bool {
+	return b.count.Load() > 0
+}
+
+func (b *matchEventBus) publish(e MatchEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			// buffer is full, the event is dropped for this subscriber
+		}
+	}
+}
+
+// Subscribe registers a consumer of the match events of all the transactions
+// of this WAF. Events are buffered up to size (64 if size is not positive) and
+// dropped when the buffer is full. The returned function unsubscribes and
+// closes the channel, it must be called once the consumer is done.
+func (w *WAF) Subscribe(size int) (<-chan MatchEvent, func()) {
+	return w.matchEvents.subscribe(size)
+}
+
+// SetRuleMatchCallback sets a function called synchronously every time a rule
+// matches, after the actions of the rule are evaluated and before the next
+// rule is. Rules that don't match are not notified. The callback receives a
+// copy of the match so it cannot affect the transaction, it must be set before
+// creating transactions and it should return fast as it delays them.
+func (w *WAF) SetRuleMatchCallback(cb func(MatchEvent)) {
+	w.ruleMatchCallback = cb
+}
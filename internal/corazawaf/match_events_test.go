@@ -0,0 +1,164 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+func newMatchEventsWAF(t *testing.T) *WAF {
+	t.Helper()
+	waf := NewWAF()
+	rule := NewRule()
+	rule.ID_ = 1
+	rule.Phase_ = types.PhaseRequestHeaders
+	if err := rule.AddVariable(variables.ArgsGet, "q", false); err != nil {
+		t.Fatal(err)
+	}
+	rule.SetOperator(&dummyEqOperator{}, "@eq", "0")
+	if err := waf.Rules.Add(rule); err != nil {
+		t.Fatal(err)
+	}
+	return waf
+}
+
+func TestMatchEventsDelivered(t *testing.T) {
+	waf := newMatchEventsWAF(t)
+	events, unsubscribe := waf.Subscribe(1)
+	defer unsubscribe()
+
+	tx := waf.NewTransactionWithOptions(Options{ID: "abc"})
+	tx.AddGetRequestArgument("q", "0")
+	tx.ProcessRequestHeaders()
+	if err := tx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-events:
+		want := MatchEvent{
+			RuleID:        1,
+			Phase:         types.PhaseRequestHeaders,
+			TransactionID: "abc",
+			MatchedVar:    "ARGS_GET:q",
+			MatchedValue:  "0",
+		}
+		if e != want {
+			t.Errorf("unexpected event, want %+v, have %+v", want, e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a match event")
+	}
+}
+
+func TestMatchEventsSlowConsumerDoesNotBlock(t *testing.T) {
+	waf := newMatchEventsWAF(t)
+	// the consumer never reads, only the first event fits the buffer
+	events, unsubscribe := waf.Subscribe(1)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			tx := waf.NewTransaction()
+			tx.AddGetRequestArgument("q", "0")
+			tx.ProcessRequestHeaders()
+			_ = tx.Close()
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("transactions blocked by a slow consumer")
+	}
+
+	unsubscribe()
+	unsubscribe()
+	n := 0
+	for range events {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("expected 1 buffered event, have %d", n)
+	}
+}
+
+func TestMatchEventsUnsubscribe(t *testing.T) {
+	waf := newMatchEventsWAF(t)
+	_, unsubscribe := waf.Subscribe(0)
+	if !waf.matchEvents.enabled() {
+		t.Fatal("expected match events to be enabled")
+	}
+	unsubscribe()
+	if waf.matchEvents.enabled() {
+		t.Error("expected match events to be disabled after unsubscribing")
+	}
+}
+
+func TestRuleMatchCallback(t *testing.T) {
+	waf := newMatchEventsWAF(t)
+	// rule 2 doesn't match as the variable is not set
+	for _, id := range []int{2, 3} {
+		rule := NewRule()
+		rule.ID_ = id
+		rule.Phase_ = types.PhaseRequestHeaders
+		key := "q"
+		if id == 2 {
+			key = "missing"
+		}
+		if err := rule.AddVariable(variables.ArgsGet, key, false); err != nil {
+			t.Fatal(err)
+		}
+		rule.SetOperator(&dummyEqOperator{}, "@eq", "0")
+		if id == 3 {
+			rule.DisruptiveStatus = 403
+			if err := rule.AddAction("deny", &dummyDenyAction{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := waf.Rules.Add(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var have []MatchEvent
+	waf.SetRuleMatchCallback(func(e MatchEvent) {
+		have = append(have, e)
+	})
+
+	tx := waf.NewTransactionWithOptions(Options{ID: "abc"})
+	tx.RuleEngine = types.RuleEngineOn
+	tx.AddGetRequestArgument("q", "0")
+	tx.ProcessRequestHeaders()
+	if err := tx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []MatchEvent{
+		{
+			RuleID:        1,
+			Phase:         types.PhaseRequestHeaders,
+			TransactionID: "abc",
+			MatchedVar:    "ARGS_GET:q",
+			MatchedValue:  "0",
+		},
+		{
+			RuleID:        3,
+			Phase:         types.PhaseRequestHeaders,
+			TransactionID: "abc",
+			MatchedVar:    "ARGS_GET:q",
+			MatchedValue:  "0",
+			Disruptive:    true,
+			Status:        403,
+		},
+	}
+	if !slices.Equal(have, want) {
+		t.Errorf("unexpected events, want %+v, have %+v", want, have)
+	}
+}
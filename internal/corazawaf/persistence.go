@@ -0,0 +1,101 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/corazawaf/coraza/v3/internal/collections"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+var errCollectionNotInitialized = errors.New("collection not initialized with initcol")
+
+// IsPersistentCollection returns true if the collection is stored by the
+// persistence engine across transactions.
+func IsPersistentCollection(v variables.RuleVariable) bool {
+	switch v {
+	case variables.IP, variables.Global, variables.Session, variables.User, variables.Resource:
+		return true
+	}
+	return false
+}
+
+// InitCollection loads the record of a persistent collection from the
+// persistence engine, replacing the variables of the collection in the
+// transaction.
+func (tx *Transaction) InitCollection(v variables.RuleVariable, record string) error {
+	col, ok := tx.persistentCollection(v)
+	if !ok {
+		return fmt.Errorf("collection %s is not persistent", v.Name())
+	}
+	data, err := tx.WAF.Persistence.Get(v.Name(), record)
+	if err != nil {
+		return err
+	}
+	col.Reset()
+	for k, val := range data {
+		col.Set(k, []string{val})
+	}
+	if tx.persistentRecords == nil {
+		tx.persistentRecords = map[variables.RuleVariable]string{}
+	}
+	tx.persistentRecords[v] = record
+	return nil
+}
+
+// PersistVariable stores the value the variable has in the transaction in the
+// record of the persistent collection, or removes it if the transaction has
+// no value for it.
+func (tx *Transaction) PersistVariable(v variables.RuleVariable, key string) error {
+	record, ok := tx.persistentRecords[v]
+	if !ok {
+		return errCollectionNotInitialized
+	}
+	col, _ := tx.persistentCollection(v)
+	if values := col.Get(key); len(values) > 0 {
+		return tx.WAF.Persistence.Set(v.Name(), record, key, values[0])
+	}
+	return tx.WAF.Persistence.Remove(v.Name(), record, key)
+}
+
+// AddToVariable adds delta to the variable in the record of the persistent
+// collection and sets the result in the transaction. The engine applies it
+// atomically, unlike a value computed from the record loaded by initcol, which
+// would overwrite the updates of concurrent transactions.
+func (tx *Transaction) AddToVariable(v variables.RuleVariable, key string, delta int) error {
+	record, ok := tx.persistentRecords[v]
+	if !ok {
+		return errCollectionNotInitialized
+	}
+	n, err := tx.WAF.Persistence.Add(v.Name(), record, key, delta)
+	if err != nil {
+		return err
+	}
+	col, _ := tx.persistentCollection(v)
+	col.Set(key, []string{strconv.Itoa(n)})
+	return nil
+}
+
+// ExpireVariable schedules the removal of the variable from the record of the
+// persistent collection after ttl, later transactions do not see it once ttl
+// has elapsed.
+func (tx *Transaction) ExpireVariable(v variables.RuleVariable, key string, ttl time.Duration) error {
+	record, ok := tx.persistentRecords[v]
+	if !ok {
+		return errCollectionNotInitialized
+	}
+	return tx.WAF.Persistence.Expire(v.Name(), record, key, ttl)
+}
+
+func (tx *Transaction) persistentCollection(v variables.RuleVariable) (*collections.Map, bool) {
+	if !IsPersistentCollection(v) {
+		return nil, false
+	}
+	col, ok := tx.Collection(v).(*collections.Map)
+	return col, ok
+}
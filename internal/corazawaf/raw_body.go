@@ -0,0 +1,88 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/collection"
+	"github.com/corazawaf/coraza/v3/internal/corazarules"
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+// rawBody is a Single collection backed by a body buffer holding the body bytes
+// as received, regardless of the body processor. The buffer is only read when
+// the collection is inspected, so transactions not referencing it don't pay for
+// copying the body.
+type rawBody struct {
+	variable variables.RuleVariable
+	buffer   *BodyBuffer
+	// data caches the body read from the buffer, it is read again if the
+	// buffer length changes, e.g. when the response body is modified.
+	data   string
+	length int64
+	loaded bool
+}
+
+var _ collection.Single = &rawBody{}
+
+func newRawBody(variable variables.RuleVariable) *rawBody {
+	return &rawBody{
+		variable: variable,
+	}
+}
+
+func (c *rawBody) FindAll() []types.MatchData {
+	return []types.MatchData{
+		&corazarules.MatchData{
+			Variable_: c.variable,
+			Value_:    c.Get(),
+		},
+	}
+}
+
+func (c *rawBody) Get() string {
+	if c.buffer == nil || c.buffer.length == 0 {
+		return ""
+	}
+	if c.loaded && c.length == c.buffer.length {
+		return c.data
+	}
+
+	reader, err := c.buffer.Reader()
+	if err != nil {
+		return ""
+	}
+	var buf strings.Builder
+	if _, err := io.Copy(&buf, reader); err != nil {
+		return ""
+	}
+	c.data = buf.String()
+	c.length = c.buffer.length
+	c.loaded = true
+	return c.data
+}
+
+func (c *rawBody) Name() string {
+	return c.variable.Name()
+}
+
+func (c *rawBody) Reset() {
+	c.data = ""
+	c.length = 0
+	c.loaded = false
+}
+
+func (c *rawBody) Format(res *strings.Builder) {
+	res.WriteString(c.variable.Name())
+	res.WriteString(": ")
+	res.WriteString(c.Get())
+}
+
+func (c *rawBody) String() string {
+	return fmt.Sprintf("%s: %s", c.variable.Name(), c.Get())
+}
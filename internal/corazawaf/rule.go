@@ -4,10 +4,13 @@
 package corazawaf
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/corazawaf/coraza/v3/debuglog"
@@ -77,6 +80,19 @@ type ruleVariableParams struct {
 type ruleTransformationParams struct {
 	// The transformation function to be used
 	Function plugintypes.Transformation
+
+	// Name of the transformation, used for debugging
+	Name string
+
+	// urlDecode is true for the transformations used to detect
+	// multiple layers of url encoding (urlDecode and urlDecodeUni)
+	urlDecode bool
+}
+
+// RuleScript is a script evaluated by a rule instead of an operator,
+// it returns whether the rule matched and an optional message.
+type RuleScript interface {
+	Evaluate(ctx context.Context, tx *Transaction) (bool, string, error)
 }
 
 // Rule is used to test a Transaction against certain operators
@@ -106,13 +122,29 @@ type Rule struct {
 	// to capture variables on TX:0-9
 	Capture bool
 
+	// SkipAfter is the SecMarker the skipAfter action of the rule jumps to,
+	// it is validated to exist once the rules are loaded
+	SkipAfter string
+
 	// Contains the child rule to chain, nil if there are no chains
 	Chain *Rule
 
+	// Script is evaluated instead of the operator by the rules created
+	// with SecRuleScript, nil for any other rule
+	Script RuleScript
+
+	// SamplePercentage is the percentage of the times the rule is evaluated,
+	// 0 means the rule is always evaluated
+	SamplePercentage int
+
 	// DisruptiveStatus is the status that will be set to interruptions
 	// by disruptive rules
 	DisruptiveStatus int
 
+	// DisruptiveStatusMacro holds the status value when it needs to be macro
+	// expanded at evaluation time, e.g. status:%{tx.blocking_status}
+	DisruptiveStatusMacro macro.Macro
+
 	// Message text to be macro expanded and logged
 	// In future versions we might use a special type of string that
 	// supports cached macro expansions. For performance
@@ -146,6 +178,10 @@ type Rule struct {
 	// chainedRules containing rules with just PhaseUnknown variables, may potentially
 	// be anticipated. This boolean ensures that it happens
 	withPhaseUnknownVariable bool
+
+	// hits counts the matches of the rule, the counter is allocated when the
+	// rule is added to a RuleGroup and shared by the copies of the rule
+	hits *atomic.Uint64
 }
 
 func (r *Rule) ParentID() int {
@@ -158,6 +194,14 @@ func (r *Rule) Status() int {
 
 const chainLevelZero = 0
 
+// urlDecodeDoubleEncodedKey is the TX key set to 1 when urlDecode or urlDecodeUni
+// detect an argument with multiple layers of url encoding, e.g. %2527
+const urlDecodeDoubleEncodedKey = "urldecode_double_encoded"
+
+// transformationErrorKey is the TX key set to 1 when SecTransformationErrorFlag is
+// enabled and a transformation fails, e.g. hexDecode over an invalid hex string
+const transformationErrorKey = "transformation_error"
+
 // Evaluate will evaluate the current rule for the indicated transaction
 // If the operator matches, actions will be evaluated, and it will return
 // the matched variables, keys and values (MatchData)
@@ -175,12 +219,20 @@ func (r *Rule) Evaluate(phase types.RulePhase, tx plugintypes.TransactionState,
 		}
 	}
 
+	if r.SamplePercentage > 0 && !tx.(*Transaction).WAF.sampler.sample(r.SamplePercentage) {
+		logger.Debug().Int("sample", r.SamplePercentage).Msg("Skipping rule not sampled")
+		return
+	}
+
 	r.doEvaluate(logger, phase, tx.(*Transaction), &collectiveMatchedValues, chainLevelZero, cache)
 }
 
 const noID = 0
 
 func (r *Rule) doEvaluate(logger debuglog.Logger, phase types.RulePhase, tx *Transaction, collectiveMatchedValues *[]types.MatchData, chainLevel int, cache map[transformationKey]*transformationValue) []types.MatchData {
+	// TX:0-9 are only written by rules with the capture action, so the captures of
+	// the parent rule remain available to the chained rules, e.g. as %{tx.1} in
+	// their operator arguments, unless a chained rule captures too.
 	tx.Capture = r.Capture
 
 	if multiphaseEvaluation {
@@ -204,8 +256,24 @@ func (r *Rule) doEvaluate(logger debuglog.Logger, phase types.RulePhase, tx *Tra
 	ruleCol.SetIndex("severity", 0, r.Severity_.String())
 	// SecMark and SecAction uses nil operator
 	if r.operator == nil {
-		logger.Debug().Msg("Forcing rule to match")
 		md := &corazarules.MatchData{}
+		if r.Script != nil {
+			ctx, cancel := tx.evaluationContext()
+			match, msg, err := r.Script.Evaluate(ctx, tx)
+			cancel()
+			if err != nil {
+				logger.Error().Err(err).Msg("Error evaluating rule script")
+				return nil
+			}
+			if !match {
+				logger.Debug().Msg("Evaluating rule script: NO MATCH")
+				return nil
+			}
+			logger.Debug().Msg("Evaluating rule script: MATCH")
+			md.Message_ = msg
+		} else {
+			logger.Debug().Msg("Forcing rule to match")
+		}
 		if r.ParentID_ != noID || r.MultiMatch {
 			// In order to support Msg and LogData for inner rules, we need to expand them now
 			if r.Msg != nil {
@@ -227,10 +295,15 @@ func (r *Rule) doEvaluate(logger debuglog.Logger, phase types.RulePhase, tx *Tra
 				continue
 			}
 			var values []types.MatchData
+			if len(ecol) > 0 {
+				// v is a copy of the rule variable shared by all the transactions, the exceptions
+				// of this transaction must not be written to the backing array of the rule ones.
+				v.Exceptions = slices.Clip(v.Exceptions)
+			}
 			for _, c := range ecol {
 				if c.Variable == v.Variable {
 					// TODO shall we check the pointer?
-					v.Exceptions = append(v.Exceptions, ruleVariableException{c.KeyStr, nil})
+					v.Exceptions = append(v.Exceptions, ruleVariableException{c.KeyStr, c.KeyRx})
 				}
 			}
 
@@ -245,14 +318,24 @@ func (r *Rule) doEvaluate(logger debuglog.Logger, phase types.RulePhase, tx *Tra
 			args := make([]string, 1)
 			var errs []error
 			var argsLen int
+			var doubleEncoded bool
 			for i, arg := range values {
+				// a single rule can take long on many or large values, the
+				// values left are not evaluated once the phase is aborted
+				if i%evaluationCheckInterval == 0 && tx.evaluationAborted() {
+					return nil
+				}
 				if r.MultiMatch {
-					args, errs = r.transformMultiMatchArg(arg)
+					args, doubleEncoded, errs = r.transformMultiMatchArg(arg)
 					argsLen = len(args)
 				} else {
-					args[0], errs = r.transformArg(arg, i, cache)
+					args[0], doubleEncoded, errs = r.transformArg(arg, i, cache)
 					argsLen = 1
 				}
+				if doubleEncoded {
+					vLog.Debug().Str("key", arg.Key()).Msg("Multiple layers of url encoding detected")
+					tx.variables.tx.Set(urlDecodeDoubleEncodedKey, []string{"1"})
+				}
 				if len(errs) > 0 {
 					vWarnLog := vLog.Warn()
 					if vWarnLog.IsEnabled() {
@@ -261,6 +344,9 @@ func (r *Rule) doEvaluate(logger debuglog.Logger, phase types.RulePhase, tx *Tra
 						}
 						vWarnLog.Msg("Error transforming argument for rule")
 					}
+					if tx.WAF.TransformationErrorFlag {
+						tx.variables.tx.Set(transformationErrorKey, []string{"1"})
+					}
 				}
 
 				// args represents the transformed variables
@@ -282,6 +368,15 @@ func (r *Rule) doEvaluate(logger debuglog.Logger, phase types.RulePhase, tx *Tra
 						// Set the txn variables for expansions before usage
 						r.matchVariable(tx, mr)
 
+						if tx.WAF.DebugLogTransformations && vLog.Debug().IsEnabled() {
+							for _, step := range r.transformationSteps(arg, i, cache) {
+								vLog.Debug().
+									Str("transformation", step.name).
+									Str("value", step.value).
+									Msg("Transformation applied")
+							}
+						}
+
 						// Expansion for parent rule of a chain is postponed in order to rely on updated MATCHED_* variables.
 						// In all other cases, we want to expand here before continuing the rule evaluation to log the matched data
 						// just after the match an not just the last one. It is needed to log more than one variable matched by the same rule.
@@ -390,45 +485,69 @@ func (r *Rule) doEvaluate(logger debuglog.Logger, phase types.RulePhase, tx *Tra
 	return matchedValues
 }
 
-func (r *Rule) transformMultiMatchArg(arg types.MatchData) ([]string, []error) {
+func (r *Rule) transformMultiMatchArg(arg types.MatchData) ([]string, bool, []error) {
 	// TODOs:
 	// - We don't need to run every transformation. We could try for each until found
 	// - Cache is not used for multimatch
 	return r.executeTransformationsMultimatch(arg.Value())
 }
 
-func (r *Rule) transformArg(arg types.MatchData, argIdx int, cache map[transformationKey]*transformationValue) (string, []error) {
+func (r *Rule) transformArg(arg types.MatchData, argIdx int, cache map[transformationKey]*transformationValue) (string, bool, []error) {
 	switch {
 	case len(r.transformations) == 0:
-		return arg.Value(), nil
+		return arg.Value(), false, nil
 	case arg.Variable().Name() == "TX":
 		// no cache for TX
-		arg, errs := r.executeTransformations(arg.Value())
-		return arg, errs
+		return r.executeTransformations(arg.Value())
 	default:
-		// NOTE: See comment on transformationKey struct to understand this hacky code
-		argKey := arg.Key()
-		argKeyPtr := unsafe.StringData(argKey)
-		key := transformationKey{
-			argKey:            argKeyPtr,
-			argIndex:          argIdx,
-			argVariable:       arg.Variable(),
-			transformationsID: r.transformationsID,
-		}
+		key := r.transformationKey(arg, argIdx)
 		if cached, ok := cache[key]; ok {
-			return cached.arg, cached.errs
+			return cached.arg, cached.doubleEncoded, cached.errs
 		} else {
-			ars, es := r.executeTransformations(arg.Value())
+			ars, doubleEncoded, es := r.executeTransformations(arg.Value())
 			errs := es
 			cache[key] = &transformationValue{
-				arg:  ars,
-				errs: es,
+				arg:           ars,
+				doubleEncoded: doubleEncoded,
+				errs:          es,
 			}
-			return ars, errs
+			return ars, doubleEncoded, errs
 		}
 	}
 }
 
+// transformationSteps returns the output of each transformation of the rule for the
+// argument. Steps are stored along with the cached transformed value, so they are only
+// recorded once per argument and transformation chain.
+func (r *Rule) transformationSteps(arg types.MatchData, argIdx int, cache map[transformationKey]*transformationValue) []transformationStep {
+	if len(r.transformations) == 0 {
+		return nil
+	}
+	if arg.Variable().Name() == "TX" {
+		return r.recordTransformations(arg.Value())
+	}
+	cached, ok := cache[r.transformationKey(arg, argIdx)]
+	if !ok {
+		// multimatch rules don't use the cache
+		return r.recordTransformations(arg.Value())
+	}
+	if cached.steps == nil {
+		cached.steps = r.recordTransformations(arg.Value())
+	}
+	return cached.steps
+}
+
+func (r *Rule) transformationKey(arg types.MatchData, argIdx int) transformationKey {
+	// NOTE: See comment on transformationKey struct to understand this hacky code
+	argKey := arg.Key()
+	return transformationKey{
+		argKey:            unsafe.StringData(argKey),
+		argIndex:          argIdx,
+		argVariable:       arg.Variable(),
+		transformationsID: r.transformationsID,
+	}
+}
+
 func (r *Rule) matchVariable(tx *Transaction, m *corazarules.MatchData) {
 	rid := r.ID_
 	if rid == noID {
@@ -447,7 +566,10 @@ func (r *Rule) matchVariable(tx *Transaction, m *corazarules.MatchData) {
 	// if multiphaseEvaluation is true, the non disruptive actions execution is deferred
 	// SecActions (r.operator == nil) are always executed
 	if !multiphaseEvaluation || r.operator == nil {
-		tx.matchVariable(m)
+		// SecActions don't match any variable, they keep the MATCHED_* variables of the last match
+		if m.Variable() != variables.Unknown {
+			tx.matchVariable(m)
+		}
 		for _, a := range r.actions {
 			if a.Function.Type() == plugintypes.ActionTypeNondisruptive {
 				tx.DebugLogger().Debug().Str("action", a.Name).Msg("Evaluating action")
@@ -483,7 +605,9 @@ func caseSensitiveVariable(v variables.RuleVariable) bool {
 	switch v {
 	case variables.Args, variables.ArgsNames,
 		variables.ArgsGet, variables.ArgsPost,
-		variables.ArgsGetNames, variables.ArgsPostNames:
+		variables.ArgsGetNames, variables.ArgsPostNames,
+		// XPath expressions are case sensitive
+		variables.XML, variables.RequestXML, variables.ResponseXML:
 		res = true
 	}
 	return res
@@ -515,7 +639,7 @@ func (r *Rule) AddVariable(v variables.RuleVariable, key string, iscount bool) e
 	}
 	var re *regexp.Regexp
 	if isRegex, rx := hasRegex(key); isRegex {
-		if vare, err := memoize.Do(rx, func() (interface{}, error) { return regexp.Compile(rx) }); err != nil {
+		if vare, err := memoize.DoBounded(rx, func() (interface{}, error) { return regexp.Compile(rx) }); err != nil {
 			return err
 		} else {
 			re = vare.(*regexp.Regexp)
@@ -559,7 +683,7 @@ func needToSplitConcatenatedVariable(v variables.RuleVariable, ve variables.Rule
 func (r *Rule) AddVariableNegation(v variables.RuleVariable, key string) error {
 	var re *regexp.Regexp
 	if isRegex, rx := hasRegex(key); isRegex {
-		if vare, err := memoize.Do(rx, func() (interface{}, error) { return regexp.Compile(rx) }); err != nil {
+		if vare, err := memoize.DoBounded(rx, func() (interface{}, error) { return regexp.Compile(rx) }); err != nil {
 			return err
 		} else {
 			re = vare.(*regexp.Regexp)
@@ -611,15 +735,41 @@ func (r *Rule) AddTransformation(name string, t plugintypes.Transformation) erro
 	if t == nil || name == "" {
 		return fmt.Errorf("invalid transformation %q not found", name)
 	}
-	r.transformations = append(r.transformations, ruleTransformationParams{Function: t})
+	lname := strings.ToLower(name)
+	r.transformations = append(r.transformations, ruleTransformationParams{
+		Function:  t,
+		Name:      name,
+		urlDecode: lname == "urldecode" || lname == "urldecodeuni",
+	})
 	r.transformationsID = transformationID(r.transformationsID, name)
 	return nil
 }
 
+// ClearVariables removes all the variables of the rule along with their exceptions,
+// it is used to replace the targets of a rule
+func (r *Rule) ClearVariables() {
+	r.variables = nil
+}
+
+// ClearDisruptiveActions removes the disruptive actions of the rule, it is used
+// to replace its disruptive action
+func (r *Rule) ClearDisruptiveActions() {
+	actions := r.actions[:0]
+	for _, a := range r.actions {
+		if a.Function.Type() != plugintypes.ActionTypeDisruptive {
+			actions = append(actions, a)
+		}
+	}
+	r.actions = actions
+}
+
 // ClearTransformations clears all the transformations
 // it is mostly used by the "none" transformation
 func (r *Rule) ClearTransformations() {
 	r.transformations = []ruleTransformationParams{}
+	// the ID identifies the cached results of the transformations, it must only
+	// cover the transformations added after the reset
+	r.transformationsID = 0
 }
 
 // SetOperator sets the operator of the rule
@@ -634,7 +784,18 @@ func (r *Rule) SetOperator(operator plugintypes.Operator, functionName string, p
 	}
 }
 
+// Operator returns the operator of the rule, nil if the rule has no operator (e.g. SecAction)
+func (r *Rule) Operator() plugintypes.Operator {
+	if r.operator == nil {
+		return nil
+	}
+	return r.operator.Operator
+}
+
 func (r *Rule) executeOperator(data string, tx *Transaction) (result bool) {
+	if span := tx.startOperatorSpan(r, data); span != nil {
+		defer span.End()
+	}
 	result = r.operator.Operator.Evaluate(tx, data)
 	if r.operator.Negation {
 		result = !result
@@ -642,10 +803,11 @@ func (r *Rule) executeOperator(data string, tx *Transaction) (result bool) {
 	return
 }
 
-func (r *Rule) executeTransformationsMultimatch(value string) ([]string, []error) {
+func (r *Rule) executeTransformationsMultimatch(value string) ([]string, bool, []error) {
 	// The original value will be evaluated
 	res := []string{value}
 	var errs []error
+	doubleEncoded := false
 	for _, t := range r.transformations {
 		transformedValue, changed, err := t.Function(value)
 		if err != nil {
@@ -654,24 +816,56 @@ func (r *Rule) executeTransformationsMultimatch(value string) ([]string, []error
 		}
 		// Every time a transformation generates a new value different from the previous one, the new value is collected to be evaluated
 		if changed {
+			if t.urlDecode && !doubleEncoded {
+				doubleEncoded = isDoubleURLEncoded(t.Function, transformedValue)
+			}
 			res = append(res, transformedValue)
 			value = transformedValue
 		}
 	}
-	return res, errs
+	return res, doubleEncoded, errs
 }
 
-func (r *Rule) executeTransformations(value string) (string, []error) {
+// executeTransformations runs the transformations of the rule against value. It also reports
+// whether a url decoding transformation found multiple layers of encoding.
+func (r *Rule) executeTransformations(value string) (string, bool, []error) {
 	var errs []error
+	doubleEncoded := false
 	for _, t := range r.transformations {
-		v, _, err := t.Function(value)
+		v, changed, err := t.Function(value)
 		if err != nil {
 			errs = append(errs, err)
 			continue
 		}
+		if changed && t.urlDecode && !doubleEncoded {
+			doubleEncoded = isDoubleURLEncoded(t.Function, v)
+		}
 		value = v
 	}
-	return value, errs
+	return value, doubleEncoded, errs
+}
+
+// recordTransformations runs the transformations of the rule against value and returns
+// the output of each of them. Transformations returning an error are skipped, as done
+// by executeTransformations.
+func (r *Rule) recordTransformations(value string) []transformationStep {
+	steps := make([]transformationStep, 0, len(r.transformations))
+	for _, t := range r.transformations {
+		v, _, err := t.Function(value)
+		if err != nil {
+			continue
+		}
+		value = v
+		steps = append(steps, transformationStep{name: t.Name, value: value})
+	}
+	return steps
+}
+
+// isDoubleURLEncoded returns true if decoding an already url decoded value
+// yields further changes, which means it had multiple layers of encoding.
+func isDoubleURLEncoded(urlDecode plugintypes.Transformation, decoded string) bool {
+	v, _, err := urlDecode(decoded)
+	return err == nil && v != decoded
 }
 
 // NewRule returns a new initialized rule
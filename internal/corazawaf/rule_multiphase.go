@@ -76,6 +76,9 @@ func minPhase(v variables.RuleVariable) types.RulePhase {
 	case variables.InboundDataError:
 		// Not populated by Coraza
 		return types.PhaseRequestBody
+	case variables.ArgsLimitExceeded, variables.RequestHeadersLimitExceeded, variables.RequestCookiesLimitExceeded:
+		// Set while adding the request headers and arguments, POST arguments are only added in phase 2
+		return types.PhaseRequestHeaders
 	case variables.MatchedVar:
 		// MatchedVar is only for logging, not evaluation
 		return types.PhaseUnknown
@@ -112,6 +115,8 @@ func minPhase(v variables.RuleVariable) types.RulePhase {
 		return types.PhaseRequestHeaders
 	case variables.RequestBody:
 		return types.PhaseRequestBody
+	case variables.RequestBodyRaw:
+		return types.PhaseRequestBody
 	case variables.RequestBodyLength:
 		return types.PhaseRequestBody
 	case variables.RequestFilename:
@@ -128,6 +133,8 @@ func minPhase(v variables.RuleVariable) types.RulePhase {
 		return types.PhaseRequestHeaders
 	case variables.ResponseBody:
 		return types.PhaseResponseBody
+	case variables.ResponseBodyRaw:
+		return types.PhaseResponseBody
 	case variables.ResponseContentLength:
 		return types.PhaseResponseBody
 	case variables.ResponseProtocol:
@@ -143,6 +150,9 @@ func minPhase(v variables.RuleVariable) types.RulePhase {
 	case variables.ServerPort:
 		// Configuration of the server itself
 		return types.PhaseRequestHeaders
+	case variables.WebserverErrorLog:
+		// Fed by the integrator once the response has been handled
+		return types.PhaseLogging
 	case variables.HighestSeverity:
 		// Result of matching, not used in phaes
 		return types.PhaseUnknown
@@ -189,9 +199,17 @@ func minPhase(v variables.RuleVariable) types.RulePhase {
 		return types.PhaseRequestHeaders
 	case variables.ResponseHeaders:
 		return types.PhaseResponseHeaders
+	case variables.RequestTrailers, variables.ResponseTrailers:
+		// Fed by the integrator once the bodies have been read
+		return types.PhaseLogging
 	case variables.Geo:
 		// Not populated by Coraza
 		return types.PhaseRequestHeaders
+	case variables.IP, variables.Global, variables.Session, variables.User, variables.Resource:
+		// Loaded by initcol, in any phase
+		return types.PhaseUnknown
+	case variables.AuthType, variables.RemoteUser:
+		return types.PhaseRequestHeaders
 	case variables.RequestCookiesNames:
 		return types.PhaseRequestHeaders
 	case variables.FilesTmpNames:
@@ -224,6 +242,30 @@ func minPhase(v variables.RuleVariable) types.RulePhase {
 		return types.PhaseRequestBody
 	case variables.MultipartPartHeaders:
 		return types.PhaseRequestBody
+	case variables.MultipartStrictError:
+		return types.PhaseRequestBody
+	case variables.MultipartBoundaryQuoted:
+		return types.PhaseRequestBody
+	case variables.MultipartBoundaryWhitespace:
+		return types.PhaseRequestBody
+	case variables.MultipartCrlfLfLines:
+		return types.PhaseRequestBody
+	case variables.MultipartDataBefore:
+		return types.PhaseRequestBody
+	case variables.MultipartHeaderFolding:
+		return types.PhaseRequestBody
+	case variables.MultipartInvalidHeaderFolding:
+		return types.PhaseRequestBody
+	case variables.MultipartInvalidPart:
+		return types.PhaseRequestBody
+	case variables.MultipartInvalidQuoting:
+		return types.PhaseRequestBody
+	case variables.MultipartLfLine:
+		return types.PhaseRequestBody
+	case variables.MultipartMissingSemicolon:
+		return types.PhaseRequestBody
+	case variables.MultipartUnmatchedBoundary:
+		return types.PhaseRequestBody
 	}
 
 	return types.PhaseUnknown
@@ -4,8 +4,10 @@
 package corazawaf
 
 import (
+	"bytes"
 	"errors"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/corazawaf/coraza/v3/debuglog"
@@ -424,7 +426,7 @@ func TestExecuteTransformations(t *testing.T) {
 	rule := NewRule()
 	_ = rule.AddTransformation("AppendA", transformationAppendA)
 	_ = rule.AddTransformation("AppendB", transformationAppendB)
-	transformedInput, error := rule.executeTransformations("input")
+	transformedInput, _, error := rule.executeTransformations("input")
 	if error != nil {
 		t.Fatalf("Unexecpted errors executing transformations: %v", error)
 	}
@@ -440,11 +442,32 @@ var transformationErrorB = func(input string) (string, bool, error) {
 	return "", false, errors.New("errorB")
 }
 
+func TestExecuteTransformationsAfterClear(t *testing.T) {
+	rule := NewRule()
+	_ = rule.AddTransformation("AppendA", transformationAppendA)
+	rule.ClearTransformations()
+	_ = rule.AddTransformation("AppendB", transformationAppendB)
+	transformedInput, _, err := rule.executeTransformations("input")
+	if err != nil {
+		t.Fatalf("Unexpected errors executing transformations: %v", err)
+	}
+	if transformedInput != "inputB" {
+		t.Fatalf("Expected inputB, got %s", transformedInput)
+	}
+
+	// the results are cached as the ones of a rule with only AppendB
+	onlyB := NewRule()
+	_ = onlyB.AddTransformation("AppendB", transformationAppendB)
+	if rule.transformationsID != onlyB.transformationsID {
+		t.Errorf("Expected transformations ID %d, got %d", onlyB.transformationsID, rule.transformationsID)
+	}
+}
+
 func TestExecuteTransformationsReturnsMultipleErrors(t *testing.T) {
 	rule := NewRule()
 	_ = rule.AddTransformation("AppendA", transformationErrorA)
 	_ = rule.AddTransformation("AppendB", transformationErrorB)
-	_, error := rule.executeTransformations("arg")
+	_, _, error := rule.executeTransformations("arg")
 	if len(error) != 2 {
 		t.Fatalf("Expected 2 errors executing transformations that returns errors, got %d", len(error))
 	}
@@ -460,7 +483,7 @@ func TestExecuteTransformationsMultiMatch(t *testing.T) {
 	rule := NewRule()
 	_ = rule.AddTransformation("AppendA", transformationAppendA)
 	_ = rule.AddTransformation("AppendB", transformationAppendB)
-	transformedInput, error := rule.executeTransformationsMultimatch("input")
+	transformedInput, _, error := rule.executeTransformationsMultimatch("input")
 	if error != nil {
 		t.Fatalf("Unexecpted errors executing transformations: %v", error)
 	}
@@ -482,7 +505,7 @@ func TestExecuteTransformationsMultiMatchReturnsMultipleErrors(t *testing.T) {
 	rule := NewRule()
 	_ = rule.AddTransformation("A", transformationErrorA)
 	_ = rule.AddTransformation("B", transformationErrorB)
-	_, error := rule.executeTransformationsMultimatch("arg")
+	_, _, error := rule.executeTransformationsMultimatch("arg")
 	if len(error) != 2 {
 		t.Fatalf("Expected 2 errors executing transformations that returns errors, got %d", len(error))
 	}
@@ -506,7 +529,7 @@ func TestTransformArgSimple(t *testing.T) {
 	rule := NewRule()
 	_ = rule.AddTransformation("AppendA", transformationAppendA)
 	_ = rule.AddTransformation("AppendB", transformationAppendB)
-	arg, errs := rule.transformArg(md, 0, transformationCache)
+	arg, _, errs := rule.transformArg(md, 0, transformationCache)
 	if errs != nil {
 		t.Fatalf("Unexpected errors executing transformations: %v", errs)
 	}
@@ -517,7 +540,7 @@ func TestTransformArgSimple(t *testing.T) {
 		t.Errorf("Expected 1 transformations in cache, got %d", len(transformationCache))
 	}
 	// Repeating the same transformation, expecting still one element in the cache (that means it is a cache hit)
-	arg, errs = rule.transformArg(md, 0, transformationCache)
+	arg, _, errs = rule.transformArg(md, 0, transformationCache)
 	if errs != nil {
 		t.Fatalf("Unexpected errors executing transformations: %v", errs)
 	}
@@ -538,7 +561,7 @@ func TestTransformArgNoCacheForTXVariable(t *testing.T) {
 	}
 	rule := NewRule()
 	_ = rule.AddTransformation("AppendA", transformationAppendA)
-	arg, errs := rule.transformArg(md, 0, transformationCache)
+	arg, _, errs := rule.transformArg(md, 0, transformationCache)
 	if errs != nil {
 		t.Fatalf("Unexpected errors executing transformations: %v", errs)
 	}
@@ -550,6 +573,159 @@ func TestTransformArgNoCacheForTXVariable(t *testing.T) {
 	}
 }
 
+func TestTransformationSteps(t *testing.T) {
+	transformationCache := map[transformationKey]*transformationValue{}
+	md := &corazarules.MatchData{
+		Variable_: variables.RequestURI,
+		Key_:      "REQUEST_URI",
+		Value_:    "/test",
+	}
+	rule := NewRule()
+	_ = rule.AddTransformation("AppendA", transformationAppendA)
+	_ = rule.AddTransformation("AppendB", transformationAppendB)
+	_ = rule.AddTransformation("AppendA", transformationAppendA)
+	if arg, _, _ := rule.transformArg(md, 0, transformationCache); arg != "/testABA" {
+		t.Fatalf("unexpected transformed value, want %q, have %q", "/testABA", arg)
+	}
+
+	expected := []transformationStep{
+		{name: "AppendA", value: "/testA"},
+		{name: "AppendB", value: "/testAB"},
+		{name: "AppendA", value: "/testABA"},
+	}
+	steps := rule.transformationSteps(md, 0, transformationCache)
+	if len(steps) != len(expected) {
+		t.Fatalf("unexpected number of steps, want %d, have %d", len(expected), len(steps))
+	}
+	for i, step := range steps {
+		if step != expected[i] {
+			t.Errorf("unexpected step %d, want %+v, have %+v", i, expected[i], step)
+		}
+	}
+	for _, cached := range transformationCache {
+		if len(cached.steps) != len(expected) {
+			t.Errorf("expected steps to be stored in the transformation cache")
+		}
+	}
+}
+
+func TestTransformationStepsSkipErrors(t *testing.T) {
+	rule := NewRule()
+	_ = rule.AddTransformation("AppendA", transformationAppendA)
+	_ = rule.AddTransformation("ErrorA", transformationErrorA)
+	_ = rule.AddTransformation("AppendB", transformationAppendB)
+	steps := rule.recordTransformations("arg")
+	expected := []transformationStep{
+		{name: "AppendA", value: "argA"},
+		{name: "AppendB", value: "argAB"},
+	}
+	if len(steps) != len(expected) {
+		t.Fatalf("unexpected number of steps, want %d, have %d", len(expected), len(steps))
+	}
+	for i, step := range steps {
+		if step != expected[i] {
+			t.Errorf("unexpected step %d, want %+v, have %+v", i, expected[i], step)
+		}
+	}
+}
+
+func TestDebugLogTransformations(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(strconv.FormatBool(enabled), func(t *testing.T) {
+			logBuffer := &bytes.Buffer{}
+			waf := NewWAF()
+			waf.SetDebugLogOutput(logBuffer)
+			_ = waf.SetDebugLogLevel(debuglog.LevelDebug)
+			waf.DebugLogTransformations = enabled
+
+			r := NewRule()
+			r.ID_ = 1
+			r.LogID_ = "1"
+			if err := r.AddVariable(variables.ArgsGet, "", false); err != nil {
+				t.Fatal(err)
+			}
+			r.SetOperator(&dummyEqOperator{}, "@eq", "0")
+			_ = r.AddTransformation("trim", func(input string) (string, bool, error) {
+				return strings.TrimSpace(input), true, nil
+			})
+			_ = r.AddTransformation("removeX", func(input string) (string, bool, error) {
+				return strings.ReplaceAll(input, "x", ""), true, nil
+			})
+
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.AddGetRequestArgument("test", " x0 ")
+			r.Evaluate(types.PhaseRequestHeaders, tx, tx.transformationCache)
+			if len(tx.matchedRules) != 1 {
+				t.Fatalf("expected the rule to match")
+			}
+
+			logs := logBuffer.String()
+			for _, want := range []string{
+				`transformation="trim" value="x0"`,
+				`transformation="removeX" value="0"`,
+			} {
+				if have := strings.Contains(logs, want); have != enabled {
+					t.Errorf("unexpected presence of %q in the debug log, want %t, have %t", want, enabled, have)
+				}
+			}
+		})
+	}
+}
+
+func TestTransformationErrorFlag(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(strconv.FormatBool(enabled), func(t *testing.T) {
+			waf := NewWAF()
+			waf.TransformationErrorFlag = enabled
+
+			r := NewRule()
+			r.ID_ = 1
+			r.LogID_ = "1"
+			if err := r.AddVariable(variables.ArgsGet, "", false); err != nil {
+				t.Fatal(err)
+			}
+			r.SetOperator(&dummyEqOperator{}, "@eq", "0")
+			_ = r.AddTransformation("ErrorA", transformationErrorA)
+
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.AddGetRequestArgument("test", "0")
+			r.Evaluate(types.PhaseRequestHeaders, tx, tx.transformationCache)
+			if len(tx.matchedRules) != 1 {
+				t.Fatalf("expected the rule to match the untransformed value")
+			}
+
+			if have := len(tx.variables.tx.Get(transformationErrorKey)) > 0; have != enabled {
+				t.Errorf("unexpected TX:%s, want %t, have %t", transformationErrorKey, enabled, have)
+			}
+		})
+	}
+}
+
+func TestTransformationErrorFlagNotSetOnSuccess(t *testing.T) {
+	waf := NewWAF()
+	waf.TransformationErrorFlag = true
+
+	r := NewRule()
+	r.ID_ = 1
+	r.LogID_ = "1"
+	if err := r.AddVariable(variables.ArgsGet, "", false); err != nil {
+		t.Fatal(err)
+	}
+	r.SetOperator(&dummyEqOperator{}, "@eq", "0")
+	_ = r.AddTransformation("AppendA", transformationAppendA)
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.AddGetRequestArgument("test", "0")
+	r.Evaluate(types.PhaseRequestHeaders, tx, tx.transformationCache)
+
+	if len(tx.variables.tx.Get(transformationErrorKey)) > 0 {
+		t.Errorf("unexpected TX:%s for transformations without errors", transformationErrorKey)
+	}
+}
+
 func TestCaptureNotPropagatedToInnerChainRule(t *testing.T) {
 	r := NewRule()
 	r.ID_ = 1
@@ -616,3 +792,90 @@ func TestExpandMacroAfterWholeRuleEvaluation(t *testing.T) {
 		t.Errorf("Expected ArgsGet-data, got %s", matchdata[0].Data())
 	}
 }
+
+// transformationPercentDecode is a simplified urlDecode only decoding %25
+var transformationPercentDecode = func(input string) (string, bool, error) {
+	if !strings.Contains(input, "%25") {
+		return input, false, nil
+	}
+	return strings.ReplaceAll(input, "%25", "%"), true, nil
+}
+
+func TestExecuteTransformationsDoubleURLEncoded(t *testing.T) {
+	testCases := map[string]struct {
+		transformation string
+		input          string
+		doubleEncoded  bool
+	}{
+		"single encoded":            {"urlDecode", "%2541", false},
+		"double encoded":            {"urlDecode", "%252541", true},
+		"double encoded uni":        {"urlDecodeUni", "%252541", true},
+		"not a url decoding":        {"AppendA", "%252541", false},
+		"unchanged by the decoding": {"urlDecode", "abc", false},
+	}
+
+	for name, tCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rule := NewRule()
+			_ = rule.AddTransformation(tCase.transformation, transformationPercentDecode)
+			_, doubleEncoded, errs := rule.executeTransformations(tCase.input)
+			if errs != nil {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if want, have := tCase.doubleEncoded, doubleEncoded; want != have {
+				t.Errorf("unexpected double encoded flag, want %t, have %t", want, have)
+			}
+			_, doubleEncoded, _ = rule.executeTransformationsMultimatch(tCase.input)
+			if want, have := tCase.doubleEncoded, doubleEncoded; want != have {
+				t.Errorf("unexpected double encoded flag for multimatch, want %t, have %t", want, have)
+			}
+		})
+	}
+}
+
+func TestRemoveRuleTargetByIDIsTransactionScoped(t *testing.T) {
+	waf := NewWAF()
+	rule := NewRule()
+	rule.ID_ = 1
+	rule.Phase_ = types.PhaseRequestHeaders
+	if err := rule.AddVariable(variables.ArgsGet, "", false); err != nil {
+		t.Fatal(err)
+	}
+	// three negations leave spare capacity in the exceptions of the rule variable
+	for _, key := range []string{"a", "b", "c"} {
+		if err := rule.AddVariableNegation(variables.ArgsGet, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rule.SetOperator(&dummyEqOperator{}, "@eq", "0")
+	if err := waf.Rules.Add(rule); err != nil {
+		t.Fatal(err)
+	}
+	exceptions := waf.Rules.FindByID(1).variables[0].Exceptions
+	if cap(exceptions) == len(exceptions) {
+		t.Fatal("expected spare capacity in the rule exceptions")
+	}
+
+	tx := waf.NewTransaction()
+	// keys are matched lowercased, as for rule variable negations
+	tx.RemoveRuleTargetByID(1, variables.ArgsGet, "/^ex/")
+	tx.AddGetRequestArgument("Excluded", "0")
+	tx.ProcessRequestHeaders()
+	if len(tx.MatchedRules()) != 0 {
+		t.Error("expected the target to be removed by regular expression")
+	}
+
+	if want, have := 3, len(waf.Rules.FindByID(1).variables[0].Exceptions); want != have {
+		t.Errorf("unexpected rule exceptions, want %d, have %d", want, have)
+	}
+	if spare := exceptions[:cap(exceptions)][len(exceptions)]; spare != (ruleVariableException{}) {
+		t.Errorf("unexpected exception written to the rule exceptions: %v", spare)
+	}
+
+	tx = waf.NewTransaction()
+	tx.AddGetRequestArgument("Excluded", "0")
+	tx.ProcessRequestHeaders()
+	if len(tx.MatchedRules()) != 1 {
+		t.Error("expected the target removal not to apply to other transactions")
+	}
+}
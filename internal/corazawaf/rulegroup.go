@@ -5,6 +5,8 @@ package corazawaf
 
 import (
 	"fmt"
+	"regexp"
+	"sync/atomic"
 	"time"
 
 	"github.com/corazawaf/coraza/v3/internal/corazatypes"
@@ -15,10 +17,15 @@ import (
 
 // RuleGroup is a collection of rules
 // It contains all helpers required to manage the rules
+// Rules are kept in the order they are added, which is the
+// order they are evaluated in within each phase
 // It is not concurrent safe, so it's not recommended to use it
 // after compilation
 type RuleGroup struct {
 	rules []Rule
+	// tags indexes the position of the rules in rules by tag, so tag based
+	// operations don't need to scan the whole group
+	tags map[string][]int
 }
 
 // Add a rule to the collection
@@ -47,10 +54,52 @@ func (rg *RuleGroup) Add(rule *Rule) error {
 		}
 	}
 
+	if rule.hits == nil {
+		rule.hits = &atomic.Uint64{}
+	}
+
 	rg.rules = append(rg.rules, *rule)
+	rg.indexTags(len(rg.rules) - 1)
 	return nil
 }
 
+// indexTags adds the rule at position i to the tag index
+func (rg *RuleGroup) indexTags(i int) {
+	for j, tag := range rg.rules[i].Tags_ {
+		if utils.InSlice(tag, rg.rules[i].Tags_[:j]) {
+			// the rule is already indexed for this tag
+			continue
+		}
+		if rg.tags == nil {
+			rg.tags = map[string][]int{}
+		}
+		rg.tags[tag] = append(rg.tags[tag], i)
+	}
+}
+
+// RebuildTagIndex rebuilds the tag index of the group. It has to be called
+// after modifying the tags of rules already added to the group.
+func (rg *RuleGroup) RebuildTagIndex() {
+	rg.tags = nil
+	for i := range rg.rules {
+		rg.indexTags(i)
+	}
+}
+
+// RulesByTag returns the rules with the given tag, in the order they were added.
+// Matching is by case-sensitive string equality.
+func (rg *RuleGroup) RulesByTag(tag string) []*Rule {
+	positions := rg.tags[tag]
+	if len(positions) == 0 {
+		return nil
+	}
+	rules := make([]*Rule, 0, len(positions))
+	for _, i := range positions {
+		rules = append(rules, &rg.rules[i])
+	}
+	return rules
+}
+
 // GetRules returns the slice of rules,
 func (rg *RuleGroup) GetRules() []Rule {
 	return rg.rules
@@ -71,6 +120,7 @@ func (rg *RuleGroup) DeleteByID(id int) {
 	for i, r := range rg.rules {
 		if r.ID_ == id {
 			rg.rules = append(rg.rules[:i], rg.rules[i+1:]...)
+			rg.RebuildTagIndex()
 			return
 		}
 	}
@@ -85,28 +135,65 @@ func (rg *RuleGroup) DeleteByRange(start, end int) {
 		}
 	}
 	rg.rules = kept
+	rg.RebuildTagIndex()
 }
 
-// DeleteByMsg deletes rules with the given message.
-func (rg *RuleGroup) DeleteByMsg(msg string) {
+// DeleteByMsg deletes the rules whose message matches the regular expression.
+// The messages of the chained rules are also checked, the whole chain is removed.
+func (rg *RuleGroup) DeleteByMsg(re *regexp.Regexp) {
 	var kept []Rule
 	for _, r := range rg.rules {
-		if r.Msg.String() != msg {
+		if !msgMatches(&r, re) {
 			kept = append(kept, r)
 		}
 	}
 	rg.rules = kept
+	rg.RebuildTagIndex()
+}
+
+// msgMatches returns whether the message of the rule or of one of its chained
+// rules matches the regular expression
+func msgMatches(r *Rule, re *regexp.Regexp) bool {
+	for ; r != nil; r = r.Chain {
+		if r.Msg != nil && re.MatchString(r.Msg.String()) {
+			return true
+		}
+	}
+	return false
 }
 
 // DeleteByTag deletes rules with the given tag.
 func (rg *RuleGroup) DeleteByTag(tag string) {
-	var kept []Rule
-	for _, r := range rg.rules {
-		if !utils.InSlice(tag, r.Tags_) {
-			kept = append(kept, r)
+	positions := rg.tags[tag]
+	if len(positions) == 0 {
+		return
+	}
+	// positions are sorted, rules in between are shifted in place
+	kept := rg.rules[:positions[0]]
+	for j, i := range positions {
+		next := len(rg.rules)
+		if j+1 < len(positions) {
+			next = positions[j+1]
 		}
+		kept = append(kept, rg.rules[i+1:next]...)
 	}
 	rg.rules = kept
+	rg.RebuildTagIndex()
+}
+
+// Stats returns the number of matches of each rule of the group by id.
+// Rules that never matched are included with a count of 0.
+func (rg *RuleGroup) Stats() map[int]uint64 {
+	stats := make(map[int]uint64, len(rg.rules))
+	for i := range rg.rules {
+		r := &rg.rules[i]
+		if r.ID_ == noID {
+			// secmarkers are not rules
+			continue
+		}
+		stats[r.ID_] = r.hits.Load()
+	}
+	return stats
 }
 
 // Count returns the count of rules
@@ -114,6 +201,28 @@ func (rg *RuleGroup) Count() int {
 	return len(rg.rules)
 }
 
+// ValidateMarkers returns an error if a rule jumps with skipAfter to a SecMarker
+// that does not exist. Markers usually follow the rules jumping to them, so it can
+// only be checked once all the rules are loaded.
+func (rg *RuleGroup) ValidateMarkers() error {
+	markers := map[string]struct{}{}
+	for i := range rg.rules {
+		if m := rg.rules[i].SecMark_; m != "" {
+			markers[m] = struct{}{}
+		}
+	}
+	for i := range rg.rules {
+		r := &rg.rules[i]
+		if r.SkipAfter == "" {
+			continue
+		}
+		if _, ok := markers[r.SkipAfter]; !ok {
+			return fmt.Errorf("rule %d: skipAfter marker %q not found", r.ID_, r.SkipAfter)
+		}
+	}
+	return nil
+}
+
 // Eval rules for the specified phase, between 1 and 5
 // Rules are evaluated in syntactic order and the evaluation finishes
 // as soon as an interruption has been triggered.
@@ -125,11 +234,24 @@ func (rg *RuleGroup) Eval(phase types.RulePhase, tx *Transaction) bool {
 
 	tx.lastPhase = phase
 	usedRules := 0
+	if span := tx.startPhaseSpan(phase); span != nil {
+		defer func() { tx.endPhaseSpan(span, usedRules) }()
+	}
 	ts := time.Now().UnixNano()
+	tx.startEvaluationBudget(phase)
 	transformationCache := tx.transformationCache
 	for k := range transformationCache {
 		delete(transformationCache, k)
 	}
+	switch {
+	case phase == types.PhaseLogging:
+		// The logging phase is always evaluated, whatever allow action matched before.
+		tx.AllowType = corazatypes.AllowTypeUnset
+	case phase >= types.PhaseResponseHeaders && tx.AllowType == corazatypes.AllowTypeRequest:
+		// allow:request only covers the request phases, e.g. when the request body phase was not evaluated.
+		tx.AllowType = corazatypes.AllowTypeUnset
+	}
+	disabledRules := tx.WAF.disabledRules.load()
 RulesLoop:
 	for i := range rg.rules {
 		r := &rg.rules[i]
@@ -138,6 +260,17 @@ RulesLoop:
 		if tx.interruption != nil && phase != types.PhaseLogging {
 			break RulesLoop
 		}
+		if tx.evaluationAborted() {
+			break RulesLoop
+		}
+		// ctl:ruleEngine=Off takes effect right away, the remaining rules of the phase
+		// are not evaluated either
+		if tx.RuleEngine == types.RuleEngineOff {
+			tx.DebugLogger().Debug().
+				Int("phase", int(phase)).
+				Msg("Skipping phase because the rule engine was turned off")
+			break RulesLoop
+		}
 		// Rules with phase 0 will always run
 		if r.Phase_ != 0 && r.Phase_ != phase {
 			// Execute the rule in inferred phases too if multiphase evaluation is enabled
@@ -164,6 +297,12 @@ RulesLoop:
 				continue RulesLoop
 			}
 		}
+		if _, disabled := disabledRules[r.ID_]; disabled {
+			tx.DebugLogger().Debug().
+				Int("rule_id", r.ID_).
+				Msg("Skipping disabled rule")
+			continue
+		}
 
 		// we always evaluate secmarkers
 		if tx.SkipAfter != "" {
@@ -179,8 +318,13 @@ RulesLoop:
 			continue
 		}
 		if tx.Skip > 0 {
-			tx.Skip--
-			// Skipping rule
+			// SecMarkers are not rules, they don't count towards the rules to skip
+			if r.SecMark_ == "" {
+				tx.Skip--
+				tx.DebugLogger().Debug().
+					Int("rule_id", r.ID_).
+					Msg("Skipping rule because of skip")
+			}
 			continue
 		}
 		switch tx.AllowType {
@@ -195,21 +339,18 @@ RulesLoop:
 			break RulesLoop
 		case corazatypes.AllowTypeRequest:
 			// Allow request requires skipping all rules of any request phase.
-			// It is done by breaking the loop only if in a request phase (1 or 2)
-			// and resetting AllowType once the request phases are over (after request body phase)
+			// It is done by breaking the loop and resetting AllowType once the request phases
+			// are over (after the request body phase).
 			tx.DebugLogger().Debug().
 				Int("phase", int(phase)).
 				Msg("Skipping phase because of allow request action")
-			if phase == types.PhaseRequestHeaders {
-				// tx.AllowType is not resetted because another request phase might be called
-				break RulesLoop
-			}
-			if phase == types.PhaseRequestBody {
-				// // tx.AllowType is resetted, currently PhaseRequestBody is the last request phase
-				tx.AllowType = corazatypes.AllowTypeUnset
-				break RulesLoop
-			}
+			break RulesLoop
 		case corazatypes.AllowTypeAll:
+			// Allow requires skipping all rules of any phase but the logging one,
+			// AllowType is resetted right before evaluating it.
+			tx.DebugLogger().Debug().
+				Int("phase", int(phase)).
+				Msg("Skipping phase because of allow action")
 			break RulesLoop
 		}
 		// TODO these lines are SUPER SLOW
@@ -220,18 +361,34 @@ RulesLoop:
 		tx.Capture = false // we reset captures
 		usedRules++
 	}
+	if tx.evaluationErr != nil {
+		tx.abortEvaluation(phase)
+	}
 	tx.DebugLogger().Debug().
 		Int("phase", int(phase)).
 		Msg("Finished phase")
 
+	// Anomaly scores are checked once the rules of the phase have accumulated them,
+	// unless the transaction is already interrupted or allowed.
+	if (phase == types.PhaseRequestBody || phase == types.PhaseResponseBody) &&
+		tx.interruption == nil && tx.AllowType == corazatypes.AllowTypeUnset {
+		tx.checkAnomalyScoreThresholds()
+	}
+
 	// Reset AllowType if meant to allow only this specific phase. It is particuarly needed
 	// to reset it at this point, in case of an allow:phase action enforced by the last rule of the phase.
 	// In this case, allow:phase must not have any impact on the next phase.
 	if tx.AllowType == corazatypes.AllowTypePhase {
 		tx.AllowType = corazatypes.AllowTypeUnset
 	}
-	// Reset Skip counter at the end of each phase. Skip actions work only within the current processing phase
+	// Likewise, allow:request must not have any impact once the request phases are over.
+	if tx.AllowType == corazatypes.AllowTypeRequest && phase >= types.PhaseRequestBody {
+		tx.AllowType = corazatypes.AllowTypeUnset
+	}
+	// Reset Skip counter and SkipAfter marker at the end of each phase. Skip actions work only within
+	// the current processing phase, the rules of the next phase are evaluated even if the marker was not found.
 	tx.Skip = 0
+	tx.SkipAfter = ""
 
 	tx.stopWatches[phase] = time.Now().UnixNano() - ts
 	return tx.interruption != nil
@@ -260,6 +417,16 @@ type transformationKey struct {
 }
 
 type transformationValue struct {
-	arg  string
-	errs []error
+	arg           string
+	doubleEncoded bool
+	errs          []error
+	// steps holds the output of each transformation, it is only recorded for
+	// matched values when DebugLogTransformations is enabled
+	steps []transformationStep
+}
+
+// transformationStep is the value returned by a transformation of a rule chain
+type transformationStep struct {
+	name  string
+	value string
 }
@@ -4,9 +4,14 @@
 package corazawaf
 
 import (
+	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/macro"
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazatypes"
+	"github.com/corazawaf/coraza/v3/types"
 )
 
 func newTestRule(id int) *Rule {
@@ -49,8 +54,13 @@ func TestRuleGroupDeleteByMsg(t *testing.T) {
 		t.Error("Failed to add rule to rulegroup")
 	}
 
-	rg.DeleteByMsg("test")
-	if rg.Count() != 0 {
+	// rules without message are kept
+	if err := rg.Add(NewRule()); err != nil {
+		t.Error("Failed to add rule to rulegroup")
+	}
+
+	rg.DeleteByMsg(regexp.MustCompile("test"))
+	if rg.Count() != 1 {
 		t.Error("Failed to remove rule from rulegroup")
 	}
 }
@@ -85,3 +95,174 @@ func TestRuleGroupDeleteByID(t *testing.T) {
 		t.Fatal("Unexpected remaining rule in the rulegroup")
 	}
 }
+
+func TestRuleGroupRulesByTag(t *testing.T) {
+	rg := NewRuleGroup()
+	for i := 1; i <= 6; i++ {
+		r := newTestRule(i)
+		r.Tags_ = append(r.Tags_, fmt.Sprintf("group-%d", i%2), "test")
+		if err := rg.Add(r); err != nil {
+			t.Fatalf("Failed to add rule to rulegroup: %s", err.Error())
+		}
+	}
+
+	ids := func(tag string) []int {
+		var res []int
+		for _, r := range rg.RulesByTag(tag) {
+			res = append(res, r.ID_)
+		}
+		return res
+	}
+	assertIDs := func(tag string, want []int) {
+		t.Helper()
+		have := ids(tag)
+		if fmt.Sprint(have) != fmt.Sprint(want) {
+			t.Errorf("unexpected rules for tag %q, want %v, have %v", tag, want, have)
+		}
+	}
+
+	// rules with a duplicated tag are only indexed once
+	assertIDs("test", []int{1, 2, 3, 4, 5, 6})
+	assertIDs("group-0", []int{2, 4, 6})
+	assertIDs("group-1", []int{1, 3, 5})
+	assertIDs("unknown", nil)
+
+	rg.DeleteByID(2)
+	assertIDs("group-0", []int{4, 6})
+	assertIDs("group-1", []int{1, 3, 5})
+
+	rg.DeleteByTag("group-1")
+	assertIDs("test", []int{4, 6})
+	assertIDs("group-1", nil)
+	if rg.Count() != 2 || rg.GetRules()[0].ID_ != 4 || rg.GetRules()[1].ID_ != 6 {
+		t.Fatal("Unexpected remaining rules in the rulegroup")
+	}
+
+	rg.FindByID(6).Tags_ = append(rg.FindByID(6).Tags_, "late")
+	assertIDs("late", nil)
+	rg.RebuildTagIndex()
+	assertIDs("late", []int{6})
+
+	rg.DeleteByRange(5, 10)
+	assertIDs("late", nil)
+	assertIDs("test", []int{4})
+}
+
+func TestRuleGroupRulesByTagReturnsGroupRules(t *testing.T) {
+	rg := NewRuleGroup()
+	if err := rg.Add(newTestRule(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	rg.RulesByTag("test")[0].Log = true
+	if !rg.FindByID(1).Log {
+		t.Error("expected the rules returned by tag to be the rules of the group")
+	}
+}
+
+type dummyAllowAction struct {
+	allowType corazatypes.AllowType
+}
+
+func (*dummyAllowAction) Init(_ plugintypes.RuleMetadata, _ string) error {
+	return nil
+}
+
+func (a *dummyAllowAction) Evaluate(_ plugintypes.RuleMetadata, tx plugintypes.TransactionState) {
+	tx.(*Transaction).AllowType = a.allowType
+}
+
+func (*dummyAllowAction) Type() plugintypes.ActionType {
+	return plugintypes.ActionTypeDisruptive
+}
+
+func TestRuleGroupEvalAllowTypes(t *testing.T) {
+	const allowRuleID = 100
+	tests := []struct {
+		name       string
+		allowType  corazatypes.AllowType
+		allowPhase types.RulePhase
+		// expected are the IDs of the evaluated rules, one per phase with the phase as ID
+		expected []int
+	}{
+		{"allow", corazatypes.AllowTypeAll, types.PhaseRequestHeaders, []int{5}},
+		{"allow in response headers", corazatypes.AllowTypeAll, types.PhaseResponseHeaders, []int{1, 2, 5}},
+		{"allow in logging", corazatypes.AllowTypeAll, types.PhaseLogging, []int{1, 2, 3, 4}},
+		{"allow:phase", corazatypes.AllowTypePhase, types.PhaseRequestHeaders, []int{2, 3, 4, 5}},
+		{"allow:phase in request body", corazatypes.AllowTypePhase, types.PhaseRequestBody, []int{1, 3, 4, 5}},
+		{"allow:request", corazatypes.AllowTypeRequest, types.PhaseRequestHeaders, []int{3, 4, 5}},
+		{"allow:request in request body", corazatypes.AllowTypeRequest, types.PhaseRequestBody, []int{1, 3, 4, 5}},
+		{"allow:request in response headers", corazatypes.AllowTypeRequest, types.PhaseResponseHeaders, []int{1, 2, 4, 5}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			waf := NewWAF()
+			waf.RuleEngine = types.RuleEngineOn
+			for phase := types.PhaseRequestHeaders; phase <= types.PhaseLogging; phase++ {
+				if phase == tc.allowPhase {
+					r := newTestRule(allowRuleID)
+					r.Phase_ = phase
+					if err := r.AddAction("dummyAllow", &dummyAllowAction{allowType: tc.allowType}); err != nil {
+						t.Fatal(err)
+					}
+					if err := waf.Rules.Add(r); err != nil {
+						t.Fatal(err)
+					}
+				}
+				r := newTestRule(int(phase))
+				r.Phase_ = phase
+				if err := waf.Rules.Add(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tx := waf.NewTransaction()
+			for phase := types.PhaseRequestHeaders; phase <= types.PhaseLogging; phase++ {
+				waf.Rules.Eval(phase, tx)
+			}
+
+			var ids []int
+			for _, mr := range tx.MatchedRules() {
+				if id := mr.Rule().ID(); id != allowRuleID {
+					ids = append(ids, id)
+				}
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tc.expected) {
+				t.Errorf("unexpected evaluated rules, want %v, have %v", tc.expected, ids)
+			}
+		})
+	}
+}
+
+func newTaggedRuleGroup(b *testing.B, size int) RuleGroup {
+	b.Helper()
+	rg := NewRuleGroup()
+	for i := 1; i <= size; i++ {
+		r := newTestRule(i)
+		r.Tags_ = append(r.Tags_, fmt.Sprintf("paranoia-level/%d", i%4), fmt.Sprintf("attack-%d", i%50))
+		if err := rg.Add(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return rg
+}
+
+func BenchmarkRuleGroupRulesByTag(b *testing.B) {
+	rg := newTaggedRuleGroup(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if len(rg.RulesByTag("attack-7")) == 0 {
+			b.Fatal("expected rules for tag")
+		}
+	}
+}
+
+func BenchmarkRuleGroupDeleteByTag(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		rg := newTaggedRuleGroup(b, 10000)
+		b.StartTimer()
+		rg.DeleteByTag("attack-7")
+	}
+}
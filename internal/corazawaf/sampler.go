@@ -0,0 +1,58 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// sampler is the random source consulted by the sampling decisions of a WAF,
+// like the evaluation of the rules using the sample action. It is shared by
+// all the transactions of the WAF, it is lazily seeded with a random seed
+// unless one is set with SetSampleSeed.
+type sampler struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// sample returns true percentage% of the times it is called
+func (s *sampler) sample(percentage int) bool {
+	if percentage >= 100 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rnd == nil {
+		s.rnd = rand.New(rand.NewSource(randomSeed()))
+	}
+	return s.rnd.Intn(100) < percentage
+}
+
+func (s *sampler) seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rnd = rand.New(rand.NewSource(seed))
+}
+
+// randomSeed returns a seed read from the system random source, or the current
+// time if it is not available, so that WAFs created at the same time don't
+// sample the same transactions.
+func randomSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return time.Now().UnixNano()
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]))
+}
+
+// SetSampleSeed seeds the random source consulted by the sampling decisions,
+// making them reproducible, e.g. in tests. The sequence of decisions depends on
+// the order the transactions are evaluated in.
+func (w *WAF) SetSampleSeed(seed int64) {
+	w.sampler.seed(seed)
+}
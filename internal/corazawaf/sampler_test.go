@@ -0,0 +1,72 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+type countingOperator struct {
+	evaluations int
+}
+
+func (o *countingOperator) Evaluate(_ plugintypes.TransactionState, _ string) bool {
+	o.evaluations++
+	return false
+}
+
+func TestRuleSampling(t *testing.T) {
+	const runs = 10000
+	for _, percentage := range []int{0, 1, 10, 50, 100} {
+		waf := NewWAF()
+		waf.SetSampleSeed(1)
+
+		op := &countingOperator{}
+		r := NewRule()
+		r.ID_ = 1
+		r.SamplePercentage = percentage
+		r.SetOperator(op, "@counting", "")
+		if err := r.AddVariable(variables.RequestURI, "", false); err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < runs; i++ {
+			tx := waf.NewTransaction()
+			r.Evaluate(types.PhaseRequestHeaders, tx, tx.transformationCache)
+			tx.Close()
+		}
+
+		want := runs * percentage / 100
+		if percentage == 0 {
+			want = runs
+		}
+		// the sequence is deterministic for the seed, allow a 10% deviation from the expected fraction
+		if diff := op.evaluations - want; diff > want/10 || -diff > want/10 {
+			t.Errorf("unexpected number of evaluations for sample:%d, want ~%d, have %d", percentage, want, op.evaluations)
+		}
+	}
+}
+
+func TestRuleSamplingSeed(t *testing.T) {
+	sequence := func(seed int64) []bool {
+		waf := NewWAF()
+		waf.SetSampleSeed(seed)
+		res := make([]bool, 100)
+		for i := range res {
+			res[i] = waf.sampler.sample(50)
+		}
+		return res
+	}
+
+	a, b := sequence(42), sequence(42)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("expected the same sequence for the same seed, differs at %d", i)
+		}
+	}
+}
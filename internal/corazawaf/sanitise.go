@@ -0,0 +1,286 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/collection"
+	"github.com/corazawaf/coraza/v3/internal/collections"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+// sanitisedValue replaces the values marked for redaction in the audit log
+const sanitisedValue = "****"
+
+// sanitisedBytes is a range of bytes of a value to be masked in the audit log
+type sanitisedBytes struct {
+	value string
+	start int
+	end   int
+}
+
+// sanitisedTarget keeps track of the values of a collection (e.g. ARGS)
+// to be redacted in the audit log, either fully or partially. Keys are
+// stored lowercased.
+type sanitisedTarget struct {
+	full  map[string]struct{}
+	bytes map[string][]sanitisedBytes
+}
+
+func (s *sanitisedTarget) isEmpty() bool {
+	return len(s.full) == 0 && len(s.bytes) == 0
+}
+
+func (s *sanitisedTarget) addFull(key string) {
+	if s.full == nil {
+		s.full = map[string]struct{}{}
+	}
+	s.full[strings.ToLower(key)] = struct{}{}
+}
+
+func (s *sanitisedTarget) addBytes(key string, b sanitisedBytes) {
+	if s.bytes == nil {
+		s.bytes = map[string][]sanitisedBytes{}
+	}
+	key = strings.ToLower(key)
+	s.bytes[key] = append(s.bytes[key], b)
+}
+
+// redact returns the value to be logged for the given key and value
+func (s *sanitisedTarget) redact(key string, value string) string {
+	if s.isEmpty() {
+		return value
+	}
+	key = strings.ToLower(key)
+	if _, ok := s.full[key]; ok {
+		return sanitisedValue
+	}
+	var masked []byte
+	for _, b := range s.bytes[key] {
+		if b.value != value {
+			continue
+		}
+		if masked == nil {
+			masked = []byte(value)
+		}
+		for i := b.start; i < b.end; i++ {
+			masked[i] = '*'
+		}
+	}
+	if masked == nil {
+		return value
+	}
+	return string(masked)
+}
+
+// redactArg is like redact for the arguments, the ones parsed from a JSON body
+// are also redacted by their name without the json prefix, e.g. json.password
+// by sanitiseArg:password.
+func (s *sanitisedTarget) redactArg(key string, value string) string {
+	redacted := s.redact(key, value)
+	if name, ok := strings.CutPrefix(strings.ToLower(key), "json."); ok && redacted == value {
+		return s.redact(name, value)
+	}
+	return redacted
+}
+
+// sanitiseTarget returns the sanitised target for the given variable, or nil
+// if the variable can't be sanitised. Only arguments and headers are supported.
+func (tx *Transaction) sanitiseTarget(v variables.RuleVariable) *sanitisedTarget {
+	switch v {
+	case variables.Args, variables.ArgsGet, variables.ArgsPost, variables.ArgsPath:
+		return &tx.sanitisedArgs
+	case variables.RequestHeaders:
+		return &tx.sanitisedRequestHeaders
+	case variables.ResponseHeaders:
+		return &tx.sanitisedResponseHeaders
+	}
+	return nil
+}
+
+// SanitiseArg marks the argument to be redacted in the audit log
+func (tx *Transaction) SanitiseArg(name string) {
+	tx.sanitisedArgs.addFull(name)
+}
+
+// SanitiseRequestHeader marks the request header to be redacted in the audit log
+func (tx *Transaction) SanitiseRequestHeader(name string) {
+	tx.sanitisedRequestHeaders.addFull(name)
+}
+
+// SanitiseResponseHeader marks the response header to be redacted in the audit log
+func (tx *Transaction) SanitiseResponseHeader(name string) {
+	tx.sanitisedResponseHeaders.addFull(name)
+}
+
+// SanitiseVariable marks the value of the given variable and key to be redacted
+// in the audit log. Only arguments and headers are supported, it returns false
+// otherwise.
+func (tx *Transaction) SanitiseVariable(v variables.RuleVariable, key string) bool {
+	target := tx.sanitiseTarget(v)
+	if target == nil {
+		return false
+	}
+	target.addFull(key)
+	return true
+}
+
+// CaptureMatchOffset records the offsets of the capture TX:0 in the value
+// evaluated by a capturing operator like @rx, so SanitiseVariableBytes masks
+// exactly the matched bytes instead of the first occurrence of the capture.
+func (tx *Transaction) CaptureMatchOffset(value string, start int, end int) {
+	if tx.Capture {
+		tx.matchOffset = sanitisedBytes{value: value, start: start, end: end}
+	}
+}
+
+// SanitiseVariableBytes marks the bytes of the given variable and key matching
+// matched to be masked in the audit log, keeping the first keepStart and the last
+// keepEnd matched bytes. For the value matched by a capturing operator the offsets
+// recorded by CaptureMatchOffset are used, for any other value the offsets of the
+// first occurrence of matched. If a value doesn't contain matched (e.g. because it
+// was transformed) the whole value is masked. Only arguments and headers are
+// supported, it returns false otherwise.
+func (tx *Transaction) SanitiseVariableBytes(v variables.RuleVariable, key string, matched string, keepStart int, keepEnd int) bool {
+	target := tx.sanitiseTarget(v)
+	if target == nil {
+		return false
+	}
+	col, ok := tx.Collection(v).(collection.Keyed)
+	if !ok {
+		return false
+	}
+	for _, value := range col.Get(key) {
+		start, end := 0, len(value)
+		if m := tx.matchOffset; matched != "" && m.value == value && value[m.start:m.end] == matched {
+			start, end = m.start, m.end
+		} else if matched != "" {
+			if idx := strings.Index(value, matched); idx >= 0 {
+				start, end = idx, idx+len(matched)
+			}
+		}
+		start += keepStart
+		end -= keepEnd
+		if start >= end {
+			continue
+		}
+		target.addBytes(key, sanitisedBytes{value: value, start: start, end: end})
+	}
+	return true
+}
+
+// sanitiseHeaders redacts in place the values of the headers present in the sanitised target
+func sanitiseHeaders(headers map[string][]string, target *sanitisedTarget) map[string][]string {
+	if target.isEmpty() {
+		return headers
+	}
+	for k, vs := range headers {
+		for i, v := range vs {
+			vs[i] = target.redact(k, v)
+		}
+	}
+	return headers
+}
+
+// sanitiseURLEncoded redacts the values of the sanitised arguments in an
+// urlencoded payload, e.g. a query string or an urlencoded body, keeping
+// the rest of the payload untouched.
+func sanitiseURLEncoded(payload string, target *sanitisedTarget) string {
+	if target.isEmpty() || payload == "" {
+		return payload
+	}
+	var res strings.Builder
+	for i, pair := range strings.Split(payload, "&") {
+		if i > 0 {
+			res.WriteByte('&')
+		}
+		key, rawValue, hasValue := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil || !hasValue {
+			res.WriteString(pair)
+			continue
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			value = rawValue
+		}
+		redacted := target.redact(name, value)
+		if redacted == value {
+			res.WriteString(pair)
+			continue
+		}
+		res.WriteString(key)
+		res.WriteByte('=')
+		if redacted == sanitisedValue {
+			res.WriteString(sanitisedValue)
+		} else {
+			res.WriteString(strings.ReplaceAll(url.QueryEscape(redacted), "%2A", "*"))
+		}
+	}
+	return res.String()
+}
+
+// sanitiseArgsPost returns the arguments of the request body URL encoded with
+// the sanitised values redacted. It replaces the bodies which can't be redacted
+// in place, e.g. JSON or multipart ones, ok is false if no value is redacted
+// so the body can be logged as is.
+func sanitiseArgsPost(args collection.Keyed, target *sanitisedTarget) (body string, ok bool) {
+	if target.isEmpty() {
+		return "", false
+	}
+	matches := args.FindAll()
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Key() < matches[j].Key() })
+	values := make([]string, 0, len(matches))
+	for _, md := range matches {
+		redacted := target.redactArg(md.Key(), md.Value())
+		if redacted != md.Value() {
+			ok = true
+		}
+		values = append(values, url.QueryEscape(md.Key())+"="+strings.ReplaceAll(url.QueryEscape(redacted), "%2A", "*"))
+	}
+	return strings.Join(values, "&"), ok
+}
+
+// sanitiseURI redacts the values of the sanitised arguments in the query string of the URI
+func sanitiseURI(uri string, target *sanitisedTarget) string {
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok || target.isEmpty() {
+		return uri
+	}
+	return path + "?" + sanitiseURLEncoded(query, target)
+}
+
+// auditLogArgs returns ARGS to be logged in the audit log. If any argument has
+// been marked for redaction, a copy with the redacted values is returned.
+func (tx *Transaction) auditLogArgs() *collections.ConcatKeyed {
+	if tx.sanitisedArgs.isEmpty() {
+		return tx.variables.args
+	}
+
+	sources := []struct {
+		variable variables.RuleVariable
+		data     collection.Keyed
+	}{
+		{variables.ArgsGet, tx.variables.argsGet},
+		{variables.ArgsPost, tx.variables.argsPost},
+		{variables.ArgsPath, tx.variables.argsPath},
+	}
+	copies := make([]collection.Keyed, 0, len(sources))
+	for _, src := range sources {
+		var m *collections.Map
+		if shouldUseCaseSensitiveNamedCollection {
+			m = collections.NewCaseSensitiveKeyMap(src.variable)
+		} else {
+			m = collections.NewMap(src.variable)
+		}
+		for _, md := range src.data.FindAll() {
+			m.Add(md.Key(), tx.sanitisedArgs.redactArg(md.Key(), md.Value()))
+		}
+		copies = append(copies, m)
+	}
+	return collections.NewConcatKeyed(variables.Args, copies...)
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+func TestSanitiseURLEncoded(t *testing.T) {
+	sanitised := &sanitisedTarget{}
+	sanitised.addFull("password")
+	sanitised.addFull("new pass")
+	tests := map[string]struct {
+		payload  string
+		expected string
+	}{
+		"empty":            {"", ""},
+		"no match":         {"a=1&b=2", "a=1&b=2"},
+		"match":            {"a=1&password=secret&b=2", "a=1&password=****&b=2"},
+		"case insensitive": {"Password=secret", "Password=****"},
+		"escaped key":      {"new+pass=secret&new%20pass=secret", "new+pass=****&new%20pass=****"},
+		"repeated":         {"password=a&password=b", "password=****&password=****"},
+		"no value":         {"password&a=1", "password&a=1"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if want, have := tt.expected, sanitiseURLEncoded(tt.payload, sanitised); want != have {
+				t.Errorf("unexpected payload, want %q, have %q", want, have)
+			}
+		})
+	}
+}
+
+func TestSanitiseURI(t *testing.T) {
+	sanitised := &sanitisedTarget{}
+	sanitised.addFull("password")
+	if want, have := "/login?password=****", sanitiseURI("/login?password=secret", sanitised); want != have {
+		t.Errorf("unexpected uri, want %q, have %q", want, have)
+	}
+	if want, have := "/login", sanitiseURI("/login", sanitised); want != have {
+		t.Errorf("unexpected uri, want %q, have %q", want, have)
+	}
+}
+
+func TestSanitiseHeaders(t *testing.T) {
+	headers := map[string][]string{
+		"authorization": {"Basic abc"},
+		"user-agent":    {"test"},
+	}
+	sanitised := &sanitisedTarget{}
+	sanitised.addFull("Authorization")
+	headers = sanitiseHeaders(headers, sanitised)
+	if want, have := "****", headers["authorization"][0]; want != have {
+		t.Errorf("unexpected authorization header, want %q, have %q", want, have)
+	}
+	if want, have := "test", headers["user-agent"][0]; want != have {
+		t.Errorf("unexpected user-agent header, want %q, have %q", want, have)
+	}
+}
+
+func TestSanitiseVariableBytes(t *testing.T) {
+	tests := map[string]struct {
+		value     string
+		matched   string
+		keepStart int
+		keepEnd   int
+		expected  string
+	}{
+		"matched bytes": {
+			value:    "card 4111111111111111 exp 12/30",
+			matched:  "4111111111111111",
+			expected: "card **************** exp 12/30",
+		},
+		"keep bytes": {
+			value:     "card 4111111111111111 exp 12/30",
+			matched:   "4111111111111111",
+			keepStart: 1,
+			keepEnd:   4,
+			expected:  "card 4***********1111 exp 12/30",
+		},
+		"not found masks the whole value": {
+			value:    "secret",
+			matched:  "SECRET",
+			expected: "******",
+		},
+		"nothing left to mask": {
+			value:     "abc",
+			matched:   "b",
+			keepStart: 1,
+			expected:  "abc",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := NewWAF().NewTransaction()
+			tx.AddGetRequestArgument("cc", tt.value)
+			if !tx.SanitiseVariableBytes(variables.ArgsGet, "cc", tt.matched, tt.keepStart, tt.keepEnd) {
+				t.Fatal("expected variable to be sanitised")
+			}
+			if want, have := tt.expected, tx.sanitisedArgs.redact("cc", tt.value); want != have {
+				t.Errorf("unexpected value, want %q, have %q", want, have)
+			}
+			// other values for the same key are not masked
+			if want, have := "other", tx.sanitisedArgs.redact("cc", "other"); want != have {
+				t.Errorf("unexpected value, want %q, have %q", want, have)
+			}
+		})
+	}
+
+	t.Run("recorded match offset", func(t *testing.T) {
+		value := "pin 1234 card 4000 1234"
+		tx := NewWAF().NewTransaction()
+		tx.Capture = true
+		tx.AddGetRequestArgument("cc", value)
+		tx.CaptureMatchOffset(value, 19, 23)
+		if !tx.SanitiseVariableBytes(variables.ArgsGet, "cc", "1234", 0, 0) {
+			t.Fatal("expected variable to be sanitised")
+		}
+		if want, have := "pin 1234 card 4000 ****", tx.sanitisedArgs.redact("cc", value); want != have {
+			t.Errorf("unexpected value, want %q, have %q", want, have)
+		}
+	})
+
+	t.Run("unsupported variable", func(t *testing.T) {
+		tx := NewWAF().NewTransaction()
+		if tx.SanitiseVariableBytes(variables.RequestURI, "", "abc", 0, 0) {
+			t.Error("unexpected sanitised variable")
+		}
+	})
+}
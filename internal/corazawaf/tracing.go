@@ -0,0 +1,79 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"context"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+// tracedOperatorMinSize is the minimum size of a value for its operator
+// evaluation to be traced in its own span, smaller values are not worth it.
+const tracedOperatorMinSize = 16 * 1024
+
+var phaseSpanNames = [...]string{
+	types.PhaseRequestHeaders:  "coraza.phase.request_headers",
+	types.PhaseRequestBody:     "coraza.phase.request_body",
+	types.PhaseResponseHeaders: "coraza.phase.response_headers",
+	types.PhaseResponseBody:    "coraza.phase.response_body",
+	types.PhaseLogging:         "coraza.phase.logging",
+}
+
+// SetTracer sets the tracer used to trace the evaluation of the phases of the
+// transactions, the spans are children of the span of the context of the
+// transaction. Tracing is disabled by default, a nil tracer disables it. It
+// must be set before creating transactions.
+func (w *WAF) SetTracer(tracer plugintypes.Tracer) {
+	w.tracer = tracer
+}
+
+// startPhaseSpan starts the span of the evaluation of a phase, the operators
+// evaluated on large values during the phase are traced as children of it.
+// It returns nil if tracing is disabled.
+func (tx *Transaction) startPhaseSpan(phase types.RulePhase) plugintypes.Span {
+	if tx.WAF.tracer == nil || int(phase) >= len(phaseSpanNames) {
+		return nil
+	}
+	ctx := tx.context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := tx.WAF.tracer.Start(ctx, phaseSpanNames[phase])
+	span.SetStringAttribute("coraza.transaction.id", tx.id)
+	span.SetIntAttribute("coraza.phase", int(phase))
+	tx.phaseSpanCtx = ctx
+	return span
+}
+
+// endPhaseSpan ends the span of the evaluation of a phase.
+func (tx *Transaction) endPhaseSpan(span plugintypes.Span, rulesEvaluated int) {
+	span.SetIntAttribute("coraza.rules.evaluated", rulesEvaluated)
+	span.SetBoolAttribute("coraza.interrupted", tx.interruption != nil)
+	if tx.interruption != nil {
+		span.SetIntAttribute("coraza.interruption.rule_id", tx.interruption.RuleID)
+		span.SetStringAttribute("coraza.interruption.action", tx.interruption.Action)
+		span.SetIntAttribute("coraza.interruption.status", tx.interruption.Status)
+	}
+	span.End()
+	tx.phaseSpanCtx = nil
+}
+
+// startOperatorSpan starts the span of the evaluation of an operator if the
+// phase is traced and the value is large enough, it returns nil otherwise.
+func (tx *Transaction) startOperatorSpan(r *Rule, value string) plugintypes.Span {
+	if tx.phaseSpanCtx == nil || len(value) < tracedOperatorMinSize {
+		return nil
+	}
+	id := r.ID_
+	if id == noID {
+		id = r.ParentID_
+	}
+	_, span := tx.WAF.tracer.Start(tx.phaseSpanCtx, "coraza.operator")
+	span.SetIntAttribute("coraza.rule.id", id)
+	span.SetStringAttribute("coraza.operator", r.operator.Function)
+	span.SetIntAttribute("coraza.value.size", len(value))
+	return span
+}
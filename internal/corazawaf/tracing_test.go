@@ -0,0 +1,173 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package corazawaf
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+// recordingTracer keeps the spans in the order they are started
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]interface{}
+	ended  bool
+}
+
+type recordedSpanKey struct{}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, plugintypes.Span) {
+	parent, _ := ctx.Value(recordedSpanKey{}).(*recordedSpan)
+	span := &recordedSpan{name: name, parent: parent, attrs: map[string]interface{}{}}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, recordedSpanKey{}, span), span
+}
+
+func (s *recordedSpan) SetStringAttribute(key, value string)    { s.attrs[key] = value }
+func (s *recordedSpan) SetIntAttribute(key string, value int)   { s.attrs[key] = value }
+func (s *recordedSpan) SetBoolAttribute(key string, value bool) { s.attrs[key] = value }
+func (s *recordedSpan) End()                                    { s.ended = true }
+
+func newTracedWAF(t *testing.T) (*WAF, *recordingTracer) {
+	t.Helper()
+	tracer := &recordingTracer{}
+	waf := NewWAF()
+	waf.SetTracer(tracer)
+	for _, id := range []int{1, 2} {
+		rule := NewRule()
+		rule.ID_ = id
+		rule.Phase_ = types.PhaseRequestHeaders
+		if err := rule.AddVariable(variables.ArgsGet, "q", false); err != nil {
+			t.Fatal(err)
+		}
+		rule.SetOperator(&dummyEqOperator{}, "@eq", "0")
+		if id == 2 {
+			rule.DisruptiveStatus = 403
+			if err := rule.AddAction("deny", &dummyDenyAction{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := waf.Rules.Add(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return waf, tracer
+}
+
+func TestTracingPhaseSpans(t *testing.T) {
+	waf, tracer := newTracedWAF(t)
+
+	ctx, parent := tracer.Start(context.Background(), "request")
+	tx := waf.NewTransactionWithOptions(Options{ID: "abc", Context: ctx})
+	tx.RuleEngine = types.RuleEngineOn
+	tx.AddGetRequestArgument("q", "0")
+	tx.ProcessRequestHeaders()
+	tx.ProcessLogging()
+	if err := tx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := tracer.spans[1:]
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, have %d", len(spans))
+	}
+	if want, have := "coraza.phase.request_headers", spans[0].name; want != have {
+		t.Errorf("unexpected span name, want %q, have %q", want, have)
+	}
+	if want, have := "coraza.phase.logging", spans[1].name; want != have {
+		t.Errorf("unexpected span name, want %q, have %q", want, have)
+	}
+	for _, span := range spans {
+		if span.parent != parent {
+			t.Errorf("unexpected parent span of %s", span.name)
+		}
+		if !span.ended {
+			t.Errorf("expected span %s to be ended", span.name)
+		}
+	}
+
+	attrs := spans[0].attrs
+	if want, have := "abc", attrs["coraza.transaction.id"]; want != have {
+		t.Errorf("unexpected transaction id, want %q, have %v", want, have)
+	}
+	if want, have := 2, attrs["coraza.rules.evaluated"]; want != have {
+		t.Errorf("unexpected rules evaluated, want %d, have %v", want, have)
+	}
+	if attrs["coraza.interrupted"] != true {
+		t.Error("expected the phase to be interrupted")
+	}
+	if want, have := 2, attrs["coraza.interruption.rule_id"]; want != have {
+		t.Errorf("unexpected interruption rule, want %d, have %v", want, have)
+	}
+	if want, have := 403, attrs["coraza.interruption.status"]; want != have {
+		t.Errorf("unexpected interruption status, want %d, have %v", want, have)
+	}
+}
+
+func TestTracingOperatorSpans(t *testing.T) {
+	waf, tracer := newTracedWAF(t)
+
+	tx := waf.NewTransaction()
+	tx.AddGetRequestArgument("q", "small")
+	tx.AddGetRequestArgument("q", strings.Repeat("a", tracedOperatorMinSize))
+	tx.ProcessRequestHeaders()
+	if err := tx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var phase, operators []*recordedSpan
+	for _, span := range tracer.spans {
+		if span.name == "coraza.operator" {
+			operators = append(operators, span)
+		} else {
+			phase = append(phase, span)
+		}
+	}
+	if len(phase) != 1 {
+		t.Fatalf("expected 1 phase span, have %d", len(phase))
+	}
+	// only the large value is traced, once per rule
+	if len(operators) != 2 {
+		t.Fatalf("expected 2 operator spans, have %d", len(operators))
+	}
+	for i, span := range operators {
+		if span.parent != phase[0] {
+			t.Errorf("unexpected parent span of operator span %d", i)
+		}
+		if want, have := i+1, span.attrs["coraza.rule.id"]; want != have {
+			t.Errorf("unexpected rule id, want %d, have %v", want, have)
+		}
+		if want, have := tracedOperatorMinSize, span.attrs["coraza.value.size"]; want != have {
+			t.Errorf("unexpected value size, want %d, have %v", want, have)
+		}
+	}
+	if phase[0].attrs["coraza.interrupted"] != false {
+		t.Error("unexpected interruption")
+	}
+}
+
+func TestTracingDisabled(t *testing.T) {
+	waf := NewWAF()
+	tx := waf.NewTransaction()
+	if span := tx.startPhaseSpan(types.PhaseRequestHeaders); span != nil {
+		t.Error("unexpected span without tracer")
+	}
+	if tx.phaseSpanCtx != nil {
+		t.Error("unexpected span context without tracer")
+	}
+}
@@ -9,11 +9,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"math"
 	"mime"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +31,7 @@ import (
 	"github.com/corazawaf/coraza/v3/internal/corazarules"
 	"github.com/corazawaf/coraza/v3/internal/corazatypes"
 	"github.com/corazawaf/coraza/v3/internal/environment"
+	"github.com/corazawaf/coraza/v3/internal/memoize"
 	stringsutil "github.com/corazawaf/coraza/v3/internal/strings"
 	urlutil "github.com/corazawaf/coraza/v3/internal/url"
 	"github.com/corazawaf/coraza/v3/types"
@@ -48,6 +52,21 @@ type Transaction struct {
 	// The context associated to the transaction.
 	context context.Context
 
+	// phaseSpanCtx is the context of the span of the phase being evaluated,
+	// nil if tracing is disabled
+	phaseSpanCtx context.Context
+
+	// phaseDeadline is when the evaluation of the current phase exceeds the
+	// RuleEvaluationTimeout of the WAF, zero if there is no timeout
+	phaseDeadline time.Time
+
+	// evaluationErr is the reason why the evaluation of the current phase was
+	// aborted, nil if it was not
+	evaluationErr error
+
+	// abortedEvaluations are the phases whose evaluation was aborted
+	abortedEvaluations []abortedEvaluation
+
 	// Contains the list of matched rules and associated match information
 	matchedRules []types.MatchedRule
 
@@ -91,6 +110,10 @@ type Transaction struct {
 	// Rules with this id are going to be skipped while processing a phase
 	ruleRemoveByID []int
 
+	// persistentRecords are the records of the persistent collections
+	// initialized with initcol, by collection
+	persistentRecords map[variables.RuleVariable]string
+
 	// ruleRemoveTargetByID is used by ctl to remove rule targets by id during the
 	// transaction. All other "target removers" like "ByTag" are an abstraction of "ById"
 	// For example, if you want to remove REQUEST_HEADERS:User-Agent from rule 85:
@@ -100,6 +123,19 @@ type Transaction struct {
 	// Will skip this number of rules, this value will be decreased on each skip
 	Skip int
 
+	// Arguments and headers whose values are redacted in the audit log,
+	// they are set by the sanitise* actions
+	sanitisedArgs            sanitisedTarget
+	sanitisedRequestHeaders  sanitisedTarget
+	sanitisedResponseHeaders sanitisedTarget
+	// matchOffset is where the capture TX:0 of the last capturing match was
+	// found in the evaluated value, used by sanitiseMatchedBytes
+	matchOffset sanitisedBytes
+
+	// Pause is the delay requested by the pause action. Coraza does not sleep,
+	// it is up to the integrator to apply it.
+	Pause time.Duration
+
 	// Actions with capture features will read the capture state from this field
 	// We have currently removed this feature as Capture will always run
 	// We must reuse it in the future
@@ -123,6 +159,9 @@ type Transaction struct {
 	variables TransactionVariables
 
 	transformationCache map[transformationKey]*transformationValue
+
+	// detectionCache holds the results of @detectSQLi and @detectXSS by value
+	detectionCache map[detectionKey]detectionValue
 }
 
 func (tx *Transaction) ID() string {
@@ -147,6 +186,12 @@ func (tx *Transaction) Collection(idx variables.RuleVariable) collection.Collect
 		return tx.variables.fullRequestLength
 	case variables.InboundDataError:
 		return tx.variables.inboundDataError
+	case variables.ArgsLimitExceeded:
+		return tx.variables.argsLimitExceeded
+	case variables.RequestHeadersLimitExceeded:
+		return tx.variables.requestHeadersLimitExceeded
+	case variables.RequestCookiesLimitExceeded:
+		return tx.variables.requestCookiesLimitExceeded
 	case variables.MatchedVar:
 		return tx.variables.matchedVar
 	case variables.MatchedVarName:
@@ -177,6 +222,8 @@ func (tx *Transaction) Collection(idx variables.RuleVariable) collection.Collect
 		return tx.variables.requestBasename
 	case variables.RequestBody:
 		return tx.variables.requestBody
+	case variables.RequestBodyRaw:
+		return tx.variables.requestBodyRaw
 	case variables.RequestBodyLength:
 		return tx.variables.requestBodyLength
 	case variables.RequestFilename:
@@ -193,6 +240,8 @@ func (tx *Transaction) Collection(idx variables.RuleVariable) collection.Collect
 		return tx.variables.requestURIRaw
 	case variables.ResponseBody:
 		return tx.variables.responseBody
+	case variables.ResponseBodyRaw:
+		return tx.variables.responseBodyRaw
 	case variables.ResponseContentLength:
 		return tx.variables.responseContentLength
 	case variables.ResponseProtocol:
@@ -205,6 +254,8 @@ func (tx *Transaction) Collection(idx variables.RuleVariable) collection.Collect
 		return tx.variables.serverName
 	case variables.ServerPort:
 		return tx.variables.serverPort
+	case variables.WebserverErrorLog:
+		return tx.variables.webserverErrorLog
 	case variables.HighestSeverity:
 		return tx.variables.highestSeverity
 	case variables.StatusLine:
@@ -245,8 +296,26 @@ func (tx *Transaction) Collection(idx variables.RuleVariable) collection.Collect
 		return tx.variables.requestHeaders
 	case variables.ResponseHeaders:
 		return tx.variables.responseHeaders
+	case variables.RequestTrailers:
+		return tx.variables.requestTrailers
+	case variables.ResponseTrailers:
+		return tx.variables.responseTrailers
 	case variables.Geo:
 		return tx.variables.geo
+	case variables.IP:
+		return tx.variables.ip
+	case variables.Global:
+		return tx.variables.global
+	case variables.Session:
+		return tx.variables.session
+	case variables.User:
+		return tx.variables.user
+	case variables.Resource:
+		return tx.variables.resource
+	case variables.AuthType:
+		return tx.variables.authType
+	case variables.RemoteUser:
+		return tx.variables.remoteUser
 	case variables.RequestCookiesNames:
 		return tx.variables.requestCookiesNames
 	case variables.FilesTmpNames:
@@ -259,6 +328,14 @@ func (tx *Transaction) Collection(idx variables.RuleVariable) collection.Collect
 		return tx.variables.argsPostNames
 	case variables.ResBodyProcessor:
 		return tx.variables.resBodyProcessor
+	case variables.ResBodyError:
+		return tx.variables.resBodyError
+	case variables.ResBodyErrorMsg:
+		return tx.variables.resBodyErrorMsg
+	case variables.ResBodyProcessorError:
+		return tx.variables.resBodyProcessorError
+	case variables.ResBodyProcessorErrorMsg:
+		return tx.variables.resBodyProcessorErrorMsg
 	case variables.TX:
 		return tx.variables.tx
 	case variables.Rule:
@@ -280,6 +357,28 @@ func (tx *Transaction) Collection(idx variables.RuleVariable) collection.Collect
 		return tx.variables.xml
 	case variables.MultipartPartHeaders:
 		return tx.variables.multipartPartHeaders
+	case variables.MultipartBoundaryQuoted:
+		return tx.variables.multipartBoundaryQuoted
+	case variables.MultipartBoundaryWhitespace:
+		return tx.variables.multipartBoundaryWhitespace
+	case variables.MultipartCrlfLfLines:
+		return tx.variables.multipartCrlfLfLines
+	case variables.MultipartDataBefore:
+		return tx.variables.multipartDataBefore
+	case variables.MultipartHeaderFolding:
+		return tx.variables.multipartHeaderFolding
+	case variables.MultipartInvalidHeaderFolding:
+		return tx.variables.multipartInvalidHeaderFolding
+	case variables.MultipartInvalidPart:
+		return tx.variables.multipartInvalidPart
+	case variables.MultipartInvalidQuoting:
+		return tx.variables.multipartInvalidQuoting
+	case variables.MultipartLfLine:
+		return tx.variables.multipartLfLine
+	case variables.MultipartMissingSemicolon:
+		return tx.variables.multipartMissingSemicolon
+	case variables.MultipartUnmatchedBoundary:
+		return tx.variables.multipartUnmatchedBoundary
 	case variables.MultipartStrictError:
 		return tx.variables.multipartStrictError
 	case variables.Time:
@@ -306,11 +405,46 @@ func (tx *Transaction) Collection(idx variables.RuleVariable) collection.Collect
 }
 
 func (tx *Transaction) Interrupt(interruption *types.Interruption) {
+	if tx.lastPhase == types.PhaseLogging {
+		// The response has already been delivered, the logging phase can't be interrupted
+		tx.debugLogger.Warn().
+			Str("action", interruption.Action).
+			Int("rule_id", interruption.RuleID).
+			Msg("Ignoring interruption in the logging phase")
+		return
+	}
 	if tx.RuleEngine == types.RuleEngineOn {
+		if interruption.Pause == 0 {
+			interruption.Pause = tx.Pause
+		}
+		if interruption.Action == "deny" && interruption.Body == "" {
+			interruption.ContentType, interruption.Body = tx.defaultBlockPage()
+		}
 		tx.interruption = interruption
 	}
 }
 
+// defaultBlockPage returns the content type and the body of the block page
+// configured with SecDefaultBlockPage. The JSON page is used if the client
+// accepts it or if it is the only one configured.
+func (tx *Transaction) defaultBlockPage() (string, string) {
+	html, json := tx.WAF.DefaultBlockPageHTML, tx.WAF.DefaultBlockPageJSON
+	if json == "" && html == "" {
+		return "", ""
+	}
+	if json != "" {
+		if html == "" {
+			return "application/json", json
+		}
+		for _, accept := range tx.variables.requestHeaders.Get("accept") {
+			if strings.Contains(strings.ToLower(accept), "application/json") {
+				return "application/json", json
+			}
+		}
+	}
+	return "text/html; charset=utf-8", html
+}
+
 func (tx *Transaction) DebugLogger() debuglog.Logger {
 	return tx.debugLogger
 }
@@ -323,6 +457,26 @@ func (tx *Transaction) ResponseBodyReader() (io.Reader, error) {
 	return tx.responseBodyBuffer.Reader()
 }
 
+// AppendResponseBody adds data at the end of the buffered response body
+// and updates RESPONSE_CONTENT_LENGTH accordingly.
+func (tx *Transaction) AppendResponseBody(data []byte) error {
+	if err := tx.responseBodyBuffer.Append(data); err != nil {
+		return err
+	}
+	tx.variables.responseContentLength.Set(strconv.FormatInt(tx.responseBodyBuffer.Size(), 10))
+	return nil
+}
+
+// PrependResponseBody adds data at the beginning of the buffered response body
+// and updates RESPONSE_CONTENT_LENGTH accordingly.
+func (tx *Transaction) PrependResponseBody(data []byte) error {
+	if err := tx.responseBodyBuffer.Prepend(data); err != nil {
+		return err
+	}
+	tx.variables.responseContentLength.Set(strconv.FormatInt(tx.responseBodyBuffer.Size(), 10))
+	return nil
+}
+
 func (tx *Transaction) RequestBodyReader() (io.Reader, error) {
 	return tx.requestBodyBuffer.Reader()
 }
@@ -336,16 +490,31 @@ func (tx *Transaction) AddRequestHeader(key string, value string) {
 	if key == "" {
 		return
 	}
+	if limitReached(tx.variables.requestHeaders, tx.WAF.RequestHeadersLimit) {
+		tx.debugLogger.Warn().Msg("skipping request header, over limit")
+		tx.variables.requestHeadersLimitExceeded.Set("1")
+		return
+	}
 	keyl := strings.ToLower(key)
 	tx.variables.requestHeaders.Add(key, value)
 
 	switch keyl {
 	case "content-type":
-		val := strings.ToLower(value)
-		if val == "application/x-www-form-urlencoded" {
+		if tx.lastPhase >= types.PhaseRequestHeaders {
+			// The body processor might have already been set by ctl:requestBodyProcessor,
+			// rules take precedence over headers added after the request headers phase.
+			break
+		}
+		mediaType, _, _ := strings.Cut(strings.ToLower(value), ";")
+		mediaType = strings.TrimSpace(mediaType)
+		if mediaType == "application/x-www-form-urlencoded" {
 			tx.variables.reqbodyProcessor.Set("URLENCODED")
-		} else if strings.HasPrefix(val, "multipart/form-data") {
+		} else if mediaType == "multipart/form-data" {
 			tx.variables.reqbodyProcessor.Set("MULTIPART")
+		} else if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			tx.variables.reqbodyProcessor.Set("JSON")
+		} else if mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml") {
+			tx.variables.reqbodyProcessor.Set("XML")
 		}
 	case "cookie":
 		// 4.2.  Cookie
@@ -364,10 +533,21 @@ func (tx *Transaction) AddRequestHeader(key string, value string) {
 		// There is no URL Decode performed no the cookies
 		values := cookies.ParseCookies(value)
 		for k, vr := range values {
+			if limitReached(tx.variables.requestCookies, tx.WAF.RequestCookiesLimit) {
+				tx.debugLogger.Warn().Msg("skipping request cookie, over limit")
+				tx.variables.requestCookiesLimitExceeded.Set("1")
+				break
+			}
 			for _, v := range vr {
 				tx.variables.requestCookies.Add(k, v)
 			}
 		}
+	case "authorization":
+		// Only the username is kept, the password of the Basic scheme is
+		// not stored in any variable
+		authType, user := parseAuthorization(value)
+		tx.variables.authType.Set(authType)
+		tx.variables.remoteUser.Set(user)
 	}
 }
 
@@ -554,12 +734,47 @@ func (tx *Transaction) MatchRule(r *Rule, mds []types.MatchData) {
 			break
 		}
 	}
+	if mr.Message_ == "" {
+		// Rules might only set logdata
+		for _, md := range mds {
+			if md.Data() != "" {
+				mr.Data_ = md.Data()
+				break
+			}
+		}
+	}
 
 	tx.matchedRules = append(tx.matchedRules, mr)
+	if r.hits != nil {
+		r.hits.Add(1)
+	}
 	if tx.WAF.ErrorLogCb != nil && r.Log {
 		tx.WAF.ErrorLogCb(mr)
 	}
-
+	if cb, publish := tx.WAF.ruleMatchCallback, tx.WAF.matchEvents.enabled(); cb != nil || publish {
+		e := MatchEvent{
+			RuleID:        r.ID_,
+			Phase:         tx.lastPhase,
+			TransactionID: tx.id,
+			Disruptive:    mr.Disruptive_,
+		}
+		if len(mds) > 0 {
+			e.MatchedVar = mds[0].Variable().Name()
+			if k := mds[0].Key(); k != "" {
+				e.MatchedVar += ":" + k
+			}
+			e.MatchedValue = mds[0].Value()
+		}
+		if tx.interruption != nil && tx.interruption.RuleID == r.ID_ {
+			e.Status = tx.interruption.Status
+		}
+		if cb != nil {
+			cb(e)
+		}
+		if publish {
+			tx.WAF.matchEvents.publish(e)
+		}
+	}
 }
 
 // GetStopWatch is used to debug phase durations
@@ -644,6 +859,18 @@ func (tx *Transaction) RemoveRuleTargetByID(id int, variable variables.RuleVaria
 		Variable: variable,
 		KeyStr:   key,
 	}
+	if isRegex, rx := hasRegex(key); isRegex {
+		re, err := memoize.DoBounded(rx, func() (interface{}, error) { return regexp.Compile(rx) })
+		if err != nil {
+			tx.debugLogger.Error().
+				Int("rule_id", id).
+				Str("key", key).
+				Err(err).
+				Msg("Invalid regular expression for the target to remove")
+			return
+		}
+		c.KeyRx = re.(*regexp.Regexp)
+	}
 
 	if multiphaseEvaluation && (variable == variables.Args || variable == variables.ArgsNames) {
 		// ARGS and ARGS_NAMES have to be splitted into _GET and _POST
@@ -673,17 +900,23 @@ func (tx *Transaction) RemoveRuleByID(id int) {
 // ProcessConnection should be called at very beginning of a request process, it is
 // expected to be executed prior to the virtual host resolution, when the
 // connection arrives on the server.
+//
+// It populates REMOTE_ADDR, REMOTE_HOST, REMOTE_PORT, SERVER_ADDR and SERVER_PORT,
+// SERVER_NAME is set with SetServerName.
 func (tx *Transaction) ProcessConnection(client string, cPort int, server string, sPort int) {
+	if tx.lastPhase >= types.PhaseRequestHeaders {
+		tx.debugLogger.Warn().Msg("ProcessConnection has been called after ProcessRequestHeaders")
+	}
 	p := strconv.Itoa(cPort)
 	p2 := strconv.Itoa(sPort)
 
-	// Modsecurity removed this, so maybe we do the same, such a copycat
-	// addr, err := net.LookupAddr(client)
-	// if err == nil {
-	// 	tx.Variables.VARIABLE_REMOTE_HOST.Set(addr[0])
-	// }else{
-	// 	tx.Variables.VARIABLE_REMOTE_HOST.Set(client)
-	// }
+	// Resolving the client address adds latency to every transaction with
+	// an uncached address, so it is only done when explicitly enabled.
+	if tx.WAF.HostnameLookups {
+		tx.variables.remoteHost.Set(tx.WAF.hostnameLookup.lookup(tx.context, client))
+	} else {
+		tx.variables.remoteHost.Set(client)
+	}
 
 	tx.variables.remoteAddr.Set(client)
 	tx.variables.remotePort.Set(p)
@@ -693,7 +926,21 @@ func (tx *Transaction) ProcessConnection(client string, cPort int, server string
 
 // ExtractGetArguments transforms an url encoded string to a map and creates ARGS_GET
 func (tx *Transaction) ExtractGetArguments(uri string) {
-	data := urlutil.ParseQuery(uri, '&')
+	var data map[string][]string
+	if tx.WAF.QueryStringStrict {
+		var anomalies urlutil.QueryAnomalies
+		data, anomalies = urlutil.ParseQueryWithAnomalies(uri, '&')
+		if anomalies.Any() {
+			tx.debugLogger.Debug().
+				Bool("empty_param", anomalies.EmptyParam).
+				Bool("empty_key", anomalies.EmptyKey).
+				Bool("missing_equals", anomalies.MissingEquals).
+				Msg("Malformed query string")
+			tx.variables.tx.Set(queryStrictErrorKey, []string{"1"})
+		}
+	} else {
+		data = urlutil.ParseQuery(uri, '&')
+	}
 	for k, vs := range data {
 		for _, v := range vs {
 			tx.AddGetRequestArgument(k, v)
@@ -701,10 +948,16 @@ func (tx *Transaction) ExtractGetArguments(uri string) {
 	}
 }
 
+// queryStrictErrorKey is the TX key set to 1 when SecQueryStringStrict is enabled
+// and the query string has empty parameters, parameters without a name or without
+// a value separator, e.g. a=1&&b=2, =value or key
+const queryStrictErrorKey = "query_strict_error"
+
 // AddGetRequestArgument
 func (tx *Transaction) AddGetRequestArgument(key string, value string) {
 	if tx.checkArgumentLimit(tx.variables.argsGet) {
 		tx.debugLogger.Warn().Msg("skipping get request argument, over limit")
+		tx.argumentLimitExceeded()
 		return
 	}
 	tx.variables.argsGet.Add(key, value)
@@ -714,6 +967,7 @@ func (tx *Transaction) AddGetRequestArgument(key string, value string) {
 func (tx *Transaction) AddPostRequestArgument(key string, value string) {
 	if tx.checkArgumentLimit(tx.variables.argsPost) {
 		tx.debugLogger.Warn().Msg("skipping post request argument, over limit")
+		tx.argumentLimitExceeded()
 		return
 	}
 	tx.variables.argsPost.Add(key, value)
@@ -723,13 +977,56 @@ func (tx *Transaction) AddPostRequestArgument(key string, value string) {
 func (tx *Transaction) AddPathRequestArgument(key string, value string) {
 	if tx.checkArgumentLimit(tx.variables.argsPath) {
 		tx.debugLogger.Warn().Msg("skipping path request argument, over limit")
+		tx.argumentLimitExceeded()
 		return
 	}
 	tx.variables.argsPath.Add(key, value)
 }
 
 func (tx *Transaction) checkArgumentLimit(c *collections.NamedCollection) bool {
-	return c.Len() >= tx.WAF.ArgumentLimit
+	return limitReached(c, tx.WAF.ArgumentLimit)
+}
+
+// argumentLimitExceeded sets ARGS_LIMIT_EXCEEDED and interrupts the transaction
+// if the configured SecArgumentsLimitAction is Reject.
+func (tx *Transaction) argumentLimitExceeded() {
+	tx.variables.argsLimitExceeded.Set("1")
+	if tx.WAF.ArgumentLimitAction == types.BodyLimitActionReject && tx.interruption == nil {
+		tx.debugLogger.Warn().Msg("Disrupting transaction with arguments over the limit (Action Reject)")
+		tx.interruption = &types.Interruption{
+			Status: 400,
+			Action: "deny",
+		}
+	}
+}
+
+// applyPostArgumentLimit applies the argument limit to the ARGS_POST populated
+// by the body processors. With ProcessPartial the arguments over the limit are
+// removed, the ones kept are the first in lexical order as the body processors
+// don't keep the order of the arguments. It returns whether the transaction
+// was interrupted.
+func (tx *Transaction) applyPostArgumentLimit() bool {
+	limit := tx.WAF.ArgumentLimit
+	argsPost := tx.variables.argsPost
+	if limit <= 0 || argsPost.Len() <= limit {
+		return false
+	}
+	tx.debugLogger.Warn().Msg("skipping post request arguments, over limit")
+	tx.argumentLimitExceeded()
+	if tx.interruption != nil {
+		return true
+	}
+	keys := slices.Sorted(maps.Keys(argsPost.Data()))
+	for _, k := range keys[limit:] {
+		argsPost.Remove(k)
+	}
+	return false
+}
+
+// limitReached returns whether the collection already holds limit keys, a limit
+// of 0 means there is no limit.
+func limitReached(c *collections.NamedCollection, limit int) bool {
+	return limit > 0 && c.Len() >= limit
 }
 
 // AddResponseArgument
@@ -750,13 +1047,16 @@ func (tx *Transaction) AddResponseArgument(key string, value string) {
 // phase 1 and 2.
 //
 // note: This function won't add GET arguments, they must be added with AddArgument
+//
+// The uri is the request-target and httpVersion the protocol as found in the request
+// line, e.g. HTTP/1.1, REQUEST_LINE is rebuilt from them and the method.
 func (tx *Transaction) ProcessURI(uri string, method string, httpVersion string) {
 	tx.variables.requestMethod.Set(method)
 	tx.variables.requestProtocol.Set(httpVersion)
 	tx.variables.requestURIRaw.Set(uri)
 
-	// TODO modsecurity uses HTTP/${VERSION} instead of just version, let's check it out
-	tx.variables.requestLine.Set(fmt.Sprintf("%s %s %s", method, uri, httpVersion))
+	// connectors don't provide the request line, it is rebuilt from its components
+	tx.variables.requestLine.Set(method + " " + uri + " " + httpVersion)
 
 	var err error
 
@@ -785,7 +1085,10 @@ func (tx *Transaction) ProcessURI(uri string, method string, httpVersion string)
 		*/
 	} else {
 		tx.ExtractGetArguments(parsedURL.RawQuery)
-		tx.variables.requestURI.Set(parsedURL.String())
+		// As in ModSecurity, REQUEST_URI never contains the domain name, even if it was provided
+		// in the request line. The percent-encoding is kept as received, rules decode it with
+		// transformations, while REQUEST_FILENAME holds the decoded path.
+		tx.variables.requestURI.Set(parsedURL.RequestURI())
 		path = parsedURL.Path
 		query = parsedURL.RawQuery
 	}
@@ -837,10 +1140,12 @@ func (tx *Transaction) ProcessRequestHeaders() *types.Interruption {
 	return tx.interruption
 }
 
-func setAndReturnBodyLimitInterruption(tx *Transaction) (*types.Interruption, int, error) {
+// setAndReturnBodyLimitInterruption interrupts the transaction with the given status,
+// 413 for request bodies and 500 for response bodies above the configured limit.
+func setAndReturnBodyLimitInterruption(tx *Transaction, status int) (*types.Interruption, int, error) {
 	tx.debugLogger.Warn().Msg("Disrupting transaction with body size above the configured limit (Action Reject)")
 	tx.interruption = &types.Interruption{
-		Status: 413,
+		Status: status,
 		Action: "deny",
 	}
 	return tx.interruption, 0, nil
@@ -882,10 +1187,10 @@ func (tx *Transaction) WriteRequestBody(b []byte) (*types.Interruption, int, err
 	}
 
 	if tx.requestBodyBuffer.length+writingBytes >= tx.RequestBodyLimit {
-		tx.variables.inboundDataError.Set("1")
+		tx.setRequestBodyLimitError()
 		if tx.WAF.RequestBodyLimitAction == types.BodyLimitActionReject {
 			// We interrupt this transaction in case RequestBodyLimitAction is Reject
-			return setAndReturnBodyLimitInterruption(tx)
+			return setAndReturnBodyLimitInterruption(tx, 413)
 		}
 
 		if tx.WAF.RequestBodyLimitAction == types.BodyLimitActionProcessPartial {
@@ -948,9 +1253,9 @@ func (tx *Transaction) ReadRequestBodyFrom(r io.Reader) (*types.Interruption, in
 			return nil, 0, errors.New("overflow reached while writing request body")
 		}
 		if tx.requestBodyBuffer.length+writingBytes >= tx.RequestBodyLimit {
-			tx.variables.inboundDataError.Set("1")
+			tx.setRequestBodyLimitError()
 			if tx.WAF.RequestBodyLimitAction == types.BodyLimitActionReject {
-				return setAndReturnBodyLimitInterruption(tx)
+				return setAndReturnBodyLimitInterruption(tx, 413)
 			}
 
 			if tx.WAF.RequestBodyLimitAction == types.BodyLimitActionProcessPartial {
@@ -968,9 +1273,9 @@ func (tx *Transaction) ReadRequestBodyFrom(r io.Reader) (*types.Interruption, in
 	}
 
 	if tx.requestBodyBuffer.length == tx.RequestBodyLimit {
-		tx.variables.inboundDataError.Set("1")
+		tx.setRequestBodyLimitError()
 		if tx.WAF.RequestBodyLimitAction == types.BodyLimitActionReject {
-			return setAndReturnBodyLimitInterruption(tx)
+			return setAndReturnBodyLimitInterruption(tx, 413)
 		}
 
 		if tx.WAF.RequestBodyLimitAction == types.BodyLimitActionProcessPartial {
@@ -1029,13 +1334,13 @@ func (tx *Transaction) ProcessRequestBody() (*types.Interruption, error) {
 
 	reader, err := tx.requestBodyBuffer.Reader()
 	if err != nil {
+		tx.setRequestBodyError(err.Error())
 		return nil, err
 	}
 
 	rbp := tx.variables.reqbodyProcessor.Get()
 
 	// Default variables.ReqbodyProcessor values
-	// XML and JSON must be forced with ctl:requestBodyProcessor=JSON
 	if tx.ForceRequestBodyVariable {
 		// We force URLENCODED if mime is x-www... or we have an empty RBP and ForceRequestBodyVariable
 		if rbp == "" {
@@ -1060,9 +1365,14 @@ func (tx *Transaction) ProcessRequestBody() (*types.Interruption, error) {
 		Str("body_processor", rbp).
 		Msg("Attempting to process request body")
 
+	// uploaded files are stored in the temporary directory unless an upload directory is set
+	storagePath := tx.WAF.UploadDir
+	if storagePath == "" {
+		storagePath = tx.WAF.TmpDir
+	}
 	if err := bodyprocessor.ProcessRequest(reader, tx.Variables(), plugintypes.BodyProcessorOptions{
 		Mime:        mime,
-		StoragePath: tx.WAF.UploadDir,
+		StoragePath: storagePath,
 	}); err != nil {
 		tx.debugLogger.Error().Err(err).Msg("Failed to process request body")
 		tx.generateRequestBodyError(err)
@@ -1070,6 +1380,10 @@ func (tx *Transaction) ProcessRequestBody() (*types.Interruption, error) {
 		return tx.interruption, nil
 	}
 
+	if tx.applyPostArgumentLimit() {
+		return tx.interruption, nil
+	}
+
 	tx.WAF.Rules.Eval(types.PhaseRequestBody, tx)
 	return tx.interruption, nil
 }
@@ -1151,7 +1465,7 @@ func (tx *Transaction) WriteResponseBody(b []byte) (*types.Interruption, int, er
 		tx.variables.outboundDataError.Set("1")
 		if tx.WAF.ResponseBodyLimitAction == types.BodyLimitActionReject {
 			// We interrupt this transaction in case ResponseBodyLimitAction is Reject
-			return setAndReturnBodyLimitInterruption(tx)
+			return setAndReturnBodyLimitInterruption(tx, 500)
 		}
 
 		if tx.WAF.ResponseBodyLimitAction == types.BodyLimitActionProcessPartial {
@@ -1201,7 +1515,7 @@ func (tx *Transaction) ReadResponseBodyFrom(r io.Reader) (*types.Interruption, i
 		if tx.responseBodyBuffer.length+writingBytes >= tx.ResponseBodyLimit {
 			tx.variables.outboundDataError.Set("1")
 			if tx.WAF.ResponseBodyLimitAction == types.BodyLimitActionReject {
-				return setAndReturnBodyLimitInterruption(tx)
+				return setAndReturnBodyLimitInterruption(tx, 500)
 			}
 
 			if tx.WAF.ResponseBodyLimitAction == types.BodyLimitActionProcessPartial {
@@ -1221,7 +1535,7 @@ func (tx *Transaction) ReadResponseBodyFrom(r io.Reader) (*types.Interruption, i
 	if tx.responseBodyBuffer.length == tx.ResponseBodyLimit {
 		tx.variables.outboundDataError.Set("1")
 		if tx.WAF.ResponseBodyLimitAction == types.BodyLimitActionReject {
-			return setAndReturnBodyLimitInterruption(tx)
+			return setAndReturnBodyLimitInterruption(tx, 500)
 		}
 
 		if tx.WAF.ResponseBodyLimitAction == types.BodyLimitActionProcessPartial {
@@ -1283,6 +1597,12 @@ func (tx *Transaction) ProcessResponseBody() (*types.Interruption, error) {
 		return tx.interruption, err
 	}
 
+	if codings := contentCodings(tx.variables.responseHeaders.Get("content-encoding")); len(codings) > 0 {
+		if reader, err = tx.decompressResponseBody(reader, codings); err != nil {
+			return tx.interruption, err
+		}
+	}
+
 	if bp := tx.variables.resBodyProcessor.Get(); bp != "" {
 		b, err := bodyprocessors.GetBodyProcessor(bp)
 		if err != nil {
@@ -1310,6 +1630,42 @@ func (tx *Transaction) ProcessResponseBody() (*types.Interruption, error) {
 	return tx.interruption, nil
 }
 
+// AddRequestTrailer adds a trailer field received after the request body,
+// this will feed REQUEST_TRAILERS.
+//
+// Trailers are meant to be added once the request body has been read, before
+// calling ProcessLogging so they can be inspected by logging phase rules.
+func (tx *Transaction) AddRequestTrailer(key string, value string) {
+	if tx.lastPhase >= types.PhaseLogging {
+		tx.debugLogger.Warn().Msg("AddRequestTrailer has been called after ProcessLogging")
+	}
+	tx.variables.requestTrailers.Add(key, value)
+}
+
+// AddResponseTrailer adds a trailer field received after the response body,
+// this will feed RESPONSE_TRAILERS.
+//
+// Trailers are meant to be added once the response body has been read, before
+// calling ProcessLogging so they can be inspected by logging phase rules.
+func (tx *Transaction) AddResponseTrailer(key string, value string) {
+	if tx.lastPhase >= types.PhaseLogging {
+		tx.debugLogger.Warn().Msg("AddResponseTrailer has been called after ProcessLogging")
+	}
+	tx.variables.responseTrailers.Add(key, value)
+}
+
+// AddWebserverErrorLog adds an entry of the web server error log related
+// to the transaction, this will feed WEBSERVER_ERROR_LOG.
+//
+// Entries are meant to be added before calling ProcessLogging so they can be
+// inspected by logging phase rules.
+func (tx *Transaction) AddWebserverErrorLog(line string) {
+	if tx.lastPhase >= types.PhaseLogging {
+		tx.debugLogger.Warn().Msg("AddWebserverErrorLog has been called after ProcessLogging")
+	}
+	tx.variables.webserverErrorLog.Add("", line)
+}
+
 // ProcessLogging logs all information relative to this transaction.
 // At this point there is not need to hold the connection, the response can be
 // delivered prior to the execution of this method.
@@ -1388,6 +1744,15 @@ func (tx *Transaction) MatchedRules() []types.MatchedRule {
 	return tx.matchedRules
 }
 
+// EnvVariables returns the variables set by the setenv action
+func (tx *Transaction) EnvVariables() map[string]string {
+	vars := map[string]string{}
+	for _, md := range tx.variables.env.FindAll() {
+		vars[md.Key()] = md.Value()
+	}
+	return vars
+}
+
 func (tx *Transaction) LastPhase() types.RulePhase {
 	return tx.lastPhase
 }
@@ -1426,25 +1791,43 @@ func (tx *Transaction) AuditLog() *auditlog.Log {
 		ServerID_:      tx.variables.serverName.Get(), // TODO check
 		Request_: &auditlog.TransactionRequest{
 			Method_:   tx.variables.requestMethod.Get(),
-			URI_:      tx.variables.requestURI.Get(),
+			URI_:      sanitiseURI(tx.variables.requestURI.Get(), &tx.sanitisedArgs),
 			Protocol_: tx.variables.requestProtocol.Get(),
-			Args_:     tx.variables.args,
+			Args_:     tx.auditLogArgs(),
 			Length_:   int32(requestLength),
 		},
 		IsInterrupted_: tx.IsInterrupted(),
 	}
+	if tx.interruption != nil {
+		al.Transaction_.Interruption_ = &auditlog.TransactionInterruption{
+			RuleID_: tx.interruption.RuleID,
+			Action_: tx.interruption.Action,
+			Status_: tx.interruption.Status,
+			Data_:   tx.interruption.Data,
+		}
+	}
 
 	var auditLogPartAuditLogTrailerSet, auditLogPartRulesMatchedSet bool
 	for _, part := range tx.AuditLogParts {
 		switch part {
 		case types.AuditLogPartRequestHeaders:
-			al.Transaction_.Request_.Headers_ = tx.variables.requestHeaders.Data()
+			al.Transaction_.Request_.Headers_ = sanitiseHeaders(tx.variables.requestHeaders.Data(), &tx.sanitisedRequestHeaders)
 		case types.AuditLogPartRequestBody:
 			reader, err := tx.requestBodyBuffer.Reader()
 			if err == nil {
 				content, err := io.ReadAll(reader)
 				if err == nil {
-					al.Transaction_.Request_.Body_ = string(content)
+					body := string(content)
+					switch tx.variables.reqbodyProcessor.Get() {
+					case "URLENCODED":
+						body = sanitiseURLEncoded(body, &tx.sanitisedArgs)
+					case "JSON", "MULTIPART":
+						// the raw body can't be redacted, the parsed arguments are logged instead
+						if sanitised, ok := sanitiseArgsPost(tx.variables.argsPost, &tx.sanitisedArgs); ok {
+							body = sanitised
+						}
+					}
+					al.Transaction_.Request_.Body_ = body
 				}
 			}
 
@@ -1484,8 +1867,9 @@ func (tx *Transaction) AuditLog() *auditlog.Log {
 				al.Transaction_.Response_ = &auditlog.TransactionResponse{}
 			}
 			status, _ := strconv.Atoi(tx.variables.responseStatus.Get())
+			al.Transaction_.Response_.Protocol_ = tx.variables.responseProtocol.Get()
 			al.Transaction_.Response_.Status_ = status
-			al.Transaction_.Response_.Headers_ = tx.variables.responseHeaders.Data()
+			al.Transaction_.Response_.Headers_ = sanitiseHeaders(tx.variables.responseHeaders.Data(), &tx.sanitisedResponseHeaders)
 		case types.AuditLogPartAuditLogTrailer:
 			auditLogPartAuditLogTrailerSet = true
 			al.Transaction_.Producer_ = &auditlog.TransactionProducer{
@@ -1536,6 +1920,15 @@ func (tx *Transaction) AuditLog() *auditlog.Log {
 		}
 	}
 
+	// The aborted evaluations are always logged, the rules left in their phases were not evaluated
+	for _, a := range tx.abortedEvaluations {
+		msg := auditlog.Message{Message_: a.message()}
+		if auditLogPartAuditLogTrailerSet {
+			msg.ErrorMessage_ = a.message()
+		}
+		al.Messages_ = append(al.Messages_, msg)
+	}
+
 	// If AuditLogPartRulesMatched (K) is not set, but AuditLogPartAuditLogTrailer (H) is set, we still expect to
 	// log the error messages emitted by the rules (if the rule has Log set to true)
 	if !auditLogPartRulesMatchedSet && auditLogPartAuditLogTrailerSet {
@@ -1572,6 +1965,12 @@ func (tx *Transaction) Close() error {
 	}
 
 	tx.variables.reset()
+	// The transaction is pooled, the references to the matched rules and the transformed
+	// values are released so they are not retained until the transaction is reused.
+	// The matched rules slice is not truncated as callers might still hold it.
+	tx.matchedRules = nil
+	clear(tx.transformationCache)
+	clear(tx.detectionCache)
 	if err := tx.requestBodyBuffer.Reset(); err != nil {
 		errs = append(errs, fmt.Errorf("reseting request body buffer: %v", err))
 	}
@@ -1616,10 +2015,23 @@ func (tx *Transaction) String() string {
 	return res.String()
 }
 
+// setRequestBodyError sets REQBODY_ERROR and REQBODY_ERROR_MSG. Every failure
+// while reading or processing the request body must go through it so rules
+// can rely on both variables being consistent.
+func (tx *Transaction) setRequestBodyError(msg string) {
+	tx.variables.reqbodyError.Set("1")
+	tx.variables.reqbodyErrorMsg.Set(msg)
+}
+
+// setRequestBodyLimitError flags the request body as larger than the configured limit
+func (tx *Transaction) setRequestBodyLimitError() {
+	tx.variables.inboundDataError.Set("1")
+	tx.setRequestBodyError(fmt.Sprintf("request body size exceeds the configured limit of %d bytes", tx.RequestBodyLimit))
+}
+
 // generateRequestBodyError generates all the error variables for the request body parser
 func (tx *Transaction) generateRequestBodyError(err error) {
-	tx.variables.reqbodyError.Set("1")
-	tx.variables.reqbodyErrorMsg.Set(fmt.Sprintf("%s: %s", tx.variables.reqbodyProcessor.Get(), err.Error()))
+	tx.setRequestBodyError(fmt.Sprintf("%s: %s", tx.variables.reqbodyProcessor.Get(), err.Error()))
 	tx.variables.reqbodyProcessorError.Set("1")
 	tx.variables.reqbodyProcessorErrorMsg.Set(err.Error())
 }
@@ -1653,91 +2065,117 @@ func (tx *Transaction) setTimeVariables() {
 
 // TransactionVariables has pointers to all the variables of the transaction
 type TransactionVariables struct {
-	args                     *collections.ConcatKeyed
-	argsCombinedSize         *collections.SizeCollection
-	argsGet                  *collections.NamedCollection
-	argsGetNames             collection.Collection
-	argsNames                *collections.ConcatCollection
-	argsPath                 *collections.NamedCollection
-	argsPost                 *collections.NamedCollection
-	argsPostNames            collection.Collection
-	duration                 *collections.Single
-	env                      *collections.Map
-	files                    *collections.Map
-	filesCombinedSize        *collections.Single
-	filesNames               *collections.Map
-	filesSizes               *collections.Map
-	filesTmpContent          *collections.Map
-	filesTmpNames            *collections.Map
-	fullRequestLength        *collections.Single
-	geo                      *collections.Map
-	highestSeverity          *collections.Single
-	inboundDataError         *collections.Single
-	matchedVar               *collections.Single
-	matchedVarName           *collections.Single
-	matchedVars              *collections.NamedCollection
-	matchedVarsNames         collection.Collection
-	multipartDataAfter       *collections.Single
-	multipartFilename        *collections.Map
-	multipartName            *collections.Map
-	multipartPartHeaders     *collections.Map
-	multipartStrictError     *collections.Single
-	outboundDataError        *collections.Single
-	queryString              *collections.Single
-	remoteAddr               *collections.Single
-	remoteHost               *collections.Single
-	remotePort               *collections.Single
-	reqbodyError             *collections.Single
-	reqbodyErrorMsg          *collections.Single
-	reqbodyProcessor         *collections.Single
-	reqbodyProcessorError    *collections.Single
-	reqbodyProcessorErrorMsg *collections.Single
-	requestBasename          *collections.Single
-	requestBody              *collections.Single
-	requestBodyLength        *collections.Single
-	requestCookies           *collections.NamedCollection
-	requestCookiesNames      collection.Collection
-	requestFilename          *collections.Single
-	requestHeaders           *collections.NamedCollection
-	requestHeadersNames      collection.Collection
-	requestLine              *collections.Single
-	requestMethod            *collections.Single
-	requestProtocol          *collections.Single
-	requestURI               *collections.Single
-	requestURIRaw            *collections.Single
-	requestXML               *collections.Map
-	responseBody             *collections.Single
-	responseContentLength    *collections.Single
-	responseContentType      *collections.Single
-	responseHeaders          *collections.NamedCollection
-	responseHeadersNames     collection.Collection
-	responseProtocol         *collections.Single
-	responseStatus           *collections.Single
-	responseXML              *collections.Map
-	responseArgs             *collections.Map
-	resBodyProcessor         *collections.Single
-	rule                     *collections.Map
-	serverAddr               *collections.Single
-	serverName               *collections.Single
-	serverPort               *collections.Single
-	statusLine               *collections.Single
-	tx                       *collections.Map
-	uniqueID                 *collections.Single
-	urlencodedError          *collections.Single
-	xml                      *collections.Map
-	resBodyError             *collections.Single
-	resBodyErrorMsg          *collections.Single
-	resBodyProcessorError    *collections.Single
-	resBodyProcessorErrorMsg *collections.Single
-	time                     *collections.Single
-	timeDay                  *collections.Single
-	timeEpoch                *collections.Single
-	timeHour                 *collections.Single
-	timeMin                  *collections.Single
-	timeMon                  *collections.Single
-	timeSec                  *collections.Single
-	timeWday                 *collections.Single
-	timeYear                 *collections.Single
+	args                          *collections.ConcatKeyed
+	argsCombinedSize              *collections.SizeCollection
+	argsGet                       *collections.NamedCollection
+	argsGetNames                  collection.Collection
+	argsNames                     *collections.ConcatCollection
+	argsPath                      *collections.NamedCollection
+	argsPost                      *collections.NamedCollection
+	argsPostNames                 collection.Collection
+	duration                      *collections.Single
+	env                           *collections.Map
+	files                         *collections.Map
+	filesCombinedSize             *collections.Single
+	filesNames                    *collections.Map
+	filesSizes                    *collections.Map
+	filesTmpContent               *collections.Map
+	filesTmpNames                 *collections.Map
+	fullRequestLength             *collections.Single
+	geo                           *collections.Map
+	ip                            *collections.Map
+	global                        *collections.Map
+	session                       *collections.Map
+	user                          *collections.Map
+	resource                      *collections.Map
+	authType                      *collections.Single
+	remoteUser                    *collections.Single
+	highestSeverity               *collections.Single
+	inboundDataError              *collections.Single
+	argsLimitExceeded             *collections.Single
+	requestHeadersLimitExceeded   *collections.Single
+	requestCookiesLimitExceeded   *collections.Single
+	matchedVar                    *collections.Single
+	matchedVarName                *collections.Single
+	matchedVars                   *collections.NamedCollection
+	matchedVarsNames              collection.Collection
+	multipartDataAfter            *collections.Single
+	multipartFilename             *collections.Map
+	multipartName                 *collections.Map
+	multipartPartHeaders          *collections.Map
+	multipartStrictError          *collections.Single
+	multipartBoundaryQuoted       *collections.Single
+	multipartBoundaryWhitespace   *collections.Single
+	multipartCrlfLfLines          *collections.Single
+	multipartDataBefore           *collections.Single
+	multipartHeaderFolding        *collections.Single
+	multipartInvalidHeaderFolding *collections.Single
+	multipartInvalidPart          *collections.Single
+	multipartInvalidQuoting       *collections.Single
+	multipartLfLine               *collections.Single
+	multipartMissingSemicolon     *collections.Single
+	multipartUnmatchedBoundary    *collections.Single
+	outboundDataError             *collections.Single
+	queryString                   *collections.Single
+	remoteAddr                    *collections.Single
+	remoteHost                    *collections.Single
+	remotePort                    *collections.Single
+	reqbodyError                  *collections.Single
+	reqbodyErrorMsg               *collections.Single
+	reqbodyProcessor              *collections.Single
+	reqbodyProcessorError         *collections.Single
+	reqbodyProcessorErrorMsg      *collections.Single
+	requestBasename               *collections.Single
+	requestBody                   *collections.Single
+	requestBodyRaw                *rawBody
+	requestBodyLength             *collections.Single
+	requestCookies                *collections.NamedCollection
+	requestCookiesNames           collection.Collection
+	requestFilename               *collections.Single
+	requestHeaders                *collections.NamedCollection
+	requestHeadersNames           collection.Collection
+	requestLine                   *collections.Single
+	requestTrailers               *collections.Map
+	requestMethod                 *collections.Single
+	requestProtocol               *collections.Single
+	requestURI                    *collections.Single
+	requestURIRaw                 *collections.Single
+	requestXML                    *collections.XML
+	responseBody                  *collections.Single
+	responseBodyRaw               *rawBody
+	responseContentLength         *collections.Single
+	responseContentType           *collections.Single
+	responseHeaders               *collections.NamedCollection
+	responseTrailers              *collections.Map
+	responseHeadersNames          collection.Collection
+	responseProtocol              *collections.Single
+	responseStatus                *collections.Single
+	responseXML                   *collections.Map
+	responseArgs                  *collections.Map
+	resBodyProcessor              *collections.Single
+	rule                          *collections.Map
+	serverAddr                    *collections.Single
+	serverName                    *collections.Single
+	serverPort                    *collections.Single
+	webserverErrorLog             *collections.Map
+	statusLine                    *collections.Single
+	tx                            *collections.Map
+	uniqueID                      *collections.Single
+	urlencodedError               *collections.Single
+	xml                           *collections.XML
+	resBodyError                  *collections.Single
+	resBodyErrorMsg               *collections.Single
+	resBodyProcessorError         *collections.Single
+	resBodyProcessorErrorMsg      *collections.Single
+	time                          *collections.Single
+	timeDay                       *collections.Single
+	timeEpoch                     *collections.Single
+	timeHour                      *collections.Single
+	timeMin                       *collections.Single
+	timeMon                       *collections.Single
+	timeSec                       *collections.Single
+	timeWday                      *collections.Single
+	timeYear                      *collections.Single
 }
 
 func NewTransactionVariables() *TransactionVariables {
@@ -1748,6 +2186,9 @@ func NewTransactionVariables() *TransactionVariables {
 	v.filesCombinedSize = collections.NewSingle(variables.FilesCombinedSize)
 	v.fullRequestLength = collections.NewSingle(variables.FullRequestLength)
 	v.inboundDataError = collections.NewSingle(variables.InboundDataError)
+	v.argsLimitExceeded = collections.NewSingle(variables.ArgsLimitExceeded)
+	v.requestHeadersLimitExceeded = collections.NewSingle(variables.RequestHeadersLimitExceeded)
+	v.requestCookiesLimitExceeded = collections.NewSingle(variables.RequestCookiesLimitExceeded)
 	v.matchedVar = collections.NewSingle(variables.MatchedVar)
 	v.matchedVarName = collections.NewSingle(variables.MatchedVarName)
 	v.multipartDataAfter = collections.NewSingle(variables.MultipartDataAfter)
@@ -1763,6 +2204,7 @@ func NewTransactionVariables() *TransactionVariables {
 	v.reqbodyProcessor = collections.NewSingle(variables.ReqbodyProcessor)
 	v.requestBasename = collections.NewSingle(variables.RequestBasename)
 	v.requestBody = collections.NewSingle(variables.RequestBody)
+	v.requestBodyRaw = newRawBody(variables.RequestBodyRaw)
 	v.requestBodyLength = collections.NewSingle(variables.RequestBodyLength)
 	v.requestFilename = collections.NewSingle(variables.RequestFilename)
 	v.requestLine = collections.NewSingle(variables.RequestLine)
@@ -1771,6 +2213,7 @@ func NewTransactionVariables() *TransactionVariables {
 	v.requestURI = collections.NewSingle(variables.RequestURI)
 	v.requestURIRaw = collections.NewSingle(variables.RequestURIRaw)
 	v.responseBody = collections.NewSingle(variables.ResponseBody)
+	v.responseBodyRaw = newRawBody(variables.ResponseBodyRaw)
 	v.responseContentLength = collections.NewSingle(variables.ResponseContentLength)
 	v.responseProtocol = collections.NewSingle(variables.ResponseProtocol)
 	v.responseStatus = collections.NewSingle(variables.ResponseStatus)
@@ -1778,6 +2221,7 @@ func NewTransactionVariables() *TransactionVariables {
 	v.serverAddr = collections.NewSingle(variables.ServerAddr)
 	v.serverName = collections.NewSingle(variables.ServerName)
 	v.serverPort = collections.NewSingle(variables.ServerPort)
+	v.webserverErrorLog = collections.NewMap(variables.WebserverErrorLog)
 	v.highestSeverity = collections.NewSingle(variables.HighestSeverity)
 	v.statusLine = collections.NewSingle(variables.StatusLine)
 	v.duration = collections.NewSingle(variables.Duration)
@@ -1798,8 +2242,17 @@ func NewTransactionVariables() *TransactionVariables {
 	v.requestHeadersNames = v.requestHeaders.Names(variables.RequestHeadersNames)
 	v.responseHeaders = collections.NewNamedCollection(variables.ResponseHeaders)
 	v.responseHeadersNames = v.responseHeaders.Names(variables.ResponseHeadersNames)
+	v.requestTrailers = collections.NewMap(variables.RequestTrailers)
+	v.responseTrailers = collections.NewMap(variables.ResponseTrailers)
 	v.resBodyProcessor = collections.NewSingle(variables.ResBodyProcessor)
 	v.geo = collections.NewMap(variables.Geo)
+	v.ip = collections.NewMap(variables.IP)
+	v.global = collections.NewMap(variables.Global)
+	v.session = collections.NewMap(variables.Session)
+	v.user = collections.NewMap(variables.User)
+	v.resource = collections.NewMap(variables.Resource)
+	v.authType = collections.NewSingle(variables.AuthType)
+	v.remoteUser = collections.NewSingle(variables.RemoteUser)
 	v.tx = collections.NewMap(variables.TX)
 	v.rule = collections.NewMap(variables.Rule)
 	v.env = collections.NewMap(variables.Env)
@@ -1807,9 +2260,20 @@ func NewTransactionVariables() *TransactionVariables {
 	v.filesNames = collections.NewMap(variables.FilesNames)
 	v.filesTmpNames = collections.NewMap(variables.FilesTmpNames)
 	v.responseXML = collections.NewMap(variables.ResponseXML)
-	v.requestXML = collections.NewMap(variables.RequestXML)
+	v.requestXML = collections.NewXML(variables.RequestXML)
 	v.multipartPartHeaders = collections.NewMap(variables.MultipartPartHeaders)
 	v.multipartStrictError = collections.NewSingle(variables.MultipartStrictError)
+	v.multipartBoundaryQuoted = collections.NewSingle(variables.MultipartBoundaryQuoted)
+	v.multipartBoundaryWhitespace = collections.NewSingle(variables.MultipartBoundaryWhitespace)
+	v.multipartCrlfLfLines = collections.NewSingle(variables.MultipartCrlfLfLines)
+	v.multipartDataBefore = collections.NewSingle(variables.MultipartDataBefore)
+	v.multipartHeaderFolding = collections.NewSingle(variables.MultipartHeaderFolding)
+	v.multipartInvalidHeaderFolding = collections.NewSingle(variables.MultipartInvalidHeaderFolding)
+	v.multipartInvalidPart = collections.NewSingle(variables.MultipartInvalidPart)
+	v.multipartInvalidQuoting = collections.NewSingle(variables.MultipartInvalidQuoting)
+	v.multipartLfLine = collections.NewSingle(variables.MultipartLfLine)
+	v.multipartMissingSemicolon = collections.NewSingle(variables.MultipartMissingSemicolon)
+	v.multipartUnmatchedBoundary = collections.NewSingle(variables.MultipartUnmatchedBoundary)
 	v.time = collections.NewSingle(variables.Time)
 	v.timeDay = collections.NewSingle(variables.TimeDay)
 	v.timeEpoch = collections.NewSingle(variables.TimeEpoch)
@@ -1880,6 +2344,18 @@ func (v *TransactionVariables) InboundDataError() collection.Single {
 	return v.inboundDataError
 }
 
+func (v *TransactionVariables) ArgsLimitExceeded() collection.Single {
+	return v.argsLimitExceeded
+}
+
+func (v *TransactionVariables) RequestHeadersLimitExceeded() collection.Single {
+	return v.requestHeadersLimitExceeded
+}
+
+func (v *TransactionVariables) RequestCookiesLimitExceeded() collection.Single {
+	return v.requestCookiesLimitExceeded
+}
+
 func (v *TransactionVariables) MatchedVar() collection.Single {
 	return v.matchedVar
 }
@@ -1944,6 +2420,10 @@ func (v *TransactionVariables) RequestBody() collection.Single {
 	return v.requestBody
 }
 
+func (v *TransactionVariables) RequestBodyRaw() collection.Single {
+	return v.requestBodyRaw
+}
+
 func (v *TransactionVariables) RequestBodyLength() collection.Single {
 	return v.requestBodyLength
 }
@@ -1976,6 +2456,10 @@ func (v *TransactionVariables) ResponseBody() collection.Single {
 	return v.responseBody
 }
 
+func (v *TransactionVariables) ResponseBodyRaw() collection.Single {
+	return v.responseBodyRaw
+}
+
 func (v *TransactionVariables) ResponseContentLength() collection.Single {
 	return v.responseContentLength
 }
@@ -2000,6 +2484,10 @@ func (v *TransactionVariables) ServerPort() collection.Single {
 	return v.serverPort
 }
 
+func (v *TransactionVariables) WebserverErrorLog() collection.Map {
+	return v.webserverErrorLog
+}
+
 func (v *TransactionVariables) HighestSeverity() collection.Single {
 	return v.highestSeverity
 }
@@ -2048,6 +2536,34 @@ func (v *TransactionVariables) Geo() collection.Map {
 	return v.geo
 }
 
+func (v *TransactionVariables) IP() collection.Map {
+	return v.ip
+}
+
+func (v *TransactionVariables) Global() collection.Map {
+	return v.global
+}
+
+func (v *TransactionVariables) Session() collection.Map {
+	return v.session
+}
+
+func (v *TransactionVariables) User() collection.Map {
+	return v.user
+}
+
+func (v *TransactionVariables) Resource() collection.Map {
+	return v.resource
+}
+
+func (v *TransactionVariables) AuthType() collection.Single {
+	return v.authType
+}
+
+func (v *TransactionVariables) RemoteUser() collection.Single {
+	return v.remoteUser
+}
+
 func (v *TransactionVariables) Files() collection.Map {
 	return v.files
 }
@@ -2064,6 +2580,14 @@ func (v *TransactionVariables) ResponseHeaders() collection.Map {
 	return v.responseHeaders
 }
 
+func (v *TransactionVariables) RequestTrailers() collection.Map {
+	return v.requestTrailers
+}
+
+func (v *TransactionVariables) ResponseTrailers() collection.Map {
+	return v.responseTrailers
+}
+
 func (v *TransactionVariables) MultipartName() collection.Map {
 	return v.multipartName
 }
@@ -2156,6 +2680,50 @@ func (v *TransactionVariables) MultipartStrictError() collection.Single {
 	return v.multipartStrictError
 }
 
+func (v *TransactionVariables) MultipartBoundaryQuoted() collection.Single {
+	return v.multipartBoundaryQuoted
+}
+
+func (v *TransactionVariables) MultipartBoundaryWhitespace() collection.Single {
+	return v.multipartBoundaryWhitespace
+}
+
+func (v *TransactionVariables) MultipartCrlfLfLines() collection.Single {
+	return v.multipartCrlfLfLines
+}
+
+func (v *TransactionVariables) MultipartDataBefore() collection.Single {
+	return v.multipartDataBefore
+}
+
+func (v *TransactionVariables) MultipartHeaderFolding() collection.Single {
+	return v.multipartHeaderFolding
+}
+
+func (v *TransactionVariables) MultipartInvalidHeaderFolding() collection.Single {
+	return v.multipartInvalidHeaderFolding
+}
+
+func (v *TransactionVariables) MultipartInvalidPart() collection.Single {
+	return v.multipartInvalidPart
+}
+
+func (v *TransactionVariables) MultipartInvalidQuoting() collection.Single {
+	return v.multipartInvalidQuoting
+}
+
+func (v *TransactionVariables) MultipartLfLine() collection.Single {
+	return v.multipartLfLine
+}
+
+func (v *TransactionVariables) MultipartMissingSemicolon() collection.Single {
+	return v.multipartMissingSemicolon
+}
+
+func (v *TransactionVariables) MultipartUnmatchedBoundary() collection.Single {
+	return v.multipartUnmatchedBoundary
+}
+
 // All iterates over the variables. We return both variable and its collection, i.e. key/value, to follow
 // general range iteration in Go which always has a key and value (key is int index for slices). Notably,
 // this is consistent with discussions for custom iterable types in a future language version
@@ -2215,12 +2783,42 @@ func (v *TransactionVariables) All(f func(v variables.RuleVariable, col collecti
 	if !f(variables.Geo, v.geo) {
 		return
 	}
+	if !f(variables.IP, v.ip) {
+		return
+	}
+	if !f(variables.Global, v.global) {
+		return
+	}
+	if !f(variables.Session, v.session) {
+		return
+	}
+	if !f(variables.User, v.user) {
+		return
+	}
+	if !f(variables.Resource, v.resource) {
+		return
+	}
+	if !f(variables.AuthType, v.authType) {
+		return
+	}
+	if !f(variables.RemoteUser, v.remoteUser) {
+		return
+	}
 	if !f(variables.HighestSeverity, v.highestSeverity) {
 		return
 	}
 	if !f(variables.InboundDataError, v.inboundDataError) {
 		return
 	}
+	if !f(variables.ArgsLimitExceeded, v.argsLimitExceeded) {
+		return
+	}
+	if !f(variables.RequestHeadersLimitExceeded, v.requestHeadersLimitExceeded) {
+		return
+	}
+	if !f(variables.RequestCookiesLimitExceeded, v.requestCookiesLimitExceeded) {
+		return
+	}
 	if !f(variables.MatchedVar, v.matchedVar) {
 		return
 	}
@@ -2248,6 +2846,39 @@ func (v *TransactionVariables) All(f func(v variables.RuleVariable, col collecti
 	if !f(variables.MultipartStrictError, v.multipartStrictError) {
 		return
 	}
+	if !f(variables.MultipartBoundaryQuoted, v.multipartBoundaryQuoted) {
+		return
+	}
+	if !f(variables.MultipartBoundaryWhitespace, v.multipartBoundaryWhitespace) {
+		return
+	}
+	if !f(variables.MultipartCrlfLfLines, v.multipartCrlfLfLines) {
+		return
+	}
+	if !f(variables.MultipartDataBefore, v.multipartDataBefore) {
+		return
+	}
+	if !f(variables.MultipartHeaderFolding, v.multipartHeaderFolding) {
+		return
+	}
+	if !f(variables.MultipartInvalidHeaderFolding, v.multipartInvalidHeaderFolding) {
+		return
+	}
+	if !f(variables.MultipartInvalidPart, v.multipartInvalidPart) {
+		return
+	}
+	if !f(variables.MultipartInvalidQuoting, v.multipartInvalidQuoting) {
+		return
+	}
+	if !f(variables.MultipartLfLine, v.multipartLfLine) {
+		return
+	}
+	if !f(variables.MultipartMissingSemicolon, v.multipartMissingSemicolon) {
+		return
+	}
+	if !f(variables.MultipartUnmatchedBoundary, v.multipartUnmatchedBoundary) {
+		return
+	}
 	if !f(variables.OutboundDataError, v.outboundDataError) {
 		return
 	}
@@ -2284,6 +2915,9 @@ func (v *TransactionVariables) All(f func(v variables.RuleVariable, col collecti
 	if !f(variables.RequestBody, v.requestBody) {
 		return
 	}
+	if !f(variables.RequestBodyRaw, v.requestBodyRaw) {
+		return
+	}
 	if !f(variables.RequestBodyLength, v.requestBodyLength) {
 		return
 	}
@@ -2323,6 +2957,9 @@ func (v *TransactionVariables) All(f func(v variables.RuleVariable, col collecti
 	if !f(variables.ResponseBody, v.responseBody) {
 		return
 	}
+	if !f(variables.ResponseBodyRaw, v.responseBodyRaw) {
+		return
+	}
 	if !f(variables.ResponseContentLength, v.responseContentLength) {
 		return
 	}
@@ -2332,6 +2969,12 @@ func (v *TransactionVariables) All(f func(v variables.RuleVariable, col collecti
 	if !f(variables.ResponseHeaders, v.responseHeaders) {
 		return
 	}
+	if !f(variables.RequestTrailers, v.requestTrailers) {
+		return
+	}
+	if !f(variables.ResponseTrailers, v.responseTrailers) {
+		return
+	}
 	if !f(variables.ResponseHeadersNames, v.responseHeadersNames) {
 		return
 	}
@@ -2350,6 +2993,18 @@ func (v *TransactionVariables) All(f func(v variables.RuleVariable, col collecti
 	if !f(variables.ResBodyProcessor, v.resBodyProcessor) {
 		return
 	}
+	if !f(variables.ResBodyError, v.resBodyError) {
+		return
+	}
+	if !f(variables.ResBodyErrorMsg, v.resBodyErrorMsg) {
+		return
+	}
+	if !f(variables.ResBodyProcessorError, v.resBodyProcessorError) {
+		return
+	}
+	if !f(variables.ResBodyProcessorErrorMsg, v.resBodyProcessorErrorMsg) {
+		return
+	}
 	if !f(variables.Rule, v.rule) {
 		return
 	}
@@ -2362,6 +3017,9 @@ func (v *TransactionVariables) All(f func(v variables.RuleVariable, col collecti
 	if !f(variables.ServerPort, v.serverPort) {
 		return
 	}
+	if !f(variables.WebserverErrorLog, v.webserverErrorLog) {
+		return
+	}
 	if !f(variables.StatusLine, v.statusLine) {
 		return
 	}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"reflect"
 	"regexp"
 	"runtime/debug"
 	"strconv"
@@ -671,6 +673,108 @@ func TestWriteResponseBodyOnLimitReached(t *testing.T) {
 	}
 }
 
+func TestResponseBodyLimitIndependentOfRequestBodyLimit(t *testing.T) {
+	responseBody := strings.Repeat("x", 64)
+
+	testCases := map[string]struct {
+		requestBodyLimit        int64
+		responseBodyLimit       int64
+		responseBodyLimitAction types.BodyLimitAction
+		expectedStatus          int
+		expectedResponseBody    string
+		limitReached            bool
+	}{
+		"response limit above request limit": {
+			requestBodyLimit:        8,
+			responseBodyLimit:       128,
+			responseBodyLimitAction: types.BodyLimitActionReject,
+			expectedResponseBody:    responseBody,
+		},
+		"response limit below request limit (reject)": {
+			requestBodyLimit:        128,
+			responseBodyLimit:       16,
+			responseBodyLimitAction: types.BodyLimitActionReject,
+			expectedStatus:          500,
+			limitReached:            true,
+		},
+		"response limit below request limit (partial processing)": {
+			requestBodyLimit:        128,
+			responseBodyLimit:       16,
+			responseBodyLimitAction: types.BodyLimitActionProcessPartial,
+			expectedResponseBody:    responseBody[:16],
+			limitReached:            true,
+		},
+	}
+
+	for name, tCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for wName, writer := range responseBodyWriters {
+				t.Run(wName, func(t *testing.T) {
+					waf := NewWAF()
+					waf.RuleEngine = types.RuleEngineOn
+					waf.RequestBodyAccess = true
+					waf.RequestBodyLimit = tCase.requestBodyLimit
+					waf.RequestBodyLimitAction = types.BodyLimitActionReject
+					waf.ResponseBodyAccess = true
+					waf.ResponseBodyMimeTypes = []string{"text/plain"}
+					waf.ResponseBodyLimit = tCase.responseBodyLimit
+					waf.ResponseBodyLimitAction = tCase.responseBodyLimitAction
+					if err := waf.Validate(); err != nil {
+						t.Fatal(err)
+					}
+
+					tx := waf.NewTransaction()
+					tx.ProcessRequestHeaders()
+					if it, _, err := tx.WriteRequestBody([]byte("abc")); it != nil || err != nil {
+						t.Fatalf("unexpected request body interruption: %v, %v", it, err)
+					}
+					if it, err := tx.ProcessRequestBody(); it != nil || err != nil {
+						t.Fatalf("unexpected request body interruption: %v, %v", it, err)
+					}
+					tx.AddResponseHeader("Content-Type", "text/plain")
+					if it := tx.ProcessResponseHeaders(200, "HTTP/1.1"); it != nil {
+						t.Fatal("unexpected response headers interruption")
+					}
+
+					it, _, err := writer(tx, responseBody)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if tCase.expectedStatus != 0 {
+						if it == nil {
+							t.Fatal("expected interruption")
+						}
+						if want, have := tCase.expectedStatus, it.Status; want != have {
+							t.Errorf("unexpected status, want %d, have %d", want, have)
+						}
+					} else {
+						if it != nil {
+							t.Fatalf("unexpected interruption: %v", it)
+						}
+						if _, err := tx.ProcessResponseBody(); err != nil {
+							t.Fatal(err)
+						}
+						if want, have := tCase.expectedResponseBody, tx.variables.responseBody.Get(); want != have {
+							t.Errorf("unexpected RESPONSE_BODY, want %q, have %q", want, have)
+						}
+					}
+
+					if want, have := tCase.limitReached, tx.variables.outboundDataError.Get() == "1"; want != have {
+						t.Errorf("unexpected OUTBOUND_DATA_ERROR, want %t, have %t", want, have)
+					}
+					if tx.variables.inboundDataError.Get() == "1" {
+						t.Error("unexpected INBOUND_DATA_ERROR")
+					}
+
+					if err := tx.Close(); err != nil {
+						t.Fatal(err)
+					}
+				})
+			}
+		})
+	}
+}
+
 func TestWriteResponseBodyIsNopWhenBodyIsNotAccesible(t *testing.T) {
 	testCases := []struct {
 		ruleEngine         types.RuleEngineStatus
@@ -924,6 +1028,141 @@ func TestHeaderSetters(t *testing.T) {
 	}
 }
 
+func TestContentTypeBodyProcessor(t *testing.T) {
+	tests := map[string]string{
+		"application/x-www-form-urlencoded":                "URLENCODED",
+		"application/x-www-form-urlencoded; charset=UTF-8": "URLENCODED",
+		"Application/X-WWW-Form-Urlencoded":                "URLENCODED",
+		"multipart/form-data; boundary=abc":                "MULTIPART",
+		"application/json; charset=utf-8":                  "JSON",
+		"application/problem+json":                         "JSON",
+		"text/xml; charset=utf-8":                          "XML",
+		"application/atom+xml":                             "XML",
+		"application/x-www-form-urlencoded-extra":          "",
+		"multipart/form-data-extra":                        "",
+		"text/plain":                                       "",
+	}
+	for contentType, want := range tests {
+		t.Run(contentType, func(t *testing.T) {
+			tx := NewWAF().NewTransaction()
+			defer tx.Close()
+			tx.AddRequestHeader("Content-Type", contentType)
+			if have := tx.variables.reqbodyProcessor.Get(); want != have {
+				t.Errorf("unexpected body processor, want %q, have %q", want, have)
+			}
+		})
+	}
+}
+
+func TestContentTypeAfterRequestHeadersKeepsBodyProcessor(t *testing.T) {
+	waf := NewWAF()
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.AddRequestHeader("Content-Type", "application/x-www-form-urlencoded")
+	if want, have := "URLENCODED", tx.variables.reqbodyProcessor.Get(); want != have {
+		t.Fatalf("unexpected body processor, want %q, have %q", want, have)
+	}
+	tx.ProcessRequestHeaders()
+	// e.g. set by ctl:requestBodyProcessor in phase 1
+	tx.variables.reqbodyProcessor.Set("JSON")
+	tx.AddRequestHeader("Content-Type", "multipart/form-data; boundary=abc")
+	if want, have := "JSON", tx.variables.reqbodyProcessor.Get(); want != have {
+		t.Errorf("unexpected body processor, want %q, have %q", want, have)
+	}
+}
+
+func TestJSONRequestBody(t *testing.T) {
+	body := `{"user":{"name":"john","roles":["admin","dev"]},"items":[{"id":1},{"id":2}]}`
+
+	for _, contentType := range []string{
+		"application/json",
+		"application/json; charset=utf-8",
+		"application/vnd.api+json",
+	} {
+		t.Run(contentType, func(t *testing.T) {
+			waf := NewWAF()
+			waf.RequestBodyAccess = true
+			tx := waf.NewTransaction()
+			defer tx.Close()
+
+			tx.AddRequestHeader("Content-Type", contentType)
+			tx.ProcessRequestHeaders()
+			if want, have := "JSON", tx.variables.reqbodyProcessor.Get(); want != have {
+				t.Fatalf("unexpected body processor, want %q, have %q", want, have)
+			}
+			if _, _, err := tx.WriteRequestBody([]byte(body)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tx.ProcessRequestBody(); err != nil {
+				t.Fatal(err)
+			}
+
+			for key, want := range map[string]string{
+				"json.user.name":    "john",
+				"json.user.roles.0": "admin",
+				"json.user.roles.1": "dev",
+				"json.items.0.id":   "1",
+				"json.items.1.id":   "2",
+				"json.user.roles":   "2",
+				"json.items":        "2",
+			} {
+				if have := tx.variables.args.Get(key); len(have) != 1 || have[0] != want {
+					t.Errorf("unexpected ARGS:%s, want %q, have %v", key, want, have)
+				}
+			}
+			if names := collectionValues(t, tx.variables.argsNames); !utils.InSlice("json.user.name", names) {
+				t.Errorf("expected json.user.name in ARGS_NAMES, have %v", names)
+			}
+			if want, have := "0", tx.variables.reqbodyError.Get(); want != have {
+				t.Errorf("unexpected REQBODY_ERROR, want %q, have %q", want, have)
+			}
+		})
+	}
+}
+
+func TestJSONRequestBodyForcedProcessor(t *testing.T) {
+	waf := NewWAF()
+	waf.RequestBodyAccess = true
+	tx := waf.NewTransaction()
+	defer tx.Close()
+
+	tx.AddRequestHeader("Content-Type", "text/plain")
+	tx.ProcessRequestHeaders()
+	// e.g. set by ctl:requestBodyProcessor=JSON in phase 1
+	tx.variables.reqbodyProcessor.Set("JSON")
+	if _, _, err := tx.WriteRequestBody([]byte(`{"a":{"b":"c"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ProcessRequestBody(); err != nil {
+		t.Fatal(err)
+	}
+	if have := tx.variables.argsPost.Get("json.a.b"); len(have) != 1 || have[0] != "c" {
+		t.Errorf("unexpected ARGS_POST:json.a.b, want \"c\", have %v", have)
+	}
+}
+
+func TestJSONRequestBodyMalformed(t *testing.T) {
+	waf := NewWAF()
+	waf.RequestBodyAccess = true
+	tx := waf.NewTransaction()
+	defer tx.Close()
+
+	tx.AddRequestHeader("Content-Type", "application/json")
+	tx.ProcessRequestHeaders()
+	if _, _, err := tx.WriteRequestBody([]byte(`{"user":{"name":"john"`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ProcessRequestBody(); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "1", tx.variables.reqbodyError.Get(); want != have {
+		t.Errorf("unexpected REQBODY_ERROR, want %q, have %q", want, have)
+	}
+	if have := tx.variables.argsPost.FindAll(); len(have) != 0 {
+		t.Errorf("unexpected ARGS_POST for a malformed body: %v", have)
+	}
+}
+
 func TestCookiesNotUrldecoded(t *testing.T) {
 	waf := NewWAF()
 	tx := waf.NewTransaction()
@@ -1138,6 +1377,54 @@ func TestTransactionSyncPool(t *testing.T) {
 	}
 }
 
+func TestTransactionReuseMatchedRules(t *testing.T) {
+	waf := NewWAF()
+	rule := NewRule()
+	rule.ID_ = 1
+	rule.Phase_ = types.PhaseRequestHeaders
+	if err := rule.AddVariable(variables.ArgsGet, "q", false); err != nil {
+		t.Fatal(err)
+	}
+	rule.SetOperator(&dummyEqOperator{}, "@eq", "0")
+	if err := waf.Rules.Add(rule); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := waf.NewTransaction()
+	tx.AddGetRequestArgument("q", "0")
+	tx.ProcessRequestHeaders()
+	matchedRules := tx.MatchedRules()
+	if len(matchedRules) != 1 {
+		t.Fatalf("expected 1 matched rule, got %d", len(matchedRules))
+	}
+	if err := tx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if tx.matchedRules != nil {
+		t.Error("expected matched rules to be released on close")
+	}
+	if len(tx.transformationCache) != 0 {
+		t.Error("expected transformation cache to be released on close")
+	}
+
+	for i := 0; i < 10; i++ {
+		tx = waf.NewTransaction()
+		tx.AddGetRequestArgument("q", "1")
+		tx.ProcessRequestHeaders()
+		if len(tx.MatchedRules()) != 0 {
+			t.Fatalf("unexpected matched rules after reuse: %v", tx.MatchedRules())
+		}
+		if err := tx.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the matched rules of a closed transaction are not altered by the next ones
+	if len(matchedRules) != 1 || matchedRules[0].Rule().ID() != 1 {
+		t.Errorf("unexpected matched rules of the closed transaction: %v", matchedRules)
+	}
+}
+
 func TestTxPhase4Magic(t *testing.T) {
 	waf := NewWAF()
 	waf.ResponseBodyAccess = true
@@ -1232,6 +1519,208 @@ func TestTxReqBodyForceNegative(t *testing.T) {
 	}
 }
 
+func TestRequestBodyInMemoryLimitSpillover(t *testing.T) {
+	if !environment.HasAccessToFS {
+		return // t.Skip doesn't work on TinyGo
+	}
+
+	tmpDir := t.TempDir()
+	waf := NewWAF()
+	waf.TmpDir = tmpDir
+	waf.RequestBodyAccess = true
+	waf.RequestBodyLimit = 1024
+	waf.SetRequestBodyInMemoryLimit(16)
+	rule := NewRule()
+	rule.ID_ = 1
+	rule.Phase_ = types.PhaseRequestBody
+	if err := rule.AddVariable(variables.ArgsPost, "b", false); err != nil {
+		t.Fatal(err)
+	}
+	rule.SetOperator(&dummyEqOperator{}, "@eq", "0")
+	if err := rule.AddAction("deny", &dummyDenyAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := waf.Rules.Add(rule); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := waf.NewTransaction()
+	tx.AddRequestHeader("Content-Type", "application/x-www-form-urlencoded")
+	tx.ProcessRequestHeaders()
+	body := "a=" + strings.Repeat("x", 64) + "&b=0"
+	if _, _, err := tx.WriteRequestBody([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected the body to be spilled over to a temporary file, found %d files", len(files))
+	}
+
+	it, err := tx.ProcessRequestBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it == nil {
+		t.Error("expected an interruption from the spilled over body")
+	}
+	if want, have := body, tx.variables.requestBody.Get(); want != have {
+		t.Errorf("unexpected REQUEST_BODY, want %q, have %q", want, have)
+	}
+
+	if err := tx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files, err = os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected the temporary file to be removed on close, found %d files", len(files))
+	}
+}
+
+func TestRequestBodyErrorVariables(t *testing.T) {
+	testCases := map[string]struct {
+		contentType     string
+		bodyProcessor   string
+		body            string
+		limit           int64
+		limitAction     types.BodyLimitAction
+		expectedMessage string
+	}{
+		"json parse error": {
+			contentType:     "application/json",
+			bodyProcessor:   "JSON",
+			body:            `{"a":`,
+			expectedMessage: "JSON: ",
+		},
+		"xml parse error": {
+			contentType:     "application/xml",
+			bodyProcessor:   "XML",
+			body:            "<a b=",
+			expectedMessage: "XML: ",
+		},
+		"multipart strict error": {
+			contentType:     "multipart/form-data; boundary=\"",
+			body:            "--a\r\n",
+			expectedMessage: "MULTIPART: ",
+		},
+		"invalid body processor": {
+			contentType:     "text/plain",
+			bodyProcessor:   "UNKNOWN",
+			body:            "abc",
+			expectedMessage: "UNKNOWN: invalid body processor",
+		},
+		"limit exceeded with reject": {
+			contentType:     "application/x-www-form-urlencoded",
+			body:            "a=123456",
+			limit:           4,
+			limitAction:     types.BodyLimitActionReject,
+			expectedMessage: "request body size exceeds the configured limit of 4 bytes",
+		},
+		"limit exceeded with partial processing": {
+			contentType:     "application/x-www-form-urlencoded",
+			body:            "a=123456",
+			limit:           4,
+			limitAction:     types.BodyLimitActionProcessPartial,
+			expectedMessage: "request body size exceeds the configured limit of 4 bytes",
+		},
+	}
+
+	for name, tCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			waf := NewWAF()
+			waf.RuleEngine = types.RuleEngineOn
+			waf.RequestBodyAccess = true
+			if tCase.limit > 0 {
+				waf.RequestBodyLimit = tCase.limit
+				waf.RequestBodyLimitAction = tCase.limitAction
+			}
+
+			tx := waf.NewTransaction()
+			tx.AddRequestHeader("Content-Type", tCase.contentType)
+			tx.ProcessRequestHeaders()
+			if tCase.bodyProcessor != "" {
+				tx.variables.reqbodyProcessor.Set(tCase.bodyProcessor)
+			}
+			if _, _, err := tx.WriteRequestBody([]byte(tCase.body)); err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if _, err := tx.ProcessRequestBody(); err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			if want, have := "1", tx.variables.reqbodyError.Get(); want != have {
+				t.Errorf("unexpected REQBODY_ERROR, want %q, have %q", want, have)
+			}
+			if msg := tx.variables.reqbodyErrorMsg.Get(); !strings.HasPrefix(msg, tCase.expectedMessage) {
+				t.Errorf("unexpected REQBODY_ERROR_MSG, want prefix %q, have %q", tCase.expectedMessage, msg)
+			}
+
+			if err := tx.Close(); err != nil {
+				t.Fatalf("Failed to close transaction: %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestRawBodyVariables(t *testing.T) {
+	requestBody := "{\"a\": \"\x00\xff\xfe\"}"
+	responseBody := "\x89PNG\r\n\x1a\n\x00\x00"
+
+	waf := NewWAF()
+	waf.RequestBodyAccess = true
+	waf.ResponseBodyAccess = true
+	waf.ResponseBodyMimeTypes = []string{"image/png"}
+	tx := waf.NewTransaction()
+	tx.AddRequestHeader("Content-Type", "application/json")
+	tx.ProcessRequestHeaders()
+	tx.variables.reqbodyProcessor.Set("JSON")
+	if _, _, err := tx.WriteRequestBody([]byte(requestBody)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ProcessRequestBody(); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := requestBody, tx.variables.requestBodyRaw.Get(); want != have {
+		t.Errorf("unexpected REQUEST_BODY_RAW, want %q, have %q", want, have)
+	}
+	if tx.variables.requestBody.Get() != "" {
+		t.Errorf("unexpected REQUEST_BODY %q", tx.variables.requestBody.Get())
+	}
+
+	tx.AddResponseHeader("Content-Type", "image/png")
+	tx.ProcessResponseHeaders(200, "HTTP/1.1")
+	if _, _, err := tx.WriteResponseBody([]byte(responseBody)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ProcessResponseBody(); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := responseBody, tx.variables.responseBodyRaw.Get(); want != have {
+		t.Errorf("unexpected RESPONSE_BODY_RAW, want %q, have %q", want, have)
+	}
+
+	// the cached value is refreshed when the body is modified
+	if err := tx.AppendResponseBody([]byte("end")); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := responseBody+"end", tx.variables.responseBodyRaw.Get(); want != have {
+		t.Errorf("unexpected RESPONSE_BODY_RAW, want %q, have %q", want, have)
+	}
+
+	if err := tx.Close(); err != nil {
+		t.Fatalf("Failed to close transaction: %s", err.Error())
+	}
+	if tx.variables.requestBodyRaw.Get() != "" {
+		t.Error("expected REQUEST_BODY_RAW to be empty after closing the transaction")
+	}
+}
+
 func TestTxProcessConnection(t *testing.T) {
 	waf := NewWAF()
 	tx := waf.NewTransaction()
@@ -1275,6 +1764,65 @@ func TestTxSetServerName(t *testing.T) {
 	}
 }
 
+func TestTxAddWebserverErrorLog(t *testing.T) {
+	logBuffer := &bytes.Buffer{}
+
+	waf := NewWAF()
+	waf.SetDebugLogOutput(logBuffer)
+	_ = waf.SetDebugLogLevel(debuglog.LevelWarn)
+
+	tx := waf.NewTransaction()
+	tx.AddWebserverErrorLog("first entry")
+	tx.AddWebserverErrorLog("second entry")
+	if want, have := []string{"first entry", "second entry"}, tx.variables.webserverErrorLog.Get(""); !reflect.DeepEqual(want, have) {
+		t.Fatalf("unexpected error log entries, want %v, have %v", want, have)
+	}
+	if logBuffer.Len() != 0 {
+		t.Fatalf("unexpected log entries: %s", logBuffer.String())
+	}
+
+	tx.lastPhase = types.PhaseLogging
+	tx.AddWebserverErrorLog("late entry")
+	if want, have := "AddWebserverErrorLog has been called after ProcessLogging", logBuffer.String(); !strings.Contains(have, want) {
+		t.Fatalf("unexpected message, want %q, have %q", want, have)
+	}
+
+	if err := tx.Close(); err != nil {
+		t.Fatalf("Failed to close transaction: %s", err.Error())
+	}
+}
+
+func TestTxAddTrailers(t *testing.T) {
+	logBuffer := &bytes.Buffer{}
+
+	waf := NewWAF()
+	waf.SetDebugLogOutput(logBuffer)
+	_ = waf.SetDebugLogLevel(debuglog.LevelWarn)
+
+	tx := waf.NewTransaction()
+	tx.AddRequestTrailer("Grpc-Status", "0")
+	tx.AddResponseTrailer("Grpc-Message", "ok")
+	if want, have := []string{"0"}, tx.variables.requestTrailers.Get("grpc-status"); !reflect.DeepEqual(want, have) {
+		t.Fatalf("unexpected request trailers, want %v, have %v", want, have)
+	}
+	if want, have := []string{"ok"}, tx.variables.responseTrailers.Get("grpc-message"); !reflect.DeepEqual(want, have) {
+		t.Fatalf("unexpected response trailers, want %v, have %v", want, have)
+	}
+	if logBuffer.Len() != 0 {
+		t.Fatalf("unexpected log entries: %s", logBuffer.String())
+	}
+
+	tx.lastPhase = types.PhaseLogging
+	tx.AddResponseTrailer("Late", "1")
+	if want, have := "AddResponseTrailer has been called after ProcessLogging", logBuffer.String(); !strings.Contains(have, want) {
+		t.Fatalf("unexpected message, want %q, have %q", want, have)
+	}
+
+	if err := tx.Close(); err != nil {
+		t.Fatalf("Failed to close transaction: %s", err.Error())
+	}
+}
+
 func TestTxAddArgument(t *testing.T) {
 	waf := NewWAF()
 	tx := waf.NewTransaction()
@@ -1330,9 +1878,12 @@ func TestTxProcessURI(t *testing.T) {
 	tx := waf.NewTransaction()
 	uri := "http://example.com/path/to/file.html?query=string&other=value"
 	tx.ProcessURI(uri, "GET", "HTTP/1.1")
-	if s := tx.variables.requestURI.Get(); s != uri {
+	if s := tx.variables.requestURI.Get(); s != "/path/to/file.html?query=string&other=value" {
 		t.Fatalf("failed to set request uri, got %s", s)
 	}
+	if s := tx.variables.requestURIRaw.Get(); s != uri {
+		t.Fatalf("failed to set raw request uri, got %s", s)
+	}
 	if s := tx.variables.requestBasename.Get(); s != "file.html" {
 		t.Fatalf("failed to set request path, got %s", s)
 	}
@@ -1351,6 +1902,111 @@ func TestTxProcessURI(t *testing.T) {
 	}
 }
 
+func TestTxProcessURIRawAndNormalized(t *testing.T) {
+	tests := map[string]struct {
+		uri         string
+		requestURI  string
+		requestFile string
+	}{
+		"origin form": {
+			uri:         "/a%20b/%2e%2e/c?x=%3Cscript%3E",
+			requestURI:  "/a%20b/%2e%2e/c?x=%3Cscript%3E",
+			requestFile: "/a b/../c",
+		},
+		"absolute form": {
+			uri:         "http://example.com/a%2Fb?x=1",
+			requestURI:  "/a%2Fb?x=1",
+			requestFile: "/a/b",
+		},
+		"fragment": {
+			uri:         "/a?x=1#%3Cfrag%3E",
+			requestURI:  "/a?x=1",
+			requestFile: "/a",
+		},
+		"empty query": {
+			uri:         "/a?",
+			requestURI:  "/a?",
+			requestFile: "/a",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			waf := NewWAF()
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.ProcessURI(tc.uri, "GET", "HTTP/1.1")
+			if want, have := tc.uri, tx.variables.requestURIRaw.Get(); want != have {
+				t.Errorf("unexpected REQUEST_URI_RAW, want %q, have %q", want, have)
+			}
+			if want, have := tc.requestURI, tx.variables.requestURI.Get(); want != have {
+				t.Errorf("unexpected REQUEST_URI, want %q, have %q", want, have)
+			}
+			if want, have := tc.requestFile, tx.variables.requestFilename.Get(); want != have {
+				t.Errorf("unexpected REQUEST_FILENAME, want %q, have %q", want, have)
+			}
+		})
+	}
+}
+
+func TestQueryStringStrict(t *testing.T) {
+	queries := map[string]bool{
+		"a=1&b=2":  false,
+		"a=1&&b=2": true,
+		"=noval":   true,
+		"key":      true,
+	}
+
+	for _, strict := range []bool{false, true} {
+		for query, malformed := range queries {
+			t.Run(fmt.Sprintf("%s strict %t", query, strict), func(t *testing.T) {
+				waf := NewWAF()
+				waf.QueryStringStrict = strict
+				tx := waf.NewTransaction()
+				defer tx.Close()
+				tx.ProcessURI("/?"+query, "GET", "HTTP/1.1")
+
+				want := strict && malformed
+				if have := len(tx.variables.tx.Get(queryStrictErrorKey)) > 0; want != have {
+					t.Errorf("unexpected TX:%s, want %t, have %t", queryStrictErrorKey, want, have)
+				}
+				if len(tx.variables.argsGet.FindAll()) == 0 {
+					t.Error("expected the query to be parsed")
+				}
+			})
+		}
+	}
+}
+
+func TestTxProcessURIRequestLine(t *testing.T) {
+	tests := []struct {
+		method, uri, protocol string
+	}{
+		{"GET", "/index.php?a=1", "HTTP/1.1"},
+		{"PATCH", "/api/items/1?x=%3Cb%3E", "HTTP/1.1"},
+		{"PROPFIND", "/dav/", "HTTP/1.0"},
+		{"OPTIONS", "*", "HTTP/2.0"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			waf := NewWAF()
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.ProcessURI(tc.uri, tc.method, tc.protocol)
+			if want, have := tc.method+" "+tc.uri+" "+tc.protocol, tx.variables.requestLine.Get(); want != have {
+				t.Errorf("unexpected REQUEST_LINE, want %q, have %q", want, have)
+			}
+			if want, have := tc.method, tx.variables.requestMethod.Get(); want != have {
+				t.Errorf("unexpected REQUEST_METHOD, want %q, have %q", want, have)
+			}
+			if want, have := tc.protocol, tx.variables.requestProtocol.Get(); want != have {
+				t.Errorf("unexpected REQUEST_PROTOCOL, want %q, have %q", want, have)
+			}
+		})
+	}
+}
+
 func BenchmarkTransactionCreation(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		makeTransaction(b)
@@ -1710,6 +2366,126 @@ func TestAddResponseArgsWithOverlimit(t *testing.T) {
 	}
 }
 
+func TestArgumentLimitAction(t *testing.T) {
+	for name, action := range map[string]types.BodyLimitAction{
+		"ProcessPartial": types.BodyLimitActionProcessPartial,
+		"Reject":         types.BodyLimitActionReject,
+	} {
+		newTx := func(t *testing.T) *Transaction {
+			waf := NewWAF()
+			waf.ArgumentLimit = 2
+			waf.ArgumentLimitAction = action
+			waf.RequestBodyAccess = true
+			tx := waf.NewTransaction()
+			t.Cleanup(func() { tx.Close() })
+			return tx
+		}
+		checkLimit := func(t *testing.T, tx *Transaction, it *types.Interruption, args *collections.NamedCollection) {
+			t.Helper()
+			if want, have := "1", tx.variables.argsLimitExceeded.Get(); want != have {
+				t.Errorf("unexpected ARGS_LIMIT_EXCEEDED, want %q, have %q", want, have)
+			}
+			if action == types.BodyLimitActionReject {
+				if it == nil || it.Status != 400 {
+					t.Errorf("expected the transaction to be rejected, have %v", it)
+				}
+				return
+			}
+			if it != nil {
+				t.Errorf("unexpected interruption %v", it)
+			}
+			if want, have := 2, args.Len(); want != have {
+				t.Errorf("unexpected number of arguments, want %d, have %d", want, have)
+			}
+		}
+
+		t.Run(name+" get", func(t *testing.T) {
+			tx := newTx(t)
+			tx.ProcessURI("/?a=1&b=2&c=3&d=4", "GET", "HTTP/1.1")
+			checkLimit(t, tx, tx.ProcessRequestHeaders(), tx.variables.argsGet)
+		})
+
+		t.Run(name+" urlencoded body", func(t *testing.T) {
+			tx := newTx(t)
+			tx.AddRequestHeader("Content-Type", "application/x-www-form-urlencoded")
+			tx.ProcessRequestHeaders()
+			if _, _, err := tx.WriteRequestBody([]byte("d=4&c=3&b=2&a=1")); err != nil {
+				t.Fatal(err)
+			}
+			it, err := tx.ProcessRequestBody()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkLimit(t, tx, it, tx.variables.argsPost)
+			if action == types.BodyLimitActionProcessPartial {
+				// the first arguments in lexical order are kept
+				if tx.variables.argsPost.Get("a") == nil || tx.variables.argsPost.Get("b") == nil {
+					t.Errorf("unexpected arguments kept %v", tx.variables.argsPost.Data())
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionLimits(t *testing.T) {
+	waf := NewWAF()
+	waf.ArgumentLimit = 10
+	waf.RequestHeadersLimit = 10
+	waf.RequestCookiesLimit = 10
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	for _, v := range []*collections.Single{tx.variables.argsLimitExceeded, tx.variables.requestHeadersLimitExceeded, tx.variables.requestCookiesLimitExceeded} {
+		if want, have := "0", v.Get(); want != have {
+			t.Errorf("unexpected default value for %s, want %q, have %q", v.Name(), want, have)
+		}
+	}
+
+	cookies := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		cookies = append(cookies, fmt.Sprintf("c%d=v", i))
+	}
+	tx.AddRequestHeader("Cookie", strings.Join(cookies, "; "))
+	for i := 0; i < 1000; i++ {
+		tx.AddRequestHeader(fmt.Sprintf("X-Header-%d", i), "value")
+		tx.AddGetRequestArgument(fmt.Sprintf("get%d", i), "value")
+		tx.AddPostRequestArgument(fmt.Sprintf("post%d", i), "value")
+	}
+
+	for _, tc := range []struct {
+		col     *collections.NamedCollection
+		flag    *collections.Single
+		allowed int
+	}{
+		{tx.variables.requestHeaders, tx.variables.requestHeadersLimitExceeded, 10},
+		{tx.variables.requestCookies, tx.variables.requestCookiesLimitExceeded, 10},
+		{tx.variables.argsGet, tx.variables.argsLimitExceeded, 10},
+		{tx.variables.argsPost, tx.variables.argsLimitExceeded, 10},
+	} {
+		if want, have := tc.allowed, tc.col.Len(); want != have {
+			t.Errorf("unexpected number of %s, want %d, have %d", tc.col.Name(), want, have)
+		}
+		if want, have := "1", tc.flag.Get(); want != have {
+			t.Errorf("unexpected %s, want %q, have %q", tc.flag.Name(), want, have)
+		}
+	}
+}
+
+func TestCollectionLimitsDisabled(t *testing.T) {
+	waf := NewWAF()
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	for i := 0; i < 2000; i++ {
+		tx.AddRequestHeader(fmt.Sprintf("X-Header-%d", i), "value")
+	}
+	if want, have := 2000, tx.variables.requestHeaders.Len(); want != have {
+		t.Errorf("unexpected number of headers, want %d, have %d", want, have)
+	}
+	if want, have := "0", tx.variables.requestHeadersLimitExceeded.Get(); want != have {
+		t.Errorf("unexpected REQUEST_HEADERS_LIMIT_EXCEEDED, want %q, have %q", want, have)
+	}
+}
+
 func TestResponseBodyForceProcessing(t *testing.T) {
 	waf := NewWAF()
 	waf.ResponseBodyAccess = true
@@ -1863,3 +2639,75 @@ func TestRequestFilename(t *testing.T) {
 		})
 	}
 }
+
+func TestInterruptDefaultBlockPage(t *testing.T) {
+	testCases := map[string]struct {
+		html, json          string
+		accept              string
+		action              string
+		expectedContentType string
+		expectedBody        string
+	}{
+		"no block page": {
+			action: "deny",
+		},
+		"html": {
+			html:                "<h1>blocked</h1>",
+			json:                `{"error":"blocked"}`,
+			accept:              "text/html",
+			action:              "deny",
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<h1>blocked</h1>",
+		},
+		"json accepted": {
+			html:                "<h1>blocked</h1>",
+			json:                `{"error":"blocked"}`,
+			accept:              "application/json, text/plain",
+			action:              "deny",
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"blocked"}`,
+		},
+		"only json": {
+			json:                `{"error":"blocked"}`,
+			accept:              "text/html",
+			action:              "deny",
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"blocked"}`,
+		},
+		"not a deny": {
+			html:   "<h1>blocked</h1>",
+			action: "drop",
+		},
+	}
+
+	for name, tCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			waf := NewWAF()
+			waf.DefaultBlockPageHTML = tCase.html
+			waf.DefaultBlockPageJSON = tCase.json
+			tx := waf.NewTransaction()
+			tx.AddRequestHeader("Accept", tCase.accept)
+			tx.Interrupt(&types.Interruption{Action: tCase.action, Status: 403})
+
+			it := tx.Interruption()
+			if want, have := tCase.expectedContentType, it.ContentType; want != have {
+				t.Errorf("unexpected content type, want %q, have %q", want, have)
+			}
+			if want, have := tCase.expectedBody, it.Body; want != have {
+				t.Errorf("unexpected body, want %q, have %q", want, have)
+			}
+		})
+	}
+}
+
+func TestInterruptIgnoredInLoggingPhase(t *testing.T) {
+	waf := NewWAF()
+	waf.RuleEngine = types.RuleEngineOn
+	tx := waf.NewTransaction()
+	tx.ProcessLogging()
+
+	tx.Interrupt(&types.Interruption{Action: "deny", Status: 403})
+	if tx.Interruption() != nil {
+		t.Error("expected the interruption to be ignored in the logging phase")
+	}
+}
@@ -12,12 +12,14 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	stdsync "sync"
 	"time"
 
 	"github.com/corazawaf/coraza/v3/debuglog"
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	"github.com/corazawaf/coraza/v3/internal/auditlog"
 	"github.com/corazawaf/coraza/v3/internal/environment"
+	"github.com/corazawaf/coraza/v3/internal/persistence"
 	stringutils "github.com/corazawaf/coraza/v3/internal/strings"
 	"github.com/corazawaf/coraza/v3/internal/sync"
 	"github.com/corazawaf/coraza/v3/types"
@@ -71,7 +73,8 @@ type WAF struct {
 	// Instructs the waf to change the Server response header
 	ServerSignature string
 
-	// This directory will be used to store page files
+	// This directory will be used to store temporary files, e.g. request bodies
+	// beyond the in memory limit
 	TmpDir string
 
 	// Sensor ID identifies the sensor in ac cluster
@@ -80,6 +83,20 @@ type WAF struct {
 	// Path to store data files (ex. cache)
 	DataDir string
 
+	// GeoDB is the database used by @geoLookup, loaded with SecGeoLookupDb
+	GeoDB plugintypes.GeoDatabase
+
+	// DNSResolver is the resolver used by @rbl, nil means net.DefaultResolver
+	DNSResolver plugintypes.DNSResolver
+
+	// DNSTimeout bounds the DNS queries of each @rbl evaluation, 0 means the
+	// default of 500ms
+	DNSTimeout time.Duration
+
+	// Persistence stores the persistent collections initialized with initcol,
+	// they are kept in memory by default, see SecPersistenceEngine
+	Persistence plugintypes.PersistenceEngine
+
 	// If true, the WAF will store the uploaded files in the UploadDir
 	// directory
 	UploadKeepFiles bool
@@ -111,6 +128,10 @@ type WAF struct {
 	// Used for the debug logger
 	Logger debuglog.Logger
 
+	// If true and the debug level is enabled, the output of each transformation
+	// applied to a matched value is written to the debug log
+	DebugLogTransformations bool
+
 	ErrorLogCb func(rule types.MatchedRule)
 
 	// Audit mode status
@@ -133,13 +154,76 @@ type WAF struct {
 
 	auditLogWriterInitialized bool
 
+	// auditLogWriterMu guards the lazy initialization of the audit log writer
+	// by concurrent transactions
+	auditLogWriterMu stdsync.Mutex
+
 	// Configures the maximum number of ARGS that will be accepted for processing.
 	ArgumentLimit int
+
+	// ArgumentLimitAction is what happens once ArgumentLimit is reached: the
+	// arguments over the limit are ignored (ProcessPartial) or the transaction
+	// is interrupted with status 400 (Reject).
+	ArgumentLimitAction types.BodyLimitAction
+
+	// Configures the maximum number of request headers that will be accepted for
+	// processing, 0 means no limit.
+	RequestHeadersLimit int
+
+	// Configures the maximum number of request cookies that will be accepted for
+	// processing, 0 means no limit.
+	RequestCookiesLimit int
+
+	// DefaultBlockPageHTML and DefaultBlockPageJSON are the response bodies attached
+	// to deny interruptions, JSON is preferred when the client accepts it.
+	DefaultBlockPageHTML string
+	DefaultBlockPageJSON string
+
+	// If true, malformed query string parameters set TX:query_strict_error, they are
+	// parsed the same way regardless of this setting
+	QueryStringStrict bool
+
+	// If true, a transformation returning an error sets TX:transformation_error,
+	// the failing transformation is skipped regardless of this setting
+	TransformationErrorFlag bool
+
+	// If true, REMOTE_HOST is populated with the reverse DNS of the client address,
+	// otherwise it holds the client address
+	HostnameLookups bool
+
+	// RuleEvaluationTimeout is the wall-clock budget of the evaluation of the rules
+	// of each phase, the evaluation is aborted once it is exceeded. 0 means no limit.
+	RuleEvaluationTimeout time.Duration
+
+	// AnomalyScoreThresholds are checked at the end of the request body and
+	// response body phases, the transaction is denied if any is reached
+	AnomalyScoreThresholds []AnomalyScoreThreshold
+
+	// sampler is the random source of the sampling decisions, e.g. whether
+	// rules using the sample action are evaluated
+	sampler sampler
+
+	// matchEvents delivers rule matches to the consumers registered with Subscribe
+	matchEvents matchEventBus
+
+	// ruleMatchCallback is called synchronously for every rule match
+	ruleMatchCallback func(MatchEvent)
+
+	// tracer traces the evaluation of the phases, nil if tracing is disabled
+	tracer plugintypes.Tracer
+
+	// disabledRules are the rules disabled at runtime with SetRuleEnabled
+	disabledRules disabledRules
+
+	// hostnameLookup resolves REMOTE_HOST when HostnameLookups is enabled
+	hostnameLookup hostnameLookup
 }
 
 // Options is used to pass options to the WAF instance
 type Options struct {
-	ID      string
+	ID string
+	// Context is the context of the transaction, the evaluation of the rules
+	// of a phase is aborted and the transaction interrupted once it is done.
 	Context context.Context
 }
 
@@ -188,8 +272,18 @@ func (w *WAF) newTransaction(opts Options) *Transaction {
 	tx.HashEnforcement = false
 	tx.lastPhase = 0
 	tx.ruleRemoveByID = nil
+	tx.persistentRecords = nil
 	tx.ruleRemoveTargetByID = map[int][]ruleVariableParams{}
 	tx.Skip = 0
+	tx.Pause = 0
+	tx.sanitisedArgs = sanitisedTarget{}
+	tx.sanitisedRequestHeaders = sanitisedTarget{}
+	tx.sanitisedResponseHeaders = sanitisedTarget{}
+	tx.matchOffset = sanitisedBytes{}
+	tx.phaseSpanCtx = nil
+	tx.phaseDeadline = time.Time{}
+	tx.evaluationErr = nil
+	tx.abortedEvaluations = nil
 	tx.AllowType = 0
 	tx.Capture = false
 	tx.stopWatches = map[types.RulePhase]int64{}
@@ -221,7 +315,10 @@ func (w *WAF) newTransaction(opts Options) *Transaction {
 		})
 
 		tx.variables = *NewTransactionVariables()
+		tx.variables.requestBodyRaw.buffer = tx.requestBodyBuffer
+		tx.variables.responseBodyRaw.buffer = tx.responseBodyBuffer
 		tx.transformationCache = map[transformationKey]*transformationValue{}
+		tx.detectionCache = map[detectionKey]detectionValue{}
 	}
 
 	// set capture variables
@@ -235,7 +332,22 @@ func (w *WAF) newTransaction(opts Options) *Transaction {
 	tx.variables.urlencodedError.Set("0")
 	tx.variables.fullRequestLength.Set("0")
 	tx.variables.multipartDataAfter.Set("0")
+	tx.variables.multipartStrictError.Set("0")
+	tx.variables.multipartBoundaryQuoted.Set("0")
+	tx.variables.multipartBoundaryWhitespace.Set("0")
+	tx.variables.multipartCrlfLfLines.Set("0")
+	tx.variables.multipartDataBefore.Set("0")
+	tx.variables.multipartHeaderFolding.Set("0")
+	tx.variables.multipartInvalidHeaderFolding.Set("0")
+	tx.variables.multipartInvalidPart.Set("0")
+	tx.variables.multipartInvalidQuoting.Set("0")
+	tx.variables.multipartLfLine.Set("0")
+	tx.variables.multipartMissingSemicolon.Set("0")
+	tx.variables.multipartUnmatchedBoundary.Set("0")
 	tx.variables.outboundDataError.Set("0")
+	tx.variables.argsLimitExceeded.Set("0")
+	tx.variables.requestHeadersLimitExceeded.Set("0")
+	tx.variables.requestCookiesLimitExceeded.Set("0")
 	tx.variables.reqbodyError.Set("0")
 	tx.variables.reqbodyProcessorError.Set("0")
 	tx.variables.requestBodyLength.Set("0")
@@ -313,6 +425,7 @@ func NewWAF() *WAF {
 		AuditLogFormat: "Native",
 		Logger:         logger,
 		ArgumentLimit:  1000,
+		Persistence:    persistence.NewMemory(nil),
 	}
 
 	if environment.HasAccessToFS {
@@ -337,18 +450,32 @@ func (w *WAF) SetDebugLogLevel(lvl debuglog.Level) error {
 	return nil
 }
 
+// RuleStats returns the number of times each rule matched since the WAF was
+// created, by rule id. Rules that never matched are included with a count of 0,
+// which helps identifying dormant rules.
+func (w *WAF) RuleStats() map[int]uint64 {
+	return w.Rules.Stats()
+}
+
 // SetAuditLogWriter sets the audit log writer
 func (w *WAF) SetAuditLogWriter(alw plugintypes.AuditLogWriter) {
+	w.auditLogWriterMu.Lock()
+	defer w.auditLogWriterMu.Unlock()
 	w.auditLogWriter = alw
 }
 
 // AuditLogWriter returns the audit log writer. If the writer is not initialized,
 // it will be initialized
 func (w *WAF) AuditLogWriter() plugintypes.AuditLogWriter {
+	w.auditLogWriterMu.Lock()
+	defer w.auditLogWriterMu.Unlock()
 	if !w.auditLogWriterInitialized {
 		if err := w.auditLogWriter.Init(w.AuditLogWriterConfig); err != nil {
 			w.Logger.Error().Err(err).Msg("Failed to initialize audit log")
 		}
+		// the writer is not initialized again, e.g. reopening the log file, for
+		// every transaction if it fails
+		w.auditLogWriterInitialized = true
 	}
 
 	return w.auditLogWriter
@@ -358,6 +485,8 @@ func (w *WAF) AuditLogWriter() plugintypes.AuditLogWriter {
 // initialized, it will return an error as initializing the audit log writer twice
 // seems to be a bug.
 func (w *WAF) InitAuditLogWriter() error {
+	w.auditLogWriterMu.Lock()
+	defer w.auditLogWriterMu.Unlock()
 	if w.auditLogWriterInitialized {
 		return errors.New("audit log writer already initialized")
 	}
@@ -418,5 +547,13 @@ func (w *WAF) Validate() error {
 		return errors.New("argument limit should be bigger than 0")
 	}
 
-	return nil
+	if w.RequestHeadersLimit < 0 {
+		return errors.New("request headers limit should not be negative")
+	}
+
+	if w.RequestCookiesLimit < 0 {
+		return errors.New("request cookies limit should not be negative")
+	}
+
+	return w.Rules.ValidateMarkers()
 }
@@ -6,7 +6,11 @@ package corazawaf
 import (
 	"io"
 	"os"
+	"sync"
 	"testing"
+
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/corazawaf/coraza/v3/types/variables"
 )
 
 func TestNewTransaction(t *testing.T) {
@@ -105,6 +109,35 @@ func TestValidate(t *testing.T) {
 			expectErr:  true,
 			customizer: func(w *WAF) { w.ArgumentLimit = -1 },
 		},
+		"request headers limit less than 0": {
+			expectErr:  true,
+			customizer: func(w *WAF) { w.RequestHeadersLimit = -1 },
+		},
+		"request cookies limit less than 0": {
+			expectErr:  true,
+			customizer: func(w *WAF) { w.RequestCookiesLimit = -1 },
+		},
+		"skipAfter marker found": {
+			expectErr: false,
+			customizer: func(w *WAF) {
+				r := NewRule()
+				r.ID_ = 1
+				r.SkipAfter = "END"
+				_ = w.Rules.Add(r)
+				m := NewRule()
+				m.SecMark_ = "END"
+				_ = w.Rules.Add(m)
+			},
+		},
+		"skipAfter marker not found": {
+			expectErr: true,
+			customizer: func(w *WAF) {
+				r := NewRule()
+				r.ID_ = 1
+				r.SkipAfter = "MISSING"
+				_ = w.Rules.Add(r)
+			},
+		},
 	}
 
 	for name, tCase := range testCases {
@@ -124,3 +157,45 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleStats(t *testing.T) {
+	waf := NewWAF()
+	for id, arg := range map[int]string{1: "q", 2: "p", 3: "never"} {
+		rule := NewRule()
+		rule.ID_ = id
+		rule.Phase_ = types.PhaseRequestHeaders
+		if err := rule.AddVariable(variables.ArgsGet, arg, false); err != nil {
+			t.Fatal(err)
+		}
+		rule.SetOperator(&dummyEqOperator{}, "@eq", "0")
+		if err := waf.Rules.Add(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx := waf.NewTransaction()
+			tx.AddGetRequestArgument("q", "0")
+			if i%2 == 0 {
+				tx.AddGetRequestArgument("p", "0")
+			}
+			tx.ProcessRequestHeaders()
+			_ = tx.Close()
+		}(i)
+	}
+	wg.Wait()
+
+	stats := waf.RuleStats()
+	for id, want := range map[int]uint64{1: 10, 2: 5, 3: 0} {
+		if have, ok := stats[id]; !ok || have != want {
+			t.Errorf("unexpected hits for rule %d, want %d, have %d", id, want, have)
+		}
+	}
+	if len(stats) != 3 {
+		t.Errorf("unexpected number of rules in stats, want 3, have %d", len(stats))
+	}
+}
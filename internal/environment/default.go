@@ -27,3 +27,12 @@ func IsDirWritable(dir string) error {
 	}()
 	return nil
 }
+
+// CreateTemp creates a new temporary file in dir, or in the default directory
+// for temporary files if dir is empty. The file is created exclusively and is
+// only readable and writable by the owner. Callers are in charge of removing it.
+// Every temporary file written by Coraza, e.g. spilled over bodies or uploaded
+// files, must be created through it.
+func CreateTemp(dir, pattern string) (*os.File, error) {
+	return os.CreateTemp(dir, pattern)
+}
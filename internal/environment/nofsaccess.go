@@ -6,6 +6,11 @@
 
 package environment
 
+import (
+	"errors"
+	"os"
+)
+
 var HasAccessToFS = false
 
 // IsDirWritable is a helper function to check if the WAF has access to the filesystem
@@ -13,3 +18,8 @@ var HasAccessToFS = false
 func IsDirWritable(dir string) error {
 	panic("Unexpected call to IsDirWritable with no_fs_access build tag")
 }
+
+// CreateTemp always fails as there is no access to the filesystem
+func CreateTemp(dir, pattern string) (*os.File, error) {
+	return nil, errors.New("unexpected creation of a temporary file with no_fs_access build tag")
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package geo implements the geolocation database used by @geoLookup on top of
+// MaxMind DB files, e.g. GeoLite2-Country.mmdb or GeoLite2-City.mmdb.
+package geo
+
+import (
+	"net"
+	"net/netip"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+// record holds the fields read from GeoIP2 and GeoLite2 Country and City
+// databases, Country databases have no city and location.
+type record struct {
+	Continent struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"continent"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+// Database is a MaxMind DB held in memory, the reader does not modify it after
+// it is opened, so lookups are safe for concurrent use.
+type Database struct {
+	reader *maxminddb.Reader
+}
+
+var _ plugintypes.GeoDatabase = (*Database)(nil)
+
+// FromBytes opens the database from the content of a MaxMind DB file.
+func FromBytes(data []byte) (*Database, error) {
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Database{reader: reader}, nil
+}
+
+// Lookup returns the location of the address, names are read in English.
+func (d *Database) Lookup(ip netip.Addr) (plugintypes.GeoLocation, bool) {
+	var r record
+	_, ok, err := d.reader.LookupNetwork(net.IP(ip.Unmap().AsSlice()), &r)
+	if err != nil || !ok {
+		return plugintypes.GeoLocation{}, false
+	}
+	return plugintypes.GeoLocation{
+		CountryCode:   r.Country.ISOCode,
+		CountryName:   r.Country.Names["en"],
+		ContinentCode: r.Continent.Code,
+		City:          r.City.Names["en"],
+		Latitude:      r.Location.Latitude,
+		Longitude:     r.Location.Longitude,
+	}, true
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package geo
+
+import (
+	"net/netip"
+	"os"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+// The test database contains:
+//   - 81.2.69.0/24: London, United Kingdom
+//   - 216.160.83.56/29: Milton, United States
+//   - 89.160.20.112/28: Sweden, without city nor location
+//   - 2001:218::/32: Japan, without city
+func TestLookup(t *testing.T) {
+	data, err := os.ReadFile("testdata/GeoLite2-City-Test.mmdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := FromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]plugintypes.GeoLocation{
+		"81.2.69.142": {
+			CountryCode: "GB", CountryName: "United Kingdom", ContinentCode: "EU",
+			City: "London", Latitude: 51.5142, Longitude: -0.0931,
+		},
+		"::ffff:216.160.83.60": {
+			CountryCode: "US", CountryName: "United States", ContinentCode: "NA",
+			City: "Milton", Latitude: 47.2513, Longitude: -122.3149,
+		},
+		"89.160.20.120": {
+			CountryCode: "SE", CountryName: "Sweden", ContinentCode: "EU",
+		},
+		"2001:218:1::1": {
+			CountryCode: "JP", CountryName: "Japan", ContinentCode: "AS",
+			Latitude: 35.68536, Longitude: 139.75309,
+		},
+	}
+	for ip, want := range tests {
+		have, ok := db.Lookup(netip.MustParseAddr(ip))
+		if !ok {
+			t.Errorf("expected %s to be found", ip)
+			continue
+		}
+		if want != have {
+			t.Errorf("unexpected location for %s, want %+v, have %+v", ip, want, have)
+		}
+	}
+
+	for _, ip := range []string{"127.0.0.1", "81.2.70.1", "2001:219::1"} {
+		if _, ok := db.Lookup(netip.MustParseAddr(ip)); ok {
+			t.Errorf("unexpected location for %s", ip)
+		}
+	}
+}
+
+func TestFromBytesInvalid(t *testing.T) {
+	if _, err := FromBytes([]byte("not a database")); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package lua
+
+import (
+	glua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/ast"
+)
+
+// concatFunction is the global replacing the concatenation operator, the
+// name isn't a valid identifier so scripts can't refer to it.
+const concatFunction = "#concat"
+
+// boundConcat replaces the concatenation operators of the statements with
+// calls to concatFunction, gopher-lua has no hook to bound the size of the
+// strings they build.
+func boundConcat(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			boundConcatExprs(s.Lhs)
+			boundConcatExprs(s.Rhs)
+		case *ast.LocalAssignStmt:
+			boundConcatExprs(s.Exprs)
+		case *ast.FuncCallStmt:
+			s.Expr = boundConcatExpr(s.Expr)
+		case *ast.DoBlockStmt:
+			boundConcat(s.Stmts)
+		case *ast.WhileStmt:
+			s.Condition = boundConcatExpr(s.Condition)
+			boundConcat(s.Stmts)
+		case *ast.RepeatStmt:
+			s.Condition = boundConcatExpr(s.Condition)
+			boundConcat(s.Stmts)
+		case *ast.IfStmt:
+			s.Condition = boundConcatExpr(s.Condition)
+			boundConcat(s.Then)
+			boundConcat(s.Else)
+		case *ast.NumberForStmt:
+			s.Init = boundConcatExpr(s.Init)
+			s.Limit = boundConcatExpr(s.Limit)
+			s.Step = boundConcatExpr(s.Step)
+			boundConcat(s.Stmts)
+		case *ast.GenericForStmt:
+			boundConcatExprs(s.Exprs)
+			boundConcat(s.Stmts)
+		case *ast.FuncDefStmt:
+			boundConcat(s.Func.Stmts)
+		case *ast.ReturnStmt:
+			boundConcatExprs(s.Exprs)
+		}
+	}
+}
+
+func boundConcatExprs(exprs []ast.Expr) {
+	for i, expr := range exprs {
+		exprs[i] = boundConcatExpr(expr)
+	}
+}
+
+func boundConcatExpr(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.StringConcatOpExpr:
+		call := &ast.FuncCallExpr{
+			Func:      &ast.IdentExpr{Value: concatFunction},
+			Args:      []ast.Expr{boundConcatExpr(e.Lhs), boundConcatExpr(e.Rhs)},
+			AdjustRet: true,
+		}
+		call.SetLine(e.Line())
+		call.SetLastLine(e.LastLine())
+		call.Func.SetLine(e.Line())
+		call.Func.SetLastLine(e.LastLine())
+		return call
+	case *ast.AttrGetExpr:
+		e.Object = boundConcatExpr(e.Object)
+		e.Key = boundConcatExpr(e.Key)
+	case *ast.TableExpr:
+		for _, f := range e.Fields {
+			f.Key = boundConcatExpr(f.Key)
+			f.Value = boundConcatExpr(f.Value)
+		}
+	case *ast.FuncCallExpr:
+		e.Func = boundConcatExpr(e.Func)
+		e.Receiver = boundConcatExpr(e.Receiver)
+		boundConcatExprs(e.Args)
+	case *ast.LogicalOpExpr:
+		e.Lhs = boundConcatExpr(e.Lhs)
+		e.Rhs = boundConcatExpr(e.Rhs)
+	case *ast.RelationalOpExpr:
+		e.Lhs = boundConcatExpr(e.Lhs)
+		e.Rhs = boundConcatExpr(e.Rhs)
+	case *ast.ArithmeticOpExpr:
+		e.Lhs = boundConcatExpr(e.Lhs)
+		e.Rhs = boundConcatExpr(e.Rhs)
+	case *ast.UnaryMinusOpExpr:
+		e.Expr = boundConcatExpr(e.Expr)
+	case *ast.UnaryNotOpExpr:
+		e.Expr = boundConcatExpr(e.Expr)
+	case *ast.UnaryLenOpExpr:
+		e.Expr = boundConcatExpr(e.Expr)
+	case *ast.FunctionExpr:
+		boundConcat(e.Stmts)
+	}
+	return expr
+}
+
+// concat implements the concatenation operator, refusing to build strings
+// over maxStringSize. Operands other than strings and numbers are delegated
+// to their __concat metamethod.
+func concat(L *glua.LState) int {
+	lhs, rhs := L.Get(1), L.Get(2)
+	if !glua.LVCanConvToString(lhs) || !glua.LVCanConvToString(rhs) {
+		mm := L.GetMetaField(lhs, "__concat")
+		if mm == glua.LNil {
+			mm = L.GetMetaField(rhs, "__concat")
+		}
+		if mm == glua.LNil {
+			invalid := lhs
+			if glua.LVCanConvToString(lhs) {
+				invalid = rhs
+			}
+			L.RaiseError("cannot perform concat operation between %s and %s", lhs.Type().String(), invalid.Type().String())
+			return 0
+		}
+		L.Push(mm)
+		L.Push(lhs)
+		L.Push(rhs)
+		L.Call(2, 1)
+		return 1
+	}
+	l, r := glua.LVAsString(lhs), glua.LVAsString(rhs)
+	if len(l)+len(r) > maxStringSize {
+		L.RaiseError("concatenation result exceeds %d bytes", maxStringSize)
+		return 0
+	}
+	L.Push(glua.LString(l + r))
+	return 1
+}
+
+// boundTableConcat wraps the table.concat function of gopher-lua, refusing
+// to build strings over maxStringSize.
+func boundTableConcat(tableConcat glua.LGFunction) glua.LGFunction {
+	return func(L *glua.LState) int {
+		tbl := L.CheckTable(1)
+		sep := L.OptString(2, "")
+		size := 0
+		for i, j := max(L.OptInt(3, 1), 1), min(L.OptInt(4, tbl.Len()), tbl.Len()); i <= j; i++ {
+			size += len(glua.LVAsString(tbl.RawGetInt(i))) + len(sep)
+			if size > maxStringSize+len(sep) {
+				L.RaiseError("table.concat result exceeds %d bytes", maxStringSize)
+				return 0
+			}
+		}
+		return tableConcat(L)
+	}
+}
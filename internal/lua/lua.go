@@ -0,0 +1,311 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package lua runs the Lua scripts of the rules created with SecRuleScript.
+//
+// Scripts are compiled once, when the rules are loaded, and every evaluation
+// runs in a new sandboxed Lua state which only has the base, string, table
+// and math libraries, without the functions loading code or files. Loading
+// and evaluating a script are aborted after 100ms, and the strings built by
+// the concatenation operator, string.rep and table.concat are limited to
+// 1MiB. The script must define a main function, it receives the transaction:
+//
+//	function main(tx)
+//	    local id = tx.getvar("ARGS:id")
+//	    if id ~= nil and tonumber(id) == nil then
+//	        return true, "Non numeric id: " .. id
+//	    end
+//	    return false
+//	end
+//
+// The transaction exposes:
+//   - getvar(name) returns the first value of a variable, e.g. "REMOTE_ADDR",
+//     "ARGS:id" or "tx.anomaly_score", nil if it is not set.
+//   - getvars(name) returns a list of {name = ..., value = ...} tables with all
+//     the values of a collection, e.g. "ARGS" or "REQUEST_HEADERS:host".
+//   - setvar(name, value) sets a variable of TX or of a persistent collection,
+//     e.g. "tx.score", a nil value removes it.
+//   - log(msg) writes a message to the debug log.
+//
+// main returns true, optionally followed by a message, when the rule matches.
+// For compatibility with ModSecurity scripts, the transaction is also
+// available as the global m and returning a string is a match with that
+// message.
+package lua
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	glua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+
+	"github.com/corazawaf/coraza/v3/collection"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/types/variables"
+)
+
+const mainFunction = "main"
+
+const (
+	// scriptTimeout is the maximum duration of an evaluation, a script running
+	// longer is aborted and its rule doesn't match
+	scriptTimeout = 100 * time.Millisecond
+	// callStackSize and registryMaxSize bound the depth of the calls and the
+	// number of values on the stack of a script
+	callStackSize   = 200
+	registryMaxSize = 64 * 1024
+	// maxStringSize is the maximum size of the strings built by the
+	// concatenation operator, string.rep and table.concat
+	maxStringSize = 1024 * 1024
+)
+
+// unsafeGlobals are the functions of the base library removed from the
+// sandbox as they load code or files.
+var unsafeGlobals = []string{
+	"dofile", "load", "loadfile", "loadstring", "module", "require",
+	"getfenv", "setfenv", "_printregs",
+}
+
+// Script is a compiled Lua script, it is safe for concurrent use.
+type Script struct {
+	name  string
+	proto *glua.FunctionProto
+}
+
+var _ corazawaf.RuleScript = (*Script)(nil)
+
+// Compile compiles the source of a script, name is used in the error
+// messages. It fails if the script doesn't define a main function or if
+// running its chunk takes longer than scriptTimeout.
+func Compile(name string, src string) (*Script, error) {
+	chunk, err := parse.Parse(strings.NewReader(src), name)
+	if err != nil {
+		return nil, err
+	}
+	boundConcat(chunk)
+	proto, err := glua.Compile(chunk, name)
+	if err != nil {
+		return nil, err
+	}
+	s := &Script{name: name, proto: proto}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+	defer cancel()
+	L := newState(ctx)
+	defer L.Close()
+	if _, err := s.load(L); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Evaluate runs the main function of the script for the transaction. Errors
+// raised by the script, including panics, the cancellation of ctx and
+// exceeding the limits of the sandbox, are returned without affecting the
+// transaction. The evaluation is aborted after scriptTimeout.
+func (s *Script) Evaluate(ctx context.Context, tx *corazawaf.Transaction) (match bool, msg string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			match, msg, err = false, "", fmt.Errorf("lua script %s panicked: %v", s.name, r)
+		}
+	}()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, scriptTimeout)
+	defer cancel()
+	L := newState(ctx)
+	defer L.Close()
+	L.SetGlobal("print", L.NewFunction(func(L *glua.LState) int {
+		args := make([]string, 0, L.GetTop())
+		for i := 1; i <= L.GetTop(); i++ {
+			args = append(args, L.ToStringMeta(L.Get(i)).String())
+		}
+		tx.DebugLogger().Info().Str("script", s.name).Msg(strings.Join(args, "\t"))
+		return 0
+	}))
+
+	main, err := s.load(L)
+	if err != nil {
+		return false, "", err
+	}
+	txTable := newTransactionTable(L, s.name, tx)
+	L.SetGlobal("m", txTable)
+	if err := L.CallByParam(glua.P{Fn: main, NRet: 2, Protect: true}, txTable); err != nil {
+		return false, "", err
+	}
+	ret, retMsg := L.Get(-2), L.Get(-1)
+	L.Pop(2)
+
+	switch v := ret.(type) {
+	case glua.LBool:
+		if retMsg != glua.LNil {
+			msg = L.ToStringMeta(retMsg).String()
+		}
+		return bool(v), msg, nil
+	case glua.LString:
+		return true, string(v), nil
+	}
+	return false, "", nil
+}
+
+// load runs the chunk of the script in L, defining its globals, and returns
+// the main function.
+func (s *Script) load(L *glua.LState) (*glua.LFunction, error) {
+	L.Push(L.NewFunctionFromProto(s.proto))
+	if err := L.PCall(0, glua.MultRet, nil); err != nil {
+		return nil, err
+	}
+	main, ok := L.GetGlobal(mainFunction).(*glua.LFunction)
+	if !ok {
+		return nil, fmt.Errorf("lua script %s doesn't define a %s function", s.name, mainFunction)
+	}
+	return main, nil
+}
+
+// newState returns a Lua state without access to the filesystem, the
+// network or the process, with bounded stacks.
+func newState(ctx context.Context) *glua.LState {
+	L := glua.NewState(glua.Options{
+		SkipOpenLibs:    true,
+		CallStackSize:   callStackSize,
+		RegistryMaxSize: registryMaxSize,
+	})
+	for _, lib := range []struct {
+		name string
+		fn   glua.LGFunction
+	}{
+		{glua.BaseLibName, glua.OpenBase},
+		{glua.TabLibName, glua.OpenTable},
+		{glua.StringLibName, glua.OpenString},
+		{glua.MathLibName, glua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.fn))
+		L.Push(glua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range unsafeGlobals {
+		L.SetGlobal(name, glua.LNil)
+	}
+	if strlib, ok := L.GetGlobal(glua.StringLibName).(*glua.LTable); ok {
+		strlib.RawSetString("rep", L.NewFunction(strRep))
+	}
+	if tablib, ok := L.GetGlobal(glua.TabLibName).(*glua.LTable); ok {
+		if fn, ok := tablib.RawGetString("concat").(*glua.LFunction); ok && fn.IsG {
+			tablib.RawSetString("concat", L.NewFunction(boundTableConcat(fn.GFunction)))
+		}
+	}
+	L.SetGlobal(concatFunction, L.NewFunction(concat))
+	L.SetContext(ctx)
+	return L
+}
+
+// strRep replaces string.rep, refusing to build strings over maxStringSize
+// which would exhaust the memory of the process.
+func strRep(L *glua.LState) int {
+	str := L.CheckString(1)
+	n := L.CheckInt(2)
+	if n <= 0 {
+		L.Push(glua.LString(""))
+		return 1
+	}
+	if len(str) > 0 && n > maxStringSize/len(str) {
+		L.RaiseError("string.rep result exceeds %d bytes", maxStringSize)
+		return 0
+	}
+	L.Push(glua.LString(strings.Repeat(str, n)))
+	return 1
+}
+
+// newTransactionTable returns the table exposing the transaction to the
+// script.
+func newTransactionTable(L *glua.LState, name string, tx *corazawaf.Transaction) *glua.LTable {
+	t := L.NewTable()
+	L.SetFuncs(t, map[string]glua.LGFunction{
+		"getvar": func(L *glua.LState) int {
+			v, key := variableArg(L)
+			switch col := tx.Collection(v).(type) {
+			case collection.Single:
+				if key == "" {
+					L.Push(glua.LString(col.Get()))
+					return 1
+				}
+			case collection.Keyed:
+				if values := col.Get(key); key != "" && len(values) > 0 {
+					L.Push(glua.LString(values[0]))
+					return 1
+				}
+			}
+			L.Push(glua.LNil)
+			return 1
+		},
+		"getvars": func(L *glua.LState) int {
+			v, key := variableArg(L)
+			col := tx.Collection(v)
+			res := L.NewTable()
+			if col == nil {
+				L.Push(res)
+				return 1
+			}
+			matches := col.FindAll()
+			if keyed, ok := col.(collection.Keyed); ok && key != "" {
+				matches = keyed.FindString(key)
+			}
+			for _, md := range matches {
+				entry := L.NewTable()
+				entryName := md.Variable().Name()
+				if md.Key() != "" {
+					entryName += ":" + md.Key()
+				}
+				entry.RawSetString("name", glua.LString(entryName))
+				entry.RawSetString("value", glua.LString(md.Value()))
+				res.Append(entry)
+			}
+			L.Push(res)
+			return 1
+		},
+		"setvar": func(L *glua.LState) int {
+			v, key := variableArg(L)
+			col, ok := tx.Collection(v).(collection.Map)
+			if !ok || key == "" || (v != variables.TX && !corazawaf.IsPersistentCollection(v)) {
+				L.ArgError(1, "not a writable variable")
+				return 0
+			}
+			if value := L.Get(2); value == glua.LNil {
+				col.Remove(key)
+			} else {
+				col.Set(key, []string{L.ToStringMeta(value).String()})
+			}
+			if corazawaf.IsPersistentCollection(v) {
+				if err := tx.PersistVariable(v, key); err != nil {
+					L.RaiseError("failed to persist %s: %s", L.CheckString(1), err.Error())
+				}
+			}
+			return 0
+		},
+		"log": func(L *glua.LState) int {
+			tx.DebugLogger().Info().Str("script", name).Msg(L.CheckString(1))
+			return 0
+		},
+	})
+	return t
+}
+
+// variableArg parses the variable name of the first argument, the collection
+// and the key are separated by a colon or a dot, e.g. ARGS:id or tx.score.
+func variableArg(L *glua.LState) (variables.RuleVariable, string) {
+	name := L.CheckString(1)
+	colName, key, _ := strings.Cut(name, ":")
+	if !strings.Contains(name, ":") {
+		colName, key, _ = strings.Cut(name, ".")
+	}
+	v, err := variables.Parse(colName)
+	if err != nil {
+		L.ArgError(1, fmt.Sprintf("unknown variable %q", colName))
+	}
+	return v, key
+}
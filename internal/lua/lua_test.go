@@ -0,0 +1,186 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package lua
+
+import (
+	"context"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+func newTransaction(t *testing.T) *corazawaf.Transaction {
+	t.Helper()
+	tx := corazawaf.NewWAF().NewTransaction()
+	tx.ProcessURI("/login?user=admin&user=root", "GET", "HTTP/1.1")
+	tx.AddRequestHeader("Host", "example.com")
+	t.Cleanup(func() { tx.Close() })
+	return tx
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		match  bool
+		msg    string
+		assert func(t *testing.T, tx *corazawaf.Transaction)
+	}{
+		{
+			name: "no match",
+			src:  `function main(tx) return false end`,
+		},
+		{
+			name:  "match with message",
+			src:   `function main(tx) return tx.getvar("REQUEST_HEADERS:host") == "example.com", "host" end`,
+			match: true,
+			msg:   "host",
+		},
+		{
+			name:  "string is a match",
+			src:   `function main() return "matched " .. m.getvar("REQUEST_FILENAME") end`,
+			match: true,
+			msg:   "matched /login",
+		},
+		{
+			name: "concatenation",
+			src: `local t = setmetatable({}, {__concat = function(a, b) return "t" .. b end})
+				function main(tx) return true, t .. 1 .. "-" .. table.concat({"a", "b"}, ",") end`,
+			match: true,
+			msg:   "t1-a,b",
+		},
+		{
+			name: "unset variable",
+			src:  `function main(tx) return tx.getvar("ARGS:missing") ~= nil end`,
+		},
+		{
+			name:  "getvars",
+			src:   `function main(tx) local v = tx.getvars("ARGS_GET:user") return #v == 2 and v[2].value == "root", v[1].name end`,
+			match: true,
+			msg:   "ARGS_GET:user",
+		},
+		{
+			name: "setvar",
+			src: `function main(tx)
+				tx.setvar("tx.score", 5)
+				tx.setvar("tx.removed", nil)
+				return false
+			end`,
+			assert: func(t *testing.T, tx *corazawaf.Transaction) {
+				if have := tx.Variables().TX().Get("score"); len(have) != 1 || have[0] != "5" {
+					t.Errorf("unexpected TX:score, have %q", have)
+				}
+				if have := tx.Variables().TX().Get("removed"); len(have) != 0 {
+					t.Errorf("unexpected TX:removed, have %q", have)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Compile(tc.name, tc.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx := newTransaction(t)
+			tx.Variables().TX().Set("removed", []string{"1"})
+			match, msg, err := s.Evaluate(context.Background(), tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match != tc.match || msg != tc.msg {
+				t.Errorf("unexpected result, want (%t, %q), have (%t, %q)", tc.match, tc.msg, match, msg)
+			}
+			if tc.assert != nil {
+				tc.assert(t, tx)
+			}
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := map[string]string{
+		"runtime error":     `function main(tx) return nil + 1 end`,
+		"unknown variable":  `function main(tx) return tx.getvar("NOT_A_VARIABLE") end`,
+		"readonly variable": `function main(tx) tx.setvar("ARGS:user", "x") end`,
+		"not initialized":   `function main(tx) tx.setvar("ip.blocked", "1") end`,
+		"infinite loop":     `function main(tx) while true do end end`,
+		"infinite recursion": `function f() return 1 + f() end
+			function main(tx) return f() end`,
+		"huge string": `function main(tx) return string.rep("a", 1024 * 1024 * 1024) end`,
+		"huge concatenation": `function main(tx)
+				local s = "a"
+				while true do s = s .. s end
+			end`,
+		"huge table.concat": `function main(tx)
+				local t = {string.rep("a", 1024 * 1024)}
+				return table.concat({t[1], t[1]})
+			end`,
+	}
+
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := Compile(name, src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			match, _, err := s.Evaluate(context.Background(), newTransaction(t))
+			if err == nil {
+				t.Error("expected error")
+			}
+			if match {
+				t.Error("unexpected match")
+			}
+		})
+	}
+
+	t.Run("canceled context", func(t *testing.T) {
+		s, err := Compile("loop", `function main(tx) while true do end end`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, _, err := s.Evaluate(ctx, newTransaction(t)); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestSandbox(t *testing.T) {
+	for _, name := range []string{"io", "os", "debug", "package", "dofile", "loadfile", "load", "loadstring", "require", "setfenv"} {
+		t.Run(name, func(t *testing.T) {
+			s, err := Compile(name, "function main(tx) return "+name+" ~= nil end")
+			if err != nil {
+				t.Fatal(err)
+			}
+			match, _, err := s.Evaluate(context.Background(), newTransaction(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match {
+				t.Errorf("%s is available to the scripts", name)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := map[string]string{
+		"syntax error":  `function main(tx) return`,
+		"missing main":  `x = 1`,
+		"main not func": `main = 1`,
+		"chunk error":   `error("boom") function main() end`,
+		"chunk loop":    `while true do end function main() end`,
+		"chunk concat":  `local s = "a" while true do s = s .. s end function main() end`,
+	}
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Compile(name, src); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
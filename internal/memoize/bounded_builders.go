@@ -0,0 +1,13 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build memoize_builders
+
+package memoize
+
+// DoBounded executes and returns the results of the given function, unless there
+// was a cached value of the same key. With the memoize_builders build tag it is
+// the same as Do, all the values are kept.
+func DoBounded(key string, fn func() (interface{}, error)) (interface{}, error) {
+	return Do(key, fn)
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !memoize_builders
+
+package memoize
+
+import (
+	"container/list"
+	"sync"
+)
+
+// boundedCacheSize is the maximum number of values kept by DoBounded, it is
+// well above the number of distinct regular expressions of the CRS.
+const boundedCacheSize = 4096
+
+var boundedCache = newLRU(boundedCacheSize)
+
+// DoBounded executes and returns the results of the given function, unless there
+// was a cached value of the same key. Unlike Do, the cache is enabled without the
+// memoize_builders build tag, it is bounded to the least recently used values so
+// reloading a WAF doesn't grow it. It is meant for values cheap to keep compared
+// to building them, like compiled regular expressions.
+func DoBounded(key string, fn func() (interface{}, error)) (interface{}, error) {
+	if value, found := boundedCache.get(key); found {
+		return value, nil
+	}
+	// concurrent calls for the same key may build the value more than once,
+	// all of them get the cached one
+	value, err := fn()
+	if err != nil {
+		return value, err
+	}
+	return boundedCache.add(key, value), nil
+}
+
+// lru is a cache safe for concurrent use evicting the least recently used values
+// once it reaches its capacity
+type lru struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value interface{}
+}
+
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *lru) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, found := c.items[key]
+	if !found {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+// add stores the value unless the key is already cached, it returns the cached value
+func (c *lru) add(key string, value interface{}) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, found := c.items[key]; found {
+		c.order.MoveToFront(e)
+		return e.Value.(*lruEntry).value
+	}
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+	return value
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !memoize_builders
+
+package memoize
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestLRUEviction(t *testing.T) {
+	c := newLRU(2)
+	c.add("a", 1)
+	c.add("b", 2)
+	// a becomes the most recently used value, b is evicted
+	if _, found := c.get("a"); !found {
+		t.Fatal("expected a to be cached")
+	}
+	c.add("c", 3)
+	if _, found := c.get("b"); found {
+		t.Error("expected b to be evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		value, found := c.get(key)
+		if !found {
+			t.Fatalf("expected %s to be cached", key)
+		}
+		if have := value.(int); want != have {
+			t.Errorf("unexpected value for %s, want %d, have %d", key, want, have)
+		}
+	}
+	if want, have := 2, c.order.Len(); want != have {
+		t.Errorf("unexpected number of values, want %d, have %d", want, have)
+	}
+}
+
+func TestLRUAddKeepsCachedValue(t *testing.T) {
+	c := newLRU(2)
+	if want, have := 1, c.add("a", 1).(int); want != have {
+		t.Errorf("unexpected value, want %d, have %d", want, have)
+	}
+	if want, have := 1, c.add("a", 2).(int); want != have {
+		t.Errorf("unexpected value, want %d, have %d", want, have)
+	}
+}
+
+func TestDoBounded(t *testing.T) {
+	calls := 0
+	fn := func() (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("failed")
+		}
+		return calls, nil
+	}
+	key := t.Name()
+	// errors are not cached
+	if _, err := DoBounded(key, fn); err == nil {
+		t.Fatal("expected error")
+	}
+	for i := 0; i < 2; i++ {
+		value, err := DoBounded(key, fn)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if want, have := 2, value.(int); want != have {
+			t.Errorf("unexpected value, want %d, have %d", want, have)
+		}
+	}
+}
+
+func TestDoBoundedConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	results := make([][]interface{}, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("%s-%d", t.Name(), j)
+				value, _ := DoBounded(key, func() (interface{}, error) { return new(int), nil })
+				results[i] = append(results[i], value)
+			}
+		}(i)
+	}
+	wg.Wait()
+	// all the goroutines get the same value for a key
+	for i := 1; i < len(results); i++ {
+		for j := range results[i] {
+			if results[i][j] != results[0][j] {
+				t.Fatalf("unexpected value for key %d", j)
+			}
+		}
+	}
+}
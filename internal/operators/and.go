@@ -0,0 +1,41 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.and
+
+package operators
+
+import (
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+// and matches when all of its operators match the value, e.g.
+// "@and (@beginsWith /admin) (!@endsWith .css)". Operators are evaluated in
+// order and the evaluation stops at the first one not matching. It can be
+// combined with @or to express conditions like "(A and B) or C" within a rule.
+type and struct {
+	operators []subOperator
+}
+
+var _ plugintypes.Operator = (*and)(nil)
+
+func newAnd(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
+	ops, err := parseSubOperators(options)
+	if err != nil {
+		return nil, err
+	}
+	return &and{operators: ops}, nil
+}
+
+func (o *and) Evaluate(tx plugintypes.TransactionState, value string) bool {
+	for _, op := range o.operators {
+		if !op.evaluate(tx, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	Register("and", newAnd)
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.bytes
+
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+// bytesMatch looks for a byte pattern in the input, e.g. "89 50 4E 47 ?? ?? 1A 0A".
+// The pattern is a sequence of hexadecimal bytes, optionally separated by whitespace,
+// where ?? matches any byte. It is meant to inspect binary content like REQUEST_BODY_RAW.
+// When capturing, the offset of the first match is stored in TX.0.
+type bytesMatch struct {
+	pattern []byte
+	// wildcard marks the positions of pattern matching any byte
+	wildcard []bool
+	// anchor is the position of the first non wildcard byte, -1 if there is none
+	anchor int
+}
+
+var (
+	_ plugintypes.Operator = (*bytesMatch)(nil)
+	_ CaptureGroupsCounter = (*bytesMatch)(nil)
+)
+
+func newBytes(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
+	data := strings.Join(strings.Fields(options.Arguments), "")
+	if len(data) == 0 {
+		return nil, errors.New("empty byte pattern")
+	}
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("invalid byte pattern %q, odd number of digits", options.Arguments)
+	}
+
+	o := &bytesMatch{
+		pattern:  make([]byte, 0, len(data)/2),
+		wildcard: make([]bool, 0, len(data)/2),
+		anchor:   -1,
+	}
+	for i := 0; i < len(data); i += 2 {
+		token := data[i : i+2]
+		if token == "??" {
+			o.pattern = append(o.pattern, 0)
+			o.wildcard = append(o.wildcard, true)
+			continue
+		}
+		b, err := strconv.ParseUint(token, 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid byte %q in pattern", token)
+		}
+		if o.anchor == -1 {
+			o.anchor = len(o.pattern)
+		}
+		o.pattern = append(o.pattern, byte(b))
+		o.wildcard = append(o.wildcard, false)
+	}
+	return o, nil
+}
+
+func (o *bytesMatch) Evaluate(tx plugintypes.TransactionState, value string) bool {
+	offset := o.find(value)
+	if offset == -1 {
+		return false
+	}
+	if tx.Capturing() {
+		tx.CaptureField(0, strconv.Itoa(offset))
+	}
+	return true
+}
+
+// find returns the offset of the first match of the pattern in value, or -1
+func (o *bytesMatch) find(value string) int {
+	last := len(value) - len(o.pattern)
+	for i := 0; i <= last; i++ {
+		if o.anchor != -1 {
+			// jump to the next occurrence of the first fixed byte
+			next := strings.IndexByte(value[i+o.anchor:last+o.anchor+1], o.pattern[o.anchor])
+			if next == -1 {
+				return -1
+			}
+			i += next
+		}
+		if o.matchAt(value, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (o *bytesMatch) matchAt(value string, offset int) bool {
+	for j, b := range o.pattern {
+		if !o.wildcard[j] && value[offset+j] != b {
+			return false
+		}
+	}
+	return true
+}
+
+// CaptureGroups implements CaptureGroupsCounter, only TX.0 is populated
+func (o *bytesMatch) CaptureGroups() int {
+	return 0
+}
+
+func init() {
+	Register("bytes", newBytes)
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.bytes
+
+package operators
+
+import (
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+func TestBytesInvalidPatterns(t *testing.T) {
+	for _, pattern := range []string{"", "F", "FF 0", "GG", "FF ?X"} {
+		if _, err := newBytes(plugintypes.OperatorOptions{Arguments: pattern}); err == nil {
+			t.Errorf("expected error for pattern %q", pattern)
+		}
+	}
+}
+
+func TestBytesCapturesOffset(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		match   bool
+		offset  string
+	}{
+		{pattern: "FF ?? 00 1A", input: "\x01\x02\xff\x7f\x00\x1a\x03", match: true, offset: "2"},
+		{pattern: "ff??001a", input: "\xff\x00\x00\x1b\xff\xee\x00\x1a", match: true, offset: "4"},
+		{pattern: "?? 00", input: "\x00\x00\x00", match: true, offset: "0"},
+		{pattern: "?? ?? ??", input: "\x00\x00", match: false},
+		{pattern: "FF ?? 00 1A", input: "\xff\x00\x00", match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			op, err := newBytes(plugintypes.OperatorOptions{Arguments: tt.pattern})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			tx := getTransaction()
+			tx.Capture = true
+			if want, have := tt.match, op.Evaluate(tx, tt.input); want != have {
+				t.Fatalf("unexpected result for %q, want %t, have %t", tt.input, want, have)
+			}
+			if !tt.match {
+				return
+			}
+			if want, have := tt.offset, tx.Variables().TX().Get("0")[0]; want != have {
+				t.Errorf("unexpected captured offset, want %q, have %q", want, have)
+			}
+		})
+	}
+}
@@ -6,8 +6,6 @@
 package operators
 
 import (
-	"github.com/corazawaf/libinjection-go"
-
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 )
 
@@ -20,7 +18,17 @@ func newDetectSQLi(plugintypes.OperatorOptions) (plugintypes.Operator, error) {
 }
 
 func (o *detectSQLi) Evaluate(tx plugintypes.TransactionState, value string) bool {
-	res, fingerprint := libinjection.IsSQLi(value)
+	cache, cacheable := tx.(detectionCache)
+	res, fingerprint, cached := false, "", false
+	if cacheable {
+		res, fingerprint, cached = cache.CachedDetection("detectSQLi", value)
+	}
+	if !cached {
+		res, fingerprint = isSQLi(value)
+		if cacheable {
+			cache.CacheDetection("detectSQLi", value, res, fingerprint)
+		}
+	}
 	if !res {
 		return false
 	}
@@ -6,8 +6,6 @@
 package operators
 
 import (
-	"github.com/corazawaf/libinjection-go"
-
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 )
 
@@ -19,8 +17,18 @@ func newDetectXSS(plugintypes.OperatorOptions) (plugintypes.Operator, error) {
 	return &detectXSS{}, nil
 }
 
-func (o *detectXSS) Evaluate(_ plugintypes.TransactionState, value string) bool {
-	return libinjection.IsXSS(value)
+func (o *detectXSS) Evaluate(tx plugintypes.TransactionState, value string) bool {
+	cache, cacheable := tx.(detectionCache)
+	if cacheable {
+		if res, _, ok := cache.CachedDetection("detectXSS", value); ok {
+			return res
+		}
+	}
+	res := isXSS(value)
+	if cacheable {
+		cache.CacheDetection("detectXSS", value, res, "")
+	}
+	return res
 }
 
 func init() {
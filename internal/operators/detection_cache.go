@@ -0,0 +1,20 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package operators
+
+import "github.com/corazawaf/libinjection-go"
+
+// detectionCache is implemented by the transactions caching the results of
+// @detectSQLi and @detectXSS, libinjection runs once per value even if many
+// rules evaluate it.
+type detectionCache interface {
+	CachedDetection(operator string, value string) (match bool, fingerprint string, ok bool)
+	CacheDetection(operator string, value string, match bool, fingerprint string)
+}
+
+// libinjection functions, replaced in tests to count the calls
+var (
+	isSQLi = libinjection.IsSQLi
+	isXSS  = libinjection.IsXSS
+)
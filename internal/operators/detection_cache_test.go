@@ -0,0 +1,111 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.detectSQLi && !coraza.disabled_operators.detectXSS
+
+package operators
+
+import (
+	"testing"
+
+	"github.com/corazawaf/libinjection-go"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+// countLibinjectionCalls replaces the libinjection functions with ones
+// counting their calls until the test finishes.
+func countLibinjectionCalls(tb testing.TB) *int {
+	tb.Helper()
+	calls := 0
+	isSQLi = func(s string) (bool, string) {
+		calls++
+		return libinjection.IsSQLi(s)
+	}
+	isXSS = func(s string) bool {
+		calls++
+		return libinjection.IsXSS(s)
+	}
+	tb.Cleanup(func() {
+		isSQLi = libinjection.IsSQLi
+		isXSS = libinjection.IsXSS
+	})
+	return &calls
+}
+
+// uncachedTransaction hides the detection cache of the transaction
+type uncachedTransaction struct {
+	plugintypes.TransactionState
+}
+
+func TestDetectionCache(t *testing.T) {
+	calls := countLibinjectionCalls(t)
+	sqli := &detectSQLi{}
+	xss := &detectXSS{}
+	waf := corazawaf.NewWAF()
+
+	tx := waf.NewTransaction()
+	tx.Capture = true
+	for i := 0; i < 3; i++ {
+		if !sqli.Evaluate(tx, "' or ''='") {
+			t.Fatal("expected SQLi to be detected")
+		}
+		if fingerprint := tx.Variables().TX().Get("0"); len(fingerprint) != 1 || fingerprint[0] != "s&sos" {
+			t.Errorf("unexpected fingerprint, have %q", fingerprint)
+		}
+		if sqli.Evaluate(tx, "safe value") {
+			t.Fatal("unexpected SQLi detection")
+		}
+		if !xss.Evaluate(tx, "<script>alert(1)</script>") {
+			t.Fatal("expected XSS to be detected")
+		}
+	}
+	if *calls != 3 {
+		t.Errorf("unexpected libinjection calls, want 3, have %d", *calls)
+	}
+
+	// the cache is scoped to the transaction
+	_ = tx.Close()
+	tx = waf.NewTransaction()
+	defer tx.Close()
+	sqli.Evaluate(tx, "' or ''='")
+	if *calls != 4 {
+		t.Errorf("unexpected libinjection calls, want 4, have %d", *calls)
+	}
+}
+
+func BenchmarkDetectionCache(b *testing.B) {
+	const rules = 10
+	sqli := &detectSQLi{}
+	xss := &detectXSS{}
+	waf := corazawaf.NewWAF()
+	args := []string{
+		"ascii(substring(version() from 1 for 1))",
+		"<img src=x onerror=alert(1)>",
+		"a perfectly normal comment about the product",
+	}
+
+	for name, wrap := range map[string]func(*corazawaf.Transaction) plugintypes.TransactionState{
+		"cached":   func(tx *corazawaf.Transaction) plugintypes.TransactionState { return tx },
+		"uncached": func(tx *corazawaf.Transaction) plugintypes.TransactionState { return uncachedTransaction{tx} },
+	} {
+		b.Run(name, func(b *testing.B) {
+			calls := countLibinjectionCalls(b)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				tx := waf.NewTransaction()
+				state := wrap(tx)
+				// several rules detecting attacks in the same arguments
+				for r := 0; r < rules; r++ {
+					for _, arg := range args {
+						sqli.Evaluate(state, arg)
+						xss.Evaluate(state, arg)
+					}
+				}
+				_ = tx.Close()
+			}
+			b.ReportMetric(float64(*calls)/float64(b.N), "libinjection-calls/op")
+		})
+	}
+}
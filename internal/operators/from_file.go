@@ -5,14 +5,21 @@ package operators
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
-	"os"
 	"path"
+
+	"github.com/corazawaf/coraza/v3/internal/io"
 )
 
 var errEmptyDirs = errors.New("empty dirs")
 
+// loadFromFile reads filepath from root, the file system configured in the parser
+// (e.g. an embed.FS), relative paths are looked up in dirs in order.
 func loadFromFile(filepath string, dirs []string, root fs.FS) ([]byte, error) {
+	if root == nil {
+		root = io.OSFS{}
+	}
 	if path.IsAbs(filepath) {
 		return fs.ReadFile(root, filepath)
 	}
@@ -24,24 +31,20 @@ func loadFromFile(filepath string, dirs []string, root fs.FS) ([]byte, error) {
 	// handling files by operators is hard because we must know the paths where we can
 	// search, for example, the policy path or the binary path...
 	// CRS stores the .data files in the same directory as the directives
-	var (
-		content []byte
-		err     error
-	)
-
 	for _, p := range dirs {
 		absFilepath := path.Join(p, filepath)
-		content, err = fs.ReadFile(root, absFilepath)
+		content, err := fs.ReadFile(root, absFilepath)
 		if err != nil {
-			if os.IsNotExist(err) {
+			// file systems other than the OS one reject some paths, e.g. the absolute
+			// working directory, in that case the file is looked up in the next dir
+			if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
 				continue
-			} else {
-				return nil, err
 			}
+			return nil, err
 		}
 
 		return content, nil
 	}
 
-	return nil, err
+	return nil, fmt.Errorf("file %q not found in %q: %w", filepath, dirs, fs.ErrNotExist)
 }
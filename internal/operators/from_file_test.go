@@ -4,6 +4,8 @@
 package operators
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -71,10 +73,10 @@ func TestLoadFromFileRelativePath(t *testing.T) {
 }
 
 func TestLoadFromCustomFS(t *testing.T) {
-	fs := fstest.MapFS{}
-	fs["animals/bear.txt"] = &fstest.MapFile{Data: []byte("pooh"), Mode: 0755}
+	mapFS := fstest.MapFS{}
+	mapFS["animals/bear.txt"] = &fstest.MapFile{Data: []byte("pooh"), Mode: 0755}
 
-	content, err := loadFromFile("bear.txt", []string{"animals"}, fs)
+	content, err := loadFromFile("bear.txt", []string{"animals"}, mapFS)
 	if err != nil {
 		t.Errorf("failed to load from file: %s", err.Error())
 	}
@@ -83,3 +85,35 @@ func TestLoadFromCustomFS(t *testing.T) {
 		t.Errorf("unexpected content, want %q, have %q", want, have)
 	}
 }
+
+func TestLoadFromFileSkipsPathsInvalidInFS(t *testing.T) {
+	testDir, testFile := getTestFile(t)
+
+	// os.DirFS rejects rooted paths, like the working directory
+	content, err := loadFromFile(testFile, []string{"/does-not-exist", "."}, os.DirFS(testDir))
+	if err != nil {
+		t.Fatalf("failed to load from file: %s", err.Error())
+	}
+
+	if want, have := fileContent, string(content); want != have {
+		t.Errorf("unexpected content, want %q, have %q", want, have)
+	}
+
+	_, err = loadFromFile("non-existing-file", []string{"/does-not-exist", "."}, os.DirFS(testDir))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, have %v", err)
+	}
+}
+
+func TestLoadFromFileDefaultsToOSFS(t *testing.T) {
+	testDir, testFile := getTestFile(t)
+
+	content, err := loadFromFile(testFile, []string{testDir}, nil)
+	if err != nil {
+		t.Fatalf("failed to load from file: %s", err.Error())
+	}
+
+	if want, have := fileContent, string(content); want != have {
+		t.Errorf("unexpected content, want %q, have %q", want, have)
+	}
+}
@@ -6,11 +6,55 @@
 package operators
 
 import (
+	"net/netip"
+	"strconv"
+	"sync"
+
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 )
 
-func newGeoLookup(plugintypes.OperatorOptions) (plugintypes.Operator, error) {
-	return &unconditionalMatch{}, nil
+// geoLookup looks up the location of an IP address in the database configured
+// with SecGeoLookupDb. When the address is found it matches and the GEO
+// collection is populated with the location, so it can be used by the
+// following rules, e.g. GEO:COUNTRY_CODE.
+type geoLookup struct {
+	db plugintypes.GeoDatabase
+	// missingDBWarning is used to warn about the missing database only once
+	missingDBWarning sync.Once
+}
+
+var _ plugintypes.Operator = (*geoLookup)(nil)
+
+func newGeoLookup(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
+	return &geoLookup{db: options.GeoDB}, nil
+}
+
+func (o *geoLookup) Evaluate(tx plugintypes.TransactionState, value string) bool {
+	if o.db == nil {
+		o.missingDBWarning.Do(func() {
+			tx.DebugLogger().Warn().Msg("@geoLookup requires a database, configure one with SecGeoLookupDb")
+		})
+		return false
+	}
+
+	ip, err := netip.ParseAddr(value)
+	if err != nil {
+		return false
+	}
+	loc, ok := o.db.Lookup(ip.WithZone(""))
+	if !ok {
+		return false
+	}
+
+	// all the keys are set, so nothing of a previous lookup is kept
+	geo := tx.Variables().Geo()
+	geo.Set("COUNTRY_CODE", []string{loc.CountryCode})
+	geo.Set("COUNTRY_NAME", []string{loc.CountryName})
+	geo.Set("CONTINENT_CODE", []string{loc.ContinentCode})
+	geo.Set("CITY", []string{loc.City})
+	geo.Set("LATITUDE", []string{strconv.FormatFloat(loc.Latitude, 'f', -1, 64)})
+	geo.Set("LONGITUDE", []string{strconv.FormatFloat(loc.Longitude, 'f', -1, 64)})
+	return true
 }
 
 func init() {
@@ -0,0 +1,84 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.geoLookup
+
+package operators
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/debuglog"
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/internal/geo"
+)
+
+func TestGeoLookup(t *testing.T) {
+	data, err := os.ReadFile("../geo/testdata/GeoLite2-City-Test.mmdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := geo.FromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, err := newGeoLookup(plugintypes.OperatorOptions{GeoDB: db})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := corazawaf.NewWAF().NewTransaction()
+	if !op.Evaluate(tx, "81.2.69.142") {
+		t.Fatal("expected address to match")
+	}
+	want := map[string]string{
+		"COUNTRY_CODE":   "GB",
+		"COUNTRY_NAME":   "United Kingdom",
+		"CONTINENT_CODE": "EU",
+		"CITY":           "London",
+		"LATITUDE":       "51.5142",
+		"LONGITUDE":      "-0.0931",
+	}
+	for key, value := range want {
+		if have := tx.Variables().Geo().Get(key); len(have) != 1 || have[0] != value {
+			t.Errorf("unexpected GEO:%s, want %q, have %q", key, value, have)
+		}
+	}
+
+	// the city of the previous lookup is not kept
+	if !op.Evaluate(tx, "89.160.20.120") {
+		t.Fatal("expected address to match")
+	}
+	if have := tx.Variables().Geo().Get("CITY"); len(have) != 1 || have[0] != "" {
+		t.Errorf("unexpected GEO:CITY, have %q", have)
+	}
+
+	for _, value := range []string{"127.0.0.1", "not an ip", ""} {
+		if op.Evaluate(tx, value) {
+			t.Errorf("unexpected match for %q", value)
+		}
+	}
+}
+
+func TestGeoLookupWithoutDatabase(t *testing.T) {
+	logsBuf := &bytes.Buffer{}
+	waf := corazawaf.NewWAF()
+	waf.Logger = debuglog.Default().WithLevel(debuglog.LevelWarn).WithOutput(logsBuf)
+
+	op, err := newGeoLookup(plugintypes.OperatorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if op.Evaluate(waf.NewTransaction(), "81.2.69.142") {
+			t.Error("unexpected match without database")
+		}
+	}
+	if want, have := 1, strings.Count(logsBuf.String(), "SecGeoLookupDb"); want != have {
+		t.Errorf("unexpected number of warnings, want %d, have %d: %s", want, have, logsBuf.String())
+	}
+}
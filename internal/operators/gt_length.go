@@ -0,0 +1,38 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.gtLength
+
+package operators
+
+import (
+	"strconv"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/macro"
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+// gtLength matches when the length in bytes of the value is greater than the
+// argument, e.g. @gtLength 100. It spares chaining t:length with @gt.
+type gtLength struct {
+	data macro.Macro
+}
+
+var _ plugintypes.Operator = (*gtLength)(nil)
+
+func newGTLength(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
+	m, err := macro.NewMacro(options.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	return &gtLength{data: m}, nil
+}
+
+func (o *gtLength) Evaluate(tx plugintypes.TransactionState, value string) bool {
+	k, _ := strconv.Atoi(o.data.Expand(tx))
+	return len(value) > k
+}
+
+func init() {
+	Register("gtLength", newGTLength)
+}
@@ -7,13 +7,25 @@ package operators
 
 import (
 	"net"
+	"net/netip"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 )
 
+// ipMatch matches IP addresses against a list of addresses and CIDR ranges.
+// The ranges are sorted and merged when the operator is created, so an
+// address is looked up with a binary search, in O(log n) for n ranges.
 type ipMatch struct {
-	subnets []net.IPNet
+	// ranges are sorted by from and do not overlap
+	ranges []ipRange
+}
+
+// ipRange is an inclusive range of addresses of the same family
+type ipRange struct {
+	from, to netip.Addr
 }
 
 var _ plugintypes.Operator = (*ipMatch)(nil)
@@ -21,7 +33,7 @@ var _ plugintypes.Operator = (*ipMatch)(nil)
 func newIPMatch(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
 	data := options.Arguments
 
-	var subnets []net.IPNet
+	var ranges []ipRange
 	for _, sb := range strings.Split(data, ",") {
 		sb = strings.TrimSpace(sb)
 		if sb == "" {
@@ -38,19 +50,58 @@ func newIPMatch(options plugintypes.OperatorOptions) (plugintypes.Operator, erro
 		if err != nil {
 			continue
 		}
-		subnets = append(subnets, *subnet)
+		ranges = append(ranges, newIPRange(subnet))
 	}
-	return &ipMatch{subnets: subnets}, nil
+	return &ipMatch{ranges: mergeIPRanges(ranges)}, nil
 }
 
-func (o *ipMatch) Evaluate(tx plugintypes.TransactionState, value string) bool {
-	ip := net.ParseIP(value)
-	for _, subnet := range o.subnets {
-		if subnet.Contains(ip) {
-			return true
+func newIPRange(subnet *net.IPNet) ipRange {
+	first := subnet.IP.To16()
+	if len(subnet.Mask) == net.IPv4len {
+		first = subnet.IP.To4()
+	}
+	last := make(net.IP, len(first))
+	for i := range first {
+		last[i] = first[i] | ^subnet.Mask[i]
+	}
+	from, _ := netip.AddrFromSlice(first)
+	to, _ := netip.AddrFromSlice(last)
+	if from.Is4In6() && to.Is4In6() {
+		// IPv4-mapped IPv6 ranges are matched as IPv4 ones
+		from, to = from.Unmap(), to.Unmap()
+	}
+	return ipRange{from: from, to: to}
+}
+
+// mergeIPRanges sorts the ranges and merges the overlapping ones.
+func mergeIPRanges(ranges []ipRange) []ipRange {
+	slices.SortFunc(ranges, func(a, b ipRange) int {
+		return a.from.Compare(b.from)
+	})
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.from.Compare(merged[n-1].to) <= 0 {
+			if r.to.Compare(merged[n-1].to) > 0 {
+				merged[n-1].to = r.to
+			}
+			continue
 		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func (o *ipMatch) Evaluate(tx plugintypes.TransactionState, value string) bool {
+	ip, err := netip.ParseAddr(value)
+	if err != nil {
+		return false
 	}
-	return false
+	ip = ip.Unmap().WithZone("")
+	// i is the first range starting after ip, ip can only be in the previous one
+	i := sort.Search(len(o.ranges), func(i int) bool {
+		return o.ranges[i].from.Compare(ip) > 0
+	})
+	return i > 0 && ip.Compare(o.ranges[i-1].to) <= 0
 }
 
 func init() {
@@ -6,6 +6,7 @@ package operators
 import (
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	"github.com/corazawaf/coraza/v3/internal/io"
@@ -40,3 +41,23 @@ func TestFromFile(t *testing.T) {
 		})
 	}
 }
+
+func TestFromFileAlias(t *testing.T) {
+	opts := plugintypes.OperatorOptions{
+		Arguments: "ranges.dat",
+		Path:      []string{"etc/coraza"},
+		Root: fstest.MapFS{
+			"etc/coraza/ranges.dat": &fstest.MapFile{Data: []byte("# private\n10.0.0.0/8\n\n2001:db8::/32\n")},
+		},
+	}
+	ipm, err := Get("ipMatchF", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ipm.Evaluate(nil, "10.1.2.3") || !ipm.Evaluate(nil, "2001:db8::1") {
+		t.Error("expected addresses to match")
+	}
+	if ipm.Evaluate(nil, "11.0.0.1") {
+		t.Error("unexpected match")
+	}
+}
@@ -4,7 +4,8 @@
 package operators
 
 import (
-	_ "fmt"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
@@ -52,3 +53,65 @@ func TestMultipleAddress(t *testing.T) {
 		}
 	}
 }
+
+func TestIPMatchRanges(t *testing.T) {
+	opts := plugintypes.OperatorOptions{
+		// overlapping ranges are merged
+		Arguments: "10.0.0.0/8, 10.1.0.0/16, 11.0.0.0/8, 2001:db8::/32, 2001:db8::1, invalid, ::ffff:192.0.2.0/120",
+	}
+	ipm, err := newIPMatch(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 4, len(ipm.(*ipMatch).ranges); want != have {
+		t.Errorf("unexpected number of ranges, want %d, have %d", want, have)
+	}
+	tests := map[string]bool{
+		"10.0.0.0":         true,
+		"10.255.255.255":   true,
+		"11.1.2.3":         true,
+		"11.255.255.255":   true,
+		"12.0.0.0":         false,
+		"9.255.255.255":    false,
+		"::ffff:10.1.2.3":  true,
+		"192.0.2.10":       true,
+		"2001:db8::1234":   true,
+		"2001:db8:ffff::1": true,
+		"2001:db9::":       false,
+		"::a00:1":          false,
+		"not an ip":        false,
+		"fe80::1%eth0":     false,
+		"2001:db8::1%eth0": true,
+		"10.0.0.1/8":       false,
+		"":                 false,
+		"255.255.255.255":  false,
+		"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff": false,
+	}
+	for value, want := range tests {
+		if have := ipm.Evaluate(nil, value); want != have {
+			t.Errorf("unexpected result for %q, want %t, have %t", value, want, have)
+		}
+	}
+}
+
+// BenchmarkIPMatch shows the lookup time grows logarithmically with the
+// number of ranges.
+func BenchmarkIPMatch(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		var entries []string
+		for i := 0; i < n; i++ {
+			// every other /24 of 10.0.0.0/8 and beyond, so they are not merged
+			entries = append(entries, fmt.Sprintf("%d.%d.%d.0/24", 10+i>>15, (i>>7)&0xff, (i<<1)&0xff))
+		}
+		ipm, err := newIPMatch(plugintypes.OperatorOptions{Arguments: strings.Join(entries, ",")})
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("%d entries", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ipm.Evaluate(nil, "10.0.1.1")
+				ipm.Evaluate(nil, "200.1.2.3")
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.gtLength && !coraza.disabled_operators.ltLength
+
+package operators
+
+import (
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+func TestLengthMacroArgument(t *testing.T) {
+	tests := []struct {
+		operator string
+		value    string
+		want     bool
+	}{
+		{operator: "gtLength", value: "abcd", want: true},
+		{operator: "gtLength", value: "abc", want: false},
+		{operator: "ltLength", value: "ab", want: true},
+		{operator: "ltLength", value: "abc", want: false},
+	}
+
+	tx := getTransaction()
+	tx.Variables().TX().Set("max_length", []string{"3"})
+	for _, tt := range tests {
+		t.Run(tt.operator+" "+tt.value, func(t *testing.T) {
+			op, err := Get(tt.operator, plugintypes.OperatorOptions{Arguments: "%{tx.max_length}"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have := op.Evaluate(tx, tt.value); tt.want != have {
+				t.Errorf("unexpected result for %q, want %t, have %t", tt.value, tt.want, have)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+// subOperator is an operator combined by the logical operators @and and @or
+type subOperator struct {
+	operator plugintypes.Operator
+	negated  bool
+}
+
+func (o subOperator) evaluate(tx plugintypes.TransactionState, value string) bool {
+	return o.operator.Evaluate(tx, value) != o.negated
+}
+
+// parseSubOperators parses the arguments of a logical operator, a whitespace separated
+// list of parenthesized operators, e.g. "(@rx ^a) (!@streq b) (@or (@eq 1) (@eq 2))".
+// Parentheses within the operators must be balanced or escaped with a backslash.
+// As in rules, the operator defaults to @rx when the name is omitted.
+func parseSubOperators(options plugintypes.OperatorOptions) ([]subOperator, error) {
+	var ops []subOperator
+	data := strings.TrimSpace(options.Arguments)
+	for data != "" {
+		if data[0] != '(' {
+			return nil, fmt.Errorf("expected '(' at %q", data)
+		}
+		end := closingParenthesis(data)
+		if end < 0 {
+			return nil, fmt.Errorf("unbalanced parentheses in %q", data)
+		}
+		op, err := parseSubOperator(strings.TrimSpace(data[1:end]), options)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+		data = strings.TrimSpace(data[end+1:])
+	}
+	if len(ops) < 2 {
+		return nil, errors.New("at least two operators are expected")
+	}
+	return ops, nil
+}
+
+// closingParenthesis returns the index of the parenthesis closing the one at the
+// beginning of data, skipping escaped characters, or -1 if it is not closed.
+func closingParenthesis(data string) int {
+	depth := 0
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseSubOperator(expr string, options plugintypes.OperatorOptions) (subOperator, error) {
+	op := subOperator{}
+	if expr == "" {
+		return op, errors.New("empty operator")
+	}
+	if strings.HasPrefix(expr, "!") {
+		op.negated = true
+		expr = expr[1:]
+	}
+	if !strings.HasPrefix(expr, "@") {
+		expr = "@rx " + expr
+	}
+	name, arguments, _ := strings.Cut(expr[1:], " ")
+	if name == "" {
+		return op, errors.New("empty operator name")
+	}
+
+	options.Arguments = strings.TrimSpace(arguments)
+	var err error
+	if op.operator, err = Get(name, options); err != nil {
+		return op, err
+	}
+	return op, nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.and && !coraza.disabled_operators.or
+
+package operators
+
+import (
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+func TestLogicalOperatorsInvalidArguments(t *testing.T) {
+	for _, args := range []string{
+		"",
+		"(@rx a)",
+		"@rx a",
+		"(@rx a) @rx b",
+		"(@rx a) (@rx b",
+		"(@rx a) ()",
+		"(@rx a) (@unknown b)",
+		"(@rx a) (@rx [)",
+	} {
+		for name, factory := range map[string]plugintypes.OperatorFactory{"and": newAnd, "or": newOr} {
+			if _, err := factory(plugintypes.OperatorOptions{Arguments: args}); err == nil {
+				t.Errorf("expected error for @%s %q", name, args)
+			}
+		}
+	}
+}
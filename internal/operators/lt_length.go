@@ -0,0 +1,38 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.ltLength
+
+package operators
+
+import (
+	"strconv"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/macro"
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+// ltLength matches when the length in bytes of the value is lower than the
+// argument, e.g. @ltLength 100. It spares chaining t:length with @lt.
+type ltLength struct {
+	data macro.Macro
+}
+
+var _ plugintypes.Operator = (*ltLength)(nil)
+
+func newLTLength(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
+	m, err := macro.NewMacro(options.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	return &ltLength{data: m}, nil
+}
+
+func (o *ltLength) Evaluate(tx plugintypes.TransactionState, value string) bool {
+	k, _ := strconv.Atoi(o.data.Expand(tx))
+	return len(value) < k
+}
+
+func init() {
+	Register("ltLength", newLTLength)
+}
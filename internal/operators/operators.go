@@ -4,23 +4,115 @@
 package operators
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 )
 
-var operators = map[string]plugintypes.OperatorFactory{}
+var (
+	operatorsMu sync.RWMutex
+	operators   = map[string]plugintypes.OperatorFactory{}
+	// pluginOperators holds the names of the operators registered by plugins
+	pluginOperators = map[string]bool{}
+	// aliases maps the ModSecurity shorthands, e.g. pmf, to the name of the operator
+	// they stand for, so they resolve to whatever implementation is registered for it
+	aliases = map[string]string{}
+)
+
+// CaptureGroupsCounter is implemented by operators that know in advance how many
+// capture groups they populate when the rule has the capture action. It is used
+// to validate the capture references (e.g. %{tx.1}) of the rules at parse time.
+type CaptureGroupsCounter interface {
+	// CaptureGroups returns the highest TX index populated by the operator
+	// besides TX.0, which always holds the whole match.
+	CaptureGroups() int
+}
 
-// Get returns an operator by name
+// Get returns an operator by name or alias. Like in ModSecurity, names are
+// case-insensitive, e.g. @ipmatchf resolves to @ipMatchFromFile.
 func Get(name string, options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
-	if op, ok := operators[name]; ok {
+	operatorsMu.RLock()
+	op, ok := lookup(name)
+	operatorsMu.RUnlock()
+	if ok {
 		return op(options)
 	}
 	return nil, fmt.Errorf("operator %s not found", name)
 }
 
+// lookup resolves the operator by its exact name first, as registered, and then
+// case-insensitively. It must be called holding operatorsMu.
+func lookup(name string) (plugintypes.OperatorFactory, bool) {
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	if op, ok := operators[name]; ok {
+		return op, true
+	}
+	for alias, n := range aliases {
+		if strings.EqualFold(alias, name) {
+			name = n
+			break
+		}
+	}
+	for n, op := range operators {
+		if strings.EqualFold(n, name) {
+			return op, true
+		}
+	}
+	return nil, false
+}
+
 // Register registers a new operator
 // If the operator already exists it will be overwritten
 func Register(name string, op plugintypes.OperatorFactory) {
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
 	operators[name] = op
 }
+
+// registerAlias registers a shorthand for the operator with the given name
+func registerAlias(alias string, name string) {
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+	aliases[alias] = name
+}
+
+// RegisterPlugin registers an operator provided by a plugin. Built-in operators
+// can be replaced, but it fails if the name is empty, the factory is nil or
+// the name was already registered by a plugin.
+func RegisterPlugin(name string, op plugintypes.OperatorFactory) error {
+	if name == "" {
+		return errors.New("operator name is empty")
+	}
+	if op == nil {
+		return fmt.Errorf("operator %q has a nil factory", name)
+	}
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+	if pluginOperators[name] {
+		return fmt.Errorf("operator %q is already registered", name)
+	}
+	pluginOperators[name] = true
+	operators[name] = op
+	return nil
+}
+
+// List returns the sorted names of the registered operators, including aliases
+func List() []string {
+	operatorsMu.RLock()
+	defer operatorsMu.RUnlock()
+	names := make([]string, 0, len(operators)+len(aliases))
+	for name := range operators {
+		names = append(names, name)
+	}
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -4,11 +4,15 @@
 package operators
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/tidwall/gjson"
 
@@ -25,6 +29,84 @@ type Test struct {
 	Type  string
 }
 
+func TestList(t *testing.T) {
+	names := List()
+	if len(names) != len(operators)+len(aliases) {
+		t.Fatalf("unexpected number of operators, want %d, have %d", len(operators)+len(aliases), len(names))
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("expected sorted names, have %v", names)
+	}
+	for _, name := range []string{"eq", "pm", "rx", "streq", "pmf", "ipMatchF"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("expected %q to be listed", name)
+		}
+	}
+}
+
+func TestAliases(t *testing.T) {
+	root := fstest.MapFS{
+		"phrases.data": &fstest.MapFile{Data: []byte("attack\n10.0.0.0/8\n")},
+	}
+	opts := plugintypes.OperatorOptions{
+		Arguments: "phrases.data",
+		Path:      []string{"."},
+		Root:      root,
+	}
+
+	tests := []struct {
+		alias string
+		name  string
+		input string
+	}{
+		{"pmf", "pmFromFile", "an attack"},
+		{"ipMatchF", "ipMatchFromFile", "10.1.2.3"},
+		{"ipmatchf", "ipMatchFromFile", "10.1.2.3"},
+		{"PMF", "pmFromFile", "an attack"},
+		{"pmfromfile", "pmFromFile", "an attack"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.alias, func(t *testing.T) {
+			aliased, err := Get(tc.alias, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			op, err := Get(tc.name, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, have := fmt.Sprintf("%T", op), fmt.Sprintf("%T", aliased); want != have {
+				t.Errorf("unexpected implementation, want %s, have %s", want, have)
+			}
+			tx := corazawaf.NewWAF().NewTransaction()
+			if !aliased.Evaluate(tx, tc.input) {
+				t.Errorf("expected %q to match", tc.input)
+			}
+		})
+	}
+
+	t.Run("replaced operator", func(t *testing.T) {
+		operatorsMu.RLock()
+		original := operators["pmFromFile"]
+		operatorsMu.RUnlock()
+		defer Register("pmFromFile", original)
+
+		Register("pmFromFile", newUnconditionalMatch)
+		op, err := Get("pmf", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !op.Evaluate(corazawaf.NewWAF().NewTransaction(), "anything") {
+			t.Error("expected the alias to resolve to the replaced operator")
+		}
+	})
+
+	if _, err := Get("unknown", opts); err == nil {
+		t.Error("expected error for an unknown operator")
+	}
+}
+
 // https://github.com/SpiderLabs/secrules-language-tests/
 func TestOperators(t *testing.T) {
 	root := "./testdata"
@@ -64,7 +146,7 @@ func TestOperators(t *testing.T) {
 	for _, f := range files {
 		cases := unmarshalTests(t, f)
 		for _, data := range cases {
-			if utils.InSlice("containsWord", notImplemented) {
+			if utils.InSlice(data.Name, notImplemented) {
 				continue
 			}
 			for capName, capVal := range captureMatrix {
@@ -97,6 +179,10 @@ func TestOperators(t *testing.T) {
 					}
 					op, err := Get(data.Name, opts)
 					if err != nil {
+						if data.Param == "" {
+							// operators expanding macros reject empty arguments when the rule is parsed
+							t.Skipf("@%s does not accept an empty argument: %s", data.Name, err.Error())
+						}
 						t.Error(err)
 						return
 					}
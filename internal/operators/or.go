@@ -0,0 +1,40 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.or
+
+package operators
+
+import (
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+// or matches when any of its operators matches the value, e.g.
+// "@or (@and (@rx ^a) (@rx z$)) (@streq b)". Operators are evaluated in order
+// and the evaluation stops at the first one matching.
+type or struct {
+	operators []subOperator
+}
+
+var _ plugintypes.Operator = (*or)(nil)
+
+func newOr(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
+	ops, err := parseSubOperators(options)
+	if err != nil {
+		return nil, err
+	}
+	return &or{operators: ops}, nil
+}
+
+func (o *or) Evaluate(tx plugintypes.TransactionState, value string) bool {
+	for _, op := range o.operators {
+		if op.evaluate(tx, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	Register("or", newOr)
+}
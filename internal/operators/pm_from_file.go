@@ -45,11 +45,15 @@ func newPMFromFile(options plugintypes.OperatorOptions) (plugintypes.Operator, e
 		DFA:                  false,
 	})
 
-	m, _ := memoize.Do(strings.Join(options.Path, ",")+filepath, func() (interface{}, error) { return builder.Build(lines), nil })
+	// the matcher is shared by the operators with the same phrases, the path is not a
+	// valid key as it can resolve to different files, e.g. with a different root
+	key := "pmFromFile\x00" + strings.Join(lines, "\x00")
+	m, _ := memoize.Do(key, func() (interface{}, error) { return builder.Build(lines), nil })
 
 	return &pm{matcher: m.(ahocorasick.AhoCorasick)}, nil
 }
 
 func init() {
 	Register("pmFromFile", newPMFromFile)
+	registerAlias("pmf", "pmFromFile")
 }
@@ -0,0 +1,110 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.pmFromFile
+
+package operators
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+func TestPMFromFile(t *testing.T) {
+	root := fstest.MapFS{
+		"rules/blocklist.data": &fstest.MapFile{Data: []byte("# scanners\nNikto\nsqlmap\n\nacunetix\n")},
+		"other/blocklist.data": &fstest.MapFile{Data: []byte("curl\n")},
+	}
+
+	for _, name := range []string{"pmFromFile", "pmf"} {
+		t.Run(name, func(t *testing.T) {
+			op, err := Get(name, plugintypes.OperatorOptions{
+				Arguments: "blocklist.data",
+				Path:      []string{"rules"},
+				Root:      root,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tx := corazawaf.NewWAF().NewTransaction()
+			tx.Capture = true
+			if !op.Evaluate(tx, "Mozilla/5.0 SQLMap/1.7 nikto") {
+				t.Fatal("expected phrases to match")
+			}
+			if want, have := "SQLMap", tx.Variables().TX().Get("0"); len(have) != 1 || have[0] != want {
+				t.Errorf("unexpected TX:0, want %q, have %q", want, have)
+			}
+			if want, have := "nikto", tx.Variables().TX().Get("1"); len(have) != 1 || have[0] != want {
+				t.Errorf("unexpected TX:1, want %q, have %q", want, have)
+			}
+			if op.Evaluate(tx, "curl/8.0 # scanners") {
+				t.Error("unexpected match")
+			}
+		})
+	}
+
+	// the same file name under another path has other phrases
+	op, err := newPMFromFile(plugintypes.OperatorOptions{
+		Arguments: "blocklist.data",
+		Path:      []string{"other"},
+		Root:      root,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := corazawaf.NewWAF().NewTransaction()
+	if !op.Evaluate(tx, "curl/8.0") || op.Evaluate(tx, "sqlmap") {
+		t.Error("unexpected phrases for other/blocklist.data")
+	}
+}
+
+// BenchmarkPMFromFile compares the Aho-Corasick automaton used by @pm and
+// @pmFromFile, which scans the input once, to a substring search per phrase.
+func BenchmarkPMFromFile(b *testing.B) {
+	var phrases []string
+	for i := 0; i < 5000; i++ {
+		phrases = append(phrases, fmt.Sprintf("phrase%05d", i))
+	}
+	root := fstest.MapFS{
+		"phrases.data": &fstest.MapFile{Data: []byte(strings.Join(phrases, "\n"))},
+	}
+	op, err := newPMFromFile(plugintypes.OperatorOptions{
+		Arguments: "phrases.data",
+		Path:      []string{"."},
+		Root:      root,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	tx := corazawaf.NewWAF().NewTransaction()
+	input := strings.Repeat("some value without any of the phrases ", 10) + "PHRASE04999"
+
+	b.Run("ahocorasick", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if !op.Evaluate(tx, input) {
+				b.Fatal("expected match")
+			}
+		}
+	})
+	b.Run("contains", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			lower := strings.ToLower(input)
+			matched := false
+			for _, p := range phrases {
+				if strings.Contains(lower, p) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				b.Fatal("expected match")
+			}
+		}
+	})
+}
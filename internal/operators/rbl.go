@@ -8,18 +8,41 @@ package operators
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 )
 
-const timeout = 500 * time.Millisecond
+const (
+	// defaultRBLTimeout bounds the DNS queries of an evaluation unless
+	// configured otherwise, a slow DNS server must not stall the request processing
+	defaultRBLTimeout = 500 * time.Millisecond
+	// rblCacheTTL is the time the result of a lookup is cached for
+	rblCacheTTL = 5 * time.Minute
+	// rblCacheSize is the maximum number of lookups cached per operator
+	rblCacheSize = 10000
+)
+
+type rblResult struct {
+	listed  bool
+	reason  string
+	expires time.Time
+}
 
 type rbl struct {
 	service  string
-	resolver *net.Resolver
+	resolver plugintypes.DNSResolver
+	timeout  time.Duration
+
+	mu    sync.Mutex
+	cache map[string]rblResult
+	// now allows controlling the time in tests
+	now func() time.Time
 }
 
 var _ plugintypes.Operator = (*rbl)(nil)
@@ -27,62 +50,126 @@ var _ plugintypes.Operator = (*rbl)(nil)
 func newRBL(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
 	data := options.Arguments
 
+	var resolver plugintypes.DNSResolver = net.DefaultResolver
+	if options.DNSResolver != nil {
+		resolver = options.DNSResolver
+	}
+	timeout := defaultRBLTimeout
+	if options.DNSTimeout > 0 {
+		timeout = options.DNSTimeout
+	}
+
 	return &rbl{
 		service:  data,
-		resolver: net.DefaultResolver,
+		resolver: resolver,
+		timeout:  timeout,
+		cache:    map[string]rblResult{},
+		now:      time.Now,
 	}, nil
 }
 
 // https://github.com/mrichman/godnsbl
 // https://github.com/SpiderLabs/ModSecurity/blob/b66224853b4e9d30e0a44d16b29d5ed3842a6b11/src/operators/rbl.cc
 func (o *rbl) Evaluate(tx plugintypes.TransactionState, ipAddr string) bool {
-	// TODO validate address
-	resC := make(chan bool)
-	ctx, cancel := context.WithCancel(context.Background())
-
-	defer func() {
-		cancel()
-	}()
-
-	addr := fmt.Sprintf("%s.%s", ipAddr, o.service)
-	var captures []string
-	go func(ctx context.Context) {
-		defer func() {
-			close(resC)
-		}()
-		res, err := o.resolver.LookupHost(ctx, addr)
-
-		if err != nil {
-			resC <- false
-			return
+	addr := fmt.Sprintf("%s.%s", rblQueryName(ipAddr), o.service)
+
+	res, ok := o.cached(addr)
+	if !ok {
+		var err error
+		if res, err = o.lookup(addr); err != nil {
+			// errors other than the name not being listed, e.g. timeouts, are not cached
+			return false
 		}
-		// var status string
-		if len(res) > 0 {
-			txt, err := o.resolver.LookupTXT(ctx, addr)
-			if err != nil {
-				resC <- false
-				return
-			}
+		o.store(addr, res)
+	}
 
-			if len(txt) > 0 {
-				status := txt[0]
-				captures = append(captures, status)
-				tx.Variables().TX().Set("httpbl_msg", []string{status})
-			}
+	if !res.listed {
+		return false
+	}
+	if res.reason != "" {
+		tx.Variables().TX().Set("httpbl_msg", []string{res.reason})
+		tx.CaptureField(0, res.reason)
+	}
+	return true
+}
+
+// lookup queries the blocklist, the name is listed if it resolves to an address,
+// the reason is the first TXT record if any.
+func (o *rbl) lookup(addr string) (rblResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+
+	res, err := o.resolver.LookupHost(ctx, addr)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return rblResult{}, nil
 		}
+		return rblResult{}, err
+	}
+	if len(res) == 0 {
+		return rblResult{}, nil
+	}
+
+	result := rblResult{listed: true}
+	// the TXT record is optional, a failed query does not change the result
+	if txt, err := o.resolver.LookupTXT(ctx, addr); err == nil && len(txt) > 0 {
+		result.reason = txt[0]
+	}
+	return result, nil
+}
+
+func (o *rbl) cached(addr string) (rblResult, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	res, ok := o.cache[addr]
+	if !ok || o.now().After(res.expires) {
+		return rblResult{}, false
+	}
+	return res, true
+}
+
+func (o *rbl) store(addr string, res rblResult) {
+	now := o.now()
+	res.expires = now.Add(rblCacheTTL)
 
-		resC <- true
-	}(ctx)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.cache) >= rblCacheSize {
+		for k, v := range o.cache {
+			if now.After(v.expires) {
+				delete(o.cache, k)
+			}
+		}
+		if len(o.cache) >= rblCacheSize {
+			clear(o.cache)
+		}
+	}
+	o.cache[addr] = res
+}
 
-	select {
-	case res := <-resC:
-		if res && len(captures) > 0 {
-			tx.CaptureField(0, captures[0])
+// rblQueryName returns the name to look up in the blocklist zone: the octets of
+// an IPv4 address or the nibbles of an IPv6 address in reverse order. Other
+// values, e.g. hostnames for domain based blocklists, are used as is.
+func rblQueryName(value string) string {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return value
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d", ip4[3], ip4[2], ip4[1], ip4[0])
+	}
+	const hexDigits = "0123456789abcdef"
+	var sb strings.Builder
+	for i := len(ip) - 1; i >= 0; i-- {
+		if i < len(ip)-1 {
+			sb.WriteByte('.')
 		}
-		return res
-	case <-time.After(timeout):
-		return false
+		sb.WriteByte(hexDigits[ip[i]&0x0f])
+		sb.WriteByte('.')
+		sb.WriteByte(hexDigits[ip[i]>>4])
 	}
+	return sb.String()
 }
 
 func init() {
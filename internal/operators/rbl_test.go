@@ -7,7 +7,11 @@
 package operators
 
 import (
+	"context"
+	"errors"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/foxcpp/go-mockdns"
 
@@ -23,14 +27,6 @@ func (l *testLogger) Printf(format string, v ...interface{}) {
 }
 
 func TestRbl(t *testing.T) {
-	opts := plugintypes.OperatorOptions{
-		Arguments: "xbl.spamhaus.org",
-	}
-	op, err := newRBL(opts)
-	if err != nil {
-		t.Fatal("Cannot init rbl operator")
-	}
-
 	logger := &testLogger{t}
 
 	srv, err := mockdns.NewServerWithLogger(map[string]mockdns.Zone{
@@ -51,11 +47,21 @@ func TestRbl(t *testing.T) {
 	}
 	defer srv.Close()
 
-	srv.PatchNet(op.(*rbl).resolver)
-	defer mockdns.UnpatchNet(op.(*rbl).resolver)
+	resolver := &net.Resolver{}
+	srv.PatchNet(resolver)
+	defer mockdns.UnpatchNet(resolver)
+
+	opts := plugintypes.OperatorOptions{
+		Arguments:   "xbl.spamhaus.org",
+		DNSResolver: resolver,
+	}
+	op, err := newRBL(opts)
+	if err != nil {
+		t.Fatal("Cannot init rbl operator")
+	}
 
 	t.Run("Valid hostname with no TXT record", func(t *testing.T) {
-		if op.Evaluate(nil, "valid_no_txt") {
+		if !op.Evaluate(nil, "valid_no_txt") {
 			t.Errorf("Unexpected result for valid hostname with no TXT record")
 		}
 	})
@@ -87,3 +93,145 @@ func TestRbl(t *testing.T) {
 		}
 	})
 }
+
+type stubRBLResolver struct {
+	hosts   map[string][]string
+	txt     map[string][]string
+	err     error
+	queries []string
+	// block makes the lookups wait for the context to be done
+	block bool
+}
+
+func (r *stubRBLResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.queries = append(r.queries, host)
+	if r.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if addrs, ok := r.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r *stubRBLResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if txt, ok := r.txt[name]; ok {
+		return txt, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func newStubRBL(t *testing.T, resolver *stubRBLResolver) *rbl {
+	t.Helper()
+	op, err := newRBL(plugintypes.OperatorOptions{
+		Arguments:   "dnsbl.example.org",
+		DNSResolver: resolver,
+		DNSTimeout:  10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return op.(*rbl)
+}
+
+func TestRblQueryName(t *testing.T) {
+	tests := map[string]string{
+		"1.2.3.4":        "4.3.2.1",
+		"::ffff:1.2.3.4": "4.3.2.1",
+		"2001:db8::1":    "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2",
+		"example.com":    "example.com",
+	}
+	for value, want := range tests {
+		if have := rblQueryName(value); want != have {
+			t.Errorf("unexpected query name for %q, want %q, have %q", value, want, have)
+		}
+	}
+}
+
+func TestRblStubResolver(t *testing.T) {
+	resolver := &stubRBLResolver{
+		hosts: map[string][]string{"4.3.2.1.dnsbl.example.org": {"127.0.0.2"}},
+		txt:   map[string][]string{"4.3.2.1.dnsbl.example.org": {"listed for spam"}},
+	}
+	op := newStubRBL(t, resolver)
+
+	waf := corazawaf.NewWAF()
+	tx := waf.NewTransaction()
+	tx.Capture = true
+	if !op.Evaluate(tx, "1.2.3.4") {
+		t.Fatal("expected listed address to match")
+	}
+	if want, have := "listed for spam", tx.Variables().TX().Get("0"); len(have) != 1 || have[0] != want {
+		t.Errorf("unexpected capture, want %q, have %v", want, have)
+	}
+	if want, have := "listed for spam", tx.Variables().TX().Get("httpbl_msg"); len(have) != 1 || have[0] != want {
+		t.Errorf("unexpected httpbl_msg, want %q, have %v", want, have)
+	}
+
+	if op.Evaluate(waf.NewTransaction(), "5.6.7.8") {
+		t.Error("unexpected match for an address not listed")
+	}
+	if len(resolver.queries) != 2 {
+		t.Fatalf("unexpected queries: %v", resolver.queries)
+	}
+
+	// both positive and negative results are cached
+	op.Evaluate(waf.NewTransaction(), "1.2.3.4")
+	op.Evaluate(waf.NewTransaction(), "5.6.7.8")
+	if len(resolver.queries) != 2 {
+		t.Errorf("expected cached results, have queries %v", resolver.queries)
+	}
+
+	// until they expire
+	now := time.Now()
+	op.now = func() time.Time { return now.Add(rblCacheTTL + time.Second) }
+	op.Evaluate(waf.NewTransaction(), "1.2.3.4")
+	if len(resolver.queries) != 3 {
+		t.Errorf("expected the expired result to be queried again, have queries %v", resolver.queries)
+	}
+}
+
+func TestRblResolverErrorsAreNotCached(t *testing.T) {
+	resolver := &stubRBLResolver{err: errors.New("i/o timeout")}
+	op := newStubRBL(t, resolver)
+
+	for i := 0; i < 2; i++ {
+		if op.Evaluate(nil, "1.2.3.4") {
+			t.Error("unexpected match on resolver error")
+		}
+	}
+	if len(resolver.queries) != 2 {
+		t.Errorf("expected errors not to be cached, have queries %v", resolver.queries)
+	}
+}
+
+func TestRblDefaultOptions(t *testing.T) {
+	op, err := newRBL(plugintypes.OperatorOptions{Arguments: "dnsbl.example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := op.(*rbl)
+	if o.resolver != net.DefaultResolver {
+		t.Errorf("unexpected resolver %v", o.resolver)
+	}
+	if o.timeout != defaultRBLTimeout {
+		t.Errorf("unexpected timeout, want %s, have %s", defaultRBLTimeout, o.timeout)
+	}
+}
+
+func TestRblTimeout(t *testing.T) {
+	resolver := &stubRBLResolver{block: true}
+	op := newStubRBL(t, resolver)
+
+	start := time.Now()
+	if op.Evaluate(nil, "1.2.3.4") {
+		t.Error("unexpected match on timeout")
+	}
+	if elapsed := time.Since(start); elapsed >= defaultRBLTimeout {
+		t.Errorf("expected the configured timeout to be used, the evaluation took %s", elapsed)
+	}
+}
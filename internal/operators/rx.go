@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 
 	"rsc.io/binaryregexp"
@@ -21,7 +22,10 @@ type rx struct {
 	re *regexp.Regexp
 }
 
-var _ plugintypes.Operator = (*rx)(nil)
+var (
+	_ plugintypes.Operator = (*rx)(nil)
+	_ CaptureGroupsCounter = (*rx)(nil)
+)
 
 func newRX(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
 	var data string
@@ -45,43 +49,131 @@ func newRX(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
 		return newBinaryRX(options)
 	}
 
-	re, err := memoize.Do(data, func() (interface{}, error) { return regexp.Compile(data) })
+	// Patterns only differing by the way leading inline flags are written, e.g. (?sm)(?i)abc
+	// and (?ism)abc, share the same compiled expression.
+	key := rxCacheKey(data)
+	re, err := memoize.DoBounded(key, func() (interface{}, error) { return regexp.Compile(key) })
 	if err != nil {
 		return nil, err
 	}
 	return &rx{re: re.(*regexp.Regexp)}, nil
 }
 
+// rxCacheKey merges the inline flag groups at the start of the pattern into a single
+// group with the flags sorted, e.g. (?s)(?mi)abc becomes (?ims)abc. Both forms are
+// equivalent as leading flags apply to the whole expression. Patterns with different
+// flags, like abc and (?i)abc, keep different keys.
+func rxCacheKey(pattern string) string {
+	const knownFlags = "imsU"
+	var set [len(knownFlags)]bool
+	rest := pattern
+	groups := 0
+	for strings.HasPrefix(rest, "(?") {
+		end := strings.IndexByte(rest, ')')
+		if end <= 2 {
+			break
+		}
+		flags := rest[2:end]
+		valid := true
+		for i := 0; i < len(flags); i++ {
+			if strings.IndexByte(knownFlags, flags[i]) == -1 {
+				// negated flags (?-i) and groups (?i:abc) are kept as is
+				valid = false
+				break
+			}
+		}
+		if !valid {
+			break
+		}
+		for i := 0; i < len(flags); i++ {
+			set[strings.IndexByte(knownFlags, flags[i])] = true
+		}
+		rest = rest[end+1:]
+		groups++
+	}
+	if groups == 0 {
+		return pattern
+	}
+
+	var key strings.Builder
+	key.Grow(len(pattern))
+	key.WriteString("(?")
+	for i, ok := range set {
+		if ok {
+			key.WriteByte(knownFlags[i])
+		}
+	}
+	key.WriteByte(')')
+	key.WriteString(rest)
+	return key.String()
+}
+
+// Evaluate only looks for the submatches when the rule captures, otherwise the
+// cheaper MatchString is used, see BenchmarkRxCapture.
 func (o *rx) Evaluate(tx plugintypes.TransactionState, value string) bool {
 	if tx.Capturing() {
-		match := o.re.FindStringSubmatch(value)
-		if len(match) == 0 {
+		loc := o.re.FindStringSubmatchIndex(value)
+		if loc == nil {
 			return false
 		}
-		for i, c := range match {
-			if i == 9 {
-				return true
-			}
-			tx.CaptureField(i, c)
-		}
+		captureSubmatches(tx, value, loc)
 		return true
 	} else {
 		return o.re.MatchString(value)
 	}
 }
 
+// CaptureGroups implements CaptureGroupsCounter. Only TX.0 to TX.9 are populated
+// regardless of the number of groups of the expression.
+func (o *rx) CaptureGroups() int {
+	return min(o.re.NumSubexp(), maxCaptures-1)
+}
+
+// maxCaptures is the number of TX variables populated by capturing
+// operators, TX.0 to TX.9
+const maxCaptures = 10
+
+// matchOffsetRecorder is implemented by the transactions recording where the
+// capture TX.0 was found in the evaluated value, so sanitiseMatchedBytes
+// masks exactly the matched bytes.
+type matchOffsetRecorder interface {
+	CaptureMatchOffset(value string, start int, end int)
+}
+
+// captureSubmatches stores the whole match in TX.0 and the groups in TX.1 to TX.9,
+// loc holds the index pairs of the submatches of value.
+// Like ModSecurity, the captures left by a previous rule beyond the groups of the
+// expression are cleared so they are not mistaken for captures of this match.
+func captureSubmatches(tx plugintypes.TransactionState, value string, loc []int) {
+	for i := 0; i < maxCaptures; i++ {
+		if 2*i < len(loc) && loc[2*i] >= 0 {
+			tx.CaptureField(i, value[loc[2*i]:loc[2*i+1]])
+		} else {
+			tx.CaptureField(i, "")
+		}
+	}
+	if r, ok := tx.(matchOffsetRecorder); ok {
+		r.CaptureMatchOffset(value, loc[0], loc[1])
+	}
+}
+
 // binaryRx is exactly the same as rx, but using the binaryregexp package for matching
 // arbitrary bytes.
 type binaryRX struct {
 	re *binaryregexp.Regexp
 }
 
-var _ plugintypes.Operator = (*binaryRX)(nil)
+var (
+	_ plugintypes.Operator = (*binaryRX)(nil)
+	_ CaptureGroupsCounter = (*binaryRX)(nil)
+)
 
 func newBinaryRX(options plugintypes.OperatorOptions) (plugintypes.Operator, error) {
 	data := options.Arguments
 
-	re, err := memoize.Do(data, func() (interface{}, error) { return binaryregexp.Compile(data) })
+	// The key is namespaced as the cache is shared with the expressions compiled by the
+	// regexp package, the same pattern would otherwise return a *regexp.Regexp.
+	re, err := memoize.DoBounded("binaryregexp\x00"+data, func() (interface{}, error) { return binaryregexp.Compile(data) })
 	if err != nil {
 		return nil, err
 	}
@@ -90,22 +182,22 @@ func newBinaryRX(options plugintypes.OperatorOptions) (plugintypes.Operator, err
 
 func (o *binaryRX) Evaluate(tx plugintypes.TransactionState, value string) bool {
 	if tx.Capturing() {
-		match := o.re.FindStringSubmatch(value)
-		if len(match) == 0 {
+		loc := o.re.FindStringSubmatchIndex(value)
+		if loc == nil {
 			return false
 		}
-		for i, c := range match {
-			if i == 9 {
-				return true
-			}
-			tx.CaptureField(i, c)
-		}
+		captureSubmatches(tx, value, loc)
 		return true
 	} else {
 		return o.re.MatchString(value)
 	}
 }
 
+// CaptureGroups implements CaptureGroupsCounter
+func (o *binaryRX) CaptureGroups() int {
+	return min(o.re.NumSubexp(), maxCaptures-1)
+}
+
 func init() {
 	Register("rx", newRX)
 }
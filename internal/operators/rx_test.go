@@ -8,6 +8,7 @@ package operators
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"testing"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
@@ -108,6 +109,76 @@ func TestRx(t *testing.T) {
 	}
 }
 
+func TestRxEmptyPattern(t *testing.T) {
+	rx, err := newRX(plugintypes.OperatorOptions{Arguments: ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := corazawaf.NewWAF().NewTransaction()
+	tx.Capture = true
+	for _, input := range []string{"", "abc", "multi\nline", "\xac\xed"} {
+		if !rx.Evaluate(tx, input) {
+			t.Errorf("expected the empty pattern to match %q", input)
+		}
+		if have := tx.Variables().TX().Get("0"); len(have) != 1 || have[0] != "" {
+			t.Errorf("unexpected TX:0 for %q, want an empty match, have %q", input, have)
+		}
+	}
+}
+
+func TestRxWithoutCapture(t *testing.T) {
+	tx := corazawaf.NewWAF().NewTransaction()
+	for _, pattern := range []string{`id=(\d+)`, `id=(\d+)\xff?`} {
+		rx, err := newRX(plugintypes.OperatorOptions{Arguments: pattern})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rx.Evaluate(tx, "id=123") {
+			t.Fatalf("expected %q to match", pattern)
+		}
+		// the values are only matched, nothing is captured
+		if have := tx.Variables().TX().Get("0"); len(have) > 0 && have[0] != "" {
+			t.Errorf("unexpected TX:0 for %q, have %q", pattern, have)
+		}
+	}
+}
+
+func TestRxCaptures(t *testing.T) {
+	tx := corazawaf.NewWAF().NewTransaction()
+	tx.Capture = true
+
+	all, err := newRX(plugintypes.OperatorOptions{Arguments: `(a)(b)(c)(d)(e)(f)(g)(h)(i)(j)(k)`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !all.Evaluate(tx, "abcdefghijk") {
+		t.Fatal("expected match")
+	}
+	for i, want := range []string{"abcdefghijk", "a", "b", "c", "d", "e", "f", "g", "h", "i"} {
+		if have := tx.Variables().TX().Get(strconv.Itoa(i)); len(have) != 1 || have[0] != want {
+			t.Errorf("unexpected TX:%d, want %q, have %q", i, want, have)
+		}
+	}
+	if have := tx.Variables().TX().Get("10"); len(have) > 0 && have[0] != "" {
+		t.Errorf("unexpected TX:10 %q", have)
+	}
+
+	// a later capture with fewer groups clears the previous captures
+	one, err := newRX(plugintypes.OperatorOptions{Arguments: `id=(\d+)`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !one.Evaluate(tx, "id=123") {
+		t.Fatal("expected match")
+	}
+	for i, want := range []string{"id=123", "123", "", "", "", "", "", "", "", ""} {
+		if have := tx.Variables().TX().Get(strconv.Itoa(i)); len(have) != 1 || have[0] != want {
+			t.Errorf("unexpected TX:%d, want %q, have %q", i, want, have)
+		}
+	}
+}
+
 func BenchmarkRxSubstringVsMatch(b *testing.B) {
 	str := "hello world; heelloo Woorld; hello; heeeelloooo wooooooorld;hello world; heelloo Woorld; hello; heeeelloooo wooooooorld;hello world; heelloo Woorld; hello; heeeelloooo wooooooorld;hello world; heelloo Woorld; hello; heeeelloooo wooooooorld;hello world; heelloo Woorld; hello; heeeelloooo wooooooorld;hello world; heelloo Woorld; hello; heeeelloooo wooooooorld;hello world; heelloo Woorld; hello; heeeelloooo wooooooorld;"
 	rx := regexp.MustCompile(`((h.*e.*l.*l.*o.*)|\d+)`)
@@ -121,3 +192,99 @@ func BenchmarkRxSubstringVsMatch(b *testing.B) {
 		rx.FindAllString(str, 3)
 	})
 }
+
+func TestRxCacheKey(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"abc", "abc"},
+		{"(?i)abc", "(?i)abc"},
+		{"(?sm)(?i)abc", "(?ims)abc"},
+		{"(?sm)(?ism)abc", "(?ims)abc"},
+		{"(?s)(?U)(?i)abc", "(?isU)abc"},
+		{"(?sm)(?i:a)bc", "(?ms)(?i:a)bc"},
+		{"(?sm)(?-i)abc", "(?ms)(?-i)abc"},
+		{"(?sm)abc(?i)def", "(?ms)abc(?i)def"},
+		{"(?)abc", "(?)abc"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			if have := rxCacheKey(tc.pattern); have != tc.want {
+				t.Errorf("unexpected key, want %q, have %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestRxInlineFlags(t *testing.T) {
+	newOp := func(pattern string) *rx {
+		t.Helper()
+		op, err := newRX(plugintypes.OperatorOptions{Arguments: pattern})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return op.(*rx)
+	}
+
+	sensitive := newOp("abc")
+	insensitive := newOp("(?i)abc")
+	if sensitive.re.String() == insensitive.re.String() {
+		t.Fatalf("expected patterns with different flags to be compiled separately")
+	}
+	waf := corazawaf.NewWAF()
+	tx := waf.NewTransaction()
+	if sensitive.Evaluate(tx, "ABC") {
+		t.Error("expected case sensitive pattern not to match")
+	}
+	if !insensitive.Evaluate(tx, "ABC") {
+		t.Error("expected case insensitive pattern to match")
+	}
+
+	// (?sm) is prepended by the operator, both end up with the same flags
+	if want, have := insensitive.re.String(), newOp("(?ism)abc").re.String(); want != have {
+		t.Errorf("expected equivalent patterns to share the cache key, want %q, have %q", want, have)
+	}
+}
+
+func BenchmarkRxInlineFlags(b *testing.B) {
+	patterns := []string{"abc", "(?i)abc", "(?ism)abc", "(?s)(?i)abc"}
+	for i := 0; i < b.N; i++ {
+		for _, p := range patterns {
+			if _, err := newRX(plugintypes.OperatorOptions{Arguments: p}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkRxCapture(b *testing.B) {
+	rx, err := newRX(plugintypes.OperatorOptions{Arguments: `^([a-z]+)_(\d+)$`})
+	if err != nil {
+		b.Fatal(err)
+	}
+	// a large collection like the ARGS of a request, half of the values match
+	values := make([]string, 1000)
+	for i := range values {
+		if i%2 == 0 {
+			values[i] = fmt.Sprintf("arg_%d", i)
+		} else {
+			values[i] = fmt.Sprintf("value %d of a regular request argument", i)
+		}
+	}
+
+	for _, capture := range []bool{false, true} {
+		b.Run(fmt.Sprintf("capture=%t", capture), func(b *testing.B) {
+			tx := corazawaf.NewWAF().NewTransaction()
+			tx.Capture = capture
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, v := range values {
+					rx.Evaluate(tx, v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.validateDNSName
+
+package operators
+
+import (
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+const (
+	maxDNSNameLength  = 253
+	maxDNSLabelLength = 63
+)
+
+// validateDNSName matches when the value is NOT a well-formed DNS hostname, like the
+// other validate operators. A hostname is made of dot separated labels of 1 to 63
+// letters, digits and hyphens, not starting or ending with a hyphen, up to 253
+// characters in total; a single trailing dot is allowed. Internationalized names
+// must be in their punycode form (xn--), raw unicode labels match. The value must
+// not contain a port, e.g. SERVER_NAME can be inspected instead of the Host header.
+type validateDNSName struct{}
+
+var _ plugintypes.Operator = (*validateDNSName)(nil)
+
+func newValidateDNSName(plugintypes.OperatorOptions) (plugintypes.Operator, error) {
+	return &validateDNSName{}, nil
+}
+
+func (o *validateDNSName) Evaluate(_ plugintypes.TransactionState, value string) bool {
+	return !isValidDNSName(value)
+}
+
+func isValidDNSName(name string) bool {
+	if n := len(name); n > 0 && name[n-1] == '.' {
+		name = name[:n-1]
+	}
+	if len(name) == 0 || len(name) > maxDNSNameLength {
+		return false
+	}
+
+	labelStart := 0
+	for i := 0; i <= len(name); i++ {
+		if i < len(name) && name[i] != '.' {
+			if !isDNSLabelChar(name[i]) {
+				return false
+			}
+			continue
+		}
+		label := name[labelStart:i]
+		if len(label) == 0 || len(label) > maxDNSLabelLength {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		labelStart = i + 1
+	}
+	return true
+}
+
+func isDNSLabelChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-'
+}
+
+func init() {
+	Register("validateDNSName", newValidateDNSName)
+}
@@ -41,7 +41,7 @@ func newValidateNID(options plugintypes.OperatorOptions) (plugintypes.Operator,
 		return nil, fmt.Errorf("invalid @validateNid argument")
 	}
 
-	re, err := memoize.Do(expr, func() (interface{}, error) { return regexp.Compile(expr) })
+	re, err := memoize.DoBounded(expr, func() (interface{}, error) { return regexp.Compile(expr) })
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,39 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+var engines = map[string]func(url string) (plugintypes.PersistenceEngine, error){}
+
+// RegisterEngine registers a new persistence engine, the factory gets the URL
+// of the storage used by the engine. It can be used for plugins.
+func RegisterEngine(name string, engine func(url string) (plugintypes.PersistenceEngine, error)) {
+	engines[strings.ToLower(name)] = engine
+}
+
+// GetEngine returns a new persistence engine by name, connected to the storage
+// at url. It returns an error if it doesn't exist.
+func GetEngine(name string, url string) (plugintypes.PersistenceEngine, error) {
+	engine := engines[strings.ToLower(name)]
+	if engine == nil {
+		return nil, fmt.Errorf("invalid persistence engine %q", name)
+	}
+	return engine(url)
+}
+
+func init() {
+	RegisterEngine("memory", func(url string) (plugintypes.PersistenceEngine, error) {
+		if url != "" {
+			return nil, errors.New("the memory persistence engine does not take a URL")
+		}
+		return NewMemory(nil), nil
+	})
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package persistence implements the in-memory engine used by default to store
+// the persistent collections.
+package persistence
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+const (
+	// defaultMaxRecords bounds the records kept by Memory, records are usually
+	// keyed by client controlled values like the IP address or the session id.
+	defaultMaxRecords = 100000
+	// sweepInterval is the minimum time between two removals of all the
+	// expired variables.
+	sweepInterval = time.Minute
+)
+
+type variable struct {
+	value string
+	// expires is the zero time if the variable does not expire
+	expires time.Time
+}
+
+func (v variable) expired(now time.Time) bool {
+	return !v.expires.IsZero() && !now.Before(v.expires)
+}
+
+type memoryRecord struct {
+	id   string
+	vars map[string]variable
+}
+
+// Memory keeps the persistent collections in memory, they are lost when the
+// process exits. Expired variables are removed when their record is accessed
+// and, at most every minute, from all the records on any access, so no
+// goroutine is needed. Once the engine holds 100000 records, the least recently
+// used one is removed for every new record.
+type Memory struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	maxRecords int
+	lastSweep  time.Time
+	// order keeps the records from the most to the least recently used
+	order   *list.List
+	records map[string]*list.Element
+}
+
+// NewMemory returns an empty in-memory engine, now is the clock used to
+// expire the variables, time.Now is used if it is nil.
+func NewMemory(now func() time.Time) *Memory {
+	if now == nil {
+		now = time.Now
+	}
+	return &Memory{
+		now:        now,
+		maxRecords: defaultMaxRecords,
+		lastSweep:  now(),
+		order:      list.New(),
+		records:    map[string]*list.Element{},
+	}
+}
+
+// Get implements plugintypes.PersistenceEngine.
+func (m *Memory) Get(collection, record string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vars := m.record(collection, record, false)
+	res := make(map[string]string, len(vars))
+	for k, v := range vars {
+		res[k] = v.value
+	}
+	return res, nil
+}
+
+// Set implements plugintypes.PersistenceEngine.
+func (m *Memory) Set(collection, record, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vars := m.record(collection, record, true)
+	v := vars[key]
+	v.value = value
+	vars[key] = v
+	return nil
+}
+
+// Add implements plugintypes.PersistenceEngine.
+func (m *Memory) Add(collection, record, key string, delta int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vars := m.record(collection, record, true)
+	v := vars[key]
+	current := 0
+	if v.value != "" {
+		var err error
+		if current, err = strconv.Atoi(v.value); err != nil {
+			return 0, fmt.Errorf("invalid numeric value %q: %w", v.value, err)
+		}
+	}
+	v.value = strconv.Itoa(current + delta)
+	vars[key] = v
+	return current + delta, nil
+}
+
+// Remove implements plugintypes.PersistenceEngine.
+func (m *Memory) Remove(collection, record, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if vars := m.record(collection, record, false); vars != nil {
+		delete(vars, key)
+		if len(vars) == 0 {
+			m.remove(m.records[recordID(collection, record)])
+		}
+	}
+	return nil
+}
+
+// Expire implements plugintypes.PersistenceEngine.
+func (m *Memory) Expire(collection, record, key string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vars := m.record(collection, record, false)
+	v, ok := vars[key]
+	if !ok {
+		return nil
+	}
+	v.expires = m.now().Add(ttl)
+	vars[key] = v
+	return nil
+}
+
+// record returns the variables of the record after removing the expired
+// ones and marks it as the most recently used. A missing record is created
+// if create is true, otherwise nil is returned.
+func (m *Memory) record(collection, record string, create bool) map[string]variable {
+	now := m.now()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+
+	id := recordID(collection, record)
+	if e, ok := m.records[id]; ok {
+		r := e.Value.(*memoryRecord)
+		removeExpired(r.vars, now)
+		if len(r.vars) > 0 || create {
+			m.order.MoveToFront(e)
+			return r.vars
+		}
+		m.remove(e)
+		return nil
+	}
+	if !create {
+		return nil
+	}
+
+	if m.order.Len() >= m.maxRecords {
+		m.sweep(now)
+	}
+	for m.order.Len() >= m.maxRecords {
+		m.remove(m.order.Back())
+	}
+	r := &memoryRecord{id: id, vars: map[string]variable{}}
+	m.records[id] = m.order.PushFront(r)
+	return r.vars
+}
+
+// sweep removes the expired variables of all the records.
+func (m *Memory) sweep(now time.Time) {
+	m.lastSweep = now
+	for e := m.order.Front(); e != nil; {
+		next := e.Next()
+		r := e.Value.(*memoryRecord)
+		if removeExpired(r.vars, now); len(r.vars) == 0 {
+			m.remove(e)
+		}
+		e = next
+	}
+}
+
+func (m *Memory) remove(e *list.Element) {
+	m.order.Remove(e)
+	delete(m.records, e.Value.(*memoryRecord).id)
+}
+
+func removeExpired(vars map[string]variable, now time.Time) {
+	for k, v := range vars {
+		if v.expired(now) {
+			delete(vars, k)
+		}
+	}
+}
+
+func recordID(collection, record string) string {
+	return collection + "\x00" + record
+}
+
+var _ plugintypes.PersistenceEngine = (*Memory)(nil)
@@ -0,0 +1,118 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package persistence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory(func() time.Time { return now })
+
+	get := func(collection, record string) map[string]string {
+		t.Helper()
+		vars, err := m.Get(collection, record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return vars
+	}
+
+	if vars := get("IP", "1.2.3.4"); len(vars) != 0 {
+		t.Errorf("expected an empty record, have %v", vars)
+	}
+
+	_ = m.Set("IP", "1.2.3.4", "blocked", "1")
+	_ = m.Set("IP", "1.2.3.4", "score", "5")
+	_ = m.Set("IP", "5.6.7.8", "score", "1")
+	_ = m.Expire("IP", "1.2.3.4", "blocked", 10*time.Second)
+	// expiring a missing variable is a no-op
+	_ = m.Expire("IP", "1.2.3.4", "missing", time.Second)
+
+	if vars := get("IP", "1.2.3.4"); vars["blocked"] != "1" || vars["score"] != "5" || len(vars) != 2 {
+		t.Errorf("unexpected record, have %v", vars)
+	}
+	if vars := get("SESSION", "1.2.3.4"); len(vars) != 0 {
+		t.Errorf("expected records to be scoped by collection, have %v", vars)
+	}
+
+	now = now.Add(5 * time.Second)
+	// setting the variable keeps its expiration
+	_ = m.Set("IP", "1.2.3.4", "blocked", "2")
+
+	now = now.Add(5 * time.Second)
+	if vars := get("IP", "1.2.3.4"); len(vars) != 1 || vars["score"] != "5" {
+		t.Errorf("expected blocked to be expired, have %v", vars)
+	}
+
+	_ = m.Remove("IP", "1.2.3.4", "score")
+	if vars := get("IP", "1.2.3.4"); len(vars) != 0 {
+		t.Errorf("expected an empty record, have %v", vars)
+	}
+	if vars := get("IP", "5.6.7.8"); vars["score"] != "1" {
+		t.Errorf("unexpected record, have %v", vars)
+	}
+}
+
+func TestMemoryAdd(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory(func() time.Time { return now })
+
+	if n, err := m.Add("IP", "1.2.3.4", "score", 5); err != nil || n != 5 {
+		t.Errorf("unexpected result %d, %v", n, err)
+	}
+	_ = m.Expire("IP", "1.2.3.4", "score", 10*time.Second)
+	if n, err := m.Add("IP", "1.2.3.4", "score", -2); err != nil || n != 3 {
+		t.Errorf("unexpected result %d, %v", n, err)
+	}
+	_ = m.Set("IP", "1.2.3.4", "name", "abc")
+	if _, err := m.Add("IP", "1.2.3.4", "name", 1); err == nil {
+		t.Error("expected an error for a non numeric value")
+	}
+
+	now = now.Add(10 * time.Second)
+	// adding keeps the expiration
+	if vars, _ := m.Get("IP", "1.2.3.4"); len(vars) != 1 || vars["name"] != "abc" {
+		t.Errorf("expected score to be expired, have %v", vars)
+	}
+}
+
+func TestMemoryBounds(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory(func() time.Time { return now })
+	m.maxRecords = 2
+
+	_ = m.Set("IP", "1", "a", "1")
+	_ = m.Set("IP", "2", "a", "1")
+	// 1 becomes the most recently used record
+	_, _ = m.Get("IP", "1")
+	_ = m.Set("IP", "3", "a", "1")
+	if vars, _ := m.Get("IP", "2"); len(vars) != 0 {
+		t.Errorf("expected the least recently used record to be evicted, have %v", vars)
+	}
+	for _, record := range []string{"1", "3"} {
+		if vars, _ := m.Get("IP", record); vars["a"] != "1" {
+			t.Errorf("unexpected record %s, have %v", record, vars)
+		}
+	}
+
+	// expired records are removed before evicting the others
+	_ = m.Expire("IP", "3", "a", time.Second)
+	now = now.Add(time.Second)
+	_ = m.Set("IP", "4", "a", "1")
+	if vars, _ := m.Get("IP", "1"); vars["a"] != "1" {
+		t.Errorf("expected record 1 to be kept, have %v", vars)
+	}
+
+	// expired records are swept without accessing them
+	_ = m.Expire("IP", "1", "a", time.Second)
+	_ = m.Expire("IP", "4", "a", time.Second)
+	now = now.Add(sweepInterval)
+	_, _ = m.Get("IP", "5")
+	if len(m.records) != 0 || m.order.Len() != 0 {
+		t.Errorf("expected all the records to be swept, have %d", len(m.records))
+	}
+}
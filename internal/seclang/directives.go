@@ -9,15 +9,20 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/corazawaf/coraza/v3/debuglog"
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	"github.com/corazawaf/coraza/v3/internal/auditlog"
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
 	"github.com/corazawaf/coraza/v3/internal/environment"
+	"github.com/corazawaf/coraza/v3/internal/io"
 	"github.com/corazawaf/coraza/v3/internal/memoize"
+	"github.com/corazawaf/coraza/v3/internal/persistence"
 	utils "github.com/corazawaf/coraza/v3/internal/strings"
 	"github.com/corazawaf/coraza/v3/types"
 )
@@ -43,6 +48,8 @@ type directive = func(options *DirectiveOptions) error
 // Syntax: Include [PATH_TO_CONF_FILES]
 // ---
 // Include loads a file or a list of files from the filesystem using golang Glob syntax.
+// The files matching a pattern are loaded in lexical order, rules are evaluated in the
+// order they are declared within each phase, across all the included files.
 //
 // Example:
 // ```apache
@@ -59,6 +66,24 @@ func directiveInclude(_ *DirectiveOptions) error {
 
 var _ directive = directiveInclude
 
+// Description: Include and evaluate a file or file pattern, ignoring missing files.
+// Syntax: IncludeOptional [PATH_TO_CONF_FILES]
+// ---
+// IncludeOptional works like Include, but it doesn't fail if the file doesn't exist or
+// the pattern doesn't match any file, as the Apache directive with the same name. It is
+// useful for optional files, like local overrides of a rule set.
+//
+// Example:
+// ```apache
+// Include /path/coreruleset/rules/*.conf
+// IncludeOptional /path/coreruleset/local-overrides.conf
+// ```
+func directiveIncludeOptional(_ *DirectiveOptions) error {
+	return errors.New("not implemented")
+}
+
+var _ directive = directiveIncludeOptional
+
 var errEmptyOptions = errors.New("expected options")
 
 func directiveSecComponentSignature(options *DirectiveOptions) error {
@@ -208,6 +233,80 @@ func directiveSecRule(options *DirectiveOptions) error {
 	return nil
 }
 
+// Description: Creates a rule that will evaluate the main function of a Lua script.
+// Syntax: SecRuleScript /path/to/script.lua [ACTIONS]
+// ---
+// The script is compiled once, when the directive is parsed, relative paths are resolved
+// from the directory of the configuration file. Its `main(tx)` function is evaluated
+// with the transaction and returns whether the rule matches, followed by an optional
+// message used when the rule has no `msg` action. The transaction gives access to the
+// variables through `tx.getvar(name)`, `tx.getvars(collection)` and
+// `tx.setvar(name, value)`. Scripts run in a sandbox without access to the filesystem
+// or the network, an error raised by a script only interrupts the evaluation of its rule.
+// An evaluation is aborted after 100 milliseconds, or earlier if `SecRuleEvaluationTimeout`
+// is exceeded, and its rule doesn't match. Actions are handled as in `SecRule`. The
+// directive fails in TinyGo builds and when Lua is excluded with the `coraza.no_lua`
+// build tag.
+//
+// Example:
+// ```apache
+// SecRuleScript scripts/numeric_id.lua "id:100,phase:2,deny,status:403"
+// ```
+func directiveSecRuleScript(options *DirectiveOptions) error {
+	if len(options.Opts) == 0 {
+		return errEmptyOptions
+	}
+
+	scriptPath, actions, _ := strings.Cut(options.Opts, " ")
+	scriptPath = strings.Trim(scriptPath, `"`)
+	actions = strings.Trim(strings.TrimSpace(actions), `"`)
+	if !filepath.IsAbs(scriptPath) {
+		scriptPath = filepath.Join(options.Parser.ConfigDir, scriptPath)
+	}
+	root := options.Parser.Root
+	if root == nil {
+		root = io.OSFS{}
+	}
+	src, err := fs.ReadFile(root, scriptPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the rule script: %w", err)
+	}
+	script, err := compileScript(scriptPath, string(src))
+	if err != nil {
+		return fmt.Errorf("failed to compile the rule script %q: %w", scriptPath, err)
+	}
+
+	parent := getLastRuleExpectingChain(options.WAF)
+	rule, err := ParseRule(RuleOptions{
+		WithOperator: false,
+		WAF:          options.WAF,
+		ParserConfig: options.Parser,
+		Raw:          options.Raw,
+		Directive:    "SecRuleScript",
+		Data:         actions,
+	})
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		// the rule is a link of a chain, it was already appended to its parent
+		link := parent
+		for link.Chain != nil {
+			link = link.Chain
+		}
+		link.Script = script
+	} else {
+		rule.Script = script
+		if err := options.WAF.Rules.Add(rule); err != nil {
+			return err
+		}
+	}
+	options.WAF.Logger.Debug().
+		Str("script", scriptPath).
+		Msg("Added SecRuleScript")
+	return nil
+}
+
 // Description: Configures whether response bodies are to be buffered.
 // Syntax: SecResponseBodyAccess On|Off
 // Default: Off
@@ -308,6 +407,41 @@ func directiveSecServerSignature(options *DirectiveOptions) error {
 	return nil
 }
 
+// Description: Configures the response body attached to deny interruptions.
+// Syntax: SecDefaultBlockPage html|json [CONTENT]
+// ---
+// Both an HTML and a JSON page can be configured, the JSON one is used when the
+// client accepts `application/json` or when it is the only one configured. The body
+// and its content type are carried by the interruption, so the integrator (e.g.
+// the http middleware) can return them to the client.
+//
+// Example:
+// ```apache
+// SecDefaultBlockPage html "<html><body><h1>Request blocked</h1></body></html>"
+// SecDefaultBlockPage json '{"error":"request blocked"}'
+// ```
+func directiveSecDefaultBlockPage(options *DirectiveOptions) error {
+	if len(options.Opts) == 0 {
+		return errEmptyOptions
+	}
+
+	format, content, ok := strings.Cut(options.Opts, " ")
+	content = utils.MaybeRemoveQuotes(strings.TrimSpace(content))
+	if !ok || content == "" {
+		return errors.New("syntax error: SecDefaultBlockPage html|json [CONTENT]")
+	}
+
+	switch strings.ToLower(format) {
+	case "html":
+		options.WAF.DefaultBlockPageHTML = content
+	case "json":
+		options.WAF.DefaultBlockPageJSON = content
+	default:
+		return fmt.Errorf("invalid block page format %q, expected html or json", format)
+	}
+	return nil
+}
+
 // Description: Removes the matching rules from the current configuration context.
 // Syntax: SecRuleRemoveByTag [TAG]
 // ---
@@ -330,17 +464,43 @@ func directiveSecRuleRemoveByTag(options *DirectiveOptions) error {
 	return nil
 }
 
+// Description: Removes the matching rules from the current configuration context.
+// Syntax: SecRuleRemoveByMsg [REGEX]
+// ---
+// Removes the rules whose `msg` matches the regular expression, a plain message matches
+// any message containing it. Matching is case-sensitive and it is done against the message
+// as written in the rule, before macro expansion. Only the rules loaded before the directive
+// are removed, a chain is removed as a whole.
+//
+// Example:
+// ```apache
+// SecRuleRemoveByMsg "SQL Injection"
+// SecRuleRemoveByMsg "^Remote Command Execution: (Unix|Windows)"
+// ```
 func directiveSecRuleRemoveByMsg(options *DirectiveOptions) error {
 	if len(options.Opts) == 0 {
 		return errEmptyOptions
 	}
 
-	options.WAF.Rules.DeleteByMsg(options.Opts)
+	re, err := regexp.Compile(options.Opts)
+	if err != nil {
+		return fmt.Errorf("SecRuleRemoveByMsg: invalid regular expression: %w", err)
+	}
+	options.WAF.Rules.DeleteByMsg(re)
 	return nil
 }
 
 // Description: Removes the matching rules from the current configuration context.
 // Syntax: SecRuleRemoveById ...[ID OR RANGE]
+// ---
+// The IDs and ranges are separated by spaces, a range includes both its start and end IDs.
+// Only the rules loaded before the directive are removed, removing a rule also removes the
+// rules chained to it.
+//
+// Example:
+// ```apache
+// SecRuleRemoveById 920100 941000-941999 942100
+// ```
 func directiveSecRuleRemoveByID(options *DirectiveOptions) error {
 	if len(options.Opts) == 0 {
 		return errEmptyOptions
@@ -433,6 +593,10 @@ func directiveSecResponseBodyLimitAction(options *DirectiveOptions) error {
 // Anything over this limit will be rejected with status code 500 (Internal Server Error).
 // This setting will not affect the responses with MIME types that are not selected for
 // buffering. There is a hard limit of 1 GB.
+// Response bodies compressed with gzip or deflate, as told by the Content-Encoding header,
+// are decompressed before being inspected. The decompressed body is also limited to this
+// size: beyond it the body is truncated and `RES_BODY_ERROR` is set to 1, which protects
+// from decompression bombs.
 func directiveSecResponseBodyLimit(options *DirectiveOptions) error {
 	if len(options.Opts) == 0 {
 		return errEmptyOptions
@@ -469,8 +633,16 @@ func directiveSecRequestBodyLimitAction(options *DirectiveOptions) error {
 // Default: defaults to RequestBodyLimit
 // Syntax: SecRequestBodyInMemoryLimit [LIMIT_IN_BYTES]
 // ---
-// When a `multipart/form-data` request is being processed, once the in-memory limit is reached,
-// the request body will start to be streamed into a temporary file on disk.
+// Once the in-memory limit is reached, the request body will start to be streamed into a
+// temporary file created in the `SecTmpDir` directory (the system temporary directory by default).
+// The body spilled over to disk is still inspected by the body processors and the rules, and the
+// temporary file is removed once the transaction is closed.
+//
+// Example:
+// ```apache
+// SecRequestBodyLimit 13107200
+// SecRequestBodyInMemoryLimit 131072
+// ```
 func directiveSecRequestBodyInMemoryLimit(options *DirectiveOptions) error {
 	if len(options.Opts) == 0 {
 		return errEmptyOptions
@@ -484,6 +656,17 @@ func directiveSecRequestBodyInMemoryLimit(options *DirectiveOptions) error {
 	return nil
 }
 
+// Description: Defines what to do when the rules of `SecRemoteRules` cannot be fetched.
+// Default: Warn
+// Syntax: SecRemoteRulesFailAction Abort|Warn
+// ---
+// With `Abort` the configuration loading fails, with `Warn` the error is logged and the
+// remote rules are skipped. It must be configured before `SecRemoteRules`.
+//
+// Example:
+// ```apache
+// SecRemoteRulesFailAction Abort
+// ```
 func directiveSecRemoteRulesFailAction(options *DirectiveOptions) error {
 	if len(options.Opts) == 0 {
 		return errEmptyOptions
@@ -500,8 +683,23 @@ func directiveSecRemoteRulesFailAction(options *DirectiveOptions) error {
 	return nil
 }
 
-func directiveSecRemoteRules(options *DirectiveOptions) error {
-	return fmt.Errorf("not implemented")
+// Description: Loads rules from a remote server.
+// Syntax: SecRemoteRules [KEY] [URL]
+// ---
+// The rules are fetched over HTTPS when the directive is parsed and evaluated as if they
+// were included from a file. The key is sent in the `ModSec-key` header to authenticate
+// against the server. The certificate of the server is always verified, and the rules
+// can take up to 10MiB. The rules fetched from the same URL with the same key are only
+// downloaded once per configuration load. Use `SecRemoteRulesFailAction` to decide whether
+// a failure to fetch the rules aborts the configuration loading. It is not supported in TinyGo.
+//
+// Example:
+// ```apache
+// SecRemoteRulesFailAction Abort
+// SecRemoteRules some-key https://rules.example.com/coraza.conf
+// ```
+func directiveSecRemoteRules(_ *DirectiveOptions) error {
+	return errors.New("not implemented")
 }
 
 func directiveSecConnWriteStateLimit(options *DirectiveOptions) error {
@@ -537,10 +735,61 @@ func directiveSecGsbLookupDb(options *DirectiveOptions) error {
 	return nil
 }
 
+// Description: Defines the path to the geographical database file used by `@geoLookup`.
+// Syntax: SecGeoLookupDb [PATH_TO_MMDB_FILE]
+// ---
+// The database must be in the MaxMind DB format, e.g. GeoLite2-Country.mmdb or
+// GeoLite2-City.mmdb. Relative paths are resolved from the directory of the configuration
+// file, using the root of the parser. The database is loaded in memory once, when the
+// directive is parsed, and shared by all the transactions. It must be configured before
+// the rules using `@geoLookup`. The directive fails if the operator is excluded with the
+// `coraza.disabled_operators.geoLookup` build tag.
+//
+// Example:
+// ```apache
+// SecGeoLookupDb /usr/share/GeoIP/GeoLite2-Country.mmdb
+// SecRule REMOTE_ADDR "@geoLookup" "id:100,phase:1,chain,deny,msg:'Non-GB IP address'"
+// SecRule GEO:COUNTRY_CODE "!@streq GB"
+// ```
+func directiveSecGeoLookupDb(options *DirectiveOptions) error {
+	if len(options.Opts) == 0 {
+		return errEmptyOptions
+	}
+
+	dbPath := options.Opts
+	if !filepath.IsAbs(dbPath) {
+		dbPath = filepath.Join(options.Parser.ConfigDir, dbPath)
+	}
+	root := options.Parser.Root
+	if root == nil {
+		root = io.OSFS{}
+	}
+	data, err := fs.ReadFile(root, dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the geo database: %w", err)
+	}
+	db, err := openGeoDatabase(data)
+	if err != nil {
+		return fmt.Errorf("failed to open the geo database %q: %w", dbPath, err)
+	}
+	options.WAF.GeoDB = db
+	return nil
+}
+
+// Description: Configures the URLs and forms protected by the hash engine using a phrase match.
+// Syntax: SecHashMethodPm [HASH_TYPE] "[PHRASES]"
+// ---
+// Not supported in Coraza (TBI), the hash engine is not implemented yet. The directive
+// is accepted for compatibility with ModSecurity configurations and has no effect.
 func directiveSecHashMethodPm(options *DirectiveOptions) error {
 	return nil
 }
 
+// Description: Configures the URLs and forms protected by the hash engine using a regular expression.
+// Syntax: SecHashMethodRx [HASH_TYPE] "[REGEX]"
+// ---
+// Not supported in Coraza (TBI), the hash engine is not implemented yet. The directive
+// is accepted for compatibility with ModSecurity configurations and has no effect.
 func directiveSecHashMethodRx(options *DirectiveOptions) error {
 	return nil
 }
@@ -630,6 +879,22 @@ func directiveSecAuditLog(options *DirectiveOptions) error {
 	return nil
 }
 
+// Description: Configures the type of audit logging mechanism to be used.
+// Syntax: SecAuditLogType Serial|Concurrent|HTTPS
+// Default: Serial
+// ---
+// - Serial: all the audit log entries are appended to the file set with `SecAuditLog`.
+// - Concurrent: every audit log entry is written to its own file, under a
+// `YYYYMMDD/YYYYMMDD-HHMM/` hierarchy of the directory set with `SecAuditLogStorageDir`,
+// and a line referencing it is appended to the index file set with `SecAuditLog`.
+// - HTTPS: the audit log entries are sent to the URL set with `SecAuditLog`.
+//
+// Example:
+// ```apache
+// SecAuditLogType Concurrent
+// SecAuditLog /var/log/coraza/index
+// SecAuditLogStorageDir /var/log/coraza/audit
+// ```
 func directiveSecAuditLogType(options *DirectiveOptions) error {
 	if len(options.Opts) == 0 {
 		return errEmptyOptions
@@ -682,13 +947,27 @@ func directiveSecAuditLogDir(options *DirectiveOptions) error {
 	return nil
 }
 
+// Description: Configures the directory where concurrent audit log entries are stored,
+// it is the ModSecurity name of `SecAuditLogDir`.
+// Syntax: SecAuditLogStorageDir [PATH_TO_LOG_DIR]
+// ---
+// Example:
+// ```apache
+// SecAuditLogStorageDir /tmp/auditlogs/
+// ```
+func directiveSecAuditLogStorageDir(options *DirectiveOptions) error {
+	return directiveSecAuditLogDir(options)
+}
+
 // Description: Configures the mode (permissions) of any directories created for the
 // concurrent audit logs, using an octal mode value as parameter (as used in `chmod`).
 // Syntax: SecAuditLogDirMode octal_mode|"default"
-// Default: 0600
+// Default: 0700
 // ---
-// The default mode for new audit log directories (0600) only grants read/write access
-// to the owner.
+// The default mode only grants access to the owner, as audit logs contain sensitive data
+// like cookies or authorization headers. The mode is applied as is to the created
+// directories, it is not restricted by the umask of the process. "default" restores
+// the default mode.
 //
 // Example:
 // ```apache
@@ -699,6 +978,11 @@ func directiveSecAuditLogDirMode(options *DirectiveOptions) error {
 		return errEmptyOptions
 	}
 
+	if strings.EqualFold(options.Opts, "default") {
+		options.WAF.AuditLogWriterConfig.DirMode = auditlog.NewConfig().DirMode
+		return nil
+	}
+
 	auditLogDirMode, err := strconv.ParseInt(options.Opts, 8, 32)
 	if err != nil {
 		return err
@@ -714,6 +998,9 @@ func directiveSecAuditLogDirMode(options *DirectiveOptions) error {
 // Syntax: SecAuditLogFileMode octal_mode|"default"
 // Default: 0600
 // ---
+// The mode is applied as is to the created files, it is not restricted by the umask
+// of the process. "default" restores the default mode.
+//
 // Example:
 // ```apache
 // SecAuditLogFileMode 00640
@@ -723,6 +1010,11 @@ func directiveSecAuditLogFileMode(options *DirectiveOptions) error {
 		return errEmptyOptions
 	}
 
+	if strings.EqualFold(options.Opts, "default") {
+		options.WAF.AuditLogWriterConfig.FileMode = auditlog.NewConfig().FileMode
+		return nil
+	}
+
 	auditLogFileMode, err := strconv.ParseInt(options.Opts, 8, 32)
 	if err != nil {
 		return err
@@ -758,7 +1050,7 @@ func directiveSecAuditLogRelevantStatus(options *DirectiveOptions) error {
 		return errEmptyOptions
 	}
 
-	re, err := memoize.Do(options.Opts, func() (interface{}, error) { return regexp.Compile(options.Opts) })
+	re, err := memoize.DoBounded(options.Opts, func() (interface{}, error) { return regexp.Compile(options.Opts) })
 	if err != nil {
 		return err
 	}
@@ -857,6 +1149,69 @@ func directiveSecDataDir(options *DirectiveOptions) error {
 	return nil
 }
 
+// Description: Configures the engine storing the persistent collections initialized
+// with `initcol`.
+// Default: memory
+// Syntax: SecPersistenceEngine [ENGINE] [URL]
+// ---
+// The memory engine keeps the collections in the memory of the process, they are not
+// shared with other instances nor kept across restarts. Other engines, e.g. backed by
+// a database shared by all the instances behind a load balancer, are registered with
+// `plugins.RegisterPersistenceEngine` and get the URL of their storage. Selecting an
+// engine discards the collections stored by the previous one.
+//
+// Example:
+// ```apache
+// SecPersistenceEngine redis redis://localhost:6379/0
+// ```
+func directiveSecPersistenceEngine(options *DirectiveOptions) error {
+	if len(options.Opts) == 0 {
+		return errEmptyOptions
+	}
+
+	name, url, _ := strings.Cut(options.Opts, " ")
+	engine, err := persistence.GetEngine(name, strings.TrimSpace(url))
+	if err != nil {
+		return err
+	}
+	options.WAF.Persistence = engine
+	return nil
+}
+
+// Description: Configures the directory where temporary files will be created.
+// Default: the system temporary directory
+// Syntax: SecTmpDir [PATH]
+// ---
+// Temporary files are created when a request body goes beyond `SecRequestBodyInMemoryLimit`
+// and for the files uploaded in multipart requests when `SecUploadDir` is not set. They are
+// only readable and writable by the user running Coraza and are removed once the transaction
+// is closed. The directory must exist and be writable. Without access to the filesystem
+// the directive is ignored.
+//
+// Example:
+// ```apache
+// SecTmpDir /var/lib/coraza/tmp
+// ```
+func directiveSecTmpDir(options *DirectiveOptions) error {
+	if len(options.Opts) == 0 {
+		return errEmptyOptions
+	}
+
+	if !environment.HasAccessToFS {
+		// temporary files are never created without filesystem access, the directive
+		// is ignored so configurations like coraza.conf-recommended still load
+		options.WAF.Logger.Warn().
+			Str("dir", options.Opts).
+			Msg("SecTmpDir: ignored because of no access to the filesystem")
+		return nil
+	}
+	if err := environment.IsDirWritable(options.Opts); err != nil {
+		return fmt.Errorf("filesystem access check: %w. Check SecTmpDir provided dir: %s", err, options.Opts)
+	}
+	options.WAF.TmpDir = options.Opts
+	return nil
+}
+
 func directiveSecUploadKeepFiles(options *DirectiveOptions) error {
 	b, err := parseBoolean(options.Opts)
 	if err != nil {
@@ -968,11 +1323,53 @@ func directiveSecDebugLogLevel(options *DirectiveOptions) error {
 	return options.WAF.SetDebugLogLevel(debuglog.Level(lvl))
 }
 
+// Description: Configures whether the output of each transformation applied to a matched
+// value is written to the debug log.
+// Default: Off
+// Syntax: SecDebugLogTransformations On|Off
+// ---
+// It helps to understand why a rule matched, or didn't, by showing the intermediate values
+// of its transformation chain. It only has effect if the debug log level is 4 or higher.
+// Intermediate values are stored along with the transformation cache, so they are only
+// computed once per variable and transformation chain.
+//
+// Example:
+// ```apache
+// SecDebugLogLevel 9
+// SecDebugLogTransformations On
+//
+// # logs the output of urlDecodeUni, then htmlEntityDecode, then lowercase
+// SecRule ARGS "@contains <script" "id:1,phase:2,t:urlDecodeUni,t:htmlEntityDecode,t:lowercase,log"
+// ```
+func directiveSecDebugLogTransformations(options *DirectiveOptions) error {
+	b, err := parseBoolean(options.Opts)
+	if err != nil {
+		return err
+	}
+	options.WAF.DebugLogTransformations = b
+	return nil
+}
+
 // Description: Updates the target (variable) list of the specified rule(s).
-// Syntax: SecRuleUpdateTargetById ID TARGET1[|TARGET2|TARGET3]
+// Syntax: SecRuleUpdateTargetById ID TARGET1[|TARGET2|TARGET3] [append|replace]
 // ---
 // This directive will append variables to the specified rule with the targets provided in the second parameter.
 // The rule ID can be single IDs or ranges of IDs. The targets are separated by a pipe character.
+// An optional last parameter sets the update mode: `append`, the default, adds the targets to the
+// ones of the rule, while `replace` discards the targets of the rule and sets the provided ones.
+// Only the rules loaded before the directive are updated, an id without rule is ignored with a
+// warning so the directive can target rules that are not always loaded.
+//
+// Example:
+// ```apache
+// SecRule ARGS|REQUEST_HEADERS "@rx attack" "id:100,phase:2,deny"
+//
+// # the rule inspects ARGS, REQUEST_HEADERS and REQUEST_COOKIES, except ARGS:email
+// SecRuleUpdateTargetById 100 "REQUEST_COOKIES|!ARGS:email"
+//
+// # the rule only inspects ARGS_GET
+// SecRuleUpdateTargetById 100 "ARGS_GET" replace
+// ```
 func directiveSecRuleUpdateTargetByID(options *DirectiveOptions) error {
 	if len(options.Opts) == 0 {
 		return errEmptyOptions
@@ -980,8 +1377,18 @@ func directiveSecRuleUpdateTargetByID(options *DirectiveOptions) error {
 
 	idsOrRanges := strings.Fields(options.Opts)
 	length := len(idsOrRanges)
+	replace := false
+	if length > 2 {
+		switch strings.ToLower(idsOrRanges[length-1]) {
+		case "replace":
+			replace = true
+			length--
+		case "append":
+			length--
+		}
+	}
 	if length < 2 {
-		return errors.New("syntax error: SecRuleUpdateTargetById id \"VARIABLES\"")
+		return errors.New("syntax error: SecRuleUpdateTargetById id \"VARIABLES\" [append|replace]")
 	}
 	// The last element is expected to be the variable(s)
 	variables := idsOrRanges[length-1]
@@ -991,7 +1398,9 @@ func directiveSecRuleUpdateTargetByID(options *DirectiveOptions) error {
 			if err != nil {
 				return err
 			}
-			return updateTargetBySingleID(id, variables, options)
+			if err := updateTargetBySingleID(id, variables, replace, options); err != nil {
+				return err
+			}
 		} else {
 			if idx == 0 {
 				return fmt.Errorf("SecRuleUpdateTargetById: invalid negative id: %s", idOrRange)
@@ -1006,20 +1415,20 @@ func directiveSecRuleUpdateTargetByID(options *DirectiveOptions) error {
 				return err
 			}
 			if start == end {
-				return updateTargetBySingleID(start, variables, options)
+				if err := updateTargetBySingleID(start, variables, replace, options); err != nil {
+					return err
+				}
+				continue
 			}
 			if start > end {
 				return fmt.Errorf("invalid range: %s", idOrRange)
 			}
 
-			for _, rule := range options.WAF.Rules.GetRules() {
-				if rule.ID_ >= start && rule.ID_ <= end {
-					rp := RuleParser{
-						rule:           &rule,
-						options:        RuleOptions{},
-						defaultActions: map[types.RulePhase][]ruleAction{},
-					}
-					if err := rp.ParseVariables(strings.Trim(variables, "\"")); err != nil {
+			// rules are updated in place, GetRules returns the slice backing the rule group
+			rules := options.WAF.Rules.GetRules()
+			for i := range rules {
+				if rules[i].ID_ >= start && rules[i].ID_ <= end {
+					if err := updateRuleTargets(&rules[i], variables, replace); err != nil {
 						return err
 					}
 				}
@@ -1029,11 +1438,23 @@ func directiveSecRuleUpdateTargetByID(options *DirectiveOptions) error {
 	return nil
 }
 
-func updateTargetBySingleID(id int, variables string, options *DirectiveOptions) error {
-
+func updateTargetBySingleID(id int, variables string, replace bool, options *DirectiveOptions) error {
 	rule := options.WAF.Rules.FindByID(id)
 	if rule == nil {
-		return fmt.Errorf("SecRuleUpdateTargetById: rule \"%d\" not found", id)
+		// the rule may have been removed or not be loaded, e.g. an optional CRS plugin,
+		// the targets are still validated
+		options.WAF.Logger.Warn().
+			Int("rule_id", id).
+			Msg("SecRuleUpdateTargetById: rule not found, ignoring it")
+		return updateRuleTargets(corazawaf.NewRule(), variables, replace)
+	}
+	return updateRuleTargets(rule, variables, replace)
+}
+
+// updateRuleTargets adds the variables to the rule, or replaces its variables if replace is true
+func updateRuleTargets(rule *corazawaf.Rule, variables string, replace bool) error {
+	if replace {
+		rule.ClearVariables()
 	}
 	rp := RuleParser{
 		rule:           rule,
@@ -1044,35 +1465,51 @@ func updateTargetBySingleID(id int, variables string, options *DirectiveOptions)
 }
 
 // Description: Updates the action list of the specified rule(s).
-// Syntax: SecRuleUpdateActionById ID ACTIONLIST
+// Syntax: SecRuleUpdateActionById ID|RANGE [ID|RANGE ...] ACTIONLIST
 // ---
-// This directive will overwrite the action list of the specified rule with the actions provided in the second parameter.
-// It has two limitations: it cannot be used to change the ID or phase of a rule.
-// Only the actions that can appear only once are overwritten.
-// The actions that are allowed to appear multiple times in a list, will be appended to the end of the list.
+// This directive will update the action list of the specified rules with the actions provided in the last parameter.
+// The disruptive action of the rule is replaced if the list contains one, the other actions are merged:
+// the ones that can appear only once (e.g. `msg` or `severity`) are overwritten, the ones that are allowed
+// to appear multiple times (e.g. `tag`, `setvar` or the transformations) are appended to the end of the list.
+// It cannot be used to change the ID or phase of a rule. Only the rules loaded before the directive are
+// updated, the IDs of missing rules are ignored with a warning.
 // The following example demonstrates how `SecRuleUpdateActionById` is used:
 // ```apache
 // SecRuleUpdateActionById 12345 "deny,status:403"
+// SecRuleUpdateActionById 949110 "t:none,pass"
 // ```
 func directiveSecRuleUpdateActionByID(options *DirectiveOptions) error {
 	if len(options.Opts) == 0 {
 		return errEmptyOptions
 	}
 
-	idsOrRanges := strings.Fields(options.Opts)
-	idsOrRangesLen := len(idsOrRanges)
-	if idsOrRangesLen < 2 {
+	// the actions are the last parameter, quoted if they contain spaces
+	ids, actions := options.Opts, ""
+	if i := strings.IndexByte(options.Opts, '"'); i != -1 {
+		quoted, rest, err := cutQuotedString(options.Opts[i:])
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return errors.New("syntax error: SecRuleUpdateActionById id \"ACTION1,ACTION2,...\"")
+		}
+		ids, actions = options.Opts[:i], quoted[1:len(quoted)-1]
+	} else if i := strings.LastIndexAny(options.Opts, " \t"); i != -1 {
+		ids, actions = options.Opts[:i], options.Opts[i+1:]
+	}
+	idsOrRanges := strings.Fields(ids)
+	if len(idsOrRanges) == 0 || actions == "" {
 		return errors.New("syntax error: SecRuleUpdateActionById id \"ACTION1,ACTION2,...\"")
 	}
-	// The last element is expected to be the action(s)
-	actions := idsOrRanges[idsOrRangesLen-1]
-	for _, idOrRange := range idsOrRanges[:idsOrRangesLen-1] {
+	for _, idOrRange := range idsOrRanges {
 		if idx := strings.Index(idOrRange, "-"); idx == -1 {
 			id, err := strconv.Atoi(idOrRange)
 			if err != nil {
 				return err
 			}
-			return updateActionBySingleID(id, actions, options)
+			if err := updateActionBySingleID(id, actions, options); err != nil {
+				return err
+			}
 		} else {
 			if idx == 0 {
 				return fmt.Errorf("SecRuleUpdateActionById: invalid negative id: %s", idOrRange)
@@ -1087,71 +1524,120 @@ func directiveSecRuleUpdateActionByID(options *DirectiveOptions) error {
 				return err
 			}
 			if start == end {
-				return updateActionBySingleID(start, actions, options)
+				if err := updateActionBySingleID(start, actions, options); err != nil {
+					return err
+				}
+				continue
 			}
 			if start > end {
 				return fmt.Errorf("invalid range: %s", idOrRange)
 			}
 
-			for _, rule := range options.WAF.Rules.GetRules() {
-				if rule.ID_ < start && rule.ID_ > end {
-					continue
-				}
-				rp := RuleParser{
-					rule:           &rule,
-					options:        RuleOptions{},
-					defaultActions: map[types.RulePhase][]ruleAction{},
-				}
-				if err := rp.ParseActions(strings.Trim(actions, "\"")); err != nil {
-					return err
+			// rules are updated in place, GetRules returns the slice backing the rule group
+			rules := options.WAF.Rules.GetRules()
+			for i := range rules {
+				if rules[i].ID_ >= start && rules[i].ID_ <= end {
+					if err := updateRuleActions(&rules[i], actions); err != nil {
+						return err
+					}
 				}
 			}
 		}
 	}
+	// the actions might have added tags to the rules
+	options.WAF.Rules.RebuildTagIndex()
 	return nil
 }
 
 func updateActionBySingleID(id int, actions string, options *DirectiveOptions) error {
-
 	rule := options.WAF.Rules.FindByID(id)
 	if rule == nil {
-		return fmt.Errorf("SecRuleUpdateActionById: rule \"%d\" not found", id)
+		// as for SecRuleUpdateTargetById, the actions are still validated
+		options.WAF.Logger.Warn().
+			Int("rule_id", id).
+			Msg("SecRuleUpdateActionById: rule not found, ignoring it")
+		return updateRuleActions(corazawaf.NewRule(), actions)
+	}
+	return updateRuleActions(rule, actions)
+}
+
+// updateRuleActions merges the actions into the ones of the rule, its disruptive
+// action is replaced if the actions contain one
+func updateRuleActions(rule *corazawaf.Rule, actions string) error {
+	parsed, err := parseActions(actions)
+	if err != nil {
+		return err
+	}
+	disruptive := false
+	for _, a := range parsed {
+		if a.Key == "id" || a.Key == "phase" {
+			return fmt.Errorf("SecRuleUpdateActionById: the %s of a rule cannot be updated", a.Key)
+		}
+		disruptive = disruptive || a.Atype == plugintypes.ActionTypeDisruptive
+	}
+	if disruptive {
+		rule.ClearDisruptiveActions()
 	}
 	rp := RuleParser{
 		rule:           rule,
 		options:        RuleOptions{},
 		defaultActions: map[types.RulePhase][]ruleAction{},
 	}
-	return rp.ParseActions(strings.Trim(actions, "\""))
+	return rp.ParseActions(actions)
 }
 
 // Description: Updates the target (variable) list of the specified rule(s) by tag.
-// Syntax: SecRuleUpdateTargetByTag TAG TARGET1[|TARGET2|TARGET3]
+// Syntax: SecRuleUpdateTargetByTag TAG TARGET1[|TARGET2|TARGET3] [append|replace]
 // ---
 // As an alternative to `SecRuleUpdateTargetById`, this directive will append variables to the specified rule
 // with the targets provided in the second parameter. It can be handy for updating an entire group of rules.
-// Matching is by case-sensitive string equality.
-// This directive will append variables to the specified rule with the targets provided in the second parameter.
-// The rule ID can be single IDs or ranges of IDs. The targets are separated by a pipe character.
+// Matching is by case-sensitive string equality, tags containing spaces are quoted.
+// The targets are separated by a pipe character and the optional update mode is the one of
+// `SecRuleUpdateTargetById`. Only the rules loaded before the directive are updated, a tag without
+// rules is ignored with a warning.
 // Note: OWASP CRS has a list of supported tags https://coreruleset.org/docs/rules/metadata/
 func directiveSecRuleUpdateTargetByTag(options *DirectiveOptions) error {
-	tagAndvars := strings.Fields(options.Opts)
-	if len(tagAndvars) != 2 {
-		return errors.New("syntax error: SecRuleUpdateTargetByTag tag \"VARIABLES\"")
-	}
-
-	for _, rule := range options.WAF.Rules.GetRules() {
-		inputTag := strings.Trim(tagAndvars[0], "\"")
-		if utils.InSlice(inputTag, rule.Tags_) {
-			rp := RuleParser{
-				rule:           &rule,
-				options:        RuleOptions{},
-				defaultActions: map[types.RulePhase][]ruleAction{},
-			}
-			inputVars := strings.Trim(tagAndvars[1], "\"")
-			if err := rp.ParseVariables(inputVars); err != nil {
-				return err
-			}
+	opts := options.Opts
+	// the parser removes the quotes around the options, which breaks a quoted
+	// tag followed by quoted targets
+	if _, raw, ok := strings.Cut(options.Raw, " "); ok {
+		opts = raw
+	}
+	opts = strings.TrimSpace(opts)
+	var tag, rest string
+	if strings.HasPrefix(opts, `"`) {
+		quoted, r, err := cutQuotedString(opts)
+		if err != nil {
+			return err
+		}
+		tag, rest = quoted[1:len(quoted)-1], r
+	} else {
+		tag, rest, _ = strings.Cut(opts, " ")
+	}
+	fields := strings.Fields(rest)
+	replace := false
+	if len(fields) == 2 {
+		switch strings.ToLower(fields[1]) {
+		case "replace":
+			replace = true
+		case "append":
+		default:
+			fields = nil
+		}
+	}
+	if tag == "" || len(fields) == 0 || len(fields) > 2 {
+		return errors.New("syntax error: SecRuleUpdateTargetByTag tag \"VARIABLES\" [append|replace]")
+	}
+
+	rules := options.WAF.Rules.RulesByTag(tag)
+	if len(rules) == 0 {
+		options.WAF.Logger.Warn().
+			Str("tag", tag).
+			Msg("SecRuleUpdateTargetByTag: no rule found, ignoring it")
+	}
+	for _, rule := range rules {
+		if err := updateRuleTargets(rule, fields[0], replace); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -1202,11 +1688,13 @@ func directiveSecDataset(options *DirectiveOptions) error {
 // Default: 1000
 // Syntax: SecArgumentsLimit [LIMIT]
 // ---
-// Exceeding the limit will not be included.
-// With JSON body processing, there is nothing to do when exceed the limit.
+// Exceeding the limit will not be included and `ARGS_LIMIT_EXCEEDED` will be set to 1.
+// The limit applies to each of ARGS_GET, ARGS_POST and ARGS_PATH, see
+// `SecArgumentsLimitAction` to reject the transactions over the limit.
 // Example:
 // ```apache
 // SecArgumentsLimit 1000
+// SecRule ARGS_LIMIT_EXCEEDED "@eq 1" "id:100,phase:2,deny,status:400"
 // ```
 func directiveSecArgumentsLimit(options *DirectiveOptions) error {
 	limit, err := strconv.Atoi(options.Opts)
@@ -1220,6 +1708,241 @@ func directiveSecArgumentsLimit(options *DirectiveOptions) error {
 	return nil
 }
 
+// Description: Controls what happens once the arguments limit, configured with
+// SecArgumentsLimit, is reached.
+// Syntax: SecArgumentsLimitAction Reject|ProcessPartial
+// Default: ProcessPartial
+// ---
+// With ProcessPartial the arguments over the limit are ignored and `ARGS_LIMIT_EXCEEDED`
+// is set to 1 so rules can act on it. The arguments of the request body populated by a
+// body processor are all parsed, the ones kept are the first in lexical order. With Reject
+// the transaction is also interrupted with status 400 as soon as the limit is exceeded,
+// before the rules of the phase are evaluated.
+// Example:
+// ```apache
+// SecArgumentsLimit 255
+// SecArgumentsLimitAction Reject
+// ```
+func directiveSecArgumentsLimitAction(options *DirectiveOptions) error {
+	switch strings.ToLower(options.Opts) {
+	case "reject":
+		options.WAF.ArgumentLimitAction = types.BodyLimitActionReject
+	case "processpartial":
+		options.WAF.ArgumentLimitAction = types.BodyLimitActionProcessPartial
+	default:
+		return errors.New("syntax error: SecArgumentsLimitAction [Reject/ProcessPartial]")
+	}
+	return nil
+}
+
+// Description: Configures the maximum number of request headers that will be accepted for processing.
+// Default: 0 (no limit)
+// Syntax: SecRequestHeadersLimit [LIMIT]
+// ---
+// Headers exceeding the limit will not be included and `REQUEST_HEADERS_LIMIT_EXCEEDED` will
+// be set to 1. Headers are counted by name, so repeated headers count once.
+// Example:
+// ```apache
+// SecRequestHeadersLimit 100
+// SecRule REQUEST_HEADERS_LIMIT_EXCEEDED "@eq 1" "id:100,phase:1,deny,status:400"
+// ```
+func directiveSecRequestHeadersLimit(options *DirectiveOptions) error {
+	limit, err := parseCollectionLimit(options.Opts)
+	if err != nil {
+		return err
+	}
+	options.WAF.RequestHeadersLimit = limit
+	return nil
+}
+
+// Description: Configures the maximum number of request cookies that will be accepted for processing.
+// Default: 0 (no limit)
+// Syntax: SecRequestCookiesLimit [LIMIT]
+// ---
+// Cookies exceeding the limit will not be included and `REQUEST_COOKIES_LIMIT_EXCEEDED` will
+// be set to 1. Cookies are counted by name, so repeated cookies count once.
+// Example:
+// ```apache
+// SecRequestCookiesLimit 50
+// SecRule REQUEST_COOKIES_LIMIT_EXCEEDED "@eq 1" "id:100,phase:1,deny,status:400"
+// ```
+func directiveSecRequestCookiesLimit(options *DirectiveOptions) error {
+	limit, err := parseCollectionLimit(options.Opts)
+	if err != nil {
+		return err
+	}
+	options.WAF.RequestCookiesLimit = limit
+	return nil
+}
+
+// Description: Configures whether malformed query string parameters are flagged.
+// Default: Off
+// Syntax: SecQueryStringStrict On|Off
+// ---
+// Query strings are always parsed tolerantly: empty parameters (`a=1&&b=2`) are ignored,
+// parameters without a name (`=value`) are added with an empty name and parameters
+// without a value separator (`key`) are added with an empty value. When set to On, any
+// of these anomalies also sets `TX:QUERY_STRICT_ERROR` to 1 so rules can act on them.
+// Example:
+// ```apache
+// SecQueryStringStrict On
+// SecRule TX:QUERY_STRICT_ERROR "@eq 1" "id:100,phase:1,deny,status:400"
+// ```
+func directiveSecQueryStringStrict(options *DirectiveOptions) error {
+	if len(options.Opts) == 0 {
+		return errEmptyOptions
+	}
+
+	b, err := parseBoolean(options.Opts)
+	if err != nil {
+		return err
+	}
+	options.WAF.QueryStringStrict = b
+	return nil
+}
+
+// Description: Configures whether transformation failures are flagged.
+// Default: Off
+// Syntax: SecTransformationErrorFlag On|Off
+// ---
+// A transformation failing, e.g. `t:hexDecode` over an invalid hex string, is skipped
+// and the value is passed unchanged to the next transformation. When set to On, the
+// failure also sets `TX:TRANSFORMATION_ERROR` to 1 so rules can act on it.
+// Example:
+// ```apache
+// SecTransformationErrorFlag On
+// SecRule ARGS:id "@rx ^[a-z]+$" "id:100,phase:2,pass,t:hexDecode"
+// SecRule TX:TRANSFORMATION_ERROR "@eq 1" "id:101,phase:2,deny,status:400"
+// ```
+func directiveSecTransformationErrorFlag(options *DirectiveOptions) error {
+	if len(options.Opts) == 0 {
+		return errEmptyOptions
+	}
+
+	b, err := parseBoolean(options.Opts)
+	if err != nil {
+		return err
+	}
+	options.WAF.TransformationErrorFlag = b
+	return nil
+}
+
+// Description: Configures whether REMOTE_HOST is populated with the reverse DNS of the
+// client address.
+// Default: Off
+// Syntax: SecHostnameLookups On|Off
+// ---
+// When set to Off, `REMOTE_HOST` holds the client address. When set to On, the client
+// address is resolved and the hostname, or its absence, is cached for 5 minutes. A lookup
+// failing or taking longer than the timeout (500ms by default) leaves the client address
+// in `REMOTE_HOST`, timeouts and server failures are not cached. The
+// hostname is only used if it resolves back to the client address, so the owner of the
+// reverse DNS zone of an address cannot claim any hostname.
+// Enabling it adds the DNS latency to the transactions of uncached client addresses.
+// Example:
+// ```apache
+// SecHostnameLookups On
+// SecRule REMOTE_HOST "@endsWith .example.com" "id:100,phase:1,pass,nolog"
+// ```
+func directiveSecHostnameLookups(options *DirectiveOptions) error {
+	if len(options.Opts) == 0 {
+		return errEmptyOptions
+	}
+
+	b, err := parseBoolean(options.Opts)
+	if err != nil {
+		return err
+	}
+	options.WAF.HostnameLookups = b
+	return nil
+}
+
+// Description: Denies the transaction when an anomaly score accumulated in a TX variable
+// reaches a threshold.
+// Syntax: SecAnomalyScoreThreshold [TX_VARIABLE] [THRESHOLD]
+// ---
+// The score is checked at the end of the request body (2) and response body (4) phases,
+// once the rules of the phase have been evaluated. When the threshold is reached the
+// transaction is interrupted with a deny action and status 403, it doesn't depend on
+// a blocking rule like the ones of the Core Rule Set. The directive can be used several
+// times to check different scores.
+// Example:
+// ```apache
+// SecAnomalyScoreThreshold tx.anomaly_score 5
+// SecRule ARGS "@contains attack" "id:100,phase:2,pass,setvar:tx.anomaly_score=+5"
+// ```
+func directiveSecAnomalyScoreThreshold(options *DirectiveOptions) error {
+	if len(options.Opts) == 0 {
+		return errEmptyOptions
+	}
+	fields := strings.Fields(options.Opts)
+	if len(fields) != 2 {
+		return errors.New("syntax error: SecAnomalyScoreThreshold [TX_VARIABLE] [THRESHOLD]")
+	}
+	variable := fields[0]
+	if len(variable) > 3 && (strings.EqualFold(variable[:3], "tx.") || strings.EqualFold(variable[:3], "tx:")) {
+		variable = variable[3:]
+	}
+	threshold, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return fmt.Errorf("invalid threshold %q: %w", fields[1], err)
+	}
+	if threshold <= 0 {
+		return errors.New("threshold should be bigger than 0")
+	}
+	options.WAF.AnomalyScoreThresholds = append(options.WAF.AnomalyScoreThresholds, corazawaf.AnomalyScoreThreshold{
+		Variable:  strings.ToLower(variable),
+		Threshold: threshold,
+	})
+	return nil
+}
+
+// Description: Configures the maximum time the rules of a phase can take to be evaluated.
+// Default: 0 (no limit)
+// Syntax: SecRuleEvaluationTimeout [TIMEOUT_IN_MS]
+// ---
+// The time is checked between rules and every 16 values evaluated by a rule, once it is
+// exceeded the rules left in the phase are skipped and the transaction is interrupted
+// with status 503, unless the rule engine is DetectionOnly. The evaluation is also aborted
+// when the context of the transaction is done, e.g. when the client disconnects. Aborted
+// evaluations are reported in the audit log and in `TX:rule_evaluation_aborted`, set to
+// the reason, so the rules of the logging phase can detect them. A single operator, e.g.
+// a regular expression over a large value, is not interrupted. The logging phase is never
+// aborted.
+// Example:
+// ```apache
+// SecRuleEvaluationTimeout 50
+// ```
+func directiveSecRuleEvaluationTimeout(options *DirectiveOptions) error {
+	if len(options.Opts) == 0 {
+		return errEmptyOptions
+	}
+	timeout, err := strconv.Atoi(options.Opts)
+	if err != nil {
+		return err
+	}
+	if timeout < 0 {
+		return errors.New("timeout should not be negative")
+	}
+	options.WAF.RuleEvaluationTimeout = time.Duration(timeout) * time.Millisecond
+	return nil
+}
+
+// parseCollectionLimit parses the maximum number of elements of a collection, 0 means no limit
+func parseCollectionLimit(opts string) (int, error) {
+	if len(opts) == 0 {
+		return 0, errEmptyOptions
+	}
+	limit, err := strconv.Atoi(opts)
+	if err != nil {
+		return 0, err
+	}
+	if limit < 0 {
+		return 0, errors.New("limit should not be negative")
+	}
+	return limit, nil
+}
+
 func parseBoolean(data string) (bool, error) {
 	data = strings.ToLower(data)
 	switch data {
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
 	"github.com/corazawaf/coraza/v3/internal/environment"
@@ -87,6 +88,50 @@ func TestSecRuleUpdateTargetByID(t *testing.T) {
 
 }
 
+func TestSecRuleRemove(t *testing.T) {
+	rules := `
+		SecRule ARGS "@rx a" "id:941100,phase:1,pass,msg:'XSS Attack Detected via libinjection'"
+		SecRule ARGS "@rx b" "id:941110,phase:1,pass,msg:'XSS Filter - Category 1: Script Tag Vector'"
+		SecRule ARGS "@rx c" "id:942100,phase:1,pass,msg:'SQL Injection Attack Detected via libinjection',chain"
+			SecRule ARGS "@rx d" "t:none"
+		SecRule ARGS "@rx e" "id:942110,phase:1,pass,chain"
+			SecRule ARGS "@rx f" "msg:'SQL Injection Attack: Common Injection Testing Detected'"
+		SecRule ARGS "@rx g" "id:950100,phase:1,pass"
+	`
+	tests := map[string]struct {
+		directive string
+		expected  []int
+	}{
+		"range":              {"SecRuleRemoveById 941000-941999", []int{942100, 942110, 950100}},
+		"ids and ranges":     {"SecRuleRemoveById 941100 942000-942999", []int{941110, 950100}},
+		"range without rule": {"SecRuleRemoveById 943000-943999", []int{941100, 941110, 942100, 942110, 950100}},
+		"message substring":  {`SecRuleRemoveByMsg "XSS"`, []int{942100, 942110, 950100}},
+		"message regex":      {`SecRuleRemoveByMsg "^XSS .* libinjection$"`, []int{941110, 942100, 942110, 950100}},
+		"chained message":    {`SecRuleRemoveByMsg "SQL Injection"`, []int{941100, 941110, 950100}},
+		"unmatched message":  {`SecRuleRemoveByMsg "xss"`, []int{941100, 941110, 942100, 942110, 950100}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			if err := NewParser(waf).FromString(rules + tt.directive + `
+				SecRule ARGS "@rx h" "id:941200,phase:1,pass,msg:'XSS using VML frames'"
+			`); err != nil {
+				t.Fatal(err)
+			}
+			// rules defined after the directive are kept
+			expected := append(tt.expected, 941200)
+			if want, have := len(expected), waf.Rules.Count(); want != have {
+				t.Fatalf("unexpected number of rules, want %d, have %d", want, have)
+			}
+			for i, r := range waf.Rules.GetRules() {
+				if r.ID_ != expected[i] {
+					t.Errorf("unexpected rule at %d, want %d, have %d", i, expected[i], r.ID_)
+				}
+			}
+		})
+	}
+}
+
 func TestInvalidBooleanForDirectives(t *testing.T) {
 	waf := corazawaf.NewWAF()
 	p := NewParser(waf)
@@ -186,6 +231,8 @@ func TestDirectives(t *testing.T) {
 		},
 		"SecRuleRemoveByMsg": {
 			{"", expectErrorOnDirective},
+			{"(", expectErrorOnDirective},
+			{"SQL Injection", expectNoErrorOnDirective},
 		},
 		"SecRuleRemoveById": {
 			{"", expectErrorOnDirective},
@@ -233,6 +280,10 @@ func TestDirectives(t *testing.T) {
 			{"1 2 3-4 \"ARGS:wp_post\"", expectNoErrorOnDirective},
 			{"1 \"REQUEST_BODY|ARGS:wp_post\"", expectNoErrorOnDirective},
 			{"1 2 3-4 \"ARGS:wp_post|RESPONSE_HEADERS\"", expectNoErrorOnDirective},
+			{"1 \"ARGS:wp_post\" append", expectNoErrorOnDirective},
+			{"1 \"ARGS:wp_post\" replace", expectNoErrorOnDirective},
+			{"1-2 \"ARGS:wp_post\" Replace", expectNoErrorOnDirective},
+			{"1 replace", expectErrorOnDirective},
 		},
 		"SecRuleUpdateTargetByTag": {
 			{"", expectErrorOnDirective},
@@ -303,6 +354,13 @@ func TestDirectives(t *testing.T) {
 		"SecAuditLog": {
 			{"", expectErrorOnDirective},
 		},
+		"SecDefaultBlockPage": {
+			{"", expectErrorOnDirective},
+			{"html", expectErrorOnDirective},
+			{"xml <blocked/>", expectErrorOnDirective},
+			{`html "<h1>blocked</h1>"`, func(w *corazawaf.WAF) bool { return w.DefaultBlockPageHTML == "<h1>blocked</h1>" }},
+			{`JSON '{"error":"blocked"}'`, func(w *corazawaf.WAF) bool { return w.DefaultBlockPageJSON == `{"error":"blocked"}` }},
+		},
 		"SecArgumentsLimit": {
 			{"", expectErrorOnDirective},
 			{"0", expectErrorOnDirective},
@@ -310,6 +368,87 @@ func TestDirectives(t *testing.T) {
 			// according to modsec docs SecArgumentsLimit 1000
 			{"1000", func(waf *corazawaf.WAF) bool { return waf.ArgumentLimit == 1000 }},
 		},
+		"SecArgumentsLimitAction": {
+			{"", expectErrorOnDirective},
+			{"What?", expectErrorOnDirective},
+			{"Reject", func(w *corazawaf.WAF) bool { return w.ArgumentLimitAction == types.BodyLimitActionReject }},
+			{"ProcessPartial", func(w *corazawaf.WAF) bool { return w.ArgumentLimitAction == types.BodyLimitActionProcessPartial }},
+		},
+		"SecRequestHeadersLimit": {
+			{"", expectErrorOnDirective},
+			{"-1", expectErrorOnDirective},
+			{"abc", expectErrorOnDirective},
+			{"0", func(waf *corazawaf.WAF) bool { return waf.RequestHeadersLimit == 0 }},
+			{"100", func(waf *corazawaf.WAF) bool { return waf.RequestHeadersLimit == 100 }},
+		},
+		"SecRuleEvaluationTimeout": {
+			{"", expectErrorOnDirective},
+			{"-1", expectErrorOnDirective},
+			{"1s", expectErrorOnDirective},
+			{"0", func(waf *corazawaf.WAF) bool { return waf.RuleEvaluationTimeout == 0 }},
+			{"50", func(waf *corazawaf.WAF) bool { return waf.RuleEvaluationTimeout == 50*time.Millisecond }},
+		},
+		"SecRequestCookiesLimit": {
+			{"", expectErrorOnDirective},
+			{"-1", expectErrorOnDirective},
+			{"50", func(waf *corazawaf.WAF) bool { return waf.RequestCookiesLimit == 50 }},
+		},
+		"SecAuditLogStorageDir": {
+			{"", expectErrorOnDirective},
+			{"/tmp/audit", func(w *corazawaf.WAF) bool { return w.AuditLogWriterConfig.Dir == "/tmp/audit" }},
+		},
+		"SecAuditLogDirMode": {
+			{"", expectErrorOnDirective},
+			{"abc", expectErrorOnDirective},
+			{"0770", func(w *corazawaf.WAF) bool { return w.AuditLogWriterConfig.DirMode == 0770 }},
+			{"default", func(w *corazawaf.WAF) bool { return w.AuditLogWriterConfig.DirMode == 0700 }},
+		},
+		"SecAuditLogFileMode": {
+			{"", expectErrorOnDirective},
+			{"abc", expectErrorOnDirective},
+			{"0660", func(w *corazawaf.WAF) bool { return w.AuditLogWriterConfig.FileMode == 0660 }},
+			{"default", func(w *corazawaf.WAF) bool { return w.AuditLogWriterConfig.FileMode == 0600 }},
+		},
+		"SecPersistenceEngine": {
+			{"", expectErrorOnDirective},
+			{"unknown", expectErrorOnDirective},
+			{"memory redis://localhost:6379", expectErrorOnDirective},
+			{"Memory", func(w *corazawaf.WAF) bool { return w.Persistence != nil }},
+		},
+		"SecQueryStringStrict": {
+			{"", expectErrorOnDirective},
+			{"Ox", expectErrorOnDirective},
+			{"On", func(w *corazawaf.WAF) bool { return w.QueryStringStrict }},
+			{"Off", func(w *corazawaf.WAF) bool { return !w.QueryStringStrict }},
+		},
+		"SecTransformationErrorFlag": {
+			{"", expectErrorOnDirective},
+			{"Ox", expectErrorOnDirective},
+			{"On", func(w *corazawaf.WAF) bool { return w.TransformationErrorFlag }},
+			{"Off", func(w *corazawaf.WAF) bool { return !w.TransformationErrorFlag }},
+		},
+		"SecHostnameLookups": {
+			{"", expectErrorOnDirective},
+			{"Ox", expectErrorOnDirective},
+			{"On", func(w *corazawaf.WAF) bool { return w.HostnameLookups }},
+			{"Off", func(w *corazawaf.WAF) bool { return !w.HostnameLookups }},
+		},
+		"SecAnomalyScoreThreshold": {
+			{"", expectErrorOnDirective},
+			{"tx.anomaly_score", expectErrorOnDirective},
+			{"tx.anomaly_score abc", expectErrorOnDirective},
+			{"tx.anomaly_score 0", expectErrorOnDirective},
+			{"TX:Anomaly_Score 5", func(w *corazawaf.WAF) bool {
+				return len(w.AnomalyScoreThresholds) == 1 &&
+					w.AnomalyScoreThresholds[0] == corazawaf.AnomalyScoreThreshold{Variable: "anomaly_score", Threshold: 5}
+			}},
+		},
+		"SecDebugLogTransformations": {
+			{"", expectErrorOnDirective},
+			{"Ox", expectErrorOnDirective},
+			{"On", func(w *corazawaf.WAF) bool { return w.DebugLogTransformations }},
+			{"Off", func(w *corazawaf.WAF) bool { return !w.DebugLogTransformations }},
+		},
 	}
 	if environment.HasAccessToFS {
 		directiveCases["SecUploadDir"] = []directiveCase{
@@ -317,6 +456,16 @@ func TestDirectives(t *testing.T) {
 			{"/tmp-non-existing", expectErrorOnDirective},
 			{os.TempDir(), func(w *corazawaf.WAF) bool { return w.UploadDir == os.TempDir() }},
 		}
+		directiveCases["SecTmpDir"] = []directiveCase{
+			{"", expectErrorOnDirective},
+			{"/tmp-non-existing", expectErrorOnDirective},
+			{os.TempDir(), func(w *corazawaf.WAF) bool { return w.TmpDir == os.TempDir() }},
+		}
+	} else {
+		directiveCases["SecTmpDir"] = []directiveCase{
+			{"", expectErrorOnDirective},
+			{"/tmp-non-existing", func(w *corazawaf.WAF) bool { return w.TmpDir == "" }},
+		}
 	}
 
 	for name, dCases := range directiveCases {
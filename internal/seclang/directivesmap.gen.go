@@ -10,12 +10,14 @@ var (
 	_ directive = directiveSecMarker
 	_ directive = directiveSecAction
 	_ directive = directiveSecRule
+	_ directive = directiveSecRuleScript
 	_ directive = directiveSecResponseBodyAccess
 	_ directive = directiveSecRequestBodyLimit
 	_ directive = directiveSecRequestBodyAccess
 	_ directive = directiveSecRuleEngine
 	_ directive = directiveSecWebAppID
 	_ directive = directiveSecServerSignature
+	_ directive = directiveSecDefaultBlockPage
 	_ directive = directiveSecRuleRemoveByTag
 	_ directive = directiveSecRuleRemoveByMsg
 	_ directive = directiveSecRuleRemoveByID
@@ -34,6 +36,7 @@ var (
 	_ directive = directiveSecPcreMatchLimit
 	_ directive = directiveSecHTTPBlKey
 	_ directive = directiveSecGsbLookupDb
+	_ directive = directiveSecGeoLookupDb
 	_ directive = directiveSecHashMethodPm
 	_ directive = directiveSecHashMethodRx
 	_ directive = directiveSecHashParam
@@ -46,12 +49,15 @@ var (
 	_ directive = directiveSecAuditLogType
 	_ directive = directiveSecAuditLogFormat
 	_ directive = directiveSecAuditLogDir
+	_ directive = directiveSecAuditLogStorageDir
 	_ directive = directiveSecAuditLogDirMode
 	_ directive = directiveSecAuditLogFileMode
 	_ directive = directiveSecAuditLogRelevantStatus
 	_ directive = directiveSecAuditLogParts
 	_ directive = directiveSecAuditEngine
 	_ directive = directiveSecDataDir
+	_ directive = directiveSecPersistenceEngine
+	_ directive = directiveSecTmpDir
 	_ directive = directiveSecUploadKeepFiles
 	_ directive = directiveSecUploadFileMode
 	_ directive = directiveSecUploadFileLimit
@@ -59,12 +65,21 @@ var (
 	_ directive = directiveSecRequestBodyNoFilesLimit
 	_ directive = directiveSecDebugLog
 	_ directive = directiveSecDebugLogLevel
+	_ directive = directiveSecDebugLogTransformations
 	_ directive = directiveSecRuleUpdateTargetByID
 	_ directive = directiveSecRuleUpdateActionByID
 	_ directive = directiveSecRuleUpdateTargetByTag
 	_ directive = directiveSecIgnoreRuleCompilationErrors
 	_ directive = directiveSecDataset
 	_ directive = directiveSecArgumentsLimit
+	_ directive = directiveSecArgumentsLimitAction
+	_ directive = directiveSecRequestHeadersLimit
+	_ directive = directiveSecRequestCookiesLimit
+	_ directive = directiveSecQueryStringStrict
+	_ directive = directiveSecTransformationErrorFlag
+	_ directive = directiveSecHostnameLookups
+	_ directive = directiveSecAnomalyScoreThreshold
+	_ directive = directiveSecRuleEvaluationTimeout
 )
 
 var directivesMap = map[string]directive{
@@ -72,12 +87,14 @@ var directivesMap = map[string]directive{
 	"secmarker":                      directiveSecMarker,
 	"secaction":                      directiveSecAction,
 	"secrule":                        directiveSecRule,
+	"secrulescript":                  directiveSecRuleScript,
 	"secresponsebodyaccess":          directiveSecResponseBodyAccess,
 	"secrequestbodylimit":            directiveSecRequestBodyLimit,
 	"secrequestbodyaccess":           directiveSecRequestBodyAccess,
 	"secruleengine":                  directiveSecRuleEngine,
 	"secwebappid":                    directiveSecWebAppID,
 	"secserversignature":             directiveSecServerSignature,
+	"secdefaultblockpage":            directiveSecDefaultBlockPage,
 	"secruleremovebytag":             directiveSecRuleRemoveByTag,
 	"secruleremovebymsg":             directiveSecRuleRemoveByMsg,
 	"secruleremovebyid":              directiveSecRuleRemoveByID,
@@ -96,6 +113,7 @@ var directivesMap = map[string]directive{
 	"secpcrematchlimit":              directiveSecPcreMatchLimit,
 	"sechttpblkey":                   directiveSecHTTPBlKey,
 	"secgsblookupdb":                 directiveSecGsbLookupDb,
+	"secgeolookupdb":                 directiveSecGeoLookupDb,
 	"sechashmethodpm":                directiveSecHashMethodPm,
 	"sechashmethodrx":                directiveSecHashMethodRx,
 	"sechashparam":                   directiveSecHashParam,
@@ -108,12 +126,15 @@ var directivesMap = map[string]directive{
 	"secauditlogtype":                directiveSecAuditLogType,
 	"secauditlogformat":              directiveSecAuditLogFormat,
 	"secauditlogdir":                 directiveSecAuditLogDir,
+	"secauditlogstoragedir":          directiveSecAuditLogStorageDir,
 	"secauditlogdirmode":             directiveSecAuditLogDirMode,
 	"secauditlogfilemode":            directiveSecAuditLogFileMode,
 	"secauditlogrelevantstatus":      directiveSecAuditLogRelevantStatus,
 	"secauditlogparts":               directiveSecAuditLogParts,
 	"secauditengine":                 directiveSecAuditEngine,
 	"secdatadir":                     directiveSecDataDir,
+	"secpersistenceengine":           directiveSecPersistenceEngine,
+	"sectmpdir":                      directiveSecTmpDir,
 	"secuploadkeepfiles":             directiveSecUploadKeepFiles,
 	"secuploadfilemode":              directiveSecUploadFileMode,
 	"secuploadfilelimit":             directiveSecUploadFileLimit,
@@ -121,19 +142,26 @@ var directivesMap = map[string]directive{
 	"secrequestbodynofileslimit":     directiveSecRequestBodyNoFilesLimit,
 	"secdebuglog":                    directiveSecDebugLog,
 	"secdebugloglevel":               directiveSecDebugLogLevel,
+	"secdebuglogtransformations":     directiveSecDebugLogTransformations,
 	"secruleupdatetargetbyid":        directiveSecRuleUpdateTargetByID,
 	"secruleupdateactionbyid":        directiveSecRuleUpdateActionByID,
 	"secruleupdatetargetbytag":       directiveSecRuleUpdateTargetByTag,
 	"secignorerulecompilationerrors": directiveSecIgnoreRuleCompilationErrors,
 	"secdataset":                     directiveSecDataset,
 	"secargumentslimit":              directiveSecArgumentsLimit,
+	"secargumentslimitaction":        directiveSecArgumentsLimitAction,
+	"secrequestheaderslimit":         directiveSecRequestHeadersLimit,
+	"secrequestcookieslimit":         directiveSecRequestCookiesLimit,
+	"secquerystringstrict":           directiveSecQueryStringStrict,
+	"sectransformationerrorflag":     directiveSecTransformationErrorFlag,
+	"sechostnamelookups":             directiveSecHostnameLookups,
+	"secanomalyscorethreshold":       directiveSecAnomalyScoreThreshold,
+	"secruleevaluationtimeout":       directiveSecRuleEvaluationTimeout,
 
 	// Unsupported directives
 	"secargumentseparator":     directiveUnsupported,
 	"seccookieformat":          directiveUnsupported,
 	"secruleupdatetargetbymsg": directiveUnsupported,
-	"secrulescript":            directiveUnsupported,
 	"secruleperftime":          directiveUnsupported,
 	"secunicodemap":            directiveUnsupported,
-	"sectmpdir":                directiveUnsupported,
 }
@@ -0,0 +1,314 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package seclang
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+// chainIndent is the indentation of the chained rules in the formatted output
+const chainIndent = "    "
+
+// actionOrder is the position of the actions in the formatted action lists,
+// actions not listed go after them keeping their relative order, chain is
+// always the last one. The order of the transformations is kept.
+var actionOrder = map[string]int{
+	"id":         1,
+	"phase":      2,
+	"allow":      3,
+	"block":      3,
+	"deny":       3,
+	"drop":       3,
+	"pass":       3,
+	"pause":      3,
+	"redirect":   3,
+	"status":     4,
+	"capture":    5,
+	"t":          6,
+	"log":        7,
+	"nolog":      7,
+	"auditlog":   8,
+	"noauditlog": 8,
+	"msg":        9,
+	"logdata":    10,
+	"tag":        11,
+	"ver":        12,
+	"rev":        13,
+	"maturity":   14,
+	"accuracy":   15,
+	"severity":   16,
+}
+
+const (
+	otherActionsOrder = 100
+	chainActionOrder  = 101
+)
+
+// quotedActions are the actions whose argument is always quoted, as it is
+// usually free text or contains macros
+var quotedActions = map[string]bool{
+	"msg":      true,
+	"logdata":  true,
+	"tag":      true,
+	"ver":      true,
+	"severity": true,
+	"setvar":   true,
+}
+
+// actionNames are the canonical names of the actions which are not lowercase,
+// action names are parsed case insensitively
+var actionNames = map[string]string{
+	"multimatch":             "multiMatch",
+	"sanitisearg":            "sanitiseArg",
+	"sanitisematched":        "sanitiseMatched",
+	"sanitisematchedbytes":   "sanitiseMatchedBytes",
+	"sanitiserequestheader":  "sanitiseRequestHeader",
+	"sanitiseresponseheader": "sanitiseResponseHeader",
+	"skipafter":              "skipAfter",
+}
+
+// Format returns the directives in canonical SecLang: every directive on a
+// single line, the chained rules indented, the variables and the operators
+// of the rules normalized and their actions sorted. Comments and blank lines
+// are kept, directives other than SecRule, SecAction, SecMarker and
+// SecRuleScript are only trimmed. The formatted directives are equivalent to
+// the original ones.
+func Format(directives string) (string, error) {
+	var res strings.Builder
+	var line strings.Builder
+	inBackticks := false
+	inChain := false
+	scanner := bufio.NewScanner(strings.NewReader(directives))
+	for scanner.Scan() {
+		l := strings.TrimSpace(scanner.Text())
+		if l == "" || l[0] == '#' {
+			// the parser ignores them also inside line continuations
+			if line.Len() == 0 {
+				res.WriteString(l)
+				res.WriteByte('\n')
+			}
+			continue
+		}
+		if inBackticks || strings.HasSuffix(l, "`") {
+			// lists between backticks, like the ones of SecDataset, are kept as is
+			inBackticks = !inBackticks || !strings.HasPrefix(l, "`")
+			res.WriteString(l)
+			res.WriteByte('\n')
+			continue
+		}
+		if strings.HasSuffix(l, "\\") {
+			line.WriteString(strings.TrimSuffix(l, "\\"))
+			continue
+		}
+		line.WriteString(l)
+
+		formatted, chain, err := formatDirective(line.String())
+		if err != nil {
+			return "", err
+		}
+		line.Reset()
+		if inChain {
+			res.WriteString(chainIndent)
+		}
+		res.WriteString(formatted)
+		res.WriteByte('\n')
+		inChain = chain
+	}
+	if inBackticks {
+		return "", errors.New("backticks left open")
+	}
+	if line.Len() > 0 {
+		return "", errors.New("unterminated line continuation")
+	}
+	return res.String(), nil
+}
+
+// FormatRule returns the rule and its chained rules in canonical SecLang, as
+// Format does. It fails for rules that were not parsed from SecLang.
+func FormatRule(rule *corazawaf.Rule) (string, error) {
+	if rule.Raw_ == "" {
+		return "", errors.New("rule was not parsed from SecLang")
+	}
+	var res strings.Builder
+	// the raw text of the chained rules is appended to the one of the parent
+	for i, raw := range strings.Split(rule.Raw_, " \n") {
+		formatted, _, err := formatDirective(raw)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			res.WriteByte('\n')
+			res.WriteString(chainIndent)
+		}
+		res.WriteString(formatted)
+	}
+	return res.String(), nil
+}
+
+// formatDirective formats a single directive, it also returns whether the
+// directive is a rule starting or continuing a chain.
+func formatDirective(line string) (string, bool, error) {
+	name, opts, _ := strings.Cut(strings.TrimSpace(line), " ")
+	opts = strings.TrimSpace(opts)
+	switch strings.ToLower(name) {
+	case "secrule":
+		vars, op, actions, err := parseActionOperator(opts)
+		if err != nil {
+			return "", false, err
+		}
+		formattedActions, chain, err := formatActions(actions)
+		if err != nil {
+			return "", false, err
+		}
+		// the operator is kept escaped as it was written
+		res := fmt.Sprintf(`SecRule %s "%s"`, formatVariables(vars), formatOperator(op))
+		if formattedActions != "" {
+			res += ` "` + formattedActions + `"`
+		}
+		return res, chain, nil
+	case "secaction":
+		formattedActions, chain, err := formatActions(unquote(opts))
+		if err != nil {
+			return "", false, err
+		}
+		return `SecAction "` + formattedActions + `"`, chain, nil
+	case "secmarker":
+		return `SecMarker "` + unquote(opts) + `"`, false, nil
+	case "secrulescript":
+		path, actions, _ := strings.Cut(opts, " ")
+		formattedActions, chain, err := formatActions(unquote(strings.TrimSpace(actions)))
+		if err != nil {
+			return "", false, err
+		}
+		res := "SecRuleScript " + unquote(path)
+		if formattedActions != "" {
+			res += ` "` + formattedActions + `"`
+		}
+		return res, chain, nil
+	}
+	if opts == "" {
+		return name, false, nil
+	}
+	return name + " " + opts, false, nil
+}
+
+// formatVariables uppercases the names of the collections, the keys are kept
+// as they are case sensitive for some collections.
+func formatVariables(vars string) string {
+	parts := splitVariables(vars)
+	for i, v := range parts {
+		prefix := ""
+		if len(v) > 0 && (v[0] == '!' || v[0] == '&') {
+			prefix, v = v[:1], v[1:]
+		}
+		name, key, hasKey := strings.Cut(v, ":")
+		parts[i] = prefix + strings.ToUpper(name)
+		if hasKey {
+			parts[i] += ":" + key
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// splitVariables splits the variables of a rule by the pipes which are not
+// part of a regular expression or a quoted key.
+func splitVariables(vars string) []string {
+	var parts []string
+	inRegex, inQuotes := false, false
+	start := 0
+	for i := 0; i < len(vars); i++ {
+		switch c := vars[i]; {
+		case c == '\\':
+			i++
+		case inRegex:
+			inRegex = c != '/'
+		case inQuotes:
+			inQuotes = c != '\''
+		case c == '/' && i > 0 && vars[i-1] == ':':
+			inRegex = true
+		case c == '\'' && i > 0 && vars[i-1] == ':':
+			inQuotes = true
+		case c == '|':
+			parts = append(parts, vars[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, vars[start:])
+}
+
+// formatOperator makes the implicit @rx operator explicit.
+func formatOperator(op string) string {
+	negation := strings.HasPrefix(op, "!")
+	op = strings.TrimPrefix(op, "!")
+	if !strings.HasPrefix(op, "@") {
+		op = "@rx " + op
+	}
+	if negation {
+		op = "!" + op
+	}
+	return op
+}
+
+// formatActions sorts the actions and normalizes their names and quoting, it
+// also returns whether the actions contain chain.
+func formatActions(actions string) (string, bool, error) {
+	if actions == "" {
+		return "", false, nil
+	}
+	parsed, err := parseActions(actions)
+	if err != nil {
+		return "", false, err
+	}
+	order := func(a ruleAction) int {
+		if a.Key == "chain" {
+			return chainActionOrder
+		}
+		if o, ok := actionOrder[a.Key]; ok {
+			return o
+		}
+		if a.Atype == plugintypes.ActionTypeDisruptive {
+			return actionOrder["deny"]
+		}
+		return otherActionsOrder
+	}
+	// insertion sort keeps the relative order of the actions with the same
+	// position, e.g. transformations and setvars
+	for i := 1; i < len(parsed); i++ {
+		for j := i; j > 0 && order(parsed[j]) < order(parsed[j-1]); j-- {
+			parsed[j], parsed[j-1] = parsed[j-1], parsed[j]
+		}
+	}
+
+	chain := false
+	formatted := make([]string, 0, len(parsed))
+	for _, a := range parsed {
+		name := a.Key
+		if n, ok := actionNames[name]; ok {
+			name = n
+		}
+		chain = chain || a.Key == "chain"
+		switch {
+		case a.Value == "":
+			formatted = append(formatted, name)
+		case quotedActions[a.Key] || strings.ContainsAny(a.Value, ", "):
+			formatted = append(formatted, name+":'"+a.Value+"'")
+		default:
+			formatted = append(formatted, name+":"+a.Value)
+		}
+	}
+	return strings.Join(formatted, ","), chain, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package seclang
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+const formatTestRules = `
+# comments are kept
+SecRuleEngine On
+SecAction "nolog, phase:1,id:1, setvar:'tx.score=0',pass"
+SecRule args_get:id|!ARGS_GET:/^x-/|&REQUEST_HEADERS:User-Agent "@rx ^\d+\"$" \
+	"msg:'numeric id',t:lowercase,t:none,phase:1,severity:'CRITICAL',id:2,tag:attack,tag:'OWASP CRS',log,deny,status:403"
+SecRule REMOTE_ADDR "!127.0.0.1" "skipafter:END,id:3,phase:1,pass,nolog"
+SecRule ARGS "attack" "chain,id:4,phase:2,block,logdata:'%{MATCHED_VAR}',multimatch"
+	SecRule MATCHED_VAR "@contains attack" "t:none,setvar:tx.score=+5"
+SecMarker END
+SecRule TX:SCORE "@ge 5" "id:5,phase:2,deny,status:401,nolog,ctl:ruleRemoveById=3"
+`
+
+const formatTestExpected = `
+# comments are kept
+SecRuleEngine On
+SecAction "id:1,phase:1,pass,nolog,setvar:'tx.score=0'"
+SecRule ARGS_GET:id|!ARGS_GET:/^x-/|&REQUEST_HEADERS:User-Agent "@rx ^\d+\"$" "id:2,phase:1,deny,status:403,t:lowercase,t:none,log,msg:'numeric id',tag:'attack',tag:'OWASP CRS',severity:'CRITICAL'"
+SecRule REMOTE_ADDR "!@rx 127.0.0.1" "id:3,phase:1,pass,nolog,skipAfter:END"
+SecRule ARGS "@rx attack" "id:4,phase:2,block,logdata:'%{MATCHED_VAR}',multiMatch,chain"
+    SecRule MATCHED_VAR "@contains attack" "t:none,setvar:'tx.score=+5'"
+SecMarker "END"
+SecRule TX:SCORE "@ge 5" "id:5,phase:2,deny,status:401,nolog,ctl:ruleRemoveById=3"
+`
+
+func TestFormat(t *testing.T) {
+	formatted, err := Format(formatTestRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if formatted != formatTestExpected {
+		t.Errorf("unexpected formatted rules:\n%s", formatted)
+	}
+
+	again, err := Format(formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != formatted {
+		t.Errorf("formatting is not idempotent:\n%s", again)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	formatted, err := Format(formatTestRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := corazawaf.NewWAF()
+	if err := NewParser(original).FromString(formatTestRules); err != nil {
+		t.Fatal(err)
+	}
+	reparsed := corazawaf.NewWAF()
+	if err := NewParser(reparsed).FromString(formatted); err != nil {
+		t.Fatalf("failed to parse the formatted rules: %s\n%s", err.Error(), formatted)
+	}
+
+	rules, reparsedRules := original.Rules.GetRules(), reparsed.Rules.GetRules()
+	if len(rules) != len(reparsedRules) {
+		t.Fatalf("unexpected number of rules, want %d, have %d", len(rules), len(reparsedRules))
+	}
+	for i := range rules {
+		r, rr := &rules[i], &reparsedRules[i]
+		if r.ID_ != rr.ID_ || r.Phase_ != rr.Phase_ || r.Msg != nil && r.Msg.String() != rr.Msg.String() ||
+			!slices.Equal(r.Tags_, rr.Tags_) || r.Severity_ != rr.Severity_ || r.SecMark_ != rr.SecMark_ {
+			t.Errorf("rule %d differs after formatting", r.ID_)
+		}
+		want, err := FormatRule(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have, err := FormatRule(rr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != have {
+			t.Errorf("unexpected formatted rule, want %q, have %q", want, have)
+		}
+	}
+
+	matches := func(waf *corazawaf.WAF) []int {
+		tx := waf.NewTransaction()
+		defer tx.Close()
+		tx.ProcessConnection("10.0.0.1", 0, "", 0)
+		tx.AddGetRequestArgument("id", `12"`)
+		tx.AddPostRequestArgument("q", "attack")
+		tx.ProcessRequestHeaders()
+		if _, err := tx.ProcessRequestBody(); err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, mr := range tx.MatchedRules() {
+			ids = append(ids, mr.Rule().ID())
+		}
+		return ids
+	}
+	want, have := matches(original), matches(reparsed)
+	if len(want) == 0 {
+		t.Fatal("expected matched rules")
+	}
+	if !slices.Equal(want, have) {
+		t.Errorf("unexpected matched rules after formatting, want %v, have %v", want, have)
+	}
+}
+
+func TestFormatRule(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	if err := NewParser(waf).FromString(formatTestRules); err != nil {
+		t.Fatal(err)
+	}
+	rules := waf.Rules.GetRules()
+	have, err := FormatRule(&rules[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `SecRule ARGS "@rx attack" "id:4,phase:2,block,logdata:'%{MATCHED_VAR}',multiMatch,chain"
+    SecRule MATCHED_VAR "@contains attack" "t:none,setvar:'tx.score=+5'"`
+	if want != have {
+		t.Errorf("unexpected formatted rule, want %q, have %q", want, have)
+	}
+
+	if _, err := FormatRule(corazawaf.NewRule()); err == nil {
+		t.Error("expected error for a rule not parsed from SecLang")
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	for name, directives := range map[string]string{
+		"unknown action":    `SecRule ARGS "a" "id:1,unknown"`,
+		"missing operator":  `SecRule ARGS`,
+		"open backticks":    "SecDataset list `\na",
+		"open continuation": `SecRule ARGS "a" \`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Format(directives); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
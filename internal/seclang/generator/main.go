@@ -59,7 +59,7 @@ func main() {
 
 			directiveName := fnName[9:]
 
-			if directiveName == "Include" || directiveName == "Unsupported" {
+			if directiveName == "Include" || directiveName == "IncludeOptional" || directiveName == "Unsupported" {
 				return true
 			}
 
@@ -0,0 +1,18 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.geoLookup
+
+package seclang
+
+import (
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+	"github.com/corazawaf/coraza/v3/internal/geo"
+)
+
+// openGeoDatabase opens the MaxMind database loaded by SecGeoLookupDb, it is
+// kept apart so the coraza.disabled_operators.geoLookup build tag drops the
+// MaxMind dependency.
+func openGeoDatabase(data []byte) (plugintypes.GeoDatabase, error) {
+	return geo.FromBytes(data)
+}
@@ -0,0 +1,16 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build coraza.disabled_operators.geoLookup
+
+package seclang
+
+import (
+	"errors"
+
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
+)
+
+func openGeoDatabase([]byte) (plugintypes.GeoDatabase, error) {
+	return nil, errors.New("SecGeoLookupDb is not supported, the geoLookup operator is disabled")
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.disabled_operators.geoLookup
+
+package seclang
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+func TestSecGeoLookupDb(t *testing.T) {
+	data, err := os.ReadFile("../geo/testdata/GeoLite2-City-Test.mmdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := fstest.MapFS{
+		"rules/geo/GeoLite2-City-Test.mmdb": &fstest.MapFile{Data: data},
+		"rules/geo.conf": &fstest.MapFile{Data: []byte(`
+SecGeoLookupDb geo/GeoLite2-City-Test.mmdb
+SecRule REMOTE_ADDR "@geoLookup" "id:1,phase:1,pass,nolog,chain"
+SecRule GEO:COUNTRY_CODE "@streq GB" "setvar:tx.country=%{geo.country_code}"
+`)},
+		"rules/invalid.conf": &fstest.MapFile{Data: []byte("SecGeoLookupDb geo.conf\n")},
+	}
+
+	waf := corazawaf.NewWAF()
+	p := NewParser(waf)
+	p.SetRoot(root)
+	if err := p.FromFile("rules/geo.conf"); err != nil {
+		t.Fatal(err)
+	}
+	if waf.GeoDB == nil {
+		t.Fatal("expected the geo database to be loaded")
+	}
+
+	tx := waf.NewTransaction()
+	tx.ProcessConnection("81.2.69.142", 1234, "", 0)
+	tx.ProcessRequestHeaders()
+	if have := tx.Variables().TX().Get("country"); len(have) != 1 || have[0] != "GB" {
+		t.Errorf("unexpected TX:country, have %q", have)
+	}
+
+	if err := NewParser(corazawaf.NewWAF()).FromString("SecGeoLookupDb /non-existing.mmdb"); err == nil {
+		t.Error("expected error for a missing database")
+	}
+	p = NewParser(corazawaf.NewWAF())
+	p.SetRoot(root)
+	if err := p.FromFile("rules/invalid.conf"); err == nil {
+		t.Error("expected error for an invalid database")
+	}
+}
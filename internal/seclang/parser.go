@@ -10,6 +10,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
@@ -28,17 +29,35 @@ type Parser struct {
 	currentDir   string
 	root         fs.FS
 	includeCount int
+	// includeStack holds the files being parsed, the last one is the current
+	// file. It is used to detect include cycles.
+	includeStack []string
+	// remoteRules caches the rules fetched by SecRemoteRules, so including them
+	// again does not download them again
+	remoteRules map[string]string
 }
 
 // FromFile imports directives from a file
 // It will return error if any directive fails to parse
 // or the file does not exist.
 // If the path contains a *, it will be expanded to all
-// files in the directory matching the pattern.
+// files in the directory matching the pattern, which are
+// loaded in lexical order.
 // It will return an error if there are no files matching the pattern.
 func (p *Parser) FromFile(profilePath string) error {
+	return p.fromFile(profilePath, false)
+}
+
+// fromFile imports directives from a file, if optional is true a missing file
+// or a glob not matching any file are silently skipped, as done by IncludeOptional.
+func (p *Parser) fromFile(profilePath string, optional bool) error {
 	originalDir := p.currentDir
 
+	profilePath = strings.TrimSpace(profilePath)
+	if !strings.HasPrefix(profilePath, "/") {
+		profilePath = filepath.Join(p.currentDir, profilePath)
+	}
+
 	var files []string
 	if strings.Contains(profilePath, "*") {
 		var err error
@@ -46,8 +65,11 @@ func (p *Parser) FromFile(profilePath string) error {
 		if err != nil {
 			return fmt.Errorf("failed to glob: %s", err.Error())
 		}
+		// the order of the files defines the order the rules are evaluated in,
+		// it must not depend on the order the filesystem lists them
+		slices.Sort(files)
 
-		if len(files) == 0 {
+		if len(files) == 0 && !optional {
 			p.options.WAF.Logger.Warn().Int("line", p.currentLine).Msg("empty glob result")
 		}
 	} else {
@@ -55,14 +77,21 @@ func (p *Parser) FromFile(profilePath string) error {
 	}
 
 	for _, profilePath := range files {
-		profilePath = strings.TrimSpace(profilePath)
-		if !strings.HasPrefix(profilePath, "/") {
-			profilePath = filepath.Join(p.currentDir, profilePath)
+		if i := slices.Index(p.includeStack, profilePath); i >= 0 {
+			cycle := append(slices.Clone(p.includeStack[i:]), profilePath)
+			p.currentDir = originalDir
+			p.currentFile = ""
+			return p.logAndReturnErr(fmt.Sprintf("include cycle detected: %s", strings.Join(cycle, " -> ")))
 		}
 		p.currentFile = profilePath
 		lastDir := p.currentDir
 		p.currentDir = filepath.Dir(profilePath)
 		file, err := fs.ReadFile(p.root, profilePath)
+		if err != nil && optional && errors.Is(err, fs.ErrNotExist) {
+			p.options.WAF.Logger.Debug().Str("path", profilePath).Msg("Skipping missing optional include")
+			p.currentDir = lastDir
+			continue
+		}
 		if err != nil {
 			// we don't use defer for this as tinygo does not seem to like it
 			p.currentDir = originalDir
@@ -70,7 +99,9 @@ func (p *Parser) FromFile(profilePath string) error {
 			return fmt.Errorf("failed to readfile: %s", err.Error())
 		}
 
+		p.includeStack = append(p.includeStack, profilePath)
 		err = p.parseString(string(file))
+		p.includeStack = p.includeStack[:len(p.includeStack)-1]
 		if err != nil {
 			// we don't use defer for this as tinygo does not seem to like it
 			p.currentDir = originalDir
@@ -143,6 +174,9 @@ func (p *Parser) parseString(data string) error {
 	if inBackticks {
 		return errors.New("backticks left open")
 	}
+	if parent := getLastRuleExpectingChain(p.options.WAF); parent != nil {
+		return p.logAndReturnErr(fmt.Sprintf("rule %d expects a chained rule but there are no more rules", parent.ID_))
+	}
 	return nil
 }
 
@@ -160,16 +194,26 @@ func (p *Parser) evaluateLine(l string) error {
 		opts = strings.Trim(opts, `"`)
 	}
 
-	if directive == "include" {
+	if directive == "include" || directive == "includeoptional" {
 		// this is a special hardcoded case
 		// we cannot add it as a directive type because there are recursion issues
-		// note a user might still include another file that includes the original file
-		// generating a DDOS attack
+		// include cycles are detected by FromFile, the number of includes is still
+		// limited to avoid DDOS attacks by including the same files many times
+		if p.includeCount >= maxIncludeRecursion {
+			return p.logAndReturnErr(fmt.Sprintf("cannot include more than %d files", maxIncludeRecursion))
+		}
+		p.includeCount++
+		return p.fromFile(opts, directive == "includeoptional")
+	}
+
+	if directive == "secremoterules" {
+		// like include, remote rules are parsed by this parser. They count as includes
+		// as they could include themselves
 		if p.includeCount >= maxIncludeRecursion {
 			return p.logAndReturnErr(fmt.Sprintf("cannot include more than %d files", maxIncludeRecursion))
 		}
 		p.includeCount++
-		return p.FromFile(opts)
+		return p.fromRemote(opts)
 	}
 
 	d, ok := directivesMap[directive]
@@ -209,6 +253,9 @@ func (p *Parser) logAndReturnErr(msg string) error {
 // - os.DirFS to set a path to resolve relative paths from.
 // - embed.FS to read rules from an embedded filesystem.
 // - zip.Reader to read rules from a zip file.
+//
+// Operators loading files, like @pmFromFile and @ipMatchFromFile, read them from
+// the root as well.
 func (p *Parser) SetRoot(root fs.FS) {
 	p.root = root
 }
@@ -10,8 +10,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/jcchavezs/mergefs"
 	"github.com/jcchavezs/mergefs/io"
@@ -241,25 +243,193 @@ func TestHardcodedIncludeDirectiveDDOS2(t *testing.T) {
 	}
 }
 
+func TestIncludeCycle(t *testing.T) {
+	root := fstest.MapFS{
+		"a.conf":       {Data: []byte("Include rules/b.conf\n")},
+		"rules/b.conf": {Data: []byte("SecAction \"id:1,phase:1,pass,nolog\"\nInclude ../a.conf\n")},
+		"self.conf":    {Data: []byte("Include self.conf\n")},
+	}
+	tests := map[string]string{
+		"a.conf":    "include cycle detected: a.conf -> rules/b.conf -> a.conf",
+		"self.conf": "include cycle detected: self.conf -> self.conf",
+	}
+	for file, want := range tests {
+		t.Run(file, func(t *testing.T) {
+			p := NewParser(coraza.NewWAF())
+			p.SetRoot(root)
+			err := p.FromFile(file)
+			if err == nil {
+				t.Fatal("expected error for include cycle")
+			}
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("unexpected error, want %q, have %q", want, err.Error())
+			}
+		})
+	}
+}
+
+func TestIncludeSameFileTwice(t *testing.T) {
+	root := fstest.MapFS{
+		"main.conf":   {Data: []byte("Include common.conf\nInclude common.conf\n")},
+		"common.conf": {Data: []byte("SecRuleEngine On\n")},
+	}
+	p := NewParser(coraza.NewWAF())
+	p.SetRoot(root)
+	if err := p.FromFile("main.conf"); err != nil {
+		t.Errorf("unexpected error including a file twice: %s", err.Error())
+	}
+}
+
+func TestIncludeOptional(t *testing.T) {
+	root := fstest.MapFS{
+		"rules/main.conf": {Data: []byte("SecAction \"id:1,phase:1,pass,nolog\"\n")},
+	}
+	tests := []struct {
+		directive   string
+		expectError bool
+		expectRules int
+	}{
+		{directive: "Include rules/missing.conf", expectError: true},
+		{directive: "Include rules/*.missing"},
+		{directive: "Include rules/main.conf", expectRules: 1},
+		{directive: "IncludeOptional rules/missing.conf"},
+		{directive: "IncludeOptional rules/*.missing"},
+		{directive: "IncludeOptional rules/main.conf", expectRules: 1},
+		{directive: "IncludeOptional rules/*.conf", expectRules: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.directive, func(t *testing.T) {
+			waf := coraza.NewWAF()
+			p := NewParser(waf)
+			p.SetRoot(root)
+			err := p.FromString(tt.directive)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if want, have := tt.expectRules, waf.Rules.Count(); want != have {
+				t.Errorf("unexpected number of rules, want %d, have %d", want, have)
+			}
+		})
+	}
+}
+
+func TestIncludeOptionalReadError(t *testing.T) {
+	root := fstest.MapFS{
+		// a directory can't be read as a file
+		"rules/dir/nested.conf": {Data: []byte("SecRuleEngine On\n")},
+	}
+	p := NewParser(coraza.NewWAF())
+	p.SetRoot(root)
+	if err := p.FromString("IncludeOptional rules/dir"); err == nil {
+		t.Error("expected error for a file existing but not readable")
+	}
+}
+
 func TestChains(t *testing.T) {
-	/*
-		waf := coraza.NewWAF()
-		p, _ := NewParser(waf)
-		if err := p.FromString(`
-		SecAction "id:1,deny,log,phase:1,chain"
-		SecRule ARGS "chain"
-		SecRule REQUEST_HEADERS ""
-		`); err != nil {
-			t.Error("Could not create from string")
+	waf := coraza.NewWAF()
+	p := NewParser(waf)
+	if err := p.FromString(`
+	SecAction "id:1,deny,log,phase:1,chain"
+	SecRule ARGS "x" "chain"
+	SecRule REQUEST_HEADERS "y"
+	`); err != nil {
+		t.Fatal(err)
+	}
+	rules := waf.Rules.GetRules()
+	if len(rules) != 1 || rules[0].Chain == nil {
+		t.Fatalf("Chain not created %v", rules)
+	}
+	if rules[0].Chain.Chain == nil {
+		t.Error("Chain over chain not created")
+	}
+	if rules[0].Chain.Chain.Chain != nil {
+		t.Error("Unexpected chained rule after the last one")
+	}
+}
+
+func TestChainsCaptureVisibleToChainedRules(t *testing.T) {
+	waf := coraza.NewWAF()
+	p := NewParser(waf)
+	if err := p.FromString(`
+	SecRule ARGS:id "@rx ^(\d+)-(\d+)$" "id:1,phase:1,deny,capture,chain"
+	SecRule TX:1 "@eq 12" "chain"
+	SecRule TX:2 "@eq 34"
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	for value, interrupted := range map[string]bool{
+		"12-34": true,
+		"12-35": false,
+		"13-34": false,
+	} {
+		tx := waf.NewTransaction()
+		tx.AddGetRequestArgument("id", value)
+		if it := tx.ProcessRequestHeaders(); (it != nil) != interrupted {
+			t.Errorf("unexpected interruption for %q, want %t, have %v", value, interrupted, it)
 		}
-		rules := waf.Rules.GetRules()
-		if len(rules) != 1 || rules[0].Chain == nil {
-			t.Errorf("Chain not created %v", rules[0])
-			return
+		if err := tx.Close(); err != nil {
+			t.Fatal(err)
 		}
-			if rules[0].Chain.Chain == nil {
-				t.Error("Chain over chain not created")
-			}*/
+	}
+}
+
+func TestChainsCaptureInChainedRuleOperator(t *testing.T) {
+	waf := coraza.NewWAF()
+	p := NewParser(waf)
+	// the chained rules capture nothing, so the captures of the parent are kept
+	// along the chain, even when the chained rules use @rx
+	if err := p.FromString(`
+	SecRule ARGS:email "@rx ^[^@]+@([a-z.]+)$" "id:1,phase:1,deny,capture,chain"
+	SecRule ARGS:domain "@streq %{tx.1}" "chain"
+	SecRule REQUEST_HEADERS:host "@rx ^www\.(.+)$" "chain"
+	SecRule REQUEST_HEADERS:host "@endsWith %{tx.1}"
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		email, domain, host string
+		interrupted         bool
+	}{
+		{"user@coraza.io", "coraza.io", "www.coraza.io", true},
+		{"user@coraza.io", "coreruleset.org", "www.coraza.io", false},
+		{"user@coraza.io", "coraza.io", "www.coreruleset.org", false},
+		{"not-an-email", "not-an-email", "www.coraza.io", false},
+	}
+	for _, test := range tests {
+		tx := waf.NewTransaction()
+		tx.AddGetRequestArgument("email", test.email)
+		tx.AddGetRequestArgument("domain", test.domain)
+		tx.AddRequestHeader("Host", test.host)
+		if it := tx.ProcessRequestHeaders(); (it != nil) != test.interrupted {
+			t.Errorf("unexpected interruption for %+v, have %v", test, it)
+		}
+		if err := tx.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestChainWithoutChainedRule(t *testing.T) {
+	waf := coraza.NewWAF()
+	p := NewParser(waf)
+	err := p.FromString(`
+	SecAction "id:1,deny,log,phase:1,chain"
+	SecRule ARGS "x" "chain"
+	`)
+	if err == nil {
+		t.Fatal("expected an error for a chain action without a following rule")
+	}
+	if !strings.Contains(err.Error(), "rule 1 expects a chained rule") {
+		t.Errorf("unexpected error: %s", err)
+	}
 }
 
 func TestEmbedFS(t *testing.T) {
@@ -278,6 +448,101 @@ func TestEmbedFS(t *testing.T) {
 	}
 }
 
+// reverseGlobFS lists the files matching a pattern in reverse order, the
+// order of fs.GlobFS implementations is not specified.
+type reverseGlobFS struct {
+	fstest.MapFS
+}
+
+func (f reverseGlobFS) Glob(pattern string) ([]string, error) {
+	files, err := f.MapFS.Glob(pattern)
+	slices.Reverse(files)
+	return files, err
+}
+
+func TestRuleOrderAcrossIncludes(t *testing.T) {
+	files := fstest.MapFS{
+		"rules/main.conf": &fstest.MapFile{Data: []byte(`
+SecAction "id:1,phase:2,pass,log"
+Include conf.d/*.conf
+SecAction "id:7,phase:1,pass,log"
+`)},
+		"rules/conf.d/10-first.conf": &fstest.MapFile{Data: []byte(`
+SecAction "id:2,phase:1,pass,log"
+SecAction "id:3,phase:2,pass,log"
+`)},
+		"rules/conf.d/20-second.conf": &fstest.MapFile{Data: []byte(`
+SecAction "id:4,phase:2,pass,log"
+Include ../extra.conf
+SecAction "id:6,phase:1,pass,log"
+`)},
+		"rules/extra.conf": &fstest.MapFile{Data: []byte(`
+SecAction "id:5,phase:1,pass,log"
+`)},
+	}
+	want := []int{2, 5, 6, 7, 1, 3, 4}
+
+	for name, root := range map[string]fs.FS{
+		"sorted glob":   files,
+		"unsorted glob": reverseGlobFS{files},
+	} {
+		t.Run(name, func(t *testing.T) {
+			waf := coraza.NewWAF()
+			p := NewParser(waf)
+			p.SetRoot(root)
+			if err := p.FromFile("rules/main.conf"); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 10; i++ {
+				tx := waf.NewTransaction()
+				tx.ProcessRequestHeaders()
+				if _, err := tx.ProcessRequestBody(); err != nil {
+					t.Fatal(err)
+				}
+				var have []int
+				for _, mr := range tx.MatchedRules() {
+					have = append(have, mr.Rule().ID())
+				}
+				if !slices.Equal(have, want) {
+					t.Fatalf("unexpected rule order, want %v, have %v", want, have)
+				}
+				tx.Close()
+			}
+		})
+	}
+}
+
+func TestEmbedFSFileOperators(t *testing.T) {
+	root, err := fs.Sub(testdata, "testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// @pmf is the ModSecurity shorthand of @pmFromFile
+	for _, op := range []string{"@pmFromFile", "@pmf"} {
+		t.Run(op, func(t *testing.T) {
+			waf := coraza.NewWAF()
+			p := NewParser(waf)
+			p.SetRoot(root)
+			if err := p.FromString(`SecRule ARGS "` + op + ` includes/subinclude/pmFromFile-01.dat" "id:1,phase:1,log,pass"`); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.FromString(`SecRule ARGS "` + op + ` includes/subinclude/missing.dat" "id:2,phase:1,log,pass"`); err == nil {
+				t.Error("expected error for a file missing in the embedded filesystem")
+			}
+
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.AddGetRequestArgument("q", "xxx ghi")
+			tx.ProcessRequestHeaders()
+			if len(tx.MatchedRules()) != 1 {
+				t.Errorf("expected the rule loading the embedded file to match")
+			}
+		})
+	}
+}
+
 //go:embed testdata/parserbenchmark.conf
 var parsingRule string
 
@@ -289,3 +554,20 @@ func BenchmarkParseFromString(b *testing.B) {
 		_ = parser.FromString(parsingRule)
 	}
 }
+
+// BenchmarkParseCRS parses the CRS into a new WAF every time, as when reloading
+// the configuration. The regular expressions are compiled once and shared by the
+// following iterations.
+func BenchmarkParseCRS(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := NewParser(coraza.NewWAF())
+		p.SetRoot(mergefs.Merge(coreruleset.FS, io.OSFS))
+		if err := p.FromFile("../../coraza.conf-recommended"); err != nil {
+			b.Fatal(err)
+		}
+		if err := p.FromString("Include @crs-setup.conf.example\nInclude @owasp_crs/*.conf"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
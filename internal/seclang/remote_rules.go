@@ -0,0 +1,123 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+// +build !tinygo
+
+package seclang
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// remoteRulesMaxSize limits the size of the fetched rules, so a malicious
+	// server cannot exhaust the memory
+	remoteRulesMaxSize = 10 * 1024 * 1024
+	// remoteRulesKeyHeader is the header carrying the key, as sent by ModSecurity
+	remoteRulesKeyHeader = "ModSec-key"
+)
+
+// remoteRulesClient fetches the remote rules, the default transport verifies
+// the certificate of the server.
+var remoteRulesClient = &http.Client{
+	Timeout:       30 * time.Second,
+	CheckRedirect: checkRemoteRulesRedirect,
+}
+
+// checkRemoteRulesRedirect only follows redirects over HTTPS, so the key is never
+// sent in cleartext nor the rules fetched without TLS. The key is only sent to the
+// host of the directive.
+func checkRemoteRulesRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("remote rules redirected to %s, only HTTPS is allowed", req.URL.Scheme)
+	}
+	if len(via) >= 10 {
+		return errors.New("remote rules stopped after 10 redirects")
+	}
+	if req.URL.Host != via[0].URL.Host {
+		req.Header.Del(remoteRulesKeyHeader)
+	}
+	return nil
+}
+
+// fromRemote fetches the rules of a SecRemoteRules directive and parses them.
+// When the rules cannot be fetched, the error is returned if
+// SecRemoteRulesFailAction is Abort and only logged otherwise.
+func (p *Parser) fromRemote(opts string) error {
+	args := strings.Fields(opts)
+	if len(args) != 2 {
+		return p.logAndReturnErr("SecRemoteRules requires a key and a URL")
+	}
+	key, rulesURL := args[0], args[1]
+
+	cacheKey := key + " " + rulesURL
+	rules, ok := p.remoteRules[cacheKey]
+	if !ok {
+		var err error
+		rules, err = fetchRemoteRules(key, rulesURL)
+		if err != nil {
+			if p.options.WAF.AbortOnRemoteRulesFail {
+				return p.logAndReturnErr(fmt.Sprintf("failed to fetch remote rules: %s", err.Error()))
+			}
+			p.options.WAF.Logger.Warn().
+				Str("url", rulesURL).
+				Err(err).
+				Msg("Failed to fetch remote rules, skipping them")
+			return nil
+		}
+		if p.remoteRules == nil {
+			p.remoteRules = map[string]string{}
+		}
+		p.remoteRules[cacheKey] = rules
+	}
+
+	oldCurrentFile := p.currentFile
+	p.currentFile = rulesURL
+	err := p.parseString(rules)
+	p.currentFile = oldCurrentFile
+	if err != nil {
+		return fmt.Errorf("failed to parse remote rules from %s: %w", rulesURL, err)
+	}
+	return nil
+}
+
+func fetchRemoteRules(key string, rulesURL string) (string, error) {
+	u, err := url.Parse(rulesURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "https" {
+		return "", errors.New("remote rules must be fetched over HTTPS")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(remoteRulesKeyHeader, key)
+
+	res, err := remoteRulesClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, remoteRulesMaxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(body) > remoteRulesMaxSize {
+		return "", fmt.Errorf("remote rules exceed the maximum size of %d bytes", remoteRulesMaxSize)
+	}
+	return string(body), nil
+}
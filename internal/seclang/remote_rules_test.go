@@ -0,0 +1,158 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+// +build !tinygo
+
+package seclang
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+func newRemoteRulesServer(t *testing.T, rules string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	requests := &atomic.Int32{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("ModSec-key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(rules))
+	}))
+	t.Cleanup(srv.Close)
+
+	// trust the certificate of the test server
+	client := remoteRulesClient
+	remoteRulesClient = srv.Client()
+	remoteRulesClient.CheckRedirect = checkRemoteRulesRedirect
+	t.Cleanup(func() { remoteRulesClient = client })
+	return srv, requests
+}
+
+func TestSecRemoteRules(t *testing.T) {
+	srv, requests := newRemoteRulesServer(t, `
+SecRuleEngine On
+SecRule ARGS:id "@eq 1" "id:1,phase:1,deny,status:403"
+`)
+
+	waf := corazawaf.NewWAF()
+	p := NewParser(waf)
+	if err := p.FromString("SecRemoteRules secret " + srv.URL + "/rules.conf"); err != nil {
+		t.Fatal(err)
+	}
+	if waf.Rules.Count() != 1 {
+		t.Fatalf("unexpected number of rules, want 1, have %d", waf.Rules.Count())
+	}
+
+	tx := waf.NewTransaction()
+	tx.AddGetRequestArgument("id", "1")
+	if it := tx.ProcessRequestHeaders(); it == nil || it.Status != 403 {
+		t.Errorf("expected the remote rule to interrupt the transaction, have %v", it)
+	}
+	_ = tx.Close()
+
+	// the rules are cached, parsing them again only fails because of the duplicated ID
+	err := p.FromString("SecRemoteRules secret " + srv.URL + "/rules.conf")
+	if err == nil || !strings.Contains(err.Error(), "another rule with id 1") {
+		t.Errorf("expected a duplicated rule error, have %v", err)
+	}
+	if have := requests.Load(); have != 1 {
+		t.Errorf("expected the rules to be fetched once, have %d requests", have)
+	}
+}
+
+func TestSecRemoteRulesFailAction(t *testing.T) {
+	srv, _ := newRemoteRulesServer(t, `SecRule ARGS:id "@eq 1" "id:1,phase:1,deny"`)
+
+	tests := map[string]string{
+		"wrong key":       "SecRemoteRules wrong " + srv.URL,
+		"plain HTTP":      "SecRemoteRules secret " + strings.Replace(srv.URL, "https://", "http://", 1),
+		"missing URL":     "SecRemoteRules secret",
+		"unreachable URL": "SecRemoteRules secret https://127.0.0.1:1/rules.conf",
+	}
+	for name, directive := range tests {
+		t.Run(name, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			if err := NewParser(waf).FromString("SecRemoteRulesFailAction Abort\n" + directive); err == nil {
+				t.Error("expected an error with Abort")
+			}
+
+			waf = corazawaf.NewWAF()
+			err := NewParser(waf).FromString("SecRemoteRulesFailAction Warn\n" + directive)
+			if name == "missing URL" {
+				// syntax errors are not fetch failures
+				if err == nil {
+					t.Error("expected an error for an invalid directive")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error with Warn: %s", err.Error())
+			}
+			if waf.Rules.Count() != 0 {
+				t.Errorf("unexpected rules loaded")
+			}
+		})
+	}
+}
+
+func TestSecRemoteRulesUntrustedCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`SecRule ARGS:id "@eq 1" "id:1,phase:1,deny"`))
+	}))
+	defer srv.Close()
+
+	waf := corazawaf.NewWAF()
+	err := NewParser(waf).FromString("SecRemoteRulesFailAction Abort\nSecRemoteRules secret " + srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("expected a certificate error, have %v", err)
+	}
+}
+
+func TestSecRemoteRulesMaxSize(t *testing.T) {
+	srv, _ := newRemoteRulesServer(t, "# "+strings.Repeat("a", remoteRulesMaxSize))
+
+	waf := corazawaf.NewWAF()
+	err := NewParser(waf).FromString("SecRemoteRulesFailAction Abort\nSecRemoteRules secret " + srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "maximum size") {
+		t.Errorf("expected a size error, have %v", err)
+	}
+}
+
+func TestSecRemoteRulesRedirectToPlainHTTP(t *testing.T) {
+	keys := make(chan string, 1)
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get("ModSec-key")
+		_, _ = w.Write([]byte(`SecRule ARGS:id "@eq 1" "id:1,phase:1,deny"`))
+	}))
+	defer plain.Close()
+
+	srv := httptest.NewTLSServer(http.RedirectHandler(plain.URL+"/rules.conf", http.StatusFound))
+	defer srv.Close()
+	client := remoteRulesClient
+	remoteRulesClient = srv.Client()
+	remoteRulesClient.CheckRedirect = checkRemoteRulesRedirect
+	defer func() { remoteRulesClient = client }()
+
+	waf := corazawaf.NewWAF()
+	err := NewParser(waf).FromString("SecRemoteRulesFailAction Abort\nSecRemoteRules secret " + srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "only HTTPS is allowed") {
+		t.Errorf("expected a redirect error, have %v", err)
+	}
+	select {
+	case key := <-keys:
+		t.Errorf("unexpected request over plain HTTP with key %q", key)
+	default:
+	}
+	if waf.Rules.Count() != 0 {
+		t.Error("unexpected rules loaded")
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build tinygo
+// +build tinygo
+
+package seclang
+
+func (p *Parser) fromRemote(string) error {
+	return p.logAndReturnErr("SecRemoteRules is not supported in TinyGo")
+}
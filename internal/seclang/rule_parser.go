@@ -6,8 +6,11 @@ package seclang
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/corazawaf/coraza/v3/debuglog"
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	actionsmod "github.com/corazawaf/coraza/v3/internal/actions"
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
@@ -160,6 +163,8 @@ func (rp *RuleParser) ParseVariables(vars string) error {
 // ParseOperator parses a seclang formatted operator string
 // A operator must begin with @ (like @rx), if no operator is specified, rx
 // will be used. Everything after the operator will be used as operator argument
+// An empty operator, e.g. "" or "@rx", is an empty pattern matching every value,
+// the empty one included, as in ModSecurity.
 func (rp *RuleParser) ParseOperator(operator string) error {
 	// default operator @RX
 	operatorLen := len(operator)
@@ -191,8 +196,15 @@ func (rp *RuleParser) ParseOperator(operator string) error {
 		Path: []string{
 			rp.options.ParserConfig.ConfigDir,
 		},
-		Root:     rp.options.ParserConfig.Root,
-		Datasets: rp.options.Datasets,
+		Root:      rp.options.ParserConfig.Root,
+		Datasets:  rp.options.Datasets,
+		DataDir:   rp.options.WAF.DataDir,
+		TmpDir:    rp.options.WAF.TmpDir,
+		UploadDir: rp.options.WAF.UploadDir,
+		GeoDB:     rp.options.WAF.GeoDB,
+
+		DNSResolver: rp.options.WAF.DNSResolver,
+		DNSTimeout:  rp.options.WAF.DNSTimeout,
 	}
 
 	if wd := rp.options.ParserConfig.WorkingDir; wd != "" {
@@ -247,6 +259,9 @@ func (rp *RuleParser) ParseDefaultActions(actions string) error {
 	if defaultDisruptive == "" {
 		return fmt.Errorf("SecDefaultAction must contain a disruptive action: %s", actions)
 	}
+	if err := validateLoggingPhaseActions(phase, act); err != nil {
+		return fmt.Errorf("invalid SecDefaultAction: %s", err.Error())
+	}
 	if rp.defaultActions[types.RulePhase(phase)] != nil {
 		return fmt.Errorf("SecDefaultAction already defined for this phase: %s", actions)
 	}
@@ -284,6 +299,9 @@ func (rp *RuleParser) ParseActions(actions string) error {
 	if defaults != nil {
 		act = mergeActions(act, defaults)
 	}
+	if err := validateLoggingPhaseActions(phase, act); err != nil {
+		return err
+	}
 
 	for _, action := range act {
 		// now we evaluate non-metadata actions
@@ -399,14 +417,57 @@ func ParseRule(options RuleOptions) (*corazawaf.Rule, error) {
 		lastChain.Chain = rule
 		// This way we store the raw rule in the parent
 		parent.Raw_ += " \n" + options.Raw
+		if !rule.HasChain {
+			validateCaptureReferences(options.WAF.Logger, parent)
+		}
 		return nil, nil
 	} else {
 		// we only want Raw for the parent
 		rule.Raw_ = options.Raw
 	}
+	if !rule.HasChain {
+		validateCaptureReferences(options.WAF.Logger, rule)
+	}
 	return rule, nil
 }
 
+var captureReferenceRegex = regexp.MustCompile(`(?i)%\{tx\.(\d+)\}`)
+
+// validateCaptureReferences warns about capture references (e.g. %{tx.3}) in a rule,
+// including its chained rules, beyond the capture groups populated by the operators
+// of the rules with the capture action. Those references are silently expanded to
+// an empty string at evaluation time. The validation is skipped when the number of
+// capture groups of an operator is not known in advance.
+func validateCaptureReferences(logger debuglog.Logger, rule *corazawaf.Rule) {
+	groups := -1
+	for r := rule; r != nil; r = r.Chain {
+		if !r.Capture {
+			continue
+		}
+		counter, ok := r.Operator().(operators.CaptureGroupsCounter)
+		if !ok {
+			return
+		}
+		groups = max(groups, counter.CaptureGroups())
+	}
+	if groups < 0 {
+		// TX.0-9 might be populated by other rules or setvar
+		return
+	}
+
+	for _, m := range captureReferenceRegex.FindAllStringSubmatch(rule.Raw_, -1) {
+		idx, err := strconv.Atoi(m[1])
+		if err != nil || idx <= groups {
+			continue
+		}
+		logger.Warn().
+			Int("rule_id", rule.ID_).
+			Str("reference", m[0]).
+			Int("capture_groups", groups).
+			Msg("Capture reference beyond the capture groups of the rule, it will be expanded to an empty string")
+	}
+}
+
 func parseActionOperator(data string) (vars string, op string, actions string, err error) {
 	// So only need to TrimLeft below
 	data = strings.Trim(data, " ")
@@ -578,6 +639,25 @@ func appendRuleAction(res []ruleAction, key string, val string, disruptiveAction
 	return res, disruptiveActionIndex, nil
 }
 
+// loggingPhaseDisruptiveActions are the disruptive actions not interrupting the transaction,
+// the only ones allowed in the logging phase.
+var loggingPhaseDisruptiveActions = []string{"allow", "block", "pass"}
+
+// validateLoggingPhaseActions returns an error if the actions of a logging phase rule
+// contain a disruptive action meant to interrupt the transaction, e.g. deny.
+// The logging phase runs once the response is already delivered, it can not be interrupted.
+func validateLoggingPhaseActions(phase types.RulePhase, actions []ruleAction) error {
+	if phase != types.PhaseLogging {
+		return nil
+	}
+	for _, a := range actions {
+		if a.Atype == plugintypes.ActionTypeDisruptive && !utils.InSlice(a.Key, loggingPhaseDisruptiveActions) {
+			return fmt.Errorf("disruptive action %q is not allowed in the logging phase", a.Key)
+		}
+	}
+	return nil
+}
+
 /*
 So here is my research:
 SecDefaultAction must contain a phase and a disruptive action
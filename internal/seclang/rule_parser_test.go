@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/corazawaf/coraza/v3/debuglog"
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
 )
 
@@ -116,14 +117,182 @@ func TestSecRuleUpdateTargetVariableNegation(t *testing.T) {
 		t.Fatalf("unexpexted error, want %q, have %q", expectedErr, errors.Unwrap(err).Error())
 	}
 
-	// Try to update undefined rule
+	// Updating an undefined rule is ignored
 	err = p.FromString(`
 		SecRule REQUEST_URI|REQUEST_COOKIES "abc" "id:9,phase:2"
 		SecRuleUpdateTargetById 99 "!REQUEST_HEADERS:xyz"
 	`)
-	expectedErr = errors.New("SecRuleUpdateTargetById: rule \"99\" not found")
-	if errors.Unwrap(err).Error() != expectedErr.Error() {
-		t.Fatalf("unexpected error, want %q, have %q", expectedErr, errors.Unwrap(err).Error())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+}
+
+func TestSecRuleUpdateTargetEvaluation(t *testing.T) {
+	tests := map[string]struct {
+		update  string
+		matched []int
+	}{
+		"none":              {"", []int{1, 2, 3}},
+		"exclusion by id":   {`SecRuleUpdateTargetById 1 "!ARGS:foo"`, []int{2, 3}},
+		"several ids":       {`SecRuleUpdateTargetById 1 2 "!ARGS:foo"`, []int{3}},
+		"cookie by id":      {`SecRuleUpdateTargetById 3 "!REQUEST_COOKIES:token"`, []int{1, 2}},
+		"exclusion by tag":  {`SecRuleUpdateTargetByTag attack-sqli "!ARGS:foo"`, []int{3}},
+		"quoted tag":        {`SecRuleUpdateTargetByTag "OWASP CRS" "!ARGS:foo|!REQUEST_COOKIES:token"`, nil},
+		"replaced by tag":   {`SecRuleUpdateTargetByTag attack-sqli "REQUEST_COOKIES" replace`, []int{1, 3}},
+		"appended target":   {`SecRuleUpdateTargetById 1 "!ARGS:foo|REQUEST_HEADERS:x-test"`, []int{1, 2, 3}},
+		"undefined rule":    {`SecRuleUpdateTargetById 99 "!ARGS:foo"`, []int{1, 2, 3}},
+		"undefined tag":     {`SecRuleUpdateTargetByTag missing "!ARGS:foo"`, []int{1, 2, 3}},
+		"rule loaded after": {`SecRuleUpdateTargetById 4 "!ARGS:foo"`, []int{1, 2, 3}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			err := NewParser(waf).FromString(`
+				SecRule ARGS "@contains select" "id:1,phase:1,pass,log,tag:attack-sqli,tag:'OWASP CRS'"
+				SecRule ARGS "@contains union" "id:2,phase:1,pass,log,tag:attack-sqli,tag:'OWASP CRS'"
+				SecRule REQUEST_COOKIES "@contains select" "id:3,phase:1,pass,log,tag:'OWASP CRS'"
+				` + tc.update + `
+				SecRule ARGS "@contains never" "id:4,phase:1,pass,log"
+			`)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.AddGetRequestArgument("foo", "union select")
+			tx.AddRequestHeader("Cookie", "token=select")
+			tx.AddRequestHeader("X-Test", "union select")
+			tx.ProcessRequestHeaders()
+
+			var matched []int
+			for _, mr := range tx.MatchedRules() {
+				matched = append(matched, mr.Rule().ID())
+			}
+			if !reflect.DeepEqual(tc.matched, matched) {
+				t.Errorf("unexpected matched rules, want %v, have %v", tc.matched, matched)
+			}
+		})
+	}
+}
+
+func TestSecRuleUpdateTargetModes(t *testing.T) {
+	tests := []struct {
+		update   string
+		expected []string
+	}{
+		{update: `SecRuleUpdateTargetById 1 "REQUEST_HEADERS:x-test"`, expected: []string{"ARGS_GET", "REQUEST_HEADERS"}},
+		{update: `SecRuleUpdateTargetById 1 "REQUEST_HEADERS:x-test" append`, expected: []string{"ARGS_GET", "REQUEST_HEADERS"}},
+		{update: `SecRuleUpdateTargetById 1 "REQUEST_HEADERS:x-test" replace`, expected: []string{"REQUEST_HEADERS"}},
+		{update: `SecRuleUpdateTargetById 1-2 "REQUEST_HEADERS:x-test" REPLACE`, expected: []string{"REQUEST_HEADERS"}},
+		{update: `SecRuleUpdateTargetById 1 "ARGS_GET|!ARGS_GET:q|REQUEST_HEADERS" replace`, expected: []string{"REQUEST_HEADERS"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.update, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			p := NewParser(waf)
+			err := p.FromString(`
+				SecRule ARGS_GET "@rx attack" "id:1,phase:1,log,pass"
+				` + tt.update)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.AddGetRequestArgument("q", "attack")
+			tx.AddRequestHeader("X-Test", "attack")
+			tx.ProcessRequestHeaders()
+
+			var matched []string
+			for _, mr := range tx.MatchedRules() {
+				for _, md := range mr.MatchedDatas() {
+					matched = append(matched, md.Variable().Name())
+				}
+			}
+			if !reflect.DeepEqual(tt.expected, matched) {
+				t.Errorf("unexpected matched variables, want %v, have %v", tt.expected, matched)
+			}
+		})
+	}
+}
+
+func TestSecRuleUpdateActionEvaluation(t *testing.T) {
+	tests := map[string]struct {
+		update      string
+		matched     []int
+		interrupted int
+		msg         string
+	}{
+		"none":               {"", []int{1}, 1, ""},
+		"downgraded to pass": {`SecRuleUpdateActionById 1 "pass"`, []int{1, 2}, 2, ""},
+		"several ids":        {`SecRuleUpdateActionById 1 2 "pass"`, []int{1, 2}, 0, ""},
+		"range":              {`SecRuleUpdateActionById 1-2 "pass"`, []int{1, 2}, 0, ""},
+		"transformations":    {`SecRuleUpdateActionById 1 "t:none"`, []int{2}, 2, ""},
+		"merged actions":     {`SecRuleUpdateActionById 1 2 "t:none,pass"`, []int{2}, 0, ""},
+		"quoted message":     {`SecRuleUpdateActionById 1 "pass,msg:'select found'"`, []int{1, 2}, 2, "select found"},
+		"undefined rule":     {`SecRuleUpdateActionById 99 "pass"`, []int{1}, 1, ""},
+		"rule loaded after":  {`SecRuleUpdateActionById 3 "deny"`, []int{1}, 1, ""},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			err := NewParser(waf).FromString(`
+				SecRuleEngine On
+				SecRule ARGS "@contains select" "id:1,phase:1,t:lowercase,deny,status:403,log"
+				SecRule ARGS "@contains union" "id:2,phase:1,deny,status:403,log"
+				` + tc.update + `
+				SecRule ARGS "@contains never" "id:3,phase:1,pass,log"
+			`)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.AddGetRequestArgument("foo", "union SELECT")
+			it := tx.ProcessRequestHeaders()
+
+			var matched []int
+			for _, mr := range tx.MatchedRules() {
+				matched = append(matched, mr.Rule().ID())
+				if mr.Rule().ID() == 1 && mr.Message() != tc.msg {
+					t.Errorf("unexpected message, want %q, have %q", tc.msg, mr.Message())
+				}
+			}
+			if !reflect.DeepEqual(tc.matched, matched) {
+				t.Errorf("unexpected matched rules, want %v, have %v", tc.matched, matched)
+			}
+			interrupted := 0
+			if it != nil {
+				interrupted = it.RuleID
+			}
+			if interrupted != tc.interrupted {
+				t.Errorf("unexpected interruption, want rule %d, have %d", tc.interrupted, interrupted)
+			}
+		})
+	}
+}
+
+func TestSecRuleUpdateActionErrors(t *testing.T) {
+	for _, update := range []string{
+		`SecRuleUpdateActionById 1 "id:2"`,
+		`SecRuleUpdateActionById 1 "phase:2,pass"`,
+		`SecRuleUpdateActionById 1 "unknown"`,
+		`SecRuleUpdateActionById 99 "unknown"`,
+		`SecRuleUpdateActionById 1 "pass" extra`,
+		`SecRuleUpdateActionById 1 "pass`,
+		`SecRuleUpdateActionById "pass"`,
+	} {
+		t.Run(update, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			err := NewParser(waf).FromString(`
+				SecRule ARGS "@contains select" "id:1,phase:1,deny,log"
+				` + update)
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
 	}
 }
 
@@ -152,6 +321,9 @@ func TestDefaultActionsErrors(t *testing.T) {
 		"SecDefaultAction with a transformation uppercase": {
 			rules: `SecDefaultAction "phase:1,log,auditlog,pass,T:NoNe"`,
 		},
+		"SecDefaultAction with deny in logging phase": {
+			rules: `SecDefaultAction "phase:5,log,auditlog,deny"`,
+		},
 		"Multiple SecDefaultAction for the same phase": {
 			rules: `SecDefaultAction "phase:1,log,auditlog,pass"
 			SecDefaultAction "phase:1,nolog,noauditlog,pass"`,
@@ -172,6 +344,42 @@ func TestDefaultActionsErrors(t *testing.T) {
 	}
 }
 
+func TestLoggingPhaseDisruptiveActions(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       string
+		expectedErr bool
+	}{
+		{"deny", `SecAction "id:1,phase:5,deny"`, true},
+		{"drop", `SecAction "id:1,phase:5,drop"`, true},
+		{"redirect", `SecAction "id:1,phase:5,redirect:https://www.example.com"`, true},
+		{"pass", `SecAction "id:1,phase:5,pass"`, false},
+		{"allow", `SecAction "id:1,phase:5,allow"`, false},
+		{"block", `SecAction "id:1,phase:5,block"`, false},
+		{"default action", `SecDefaultAction "phase:5,log,pass"
+			SecAction "id:1,phase:5,block"`, false},
+		{"updated action", `SecAction "id:1,phase:5,pass"
+			SecRuleUpdateActionById 1 "deny"`, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			p := NewParser(waf)
+			err := p.FromString(tc.rules)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if !strings.Contains(err.Error(), "not allowed in the logging phase") {
+					t.Errorf("unexpected error: %s", err.Error())
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+		})
+	}
+}
+
 func TestDefaultActionsForPhase2Overridable(t *testing.T) {
 	waf := corazawaf.NewWAF()
 	p := NewParser(waf)
@@ -309,3 +517,59 @@ func BenchmarkParseActions(b *testing.B) {
 		_, _ = parseActions(actionsToBeParsed)
 	}
 }
+
+func TestCaptureReferencesValidation(t *testing.T) {
+	tests := map[string]struct {
+		rules       string
+		expectWarns int
+	}{
+		"reference within groups": {
+			rules:       `SecRule ARGS "@rx (a)(b)" "id:1,capture,logdata:'%{tx.0} %{tx.2}'"`,
+			expectWarns: 0,
+		},
+		"reference beyond groups in logdata": {
+			rules:       `SecRule ARGS "@rx (a)" "id:1,capture,logdata:'%{TX.3}'"`,
+			expectWarns: 1,
+		},
+		"reference beyond groups in setvar": {
+			rules:       `SecRule ARGS "@rx (a)" "id:1,capture,setvar:'tx.value=%{tx.2}'"`,
+			expectWarns: 1,
+		},
+		"reference captured by chained rule": {
+			rules: `SecRule ARGS "@rx a" "id:1,chain,logdata:'%{tx.1}'"
+SecRule ARGS "@rx (b)" "capture"`,
+			expectWarns: 0,
+		},
+		"reference beyond groups of chain": {
+			rules: `SecRule ARGS "@rx (a)" "id:1,capture,chain,logdata:'%{tx.2}'"
+SecRule ARGS "@rx b" "t:none"`,
+			expectWarns: 1,
+		},
+		"rule without capture": {
+			rules:       `SecRule ARGS "@rx a" "id:1,logdata:'%{tx.3}'"`,
+			expectWarns: 0,
+		},
+		"operator with unknown groups": {
+			rules:       `SecRule ARGS "@pm a b" "id:1,capture,logdata:'%{tx.3}'"`,
+			expectWarns: 0,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			logs := &strings.Builder{}
+			waf := corazawaf.NewWAF()
+			waf.SetDebugLogOutput(logs)
+			if err := waf.SetDebugLogLevel(debuglog.LevelWarn); err != nil {
+				t.Fatal(err)
+			}
+			p := NewParser(waf)
+			if err := p.FromString(tt.rules); err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if want, have := tt.expectWarns, strings.Count(logs.String(), "Capture reference beyond the capture groups"); want != have {
+				t.Errorf("unexpected number of warnings, want %d, have %d: %s", want, have, logs.String())
+			}
+		})
+	}
+}
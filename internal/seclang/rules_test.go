@@ -4,12 +4,20 @@
 package seclang
 
 import (
+	"bytes"
+	"compress/gzip"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/corazawaf/coraza/v3/internal/collections"
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/internal/persistence"
 	"github.com/corazawaf/coraza/v3/types"
+	"github.com/corazawaf/coraza/v3/types/variables"
 )
 
 func TestRuleMatch(t *testing.T) {
@@ -272,6 +280,196 @@ func TestPrintedExtraMsgAndDataFromRuleWithMultipleMatches(t *testing.T) {
 		t.Errorf("failed to log second logdata, expected %q occurence, got %v", "1 in ARGS_GET:test", logs[0])
 	}
 }
+func TestMatchedVarExpansionInLogdata(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		expected string
+	}{
+		{
+			name:     "rule",
+			rules:    `SecRule ARGS_GET:q "@rx b" "id:1, phase:1, log, pass, logdata:'%{MATCHED_VAR} in %{MATCHED_VAR_NAME}'"`,
+			expected: "abc in ARGS_GET:q",
+		},
+		{
+			name:     "transformed value",
+			rules:    `SecRule ARGS_GET:q "@rx B" "id:1, phase:1, log, pass, t:uppercase, logdata:'%{MATCHED_VAR} in %{MATCHED_VAR_NAME}'"`,
+			expected: "ABC in ARGS_GET:q",
+		},
+		{
+			name: "secaction after a match",
+			rules: `SecRule ARGS_GET:q "@rx b" "id:1, phase:1, nolog, pass"
+			SecAction "id:2, phase:1, log, pass, logdata:'%{MATCHED_VAR} in %{MATCHED_VAR_NAME}'"`,
+			expected: "abc in ARGS_GET:q",
+		},
+		{
+			name: "chain",
+			rules: `SecRule ARGS_GET:q "@rx b" "id:1, phase:1, log, pass, chain, logdata:'%{MATCHED_VAR} in %{MATCHED_VAR_NAME}'"
+			  SecRule REQUEST_HEADERS:X-Test "@rx e" ""`,
+			expected: "def in REQUEST_HEADERS:X-Test",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			parser := NewParser(waf)
+			if err := parser.FromString(tc.rules); err != nil {
+				t.Fatal(err)
+			}
+			tx := waf.NewTransaction()
+			tx.AddGetRequestArgument("q", "abc")
+			tx.AddRequestHeader("X-Test", "def")
+			tx.ProcessRequestHeaders()
+
+			var data []string
+			for _, mr := range tx.MatchedRules() {
+				if mr.Data() != "" {
+					data = append(data, mr.Data())
+				}
+			}
+			if len(data) != 1 || data[0] != tc.expected {
+				t.Errorf("unexpected logdata, want %q, have %q", tc.expected, data)
+			}
+		})
+	}
+}
+
+func TestMacroExpansion(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	parser := NewParser(waf)
+	err := parser.FromString(`
+	SecAction "id:1, phase:1, nolog, pass, setvar:tx.n=2"
+	SecAction "id:2, phase:1, nolog, pass, setvar:tx.n=+%{tx.n}"
+	SecAction "id:3, phase:1, nolog, pass, setvar:tx.n=+%{tx.n}"
+	SecRule ARGS_GET:q "@rx b" "id:4, phase:1, log, pass, logdata:'%{tx.n}% of %{MATCHED_VAR} from %{REMOTE_ADDR}%{tx.missing}!'"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.ProcessConnection("192.168.1.1", 1234, "", 0)
+	tx.AddGetRequestArgument("q", "abc")
+	tx.ProcessRequestHeaders()
+
+	if want, have := "8", tx.Variables().TX().Get("n"); len(have) != 1 || have[0] != want {
+		t.Errorf("unexpected TX:n, want %q, have %q", want, have)
+	}
+
+	var data []string
+	for _, mr := range tx.MatchedRules() {
+		if mr.Data() != "" {
+			data = append(data, mr.Data())
+		}
+	}
+	// unknown keys expand to an empty string and a literal % is kept
+	if want := "8% of abc from 192.168.1.1!"; len(data) != 1 || data[0] != want {
+		t.Errorf("unexpected logdata, want %q, have %q", want, data)
+	}
+}
+
+func TestPersistentCollectionExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	waf := corazawaf.NewWAF()
+	waf.Persistence = persistence.NewMemory(func() time.Time { return now })
+	parser := NewParser(waf)
+	err := parser.FromString(`
+	SecAction "id:1, phase:1, nolog, pass, initcol:ip=%{REMOTE_ADDR}"
+	SecRule IP:blocked "@eq 1" "id:2, phase:1, deny, status:403, log"
+	SecRule ARGS_GET:login "@streq failed" "id:3, phase:1, nolog, pass, setvar:ip.blocked=1, expirevar:ip.blocked=300"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		elapsed     time.Duration
+		login       string
+		interrupted bool
+	}{
+		{"failed login", 0, "failed", false},
+		{"blocked", 100 * time.Second, "", true},
+		{"expired", 300 * time.Second, "", false},
+	}
+	for _, tc := range tests {
+		now = now.Add(tc.elapsed)
+		tx := waf.NewTransaction()
+		tx.ProcessConnection("192.168.1.1", 1234, "", 0)
+		if tc.login != "" {
+			tx.AddGetRequestArgument("login", tc.login)
+		}
+		if it := tx.ProcessRequestHeaders(); (it != nil) != tc.interrupted {
+			t.Errorf("%s: unexpected interruption %v", tc.name, it)
+		}
+		tx.Close()
+	}
+}
+
+func TestPersistentCollectionConcurrentIncrements(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	parser := NewParser(waf)
+	err := parser.FromString(`
+	SecAction "id:1, phase:1, nolog, pass, initcol:ip=%{REMOTE_ADDR}"
+	SecAction "id:2, phase:2, nolog, pass, setvar:ip.requests=+1"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both transactions load the record before any of them increments it
+	txs := []*corazawaf.Transaction{waf.NewTransaction(), waf.NewTransaction()}
+	for _, tx := range txs {
+		tx.ProcessConnection("192.168.1.1", 1234, "", 0)
+		tx.ProcessRequestHeaders()
+	}
+	for i, tx := range txs {
+		if _, err := tx.ProcessRequestBody(); err != nil {
+			t.Fatal(err)
+		}
+		want := strconv.Itoa(i + 1)
+		if requests := tx.Collection(variables.IP).(*collections.Map).Get("requests"); len(requests) != 1 || requests[0] != want {
+			t.Errorf("unexpected requests in the transaction, want %s, have %v", want, requests)
+		}
+		tx.Close()
+	}
+
+	vars, err := waf.Persistence.Get("IP", "192.168.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["requests"] != "2" {
+		t.Errorf("expected no increment to be lost, have %q", vars["requests"])
+	}
+}
+
+func TestConnectionVariables(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	parser := NewParser(waf)
+	err := parser.FromString(`
+	SecRule SERVER_NAME "@streq www.example.com" "id:1, phase:1, log, pass"
+	SecRule SERVER_ADDR "@ipMatch 10.0.0.0/8" "id:2, phase:1, log, pass"
+	SecRule SERVER_PORT "@eq 8443" "id:3, phase:1, log, pass"
+	SecRule REMOTE_ADDR "@streq 192.168.1.1" "id:4, phase:1, log, pass"
+	SecRule REMOTE_PORT "@gt 1023" "id:5, phase:1, log, pass"
+	SecRule SERVER_NAME "@streq other.example.com" "id:6, phase:1, log, pass"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := waf.NewTransaction()
+	defer tx.Close()
+	tx.ProcessConnection("192.168.1.1", 51234, "10.1.2.3", 8443)
+	tx.SetServerName("www.example.com")
+	tx.ProcessRequestHeaders()
+
+	var matched []int
+	for _, mr := range tx.MatchedRules() {
+		matched = append(matched, mr.Rule().ID())
+	}
+	if want := []int{1, 2, 3, 4, 5}; !slices.Equal(want, matched) {
+		t.Errorf("unexpected matched rules, want %v, have %v", want, matched)
+	}
+}
+
 func TestPrintedExtraMsgAndDataFromChainedRules(t *testing.T) {
 	waf := corazawaf.NewWAF()
 	var logs []string
@@ -424,6 +622,78 @@ func TestSampleRxRule(t *testing.T) {
 	}
 }
 
+func TestResponseBodyDecompression(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	err := NewParser(waf).FromString(`
+		SecRuleEngine On
+		SecResponseBodyAccess On
+		SecResponseBodyMimeType text/html
+		SecResponseBodyLimit 1024
+		SecRule RESPONSE_BODY "@contains <script>" "id:1,phase:4,deny,status:403"
+		SecRule RES_BODY_ERROR "@eq 1" "id:2,phase:4,deny,status:500"
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html := "<html><body><script>alert(1)</script></body></html>"
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	if _, err := w.Write([]byte(html)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var large bytes.Buffer
+	w = gzip.NewWriter(&large)
+	if _, err := w.Write([]byte(strings.Repeat("a", 2048))); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		encoding string
+		body     []byte
+		ruleID   int
+	}{
+		"gzip":               {"gzip", gz.Bytes(), 1},
+		"not compressed":     {"", []byte(html), 1},
+		"decompression bomb": {"gzip", large.Bytes(), 2},
+		"invalid":            {"gzip", []byte("<html></html>"), 2},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.ProcessRequestHeaders()
+			if _, err := tx.ProcessRequestBody(); err != nil {
+				t.Fatal(err)
+			}
+			tx.AddResponseHeader("Content-Type", "text/html")
+			if tc.encoding != "" {
+				tx.AddResponseHeader("Content-Encoding", tc.encoding)
+			}
+			tx.ProcessResponseHeaders(200, "HTTP/1.1")
+			if _, _, err := tx.WriteResponseBody(tc.body); err != nil {
+				t.Fatal(err)
+			}
+			it, err := tx.ProcessResponseBody()
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tc.ruleID == 0 && it != nil:
+				t.Errorf("unexpected interruption by rule %d", it.RuleID)
+			case tc.ruleID != 0 && (it == nil || it.RuleID != tc.ruleID):
+				t.Errorf("expected interruption by rule %d, have %v", tc.ruleID, it)
+			}
+		})
+	}
+}
+
 func TestTxIssue147(t *testing.T) {
 	// https://github.com/corazawaf/coraza/issues/147
 	waf := corazawaf.NewWAF()
@@ -563,6 +833,71 @@ func TestRxCapture(t *testing.T) {
 	}
 }
 
+func TestCaptureChain(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	parser := NewParser(waf)
+	err := parser.FromString(`
+	SecRule ARGS "@rx id=(\d+)" "id:1,phase:1,deny,status:403,capture,chain"
+		SecRule TX:1 "@gt 100" "setvar:'tx.captured_id=%{tx.1}'"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"id=123": true,
+		"id=42":  false,
+		"name=5": false,
+	}
+	for query, interrupted := range tests {
+		t.Run(query, func(t *testing.T) {
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.AddGetRequestArgument("q", query)
+			if have := tx.ProcessRequestHeaders() != nil; have != interrupted {
+				t.Fatalf("unexpected interruption, want %t, have %t", interrupted, have)
+			}
+			if !interrupted {
+				return
+			}
+			if want, have := "123", tx.Variables().TX().Get("1"); len(have) != 1 || have[0] != want {
+				t.Errorf("unexpected TX:1, want %q, have %q", want, have)
+			}
+			if want, have := "123", tx.Variables().TX().Get("captured_id"); len(have) != 1 || have[0] != want {
+				t.Errorf("unexpected TX:captured_id, want %q, have %q", want, have)
+			}
+		})
+	}
+}
+
+func TestRxEmptyPattern(t *testing.T) {
+	tests := map[string]bool{
+		`SecRule ARGS_GET:q "" "id:1,phase:1,log,pass"`:     true,
+		`SecRule ARGS_GET:q "@rx" "id:1,phase:1,log,pass"`:  true,
+		`SecRule ARGS_GET:q "@rx " "id:1,phase:1,log,pass"`: true,
+		`SecRule ARGS_GET:q "!@rx" "id:1,phase:1,log,pass"`: false,
+		`SecRule ARGS_GET:q "!" "id:1,phase:1,log,pass"`:    false,
+	}
+
+	for rule, match := range tests {
+		for _, value := range []string{"", "abc"} {
+			t.Run(rule+"/"+value, func(t *testing.T) {
+				waf := corazawaf.NewWAF()
+				parser := NewParser(waf)
+				if err := parser.FromString(rule); err != nil {
+					t.Fatal(err)
+				}
+				tx := waf.NewTransaction()
+				defer tx.Close()
+				tx.AddGetRequestArgument("q", value)
+				tx.ProcessRequestHeaders()
+				if have := len(tx.MatchedRules()) == 1; have != match {
+					t.Errorf("unexpected match, want %t, have %t", match, have)
+				}
+			})
+		}
+	}
+}
+
 func TestUnicode(t *testing.T) {
 	waf := corazawaf.NewWAF()
 	rules := `SecRule ARGS "@rx \x{30cf}\x{30ed}\x{30fc}" "id:101,phase:2,t:lowercase,deny"`
@@ -1032,3 +1367,144 @@ BINARYDATA
 		t.Error("failed test for rx captured")
 	}
 }
+
+func TestTransformationErrorFlag(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	parser := NewParser(waf)
+	err := parser.FromString(`
+	SecTransformationErrorFlag On
+	SecRule ARGS_GET:id "@rx ^secret$" "id:1,phase:1,pass,t:hexDecode"
+	SecRule TX:TRANSFORMATION_ERROR "@eq 1" "id:2,phase:1,deny,status:400"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"736563726574": false,
+		"not-hex":      true,
+	}
+	for id, interrupted := range tests {
+		t.Run(id, func(t *testing.T) {
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.ProcessURI("/?id="+id, "GET", "HTTP/1.1")
+			if have := tx.ProcessRequestHeaders() != nil; have != interrupted {
+				t.Errorf("unexpected interruption, want %t, have %t", interrupted, have)
+			}
+		})
+	}
+}
+
+func TestCtlIsTransactionScoped(t *testing.T) {
+	tests := map[string]struct {
+		ctl         string
+		matched     []int
+		interrupted bool
+		check       func(t *testing.T, tx *corazawaf.Transaction)
+	}{
+		"no effect": {
+			ctl:         "ruleRemoveById=999",
+			matched:     []int{10, 20},
+			interrupted: true,
+		},
+		"ruleEngine=Off": {
+			ctl: "ruleEngine=Off",
+		},
+		"ruleEngine=DetectionOnly": {
+			ctl:     "ruleEngine=DetectionOnly",
+			matched: []int{10, 20, 21},
+		},
+		"ruleRemoveById from phase 1": {
+			ctl:     "ruleRemoveById=20",
+			matched: []int{10, 21},
+		},
+		"ruleRemoveByTag": {
+			ctl:     "ruleRemoveByTag=attack",
+			matched: []int{10, 21},
+		},
+		"requestBodyAccess=Off": {
+			ctl:     "requestBodyAccess=Off",
+			matched: []int{10, 21},
+		},
+		"requestBodyProcessor=JSON": {
+			ctl:     "requestBodyProcessor=JSON",
+			matched: []int{10, 21},
+			check: func(t *testing.T, tx *corazawaf.Transaction) {
+				if want, have := "JSON", tx.Variables().RequestBodyProcessor().Get(); want != have {
+					t.Errorf("unexpected REQBODY_PROCESSOR, want %q, have %q", want, have)
+				}
+			},
+		},
+		"auditEngine=On": {
+			ctl:         "auditEngine=On",
+			matched:     []int{10, 20},
+			interrupted: true,
+			check: func(t *testing.T, tx *corazawaf.Transaction) {
+				if want, have := types.AuditEngineOn, tx.AuditEngine; want != have {
+					t.Errorf("unexpected audit engine, want %d, have %d", want, have)
+				}
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			parser := NewParser(waf)
+			err := parser.FromString(`
+			SecRuleEngine On
+			SecRequestBodyAccess On
+			SecAuditEngine Off
+			SecAction "id:1,phase:1,pass,nolog,ctl:` + tc.ctl + `"
+			SecRule ARGS_GET:attack "@streq 1" "id:10,phase:1,pass,log"
+			SecRule ARGS_POST "@streq 1" "id:20,phase:2,deny,status:403,log,tag:attack"
+			SecAction "id:21,phase:2,pass,log"`)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.ProcessURI("/?attack=1", "POST", "HTTP/1.1")
+			tx.AddRequestHeader("Content-Type", "application/x-www-form-urlencoded")
+			tx.ProcessRequestHeaders()
+			if _, _, err := tx.ReadRequestBodyFrom(strings.NewReader("attack=1")); err != nil {
+				t.Fatal(err)
+			}
+			it, err := tx.ProcessRequestBody()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var matched []int
+			for _, mr := range tx.MatchedRules() {
+				if id := mr.Rule().ID(); id != 1 {
+					matched = append(matched, id)
+				}
+			}
+			if !slices.Equal(tc.matched, matched) {
+				t.Errorf("unexpected matched rules, want %v, have %v", tc.matched, matched)
+			}
+			if have := it != nil; have != tc.interrupted {
+				t.Errorf("unexpected interruption, want %t, have %t", tc.interrupted, have)
+			}
+			if tc.check != nil {
+				tc.check(t, tx)
+			}
+
+			// ctl only changes the transaction, the WAF configuration is left untouched
+			if waf.RuleEngine != types.RuleEngineOn {
+				t.Errorf("unexpected WAF rule engine %s", waf.RuleEngine)
+			}
+			if !waf.RequestBodyAccess {
+				t.Error("unexpected WAF request body access")
+			}
+			if waf.AuditEngine != types.AuditEngineOff {
+				t.Errorf("unexpected WAF audit engine %d", waf.AuditEngine)
+			}
+			if waf.Rules.FindByID(20) == nil {
+				t.Error("unexpected removal of rule 20 from the WAF")
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo && !coraza.no_lua
+
+package seclang
+
+import (
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+	"github.com/corazawaf/coraza/v3/internal/lua"
+)
+
+// compileScript compiles the Lua script of SecRuleScript, it is kept apart so
+// TinyGo builds and the coraza.no_lua build tag drop the Lua dependency.
+func compileScript(name string, src string) (corazawaf.RuleScript, error) {
+	return lua.Compile(name, src)
+}
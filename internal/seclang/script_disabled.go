@@ -0,0 +1,16 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build tinygo || coraza.no_lua
+
+package seclang
+
+import (
+	"errors"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+func compileScript(string, string) (corazawaf.RuleScript, error) {
+	return nil, errors.New("SecRuleScript is not supported, Lua is disabled in this build")
+}
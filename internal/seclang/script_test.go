@@ -0,0 +1,107 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo && !coraza.no_lua
+
+package seclang
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/corazawaf/coraza/v3/internal/corazawaf"
+)
+
+func TestSecRuleScript(t *testing.T) {
+	root := fstest.MapFS{
+		"rules/scripts/numeric_id.lua": &fstest.MapFile{Data: []byte(`
+function main(tx)
+    for _, arg in ipairs(tx.getvars("ARGS")) do
+        if arg.name == "ARGS:id" and tonumber(arg.value) == nil then
+            tx.setvar("tx.invalid_id", arg.value)
+            return true, "Non numeric id: " .. arg.value
+        end
+    end
+    return false
+end
+`)},
+		"rules/scripts/broken.lua": &fstest.MapFile{Data: []byte(`
+function main(tx)
+    return tx.getvar("ARGS:id"):len() > nil
+end
+`)},
+		// a script which never returns is aborted, only its rule is affected
+		"rules/scripts/loop.lua": &fstest.MapFile{Data: []byte(`
+function main(tx)
+    while true do end
+end
+`)},
+		"rules/script.conf": &fstest.MapFile{Data: []byte(`
+SecRuleEngine On
+SecRuleScript scripts/loop.lua "id:3,phase:1,deny,status:500"
+SecRuleScript scripts/broken.lua "id:1,phase:1,deny,status:500"
+SecRuleScript scripts/numeric_id.lua "id:2,phase:1,deny,status:403,log"
+`)},
+		"rules/no_main.lua": &fstest.MapFile{Data: []byte("x = 1\n")},
+	}
+
+	waf := corazawaf.NewWAF()
+	p := NewParser(waf)
+	p.SetRoot(root)
+	if err := p.FromFile("rules/script.conf"); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := waf.NewTransaction()
+	tx.ProcessURI("/?id=1", "GET", "HTTP/1.1")
+	if it := tx.ProcessRequestHeaders(); it != nil {
+		t.Errorf("unexpected interruption by rule %d", it.RuleID)
+	}
+
+	tx = waf.NewTransaction()
+	tx.ProcessURI("/?id=1%20or%201=1", "GET", "HTTP/1.1")
+	it := tx.ProcessRequestHeaders()
+	if it == nil || it.RuleID != 2 || it.Status != 403 {
+		t.Fatalf("expected interruption by rule 2, have %v", it)
+	}
+	if have := tx.Variables().TX().Get("invalid_id"); len(have) != 1 || have[0] != "1 or 1=1" {
+		t.Errorf("unexpected TX:invalid_id, have %q", have)
+	}
+	if mr := tx.MatchedRules(); len(mr) != 1 || mr[0].Message() != "Non numeric id: 1 or 1=1" {
+		t.Errorf("unexpected matched rules %v", mr)
+	}
+
+	// a script can be a link of a chain
+	waf = corazawaf.NewWAF()
+	p = NewParser(waf)
+	p.SetRoot(root)
+	if err := p.FromString(`
+SecRuleEngine On
+SecRule ARGS_GET:id "@rx or" "id:10,phase:1,deny,status:403,chain"
+	SecRuleScript rules/scripts/numeric_id.lua "log"`); err != nil {
+		t.Fatal(err)
+	}
+	for uri, interrupted := range map[string]bool{
+		"/?id=1":            false,
+		"/?id=1%20or%201=1": true,
+	} {
+		tx := waf.NewTransaction()
+		tx.ProcessURI(uri, "GET", "HTTP/1.1")
+		if it := tx.ProcessRequestHeaders(); (it != nil) != interrupted {
+			t.Errorf("unexpected interruption for %s: %v", uri, it)
+		}
+		tx.Close()
+	}
+
+	for _, rules := range []string{
+		"SecRuleScript",
+		"SecRuleScript /non-existing.lua \"id:1\"",
+		"SecRuleScript rules/no_main.lua \"id:1\"",
+	} {
+		p := NewParser(corazawaf.NewWAF())
+		p.SetRoot(root)
+		if err := p.FromString(rules); err == nil {
+			t.Errorf("expected error for %q", rules)
+		}
+	}
+}
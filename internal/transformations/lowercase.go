@@ -3,13 +3,32 @@
 
 package transformations
 
-import (
-	"strings"
-)
-
+// lowerCase converts ASCII uppercase letters to lowercase. As in ModSecurity, the
+// transformation is locale independent and works byte by byte: any other byte,
+// including non ASCII characters and invalid UTF-8 sequences, is left unchanged.
 func lowerCase(data string) (string, bool, error) {
-	// TODO: Explicit implementation of ToLower would allow optimizing away the byte by byte comparison for returning the changed boolean
-	// See https://github.com/corazawaf/coraza/pull/778#discussion_r1186963422
-	transformedData := strings.ToLower(data)
-	return transformedData, data != transformedData, nil
+	return switchASCIICase(data, 'A', 'Z')
+}
+
+// switchASCIICase switches the case of the ASCII letters of data between from
+// and to (inclusive). The input is only copied when a byte has to be changed.
+func switchASCIICase(data string, from, to byte) (string, bool, error) {
+	i := 0
+	for ; i < len(data); i++ {
+		if c := data[i]; c >= from && c <= to {
+			break
+		}
+	}
+	if i == len(data) {
+		return data, false, nil
+	}
+
+	res := []byte(data)
+	for ; i < len(res); i++ {
+		if c := res[i]; c >= from && c <= to {
+			// upper and lower case ASCII letters only differ in this bit
+			res[i] = c ^ 0x20
+		}
+	}
+	return string(res), true, nil
 }
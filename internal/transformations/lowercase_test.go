@@ -26,6 +26,26 @@ func TestLowerCase(t *testing.T) {
 			input: "",
 			want:  "",
 		},
+		{
+			input: "ÀÉÎ ÕÜ",
+			want:  "ÀÉÎ ÕÜ",
+		},
+		{
+			input: "ÀBC\xffDEF",
+			want:  "Àbc\xffdef",
+		},
+		{
+			input: "\xc3\x28ABC",
+			want:  "\xc3\x28abc",
+		},
+		{
+			input: "\u212a",
+			want:  "\u212a",
+		},
+		{
+			input: "İSTANBUL",
+			want:  "İstanbul",
+		},
 		{
 			input: "ThIs Is A tExT fOr TeStInG lOwErCaSe FuNcTiOnAlItY.",
 			want:  "this is a text for testing lowercase functionality.",
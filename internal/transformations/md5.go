@@ -12,6 +12,8 @@ import (
 
 var emptyMD5 string
 
+// md5T calculates the MD5 hash of the input. As in ModSecurity the
+// output is the raw binary digest, chain it with hexEncode to get a printable value.
 func md5T(data string) (string, bool, error) {
 	if len(data) == 0 {
 		return emptyMD5, true, nil
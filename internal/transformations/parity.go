@@ -0,0 +1,59 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package transformations
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// parityEven7bit calculates the even parity of the 7-bit data of each byte, replacing
+// the 8th bit with the calculated parity bit.
+func parityEven7bit(data string) (string, bool, error) {
+	return parity7bit(data, func(c byte) byte {
+		c &= 0x7f
+		if bits.OnesCount8(c)%2 == 1 {
+			c |= 0x80
+		}
+		return c
+	})
+}
+
+// parityOdd7bit calculates the odd parity of the 7-bit data of each byte, replacing
+// the 8th bit with the calculated parity bit.
+func parityOdd7bit(data string) (string, bool, error) {
+	return parity7bit(data, func(c byte) byte {
+		c &= 0x7f
+		if bits.OnesCount8(c)%2 == 0 {
+			c |= 0x80
+		}
+		return c
+	})
+}
+
+// parityZero7bit assumes a parity bit in the 8th bit of each byte and zeroes it,
+// allowing inspection of even/odd parity 7-bit data.
+func parityZero7bit(data string) (string, bool, error) {
+	return parity7bit(data, func(c byte) byte {
+		return c & 0x7f
+	})
+}
+
+// parity7bit applies f to every byte of data, the result is only allocated if
+// a byte is changed.
+func parity7bit(data string, f func(byte) byte) (string, bool, error) {
+	for i := 0; i < len(data); i++ {
+		if f(data[i]) == data[i] {
+			continue
+		}
+		var res strings.Builder
+		res.Grow(len(data))
+		res.WriteString(data[:i])
+		for ; i < len(data); i++ {
+			res.WriteByte(f(data[i]))
+		}
+		return res.String(), true, nil
+	}
+	return data, false, nil
+}
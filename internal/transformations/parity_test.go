@@ -0,0 +1,42 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package transformations
+
+import (
+	"testing"
+)
+
+func TestParity7bit(t *testing.T) {
+	tests := []struct {
+		name  string
+		fn    func(string) (string, bool, error)
+		input string
+		want  string
+	}{
+		// 'a' (0x61) has 3 bits set, 'c' (0x63) has 4 bits set
+		{name: "even", fn: parityEven7bit, input: "ac", want: "\xe1c"},
+		{name: "even unchanged", fn: parityEven7bit, input: "c\xe1", want: "c\xe1"},
+		{name: "odd", fn: parityOdd7bit, input: "ac", want: "a\xe3"},
+		{name: "odd unchanged", fn: parityOdd7bit, input: "a\xe3", want: "a\xe3"},
+		{name: "zero", fn: parityZero7bit, input: "\xe1\xe3", want: "ac"},
+		{name: "zero unchanged", fn: parityZero7bit, input: "ac", want: "ac"},
+		{name: "empty", fn: parityEven7bit, input: "", want: ""},
+	}
+
+	for _, tc := range tests {
+		tt := tc
+		t.Run(tt.name, func(t *testing.T) {
+			have, changed, err := tt.fn(tt.input)
+			if err != nil {
+				t.Error(err)
+			}
+			if tt.input == tt.want && changed || tt.input != tt.want && !changed {
+				t.Errorf("input %q, have %q with changed %t", tt.input, have, changed)
+			}
+			if have != tt.want {
+				t.Errorf("have %q, want %q", have, tt.want)
+			}
+		})
+	}
+}
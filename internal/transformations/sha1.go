@@ -12,6 +12,8 @@ import (
 
 var emptySHA1 string
 
+// sha1T calculates the SHA-1 hash of the input. As in ModSecurity the
+// output is the raw binary digest, chain it with hexEncode to get a printable value.
 func sha1T(data string) (string, bool, error) {
 	if len(data) == 0 {
 		return emptySHA1, true, nil
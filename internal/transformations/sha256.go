@@ -0,0 +1,33 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package transformations
+
+import (
+	"crypto/sha256"
+	"io"
+
+	"github.com/corazawaf/coraza/v3/internal/strings"
+)
+
+var emptySHA256 string
+
+// sha256T calculates the SHA-256 hash of the input. As in ModSecurity the
+// output is the raw binary digest, chain it with hexEncode to get a printable value.
+func sha256T(data string) (string, bool, error) {
+	if len(data) == 0 {
+		return emptySHA256, true, nil
+	}
+	h := sha256.New()
+	_, err := io.WriteString(h, data)
+	if err != nil {
+		return data, false, err
+	}
+	// The occurrence of an invariant transformation is so unlikely that we can assume the transformation returns a changed value
+	return strings.WrapUnsafe(h.Sum(nil)), true, nil
+}
+
+func init() {
+	buf := sha256.Sum256(nil)
+	emptySHA256 = string(buf[:])
+}
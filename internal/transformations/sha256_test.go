@@ -0,0 +1,43 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package transformations
+
+import "testing"
+
+func TestSHA256HexEncoded(t *testing.T) {
+	tests := map[string]string{
+		"":         "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"TestCase": "20db02f08b7ab16ae150ae0c20b3e47fe367ae2a1d1a35fc4c080bca5ded1b1f",
+	}
+	for in, want := range tests {
+		digest, _, err := sha256T(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have, _, err := hexEncode(digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != have {
+			t.Errorf("unexpected digest for %q, want %q, have %q", in, want, have)
+		}
+	}
+}
+
+func BenchmarkSHA256(b *testing.B) {
+	tests := []string{
+		"",
+		"1234567890",
+	}
+	for _, tc := range tests {
+		tt := tc
+		b.Run(tt, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := sha256T(tt); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
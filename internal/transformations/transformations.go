@@ -4,29 +4,76 @@
 package transformations
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 )
 
-var transformations = map[string]plugintypes.Transformation{}
+var (
+	transformationsMu sync.RWMutex
+	transformations   = map[string]plugintypes.Transformation{}
+	// pluginTransformations holds the lowercased names of the transformations
+	// registered by plugins
+	pluginTransformations = map[string]bool{}
+)
 
 // Register registers a transformation by name
 // If the transformation is already registered, it will be overwritten
 func Register(name string, trans plugintypes.Transformation) {
+	transformationsMu.Lock()
+	defer transformationsMu.Unlock()
 	transformations[strings.ToLower(name)] = trans
 }
 
+// RegisterPlugin registers a transformation provided by a plugin. Built-in
+// transformations can be replaced, but it fails if the name is empty, the
+// transformation is nil or the name was already registered by a plugin.
+func RegisterPlugin(name string, trans plugintypes.Transformation) error {
+	if name == "" {
+		return errors.New("transformation name is empty")
+	}
+	if trans == nil {
+		return fmt.Errorf("transformation %q is nil", name)
+	}
+	lname := strings.ToLower(name)
+	transformationsMu.Lock()
+	defer transformationsMu.Unlock()
+	if pluginTransformations[lname] {
+		return fmt.Errorf("transformation %q is already registered", name)
+	}
+	pluginTransformations[lname] = true
+	transformations[lname] = trans
+	return nil
+}
+
 // GetTransformation returns a transformation by name
 // If the transformation is not found, it returns an error
 func GetTransformation(name string) (plugintypes.Transformation, error) {
+	transformationsMu.RLock()
+	defer transformationsMu.RUnlock()
 	if t, ok := transformations[strings.ToLower(name)]; ok {
 		return t, nil
 	}
 	return nil, fmt.Errorf("invalid transformation name %q", name)
 }
 
+// List returns the sorted names of the registered transformations.
+// Names are lowercased, as transformations are case-insensitive.
+func List() []string {
+	transformationsMu.RLock()
+	defer transformationsMu.RUnlock()
+	names := make([]string, 0, len(transformations))
+	for name := range transformations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func init() {
 	Register("base64Decode", base64decode)
 	Register("base64DecodeExt", base64decodeext)
@@ -47,6 +94,9 @@ func init() {
 	Register("normalisePathWin", normalisePathWin)
 	Register("normalizePath", normalisePath)
 	Register("normalizePathWin", normalisePathWin)
+	Register("parityEven7bit", parityEven7bit)
+	Register("parityOdd7bit", parityOdd7bit)
+	Register("parityZero7bit", parityZero7bit)
 	Register("removeComments", removeComments)
 	Register("removeCommentsChar", removeCommentsChar)
 	Register("removeNulls", removeNulls)
@@ -54,6 +104,7 @@ func init() {
 	Register("replaceComments", replaceComments)
 	Register("replaceNulls", replaceNulls)
 	Register("sha1", sha1T)
+	Register("sha256", sha256T)
 	Register("uppercase", upperCase)
 	Register("urlDecode", urlDecode)
 	Register("urlDecodeUni", urlDecodeUni)
@@ -6,6 +6,8 @@ package transformations
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -78,6 +80,21 @@ func TestTransformationsAreCaseInsensitive(t *testing.T) {
 	}
 }
 
+func TestList(t *testing.T) {
+	names := List()
+	if len(names) != len(transformations) {
+		t.Fatalf("unexpected number of transformations, want %d, have %d", len(transformations), len(names))
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("expected sorted names, have %v", names)
+	}
+	for _, name := range []string{"base64decode", "lowercase", "none", "urldecodeuni"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("expected %q to be listed", name)
+		}
+	}
+}
+
 func unmarshalTests(json []byte) []Test {
 	var tests []Test
 	v := gjson.ParseBytes(json).Value()
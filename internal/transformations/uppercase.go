@@ -3,13 +3,8 @@
 
 package transformations
 
-import (
-	"strings"
-)
-
+// upperCase converts ASCII lowercase letters to uppercase. As lowercase, it is
+// locale independent and leaves any other byte unchanged.
 func upperCase(data string) (string, bool, error) {
-	// TODO: Explicit implementation of ToUpper would allow optimizing away the byte by byte comparison for returning the changed boolean
-	// See https://github.com/corazawaf/coraza/pull/778#discussion_r1186963422
-	transformedData := strings.ToUpper(data)
-	return transformedData, data != transformedData, nil
+	return switchASCIICase(data, 'a', 'z')
 }
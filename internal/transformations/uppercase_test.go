@@ -26,6 +26,26 @@ func TestUpperCase(t *testing.T) {
 			input: "",
 			want:  "",
 		},
+		{
+			input: "àéî õü",
+			want:  "àéî õü",
+		},
+		{
+			input: "àbc\xffdef",
+			want:  "àBC\xffDEF",
+		},
+		{
+			input: "\xc3\x28abc",
+			want:  "\xc3\x28ABC",
+		},
+		{
+			input: "ß",
+			want:  "ß",
+		},
+		{
+			input: "ıstanbul",
+			want:  "ıSTANBUL",
+		},
 		{
 			input: "ThIs Is A tExT fOr TeStInG uPPerCAse FuNcTiOnAlItY.",
 			want:  "THIS IS A TEXT FOR TESTING UPPERCASE FUNCTIONALITY.",
@@ -7,13 +7,36 @@ import (
 	"strings"
 )
 
+// QueryAnomalies reports the malformed parameters tolerated while parsing a query
+type QueryAnomalies struct {
+	// EmptyParam is set for empty parameters, e.g. a=1&&b=2
+	EmptyParam bool
+	// EmptyKey is set for parameters without a name, e.g. =value
+	EmptyKey bool
+	// MissingEquals is set for parameters without a value separator, e.g. key
+	MissingEquals bool
+}
+
+// Any returns whether any anomaly was found
+func (a QueryAnomalies) Any() bool {
+	return a.EmptyParam || a.EmptyKey || a.MissingEquals
+}
+
 // ParseQuery parses the URL-encoded query string and returns the corresponding map.
 // It takes separators as parameter, for example: & or ; or &;
 func ParseQuery(query string, separator byte) map[string][]string {
-	return doParseQuery(query, separator, true)
+	return doParseQuery(query, separator, true, nil)
 }
 
-func doParseQuery(query string, separator byte, urlUnescape bool) map[string][]string {
+// ParseQueryWithAnomalies is the same as ParseQuery but also reports the malformed
+// parameters, which are parsed the same way.
+func ParseQueryWithAnomalies(query string, separator byte) (map[string][]string, QueryAnomalies) {
+	var anomalies QueryAnomalies
+	m := doParseQuery(query, separator, true, &anomalies)
+	return m, anomalies
+}
+
+func doParseQuery(query string, separator byte, urlUnescape bool, anomalies *QueryAnomalies) map[string][]string {
 	m := make(map[string][]string)
 	for query != "" {
 		key := query
@@ -23,11 +46,19 @@ func doParseQuery(query string, separator byte, urlUnescape bool) map[string][]s
 			query = ""
 		}
 		if key == "" {
+			if anomalies != nil {
+				anomalies.EmptyParam = true
+			}
 			continue
 		}
 		value := ""
 		if i := strings.IndexByte(key, '='); i >= 0 {
 			key, value = key[:i], key[i+1:]
+			if key == "" && anomalies != nil {
+				anomalies.EmptyKey = true
+			}
+		} else if anomalies != nil {
+			anomalies.MissingEquals = true
 		}
 		if urlUnescape {
 			key = queryUnescape(key)
@@ -49,3 +49,56 @@ func BenchmarkQueryUnescape(b *testing.B) {
 		}
 	}
 }
+
+func TestParseQueryWithAnomalies(t *testing.T) {
+	tests := map[string]struct {
+		query     string
+		want      map[string][]string
+		anomalies QueryAnomalies
+	}{
+		"well formed": {
+			query: "a=1&b=2",
+			want:  map[string][]string{"a": {"1"}, "b": {"2"}},
+		},
+		"empty param": {
+			query:     "a=1&&b=2",
+			want:      map[string][]string{"a": {"1"}, "b": {"2"}},
+			anomalies: QueryAnomalies{EmptyParam: true},
+		},
+		"empty key": {
+			query:     "=noval",
+			want:      map[string][]string{"": {"noval"}},
+			anomalies: QueryAnomalies{EmptyKey: true},
+		},
+		"missing equals": {
+			query:     "key",
+			want:      map[string][]string{"key": {""}},
+			anomalies: QueryAnomalies{MissingEquals: true},
+		},
+		"all": {
+			query:     "key&&=noval",
+			want:      map[string][]string{"key": {""}, "": {"noval"}},
+			anomalies: QueryAnomalies{EmptyParam: true, EmptyKey: true, MissingEquals: true},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, anomalies := ParseQueryWithAnomalies(tc.query, '&')
+			if anomalies != tc.anomalies {
+				t.Errorf("unexpected anomalies, want %+v, have %+v", tc.anomalies, anomalies)
+			}
+			// the tolerant parser returns the same parameters
+			for _, have := range []map[string][]string{q, ParseQuery(tc.query, '&')} {
+				if len(have) != len(tc.want) {
+					t.Fatalf("unexpected params, want %v, have %v", tc.want, have)
+				}
+				for k, v := range tc.want {
+					if len(have[k]) != len(v) || have[k][0] != v[0] {
+						t.Errorf("unexpected values for %q, want %v, have %v", k, v, have[k])
+					}
+				}
+			}
+		})
+	}
+}
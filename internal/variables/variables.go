@@ -31,6 +31,15 @@ const (
 	// InboundDataError will be set to 1 when the request body size
 	// is above the setting configured by SecRequesteBodyLimit
 	InboundDataError
+	// ArgsLimitExceeded will be set to 1 when request arguments were
+	// skipped because of the limit configured by SecArgumentsLimit
+	ArgsLimitExceeded
+	// RequestHeadersLimitExceeded will be set to 1 when request headers
+	// were skipped because of the limit configured by SecRequestHeadersLimit
+	RequestHeadersLimitExceeded
+	// RequestCookiesLimitExceeded will be set to 1 when request cookies
+	// were skipped because of the limit configured by SecRequestCookiesLimit
+	RequestCookiesLimitExceeded
 	// MatchedVar is the value of the matched variable
 	MatchedVar
 	// MatchedVarName is the name of the matched variable
@@ -44,7 +53,7 @@ const (
 	QueryString
 	// RemoteAddr is the remote address of the connection
 	RemoteAddr
-	// RemoteHost is the remote host of the connection, not implemented
+	// RemoteHost is the hostname of the client, resolved with SecHostnameLookups On, or the client address otherwise
 	RemoteHost
 	// RemotePort is the remote port of the connection
 	RemotePort
@@ -58,7 +67,7 @@ const (
 	// ReqbodyProcessorErrorMsg is the same as ReqbodyErrorMsg ?
 	ReqbodyProcessorErrorMsg
 	// ReqbodyProcessor contains the name of the request body processor used, default
-	// ones are: URLENCODED, MULTIPART, and XML. They can be extended using plugins.
+	// ones are: URLENCODED, MULTIPART, JSON and XML. They can be extended using plugins.
 	ReqbodyProcessor
 	// RequestBasename contains the name after the last slash in the request URI
 	// It does not pass through any anti-evasion, use with transformations
@@ -67,6 +76,10 @@ const (
 	// For urlencoded requests. It is possible to force it's presence by using
 	// the ctl:forceRequestBodyVariable action
 	RequestBody
+	// RequestBodyRaw contains the request body bytes as received, up to the
+	// request body limit, regardless of the body processor and transformations.
+	// It is only available if requestBodyAccess is set to on
+	RequestBodyRaw
 	// RequestBodyLength contains the length of the request body in bytes calculated from
 	// the BodyBuffer, not from the content-type header
 	RequestBodyLength
@@ -81,15 +94,19 @@ const (
 	// RequestProtocol is the protocol used in the request
 	RequestProtocol
 	// RequestURI holds the full request URL including the query string data without
-	// the domain name
+	// the domain name and the fragment, the percent-encoding is not decoded
 	RequestURI
-	// RequestURIRaw is the same as RequestURI but with the domain name in case
-	// it was provided in the request line
+	// RequestURIRaw is the request target exactly as received, with the domain name
+	// in case it was provided in the request line
 	RequestURIRaw
 	// ResponseBody contains the full response body, it will only be available if
 	// responseBodyAccess is set to on and the response mime matches the configured
 	// processable mime types
 	ResponseBody
+	// ResponseBodyRaw contains the response body bytes as received, up to the
+	// response body limit, regardless of the body processor. It is only available
+	// if responseBodyAccess is set to on and the response mime is processable
+	ResponseBodyRaw
 	// ResponseContentLength contains the length of the response body in bytes calculated from
 	// the BodyBuffer, not from the content-type header
 	ResponseContentLength
@@ -103,6 +120,9 @@ const (
 	ServerName
 	// ServerPort is the port of the server
 	ServerPort
+	// WebserverErrorLog contains the error log entries of the web server
+	// for the transaction, fed by the integrator
+	WebserverErrorLog
 	// HighestSeverity is the highest severity from all matched rules
 	HighestSeverity
 	// StatusLine is the status line of the response, including the request method
@@ -151,11 +171,32 @@ const (
 	// ResponseHeaders can be used as either a collection of all of the response
 	// headers or can be used to inspect selected headers
 	ResponseHeaders
+	// RequestTrailers contains the trailer fields sent after the request body,
+	// fed by the integrator
+	RequestTrailers
+	// ResponseTrailers contains the trailer fields sent after the response body,
+	// fed by the integrator
+	ResponseTrailers
 	// ReseBodyProcessor contains the name of the response body processor used,
 	// no default
 	ResBodyProcessor
-	// Geo contains the location information of the client
+	// Geo contains the location information of the client, populated by @geoLookup
+	// with the COUNTRY_CODE, COUNTRY_NAME, CONTINENT_CODE, CITY, LATITUDE and LONGITUDE keys
 	Geo
+	// IP is the persistent collection of the client, initialized with initcol:ip=%{REMOTE_ADDR}
+	IP
+	// Global is the persistent collection shared by all the transactions, initialized with initcol:global
+	Global
+	// Session is the persistent collection of the session, initialized with initcol:session
+	Session
+	// User is the persistent collection of the user, initialized with initcol:user
+	User
+	// Resource is the persistent collection of the resource, initialized with initcol:resource
+	Resource
+	// AuthType is the authentication scheme of the Authorization request header, e.g. Basic or Bearer
+	AuthType
+	// RemoteUser is the username of the Authorization request header using the Basic scheme
+	RemoteUser
 	// RequestCookiesNames contains the names of the request cookies
 	RequestCookiesNames
 	// FilesTmpNames contains the names of the uploaded temporal files
@@ -172,7 +213,7 @@ const (
 	Rule
 	// JSON does not provide any data, might be removed
 	JSON
-	// Env contains the process environment variables
+	// Env contains the environment variables set by the setenv action
 	Env
 	// UrlencodedError equals 1 if we failed to parse de URL
 	// It applies for URL query part and urlencoded post body
@@ -183,51 +224,51 @@ const (
 	ResponseXML
 	// RequestXML contains the request parsed XML
 	RequestXML
-	// XML is a pointer to ResponseXML
+	// XML is a pointer to RequestXML, besides /* and //@* keys are evaluated
+	// as XPath expressions, e.g. XML:/order/item/text()
 	XML
 	// MultipartPartHeaders contains the multipart headers
 	MultipartPartHeaders
-
-	// Unsupported variables
-
-	// AuthType is the authentication type
-	AuthType
-	// FullRequest is the full request
-	FullRequest
-	// MultipartBoundaryQuoted kept for compatibility
+	// MultipartBoundaryQuoted will be set to 1 when the multipart boundary is quoted
 	MultipartBoundaryQuoted
-	// MultipartBoundaryWhitespace kept for compatibility
+	// MultipartBoundaryWhitespace will be set to 1 when the multipart boundary contains whitespace
 	MultipartBoundaryWhitespace
-	// MultipartCrlfLfLines kept for compatibility
+	// MultipartCrlfLfLines will be set to 1 when the multipart body mixes CRLF and LF line endings
 	MultipartCrlfLfLines
-	// MultipartDataBefore kept for compatibility
+	// MultipartDataBefore will be set to 1 when there is data before the first multipart boundary
 	MultipartDataBefore
-	// MultipartFileLimitExceeded kept for compatibility
-	MultipartFileLimitExceeded
-	// MultipartHeaderFolding kept for compatibility
+	// MultipartHeaderFolding will be set to 1 when a multipart part header is folded
 	MultipartHeaderFolding
-	// MultipartInvalidHeaderFolding kept for compatibility
+	// MultipartInvalidHeaderFolding will be set to 1 when a multipart part header is folded
+	// with characters other than space or horizontal tab
 	MultipartInvalidHeaderFolding
-	// MultipartInvalidPart kept for compatibility
+	// MultipartInvalidPart will be set to 1 when a multipart part has invalid headers
 	MultipartInvalidPart
-	// MultipartInvalidQuoting kept for compatibility
+	// MultipartInvalidQuoting will be set to 1 when a Content-Disposition parameter is badly quoted
 	MultipartInvalidQuoting
-	// MultipartLfLine kept for compatibility
+	// MultipartLfLine will be set to 1 when the multipart body uses LF line endings
 	MultipartLfLine
-	// MultipartMissingSemicolon kept for compatibility
+	// MultipartMissingSemicolon will be set to 1 when a Content-Disposition parameter is not
+	// preceded by a semicolon
 	MultipartMissingSemicolon
-	// MultipartStrictError kept for compatibility
+	// MultipartStrictError will be set to 1 when any of the multipart strict parsing flags is set
+	// or the multipart body can't be parsed
 	MultipartStrictError
-	// MultipartUnmatchedBoundary kept for compatibility
+	// MultipartUnmatchedBoundary will be set to 1 when a line looks like a boundary but doesn't match it
 	MultipartUnmatchedBoundary
+
+	// Unsupported variables
+
+	// FullRequest is the full request
+	FullRequest
+	// MultipartFileLimitExceeded kept for compatibility
+	MultipartFileLimitExceeded
 	// PathInfo is kept for compatibility
 	PathInfo
 	// Sessionid is not supported
 	Sessionid
 	// Userid is not supported
 	Userid
-	// IP is kept for compatibility
-	IP
 	// ResBodyError
 	ResBodyError
 	// ResBodyErrorMsg
@@ -28,6 +28,12 @@ func (v RuleVariable) Name() string {
 		return "FULL_REQUEST_LENGTH"
 	case InboundDataError:
 		return "INBOUND_DATA_ERROR"
+	case ArgsLimitExceeded:
+		return "ARGS_LIMIT_EXCEEDED"
+	case RequestHeadersLimitExceeded:
+		return "REQUEST_HEADERS_LIMIT_EXCEEDED"
+	case RequestCookiesLimitExceeded:
+		return "REQUEST_COOKIES_LIMIT_EXCEEDED"
 	case MatchedVar:
 		return "MATCHED_VAR"
 	case MatchedVarName:
@@ -58,6 +64,8 @@ func (v RuleVariable) Name() string {
 		return "REQUEST_BASENAME"
 	case RequestBody:
 		return "REQUEST_BODY"
+	case RequestBodyRaw:
+		return "REQUEST_BODY_RAW"
 	case RequestBodyLength:
 		return "REQUEST_BODY_LENGTH"
 	case RequestFilename:
@@ -74,6 +82,8 @@ func (v RuleVariable) Name() string {
 		return "REQUEST_URI_RAW"
 	case ResponseBody:
 		return "RESPONSE_BODY"
+	case ResponseBodyRaw:
+		return "RESPONSE_BODY_RAW"
 	case ResponseContentLength:
 		return "RESPONSE_CONTENT_LENGTH"
 	case ResponseProtocol:
@@ -86,6 +96,8 @@ func (v RuleVariable) Name() string {
 		return "SERVER_NAME"
 	case ServerPort:
 		return "SERVER_PORT"
+	case WebserverErrorLog:
+		return "WEBSERVER_ERROR_LOG"
 	case HighestSeverity:
 		return "HIGHEST_SEVERITY"
 	case StatusLine:
@@ -126,10 +138,28 @@ func (v RuleVariable) Name() string {
 		return "REQUEST_HEADERS"
 	case ResponseHeaders:
 		return "RESPONSE_HEADERS"
+	case RequestTrailers:
+		return "REQUEST_TRAILERS"
+	case ResponseTrailers:
+		return "RESPONSE_TRAILERS"
 	case ResBodyProcessor:
 		return "RES_BODY_PROCESSOR"
 	case Geo:
 		return "GEO"
+	case IP:
+		return "IP"
+	case Global:
+		return "GLOBAL"
+	case Session:
+		return "SESSION"
+	case User:
+		return "USER"
+	case Resource:
+		return "RESOURCE"
+	case AuthType:
+		return "AUTH_TYPE"
+	case RemoteUser:
+		return "REMOTE_USER"
 	case RequestCookiesNames:
 		return "REQUEST_COOKIES_NAMES"
 	case FilesTmpNames:
@@ -160,10 +190,6 @@ func (v RuleVariable) Name() string {
 		return "XML"
 	case MultipartPartHeaders:
 		return "MULTIPART_PART_HEADERS"
-	case AuthType:
-		return "AUTH_TYPE"
-	case FullRequest:
-		return "FULL_REQUEST"
 	case MultipartBoundaryQuoted:
 		return "MULTIPART_BOUNDARY_QUOTED"
 	case MultipartBoundaryWhitespace:
@@ -172,8 +198,6 @@ func (v RuleVariable) Name() string {
 		return "MULTIPART_CRLF_LF_LINES"
 	case MultipartDataBefore:
 		return "MULTIPART_DATA_BEFORE"
-	case MultipartFileLimitExceeded:
-		return "MULTIPART_FILE_LIMIT_EXCEEDED"
 	case MultipartHeaderFolding:
 		return "MULTIPART_HEADER_FOLDING"
 	case MultipartInvalidHeaderFolding:
@@ -190,14 +214,16 @@ func (v RuleVariable) Name() string {
 		return "MULTIPART_STRICT_ERROR"
 	case MultipartUnmatchedBoundary:
 		return "MULTIPART_UNMATCHED_BOUNDARY"
+	case FullRequest:
+		return "FULL_REQUEST"
+	case MultipartFileLimitExceeded:
+		return "MULTIPART_FILE_LIMIT_EXCEEDED"
 	case PathInfo:
 		return "PATH_INFO"
 	case Sessionid:
 		return "SESSIONID"
 	case Userid:
 		return "USERID"
-	case IP:
-		return "IP"
 	case ResBodyError:
 		return "RES_BODY_ERROR"
 	case ResBodyErrorMsg:
@@ -238,6 +264,9 @@ var rulemapRev = map[string]RuleVariable{
 	"FILES_COMBINED_SIZE":              FilesCombinedSize,
 	"FULL_REQUEST_LENGTH":              FullRequestLength,
 	"INBOUND_DATA_ERROR":               InboundDataError,
+	"ARGS_LIMIT_EXCEEDED":              ArgsLimitExceeded,
+	"REQUEST_HEADERS_LIMIT_EXCEEDED":   RequestHeadersLimitExceeded,
+	"REQUEST_COOKIES_LIMIT_EXCEEDED":   RequestCookiesLimitExceeded,
 	"MATCHED_VAR":                      MatchedVar,
 	"MATCHED_VAR_NAME":                 MatchedVarName,
 	"MULTIPART_DATA_AFTER":             MultipartDataAfter,
@@ -253,6 +282,7 @@ var rulemapRev = map[string]RuleVariable{
 	"REQBODY_PROCESSOR":                ReqbodyProcessor,
 	"REQUEST_BASENAME":                 RequestBasename,
 	"REQUEST_BODY":                     RequestBody,
+	"REQUEST_BODY_RAW":                 RequestBodyRaw,
 	"REQUEST_BODY_LENGTH":              RequestBodyLength,
 	"REQUEST_FILENAME":                 RequestFilename,
 	"REQUEST_LINE":                     RequestLine,
@@ -261,12 +291,14 @@ var rulemapRev = map[string]RuleVariable{
 	"REQUEST_URI":                      RequestURI,
 	"REQUEST_URI_RAW":                  RequestURIRaw,
 	"RESPONSE_BODY":                    ResponseBody,
+	"RESPONSE_BODY_RAW":                ResponseBodyRaw,
 	"RESPONSE_CONTENT_LENGTH":          ResponseContentLength,
 	"RESPONSE_PROTOCOL":                ResponseProtocol,
 	"RESPONSE_STATUS":                  ResponseStatus,
 	"SERVER_ADDR":                      ServerAddr,
 	"SERVER_NAME":                      ServerName,
 	"SERVER_PORT":                      ServerPort,
+	"WEBSERVER_ERROR_LOG":              WebserverErrorLog,
 	"HIGHEST_SEVERITY":                 HighestSeverity,
 	"STATUS_LINE":                      StatusLine,
 	"DURATION":                         Duration,
@@ -287,8 +319,17 @@ var rulemapRev = map[string]RuleVariable{
 	"REQUEST_COOKIES":                  RequestCookies,
 	"REQUEST_HEADERS":                  RequestHeaders,
 	"RESPONSE_HEADERS":                 ResponseHeaders,
+	"REQUEST_TRAILERS":                 RequestTrailers,
+	"RESPONSE_TRAILERS":                ResponseTrailers,
 	"RES_BODY_PROCESSOR":               ResBodyProcessor,
 	"GEO":                              Geo,
+	"IP":                               IP,
+	"GLOBAL":                           Global,
+	"SESSION":                          Session,
+	"USER":                             User,
+	"RESOURCE":                         Resource,
+	"AUTH_TYPE":                        AuthType,
+	"REMOTE_USER":                      RemoteUser,
 	"REQUEST_COOKIES_NAMES":            RequestCookiesNames,
 	"FILES_TMPNAMES":                   FilesTmpNames,
 	"ARGS_NAMES":                       ArgsNames,
@@ -304,13 +345,10 @@ var rulemapRev = map[string]RuleVariable{
 	"REQUEST_XML":                      RequestXML,
 	"XML":                              XML,
 	"MULTIPART_PART_HEADERS":           MultipartPartHeaders,
-	"AUTH_TYPE":                        AuthType,
-	"FULL_REQUEST":                     FullRequest,
 	"MULTIPART_BOUNDARY_QUOTED":        MultipartBoundaryQuoted,
 	"MULTIPART_BOUNDARY_WHITESPACE":    MultipartBoundaryWhitespace,
 	"MULTIPART_CRLF_LF_LINES":          MultipartCrlfLfLines,
 	"MULTIPART_DATA_BEFORE":            MultipartDataBefore,
-	"MULTIPART_FILE_LIMIT_EXCEEDED":    MultipartFileLimitExceeded,
 	"MULTIPART_HEADER_FOLDING":         MultipartHeaderFolding,
 	"MULTIPART_INVALID_HEADER_FOLDING": MultipartInvalidHeaderFolding,
 	"MULTIPART_INVALID_PART":           MultipartInvalidPart,
@@ -319,10 +357,11 @@ var rulemapRev = map[string]RuleVariable{
 	"MULTIPART_MISSING_SEMICOLON":      MultipartMissingSemicolon,
 	"MULTIPART_STRICT_ERROR":           MultipartStrictError,
 	"MULTIPART_UNMATCHED_BOUNDARY":     MultipartUnmatchedBoundary,
+	"FULL_REQUEST":                     FullRequest,
+	"MULTIPART_FILE_LIMIT_EXCEEDED":    MultipartFileLimitExceeded,
 	"PATH_INFO":                        PathInfo,
 	"SESSIONID":                        Sessionid,
 	"USERID":                           Userid,
-	"IP":                               IP,
 	"RES_BODY_ERROR":                   ResBodyError,
 	"RES_BODY_ERROR_MSG":               ResBodyErrorMsg,
 	"RES_BODY_PROCESSOR_ERROR":         ResBodyProcessorError,
@@ -9,16 +9,20 @@
 package testing
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/corazawaf/coraza/v3/internal/auditlog"
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
 	"github.com/corazawaf/coraza/v3/internal/seclang"
+	"github.com/corazawaf/coraza/v3/types"
 )
 
 func TestAuditLogMessages(t *testing.T) {
@@ -435,3 +439,434 @@ func TestAuditLogWithKFlagWithoutHFlag(t *testing.T) {
 		t.Errorf("Not expected audit log to contain %q, got %q", notExpected, alWithErrMsg.ErrorMessage())
 	}
 }
+
+func TestAuditLogSanitise(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	parser := seclang.NewParser(waf)
+	if err := parser.FromString(`
+		SecRuleEngine DetectionOnly
+		SecAuditEngine On
+		SecAuditLogFormat json
+		SecAuditLogType serial
+		SecAuditLogParts ABCFZ
+		SecRequestBodyAccess On
+		SecAction "id:1,phase:1,nolog,pass,sanitiseArg:password,sanitiseRequestHeader:Authorization"
+		SecRule ARGS:token "@rx ^s3cr3t" "id:2,phase:2,nolog,pass,sanitiseMatched"
+	`); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(filepath.Join(t.TempDir(), "tmp.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if err := parser.FromString(fmt.Sprintf("SecAuditLog %s", file.Name())); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := waf.NewTransaction()
+	tx.ProcessURI("/login?password=hunter2&user=admin", "POST", "HTTP/1.1")
+	tx.AddRequestHeader("Authorization", "Basic dXNlcjpodW50ZXIy")
+	tx.AddRequestHeader("Content-Type", "application/x-www-form-urlencoded")
+	tx.ProcessRequestHeaders()
+	if _, _, err := tx.ReadRequestBodyFrom(strings.NewReader("token=s3cr3t-value&comment=hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ProcessRequestBody(); err != nil {
+		t.Fatal(err)
+	}
+	tx.ProcessLogging()
+
+	if _, err := file.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	var al auditlog.Log
+	if err := json.NewDecoder(file).Decode(&al); err != nil {
+		t.Fatal(err)
+	}
+	req := al.Transaction().Request()
+	if req == nil {
+		t.Fatal("expected request in audit log")
+	}
+	if want, have := "/login?password=****&user=admin", req.URI(); want != have {
+		t.Errorf("unexpected uri, want %q, have %q", want, have)
+	}
+	if want, have := "token=****&comment=hello", req.Body(); want != have {
+		t.Errorf("unexpected body, want %q, have %q", want, have)
+	}
+	if want, have := []string{"****"}, req.Headers()["authorization"]; len(have) != 1 || want[0] != have[0] {
+		t.Errorf("unexpected authorization header, want %q, have %q", want, have)
+	}
+
+	// the transaction variables must not be altered by the redaction
+	if want, have := "hunter2", tx.Variables().ArgsGet().Get("password"); len(have) != 1 || want != have[0] {
+		t.Errorf("unexpected password argument, want %q, have %q", want, have)
+	}
+	for _, arg := range tx.AuditLog().Transaction().Request().Args().FindAll() {
+		if arg.Key() == "password" || arg.Key() == "token" {
+			if arg.Value() != "****" {
+				t.Errorf("expected argument %q to be sanitised, got %q", arg.Key(), arg.Value())
+			}
+		}
+	}
+}
+
+func TestAuditLogSanitiseMatchedBytes(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	parser := seclang.NewParser(waf)
+	if err := parser.FromString(`
+		SecRuleEngine DetectionOnly
+		SecAuditEngine On
+		SecAuditLogFormat json
+		SecAuditLogType serial
+		SecAuditLogParts ABZ
+		SecRule ARGS:cc "@rx \d{16}" "id:1,phase:1,nolog,pass,capture,sanitiseMatchedBytes:0/4"
+		SecRule REQUEST_HEADERS:X-Card "@rx \d{16}" "id:2,phase:1,nolog,pass,capture,sanitiseMatchedBytes"
+	`); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(filepath.Join(t.TempDir(), "tmp.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if err := parser.FromString(fmt.Sprintf("SecAuditLog %s", file.Name())); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := waf.NewTransaction()
+	tx.ProcessURI("/pay?cc=card-4111111111111111-visa&id=1", "GET", "HTTP/1.1")
+	tx.AddRequestHeader("X-Card", "number 4111111111111111")
+	tx.ProcessRequestHeaders()
+	tx.ProcessLogging()
+
+	if _, err := file.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	var al auditlog.Log
+	if err := json.NewDecoder(file).Decode(&al); err != nil {
+		t.Fatal(err)
+	}
+	req := al.Transaction().Request()
+	if req == nil {
+		t.Fatal("expected request in audit log")
+	}
+	if want, have := "/pay?cc=card-************1111-visa&id=1", req.URI(); want != have {
+		t.Errorf("unexpected uri, want %q, have %q", want, have)
+	}
+	if want, have := []string{"number ****************"}, req.Headers()["x-card"]; len(have) != 1 || want[0] != have[0] {
+		t.Errorf("unexpected x-card header, want %q, have %q", want, have)
+	}
+}
+
+func TestAuditLogSanitiseMatchedBytesOffsets(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	parser := seclang.NewParser(waf)
+	file, err := os.Create(filepath.Join(t.TempDir(), "tmp.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	// the expression matches the last card number, the same digits appear
+	// before in the value and must be left untouched
+	if err := parser.FromString(fmt.Sprintf(`
+		SecRuleEngine DetectionOnly
+		SecAuditEngine On
+		SecAuditLogFormat json
+		SecAuditLogType serial
+		SecAuditLogParts ABZ
+		SecAuditLog %s
+		SecRule ARGS:note "@rx \b4\d{15}$" "id:1,phase:1,nolog,pass,capture,sanitiseMatchedBytes:0/4"
+	`, file.Name())); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := waf.NewTransaction()
+	tx.ProcessURI("/pay?note=ref+4111111111111111+card+4111111111111111&id=1", "GET", "HTTP/1.1")
+	tx.ProcessRequestHeaders()
+	tx.ProcessLogging()
+
+	if _, err := file.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	var al auditlog.Log
+	if err := json.NewDecoder(file).Decode(&al); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "/pay?note=ref+4111111111111111+card+************1111&id=1", al.Transaction().Request().URI(); want != have {
+		t.Errorf("unexpected uri, want %q, have %q", want, have)
+	}
+}
+
+func TestAuditLogJSONConcurrentTransactions(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	parser := seclang.NewParser(waf)
+	file, err := os.Create(filepath.Join(t.TempDir(), "tmp.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if err := parser.FromString(`
+		SecRuleEngine On
+		SecAuditEngine On
+		SecAuditLogFormat JSON
+		SecAuditLogType serial
+		SecAuditLogParts ABHKZ
+		SecRule ARGS:id "@rx ^\d+$" "id:1,phase:1,log,pass,msg:'numeric id',logdata:'%{MATCHED_VAR}',severity:'NOTICE',tag:'id'"
+		SecRule ARGS:id "@streq 13" "id:2,phase:1,log,deny,status:403,msg:'blocked id',severity:'CRITICAL',tag:'attack'"
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.FromString(fmt.Sprintf("SecAuditLog %s", file.Name())); err != nil {
+		t.Fatal(err)
+	}
+
+	const txs = 50
+	var wg sync.WaitGroup
+	for i := 0; i < txs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx := waf.NewTransactionWithOptions(corazawaf.Options{ID: fmt.Sprintf("tx-%d", i)})
+			tx.ProcessConnection("10.0.0.1", 1234, "10.0.0.2", 80)
+			tx.ProcessURI(fmt.Sprintf("/item?id=%d", i), "GET", "HTTP/1.1")
+			tx.AddRequestHeader("Host", "example.com")
+			tx.ProcessRequestHeaders()
+			tx.ProcessLogging()
+			_ = tx.Close()
+		}(i)
+	}
+	wg.Wait()
+
+	if _, err := file.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var al auditlog.Log
+		// every transaction is logged as a single JSON line
+		if err := json.Unmarshal(scanner.Bytes(), &al); err != nil {
+			t.Fatalf("failed to unmarshal audit log line %q: %s", scanner.Text(), err.Error())
+		}
+		tr := al.Transaction()
+		seen[tr.ID()] = true
+		if want, have := "10.0.0.1", tr.ClientIP(); want != have {
+			t.Errorf("unexpected client ip, want %q, have %q", want, have)
+		}
+		if want, have := "example.com", tr.Request().Headers()["host"]; len(have) != 1 || have[0] != want {
+			t.Errorf("unexpected host header, want %q, have %q", want, have)
+		}
+
+		var id int
+		if _, err := fmt.Sscanf(tr.ID(), "tx-%d", &id); err != nil {
+			t.Fatal(err)
+		}
+		blocked := id == 13
+		want := 1
+		if blocked {
+			want = 2
+		}
+		if have := len(al.Messages()); want != have {
+			t.Fatalf("unexpected messages for %s, want %d, have %d", tr.ID(), want, have)
+		}
+		data := al.Messages()[0].Data()
+		if data.ID() != 1 || data.Msg() != "numeric id" || data.Data() != strconv.Itoa(id) ||
+			data.Severity() != types.RuleSeverityNotice || len(data.Tags()) != 1 || data.Tags()[0] != "id" {
+			t.Errorf("unexpected matched rule for %s: %+v", tr.ID(), data)
+		}
+
+		interruption := al.Transaction_.Interruption()
+		if !blocked {
+			if interruption != nil {
+				t.Errorf("unexpected interruption for %s: %+v", tr.ID(), interruption)
+			}
+			continue
+		}
+		if data := al.Messages()[1].Data(); data.ID() != 2 || data.Severity() != types.RuleSeverityCritical {
+			t.Errorf("unexpected matched rule for %s: %+v", tr.ID(), data)
+		}
+		if interruption.RuleID() != 2 || interruption.Action() != "deny" || interruption.Status() != 403 {
+			t.Errorf("unexpected interruption for %s: %+v", tr.ID(), interruption)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != txs {
+		t.Errorf("unexpected number of logged transactions, want %d, have %d", txs, len(seen))
+	}
+}
+
+func TestAuditLogJSONParts(t *testing.T) {
+	tests := []struct {
+		parts          string
+		expectHeaders  bool
+		expectMessages bool
+		expectProducer bool
+	}{
+		{"AZ", false, false, false},
+		{"ABZ", true, false, false},
+		{"AKZ", false, true, false},
+		{"ABHKZ", true, true, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.parts, func(t *testing.T) {
+			waf := corazawaf.NewWAF()
+			parser := seclang.NewParser(waf)
+			file, err := os.Create(filepath.Join(t.TempDir(), "tmp.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := parser.FromString(fmt.Sprintf(`
+				SecAuditEngine On
+				SecAuditLogFormat JSON
+				SecAuditLogType serial
+				SecAuditLogParts %s
+				SecAuditLog %s
+				SecRule ARGS "@unconditionalMatch" "id:1,phase:1,log,pass,msg:'unconditional match'"
+			`, tc.parts, file.Name())); err != nil {
+				t.Fatal(err)
+			}
+
+			tx := waf.NewTransaction()
+			tx.AddRequestHeader("Host", "example.com")
+			tx.AddGetRequestArgument("test", "test")
+			tx.ProcessRequestHeaders()
+			tx.ProcessLogging()
+
+			var al auditlog.Log
+			if err := json.NewDecoder(file).Decode(&al); err != nil {
+				t.Fatal(err)
+			}
+			if have := len(al.Transaction().Request().Headers()) > 0; have != tc.expectHeaders {
+				t.Errorf("unexpected request headers, want %t, have %t", tc.expectHeaders, have)
+			}
+			if have := len(al.Messages()) > 0; have != tc.expectMessages {
+				t.Errorf("unexpected messages, want %t, have %t", tc.expectMessages, have)
+			}
+			if have := al.Transaction().Producer() != (*auditlog.TransactionProducer)(nil); have != tc.expectProducer {
+				t.Errorf("unexpected producer, want %t, have %t", tc.expectProducer, have)
+			}
+		})
+	}
+}
+
+func TestAuditLogConcurrentStorage(t *testing.T) {
+	dir := t.TempDir()
+	waf := corazawaf.NewWAF()
+	parser := seclang.NewParser(waf)
+	if err := parser.FromString(fmt.Sprintf(`
+		SecAuditEngine On
+		SecAuditLogFormat JSON
+		SecAuditLogType Concurrent
+		SecAuditLog %s
+		SecAuditLogStorageDir %s
+		SecAuditLogParts ABHKZ
+		SecRule ARGS "@unconditionalMatch" "id:1,phase:1,log,pass,msg:'unconditional match'"
+	`, filepath.Join(dir, "index"), filepath.Join(dir, "storage"))); err != nil {
+		t.Fatal(err)
+	}
+
+	const txs = 10
+	var wg sync.WaitGroup
+	for i := 0; i < txs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx := waf.NewTransactionWithOptions(corazawaf.Options{ID: fmt.Sprintf("tx-%d", i)})
+			tx.ProcessURI("/?a=b", "GET", "HTTP/1.1")
+			tx.ProcessRequestHeaders()
+			tx.ProcessLogging()
+			_ = tx.Close()
+		}(i)
+	}
+	wg.Wait()
+
+	index, err := os.ReadFile(filepath.Join(dir, "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(index)), "\n")
+	if len(lines) != txs {
+		t.Fatalf("unexpected index entries, want %d, have %d", txs, len(lines))
+	}
+	for _, line := range lines {
+		// the entry ends with the file path relative to the storage directory,
+		// its offset and size
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			t.Fatalf("unexpected index entry %q", line)
+		}
+		relpath := fields[len(fields)-3]
+		data, err := os.ReadFile(filepath.Join(dir, "storage", relpath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var al auditlog.Log
+		if err := json.Unmarshal(data, &al); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(relpath, "-"+al.Transaction().ID()) {
+			t.Errorf("unexpected transaction %s in %s", al.Transaction().ID(), relpath)
+		}
+		if len(al.Messages()) != 1 || al.Messages()[0].Message() != "unconditional match" {
+			t.Errorf("unexpected messages in %s", relpath)
+		}
+	}
+}
+
+func TestAuditLogSanitiseJSON(t *testing.T) {
+	waf := corazawaf.NewWAF()
+	parser := seclang.NewParser(waf)
+	if err := parser.FromString(`
+		SecRuleEngine DetectionOnly
+		SecAuditEngine On
+		SecAuditLogFormat json
+		SecAuditLogType serial
+		SecAuditLogParts ABCFZ
+		SecRequestBodyAccess On
+		SecAction "id:1,phase:1,nolog,pass,sanitiseArg:password"
+	`); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(filepath.Join(t.TempDir(), "tmp.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if err := parser.FromString(fmt.Sprintf("SecAuditLog %s", file.Name())); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := waf.NewTransaction()
+	tx.ProcessURI("/login", "POST", "HTTP/1.1")
+	tx.AddRequestHeader("Content-Type", "application/json")
+	tx.ProcessRequestHeaders()
+	if _, _, err := tx.ReadRequestBodyFrom(strings.NewReader(`{"password":"hunter2","user":"admin"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ProcessRequestBody(); err != nil {
+		t.Fatal(err)
+	}
+	tx.ProcessLogging()
+
+	if _, err := file.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	var al auditlog.Log
+	if err := json.NewDecoder(file).Decode(&al); err != nil {
+		t.Fatal(err)
+	}
+	req := al.Transaction().Request()
+	if req == nil {
+		t.Fatal("expected request in audit log")
+	}
+	if strings.Contains(req.Body(), "hunter2") {
+		t.Errorf("unexpected password in body %q", req.Body())
+	}
+	if want, have := "json.password=****&json.user=admin", req.Body(); want != have {
+		t.Errorf("unexpected body, want %q, have %q", want, have)
+	}
+}
@@ -38,6 +38,7 @@ import (
 )
 
 func BenchmarkCRSCompilation(b *testing.B) {
+	b.ReportAllocs()
 	rec, err := os.ReadFile(filepath.Join("..", "..", "coraza.conf-recommended"))
 	if err != nil {
 		b.Fatal(err)
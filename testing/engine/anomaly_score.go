@@ -0,0 +1,55 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package engine
+
+import (
+	"github.com/corazawaf/coraza/v3/testing/profile"
+)
+
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Test if SecAnomalyScoreThreshold interrupts once the score reaches the threshold",
+		Enabled:     true,
+		Name:        "anomaly_score_threshold.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "anomaly score threshold",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/?a=attack",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{1},
+							NonTriggeredRules: []int{2},
+						},
+					},
+				},
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/?a=attack&b=exploit",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules: []int{1, 2},
+							Interruption: &profile.ExpectedInterruption{
+								Action: "deny",
+								Status: 403,
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+SecAnomalyScoreThreshold tx.anomaly_score 5
+SecAction "id:10, phase:1, pass, nolog, setvar:tx.anomaly_score=0"
+SecRule ARGS:a "attack" "id:1, phase:2, pass, log, setvar:tx.anomaly_score=+3"
+SecRule ARGS:b "exploit" "id:2, phase:2, pass, log, setvar:tx.anomaly_score=+2"
+`,
+})
@@ -78,3 +78,157 @@ SecRule ARGS_POST:pineapple "pizza" "id:105,log,phase:2"
 SecAction "id:444,phase:2,log"
 `,
 })
+
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Test if ctl exclusions from phase 1 apply to later phases of the transaction only",
+		Enabled:     true,
+		Name:        "ctl_exclusions.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "ctl exclusions",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/exclude?json.1.id=attack&user=attack",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{1, 30},
+							NonTriggeredRules: []int{10, 20, 21, 31},
+						},
+					},
+				},
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/include?json.1.id=attack&user=attack",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{10, 20, 21, 30, 31},
+							NonTriggeredRules: []int{1},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+SecRule REQUEST_URI "@beginsWith /exclude" "id:1, phase:1, pass, log, \
+	ctl:ruleRemoveById=10, \
+	ctl:ruleRemoveById=20-21, \
+	ctl:ruleRemoveTargetById=31;ARGS:/^json\.\d+\.id$/"
+
+SecRule ARGS "attack" "id:10, phase:2, pass, log"
+SecRule ARGS "attack" "id:20, phase:2, pass, log"
+SecRule ARGS "attack" "id:21, phase:2, pass, log"
+SecRule ARGS:user "attack" "id:30, phase:2, pass, log"
+SecRule ARGS:json.1.id "attack" "id:31, phase:2, pass, log"
+`,
+})
+
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Test if ctl:ruleRemoveTargetByTag removes the target for the current transaction only",
+		Enabled:     true,
+		Name:        "ctl_remove_target_by_tag.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "ctl ruleRemoveTargetByTag",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/login?password=attack&user=attack",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{1, 11, 20},
+							NonTriggeredRules: []int{10},
+						},
+					},
+				},
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/register?password=attack&user=attack",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{10, 11, 20},
+							NonTriggeredRules: []int{1},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+SecRule REQUEST_URI "@beginsWith /login" "id:1, phase:1, pass, log, ctl:ruleRemoveTargetByTag=attack-sqli;ARGS:password"
+
+# Rule 10 only inspects the password, removed for /login. Rule 11 still inspects the user.
+SecRule ARGS:password "attack" "id:10, phase:2, pass, log, tag:attack-sqli"
+SecRule ARGS "attack" "id:11, phase:2, pass, log, tag:attack-sqli"
+# Rule 20 is not tagged, the password is still inspected.
+SecRule ARGS:password "attack" "id:20, phase:2, pass, log, tag:attack-xss"
+`,
+})
+
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Test if ctl:requestBodyProcessor in phase 1 overrides the processor selected by the content-type",
+		Enabled:     true,
+		Name:        "ctl_request_body_processor.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "ctl requestBodyProcessor",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI:    "/api/users",
+							Method: "POST",
+							Headers: map[string]string{
+								"Content-Type": "application/x-www-form-urlencoded",
+							},
+							Data: `{"user":"admin"}`,
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{1, 10, 11},
+							NonTriggeredRules: []int{12, 20},
+						},
+					},
+				},
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI:    "/form",
+							Method: "POST",
+							Headers: map[string]string{
+								"Content-Type": "application/x-www-form-urlencoded",
+							},
+							Data: `user=admin`,
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{12, 20},
+							NonTriggeredRules: []int{1, 10, 11},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+SecRequestBodyAccess On
+SecRule REQUEST_URI "@beginsWith /api/" "id:1, phase:1, pass, log, ctl:requestBodyProcessor=JSON"
+
+SecRule REQBODY_PROCESSOR "@streq JSON" "id:10, phase:2, pass, log"
+SecRule ARGS_POST:json.user "@streq admin" "id:11, phase:2, pass, log"
+SecRule REQBODY_PROCESSOR "@streq URLENCODED" "id:12, phase:2, pass, log"
+SecRule ARGS_POST:user "@streq admin" "id:20, phase:2, pass, log"
+`,
+})
@@ -283,51 +283,19 @@ var _ = profile.RegisterProfile(profile.Profile{
 				{
 					Stage: profile.SubStage{
 						Input: profile.StageInput{
-							URI: "/redirect5",
+							URI: "/logging5",
 						},
 						Output: profile.ExpectedOutput{
-							TriggeredRules: []int{51},
-							Interruption: &profile.ExpectedInterruption{
-								Status: 302,
-								Data:   "https://www.example.com",
-								RuleID: 51,
-								Action: "redirect",
-							},
-						},
-					},
-				},
-				{
-					Stage: profile.SubStage{
-						Input: profile.StageInput{
-							URI: "/deny5",
-						},
-						Output: profile.ExpectedOutput{
-							TriggeredRules: []int{52},
+							TriggeredRules: []int{51, 52},
 							Interruption: &profile.ExpectedInterruption{
 								Status: 500,
 								Data:   "",
-								RuleID: 52,
+								RuleID: 51,
 								Action: "deny",
 							},
 						},
 					},
 				},
-				{
-					Stage: profile.SubStage{
-						Input: profile.StageInput{
-							URI: "/drop5",
-						},
-						Output: profile.ExpectedOutput{
-							TriggeredRules: []int{53},
-							Interruption: &profile.ExpectedInterruption{
-								Status: 0,
-								Data:   "",
-								RuleID: 53,
-								Action: "drop",
-							},
-						},
-					},
-				},
 				{
 					Stage: profile.SubStage{
 						Input: profile.StageInput{
@@ -366,9 +334,10 @@ SecRule REQUEST_URI "/redirect4$" "phase:4,id:41,log,status:302,redirect:https:/
 SecRule REQUEST_URI "/deny4$" "phase:4,id:42,log,status:500,deny"
 SecRule REQUEST_URI "/drop4$" "phase:4,id:43,log,drop"
 
-SecRule REQUEST_URI "/redirect5$" "phase:5,id:51,log,status:302,redirect:https://www.example.com"
-SecRule REQUEST_URI "/deny5$" "phase:5,id:52,log,status:500,deny"
-SecRule REQUEST_URI "/drop5$" "phase:5,id:53,log,drop"
+# Disruptive actions interrupting the transaction are not allowed in phase 5, which always runs.
+# Rule 52 is declared first as multiphase evaluation runs it as soon as REQUEST_URI is available.
+SecRule REQUEST_URI "/logging5$" "phase:5,id:52,log,pass"
+SecRule REQUEST_URI "/logging5$" "phase:1,id:51,log,status:500,deny"
 
 SecRule REQUEST_URI "/redirect6$" "phase:2,id:61,log,redirect:https://www.example.com"
 SecRule REQUEST_URI "/redirect7$" "phase:2,id:62,log,status:307,redirect:https://www.example.com"
@@ -133,3 +133,172 @@ SecRule MULTIPART_STRICT_ERROR "!@eq 0" \
     "id:'200003',phase:2,t:none,log,deny,status:400, msg:'Multipart request body failed strict validation."
   `,
 })
+
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Multipart anomaly flags",
+		Enabled:     true,
+		Name:        "multipart_flags.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "multipart valid body",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/",
+							Headers: map[string]string{
+								"Host":         "www.example.com",
+								"Content-Type": "multipart/form-data; boundary=0000",
+							},
+							Data: "--0000\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n--0000--\r\n",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{},
+							NonTriggeredRules: []int{100, 101, 102, 103, 104, 105, 106},
+						},
+					},
+				},
+			},
+		},
+		{
+			Title: "multipart lf lines",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/",
+							Headers: map[string]string{
+								"Host":         "www.example.com",
+								"Content-Type": "multipart/form-data; boundary=0000",
+							},
+							Data: "--0000\nContent-Disposition: form-data; name=\"a\"\n\n1\n--0000--\n",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{101, 106},
+							NonTriggeredRules: []int{100, 102, 103, 104, 105},
+						},
+					},
+				},
+			},
+		},
+		{
+			Title: "multipart crlf and lf lines",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/",
+							Headers: map[string]string{
+								"Host":         "www.example.com",
+								"Content-Type": "multipart/form-data; boundary=0000",
+							},
+							Data: "--0000\r\nContent-Disposition: form-data; name=\"a\"\n\r\n1\r\n--0000--\r\n",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{100, 101, 106},
+							NonTriggeredRules: []int{102, 103, 104, 105},
+						},
+					},
+				},
+			},
+		},
+		{
+			Title: "multipart boundary quoted",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/",
+							Headers: map[string]string{
+								"Host":         "www.example.com",
+								"Content-Type": "multipart/form-data; boundary=\"0000\"",
+							},
+							Data: "--0000\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n--0000--\r\n",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{102, 106},
+							NonTriggeredRules: []int{100, 101, 103, 104, 105},
+						},
+					},
+				},
+			},
+		},
+		{
+			Title: "multipart boundary whitespace",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/",
+							Headers: map[string]string{
+								"Host":         "www.example.com",
+								"Content-Type": "multipart/form-data; boundary= 0000",
+							},
+							Data: "--0000\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n--0000--\r\n",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{103, 106},
+							NonTriggeredRules: []int{100, 101, 102, 104, 105},
+						},
+					},
+				},
+			},
+		},
+		{
+			Title: "multipart invalid quoting",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/",
+							Headers: map[string]string{
+								"Host":         "www.example.com",
+								"Content-Type": "multipart/form-data; boundary=0000",
+							},
+							Data: "--0000\r\nContent-Disposition: form-data; name='a'\r\n\r\n1\r\n--0000--\r\n",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{104, 106},
+							NonTriggeredRules: []int{100, 101, 102, 103, 105},
+						},
+					},
+				},
+			},
+		},
+		{
+			Title: "multipart invalid header folding",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/",
+							Headers: map[string]string{
+								"Host":         "www.example.com",
+								"Content-Type": "multipart/form-data; boundary=0000",
+							},
+							Data: "--0000\r\nContent-Disposition: form-data;\r\n\vname=\"a\"\r\n\r\n1\r\n--0000--\r\n",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{105, 106},
+							NonTriggeredRules: []int{100, 101, 102, 103, 104},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+SecRuleEngine DetectionOnly
+SecRequestBodyAccess On
+SecRule MULTIPART_CRLF_LF_LINES "!@eq 0" "id:100,phase:2,log"
+SecRule MULTIPART_LF_LINE "!@eq 0" "id:101,phase:2,log"
+SecRule MULTIPART_BOUNDARY_QUOTED "!@eq 0" "id:102,phase:2,log"
+SecRule MULTIPART_BOUNDARY_WHITESPACE "!@eq 0" "id:103,phase:2,log"
+SecRule MULTIPART_INVALID_QUOTING "!@eq 0" "id:104,phase:2,log"
+SecRule MULTIPART_INVALID_HEADER_FOLDING "!@eq 0" "id:105,phase:2,log"
+SecRule MULTIPART_STRICT_ERROR "!@eq 0" "id:106,phase:2,log"
+`,
+})
@@ -28,7 +28,7 @@ var _ = profile.RegisterProfile(profile.Profile{
 							},
 						},
 						Output: profile.ExpectedOutput{
-							TriggeredRules: []int{1, 3, 5, 10},
+							TriggeredRules: []int{1, 2, 3, 5, 10},
 						},
 					},
 				},
@@ -37,6 +37,7 @@ var _ = profile.RegisterProfile(profile.Profile{
 	},
 	Rules: `
 SecRule ARGS_NAMES "@pmFromFile pmFromFile-01.dat" "id:1,log"
+SecRule ARGS_NAMES "@pmf pmFromFile-01.dat" "id:2,log"
 SecRule REQUEST_COOKIES:def "@pmFromFile pmFromFile-01.dat" "id:3,log"
 SecRule REQUEST_COOKIES_NAMES "@pmFromFile pmFromFile-01.dat" "id:5,log"
 SecRule REQUEST_HEADERS_NAMES "@pmFromFile pmFromFile-01.dat" "id:10,log"
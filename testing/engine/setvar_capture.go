@@ -0,0 +1,75 @@
+// Copyright 2026 Juan Pablo Tosso and the OWASP Coraza contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !coraza.rule.multiphase_evaluation
+
+package engine
+
+import (
+	"github.com/corazawaf/coraza/v3/testing/profile"
+)
+
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Test if captured values can be used in setvar arithmetic and keys, like CRS anomaly scoring does",
+		Enabled:     true,
+		Name:        "setvar_capture.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "setvar with captures",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/?weight=7&weight=abc&weight=5x&q=union%20select",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{942100, 100100, 100200, 100300},
+							NonTriggeredRules: []int{100400},
+						},
+					},
+				},
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/?weight=abc&q=select",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{100100, 100400},
+							NonTriggeredRules: []int{942100, 100200, 100300},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+SecAction "id:900000,phase:1,nolog,pass,setvar:tx.critical_anomaly_score=5,setvar:tx.sql_injection_score=0,setvar:tx.inbound_anomaly_score_pl1=0"
+
+SecRule ARGS "@rx (?i)union\s+select" \
+	"id:942100,\
+	phase:2,\
+	capture,\
+	t:none,\
+	log,\
+	pass,\
+	setvar:'tx.inbound_anomaly_score_pl1=+%{tx.critical_anomaly_score}',\
+	setvar:'tx.%{rule.id}-OWASP_CRS-%{matched_var_name}=%{tx.0}'"
+
+SecRule ARGS:weight "@rx ^(\S+)$" \
+	"id:100100,\
+	phase:2,\
+	capture,\
+	t:none,\
+	log,\
+	pass,\
+	setvar:'tx.sql_injection_score=+%{tx.1}'"
+
+SecRule TX:sql_injection_score "@eq 12" "id:100200,phase:2,log,pass"
+SecRule TX:/^942100-owasp_crs-args:q$/ "@streq union select" "id:100300,phase:2,log,pass,chain"
+	SecRule TX:inbound_anomaly_score_pl1 "@eq 5" "t:none"
+SecRule TX:sql_injection_score "@eq 0" "id:100400,phase:2,log,pass"
+`,
+})
@@ -131,6 +131,46 @@ SecRule REQUEST_BODY "phase_multi_skip" "id:32, phase:2, t:none, log, deny, stat
 `,
 })
 
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Tests skip actions counting chains as a single rule",
+		Enabled:     true,
+		Name:        "skip_4.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "skip actions",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/skip_chain",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{60, 63, 70},
+							NonTriggeredRules: []int{61, 62},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+SecDebugLogLevel 5
+
+# Rule 60 skips the next two phase 1 rules: the chain starting at rule 61 counts as one rule,
+# rule 70 belongs to another phase and the SecMarker is not a rule. Therefore only rule 63 runs.
+SecRule REQUEST_URI "/skip_chain" "id:60, phase:1, skip:2, log"
+SecRule REQUEST_URI "/skip_chain" "id:61, phase:1, log, chain"
+	SecRule REQUEST_URI "/skip_chain" "t:none"
+SecRule REQUEST_URI "/skip_chain" "id:70, phase:2, log"
+SecMarker SKIP_CHAIN
+SecRule REQUEST_URI "/skip_chain" "id:62, phase:1, log"
+SecRule REQUEST_URI "/skip_chain" "id:63, phase:1, log"
+`,
+})
+
 var _ = profile.RegisterProfile(profile.Profile{
 	Meta: profile.Meta{
 		Author:      "M4tteoP",
@@ -212,3 +252,39 @@ SecRule REQUEST_BODY "multi_skipafter_phase" "id:52, phase:2, t:none, log, deny,
 SecMarker LOCATION_TWO
 `,
 })
+
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Tests skipafter is scoped to the current phase when the marker is not found in it",
+		Enabled:     true,
+		Name:        "skipafter_phase_scope.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "skipafter phase scope",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/skipafter_phase_scope",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{80, 82, 83},
+							NonTriggeredRules: []int{81},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+# rule 82 precedes the marker, it is evaluated in phase 2 as the skipAfter of rule 80
+# only skips the remaining rules of phase 1
+SecRule REQUEST_URI "/skipafter_phase_scope" "id:82, phase:2, pass, log"
+SecMarker SKIP_PHASE_SCOPE
+SecRule REQUEST_URI "/skipafter_phase_scope" "id:80, phase:1, pass, log, skipAfter:SKIP_PHASE_SCOPE"
+SecRule REQUEST_URI "/skipafter_phase_scope" "id:81, phase:1, pass, log"
+SecRule REQUEST_URI "/skipafter_phase_scope" "id:83, phase:2, pass, log"
+`,
+})
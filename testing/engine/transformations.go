@@ -91,3 +91,77 @@ SecRule TX:matched_times "@eq 1" "id:11, phase:1, pass, log, t:none"
 SecRule TX:matched_times2 "@eq 2" "id:12, phase:1, pass, log, t:none"
 `,
 })
+
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Test if urlDecode flags inputs with multiple layers of url encoding",
+		Enabled:     true,
+		Name:        "transformations_urldecode_double_encoded.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "transformations_urldecode_double_encoded",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/search?q=%27%20or%201",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{1},
+							NonTriggeredRules: []int{2},
+						},
+					},
+				},
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/search?q=%2527%2520or%25201",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules: []int{1, 2},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+SecRule REQUEST_URI "@contains search" "id:1, phase:1, pass, log, t:none, t:urlDecode"
+SecRule TX:URLDECODE_DOUBLE_ENCODED "@eq 1" "id:2, phase:1, pass, log, t:none"
+`,
+})
+
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Test if t:none removes the previous transformations of the rule",
+		Enabled:     true,
+		Name:        "transformations_none.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "transformations_none",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI: "/?q=ABC%2541",
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{1, 2, 4},
+							NonTriggeredRules: []int{3},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+SecRule ARGS:q "@streq abca" "id:1, phase:1, pass, log, t:urlDecode, t:lowercase"
+SecRule ARGS:q "@streq abc%41" "id:2, phase:1, pass, log, t:urlDecode, t:none, t:lowercase"
+SecRule ARGS:q "@streq abca" "id:3, phase:1, pass, log, t:urlDecode, t:none, t:lowercase"
+SecRule ARGS:q "@streq ABC%41" "id:4, phase:1, pass, log, t:lowercase, t:none"
+`,
+})
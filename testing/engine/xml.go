@@ -51,3 +51,59 @@ SecRule XML:/* "test123" "id:500, log"
 SecRule XML://@* "attribute_value" "id:501, log"
 `,
 })
+
+var _ = profile.RegisterProfile(profile.Profile{
+	Meta: profile.Meta{
+		Author:      "coraza",
+		Description: "Test XPath expressions against XML request bodies",
+		Enabled:     true,
+		Name:        "xml_xpath.yaml",
+	},
+	Tests: []profile.Test{
+		{
+			Title: "xml_xpath",
+			Stages: []profile.Stage{
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI:    "/orders",
+							Method: "POST",
+							Headers: map[string]string{
+								"content-type": "text/xml; charset=utf-8",
+							},
+							Data: `<?xml version="1.0"?><order><item sku="a1">apple</item><item sku="b2">pear</item></order>`,
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{200, 201, 202},
+							NonTriggeredRules: []int{203, 204},
+						},
+					},
+				},
+				{
+					Stage: profile.SubStage{
+						Input: profile.StageInput{
+							URI:    "/orders",
+							Method: "POST",
+							Headers: map[string]string{
+								"content-type": "application/xml",
+							},
+							Data: `<?xml version="1.0"?><order><item sku="a1">apple`,
+						},
+						Output: profile.ExpectedOutput{
+							TriggeredRules:    []int{200, 204},
+							NonTriggeredRules: []int{201, 202, 203},
+						},
+					},
+				},
+			},
+		},
+	},
+	Rules: `
+SecRequestBodyAccess On
+SecRule REQBODY_PROCESSOR "@streq XML" "id:200, phase:2, log, pass"
+SecRule XML:/order/item/text() "@rx ^pear$" "id:201, phase:2, log, pass"
+SecRule XML://@sku "@streq a1" "id:202, phase:2, log, pass"
+SecRule XML:/Order/item/text() "@rx ^pear$" "id:203, phase:2, log, pass"
+SecRule REQBODY_ERROR "@eq 1" "id:204, phase:2, log, pass"
+`,
+})
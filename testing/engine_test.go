@@ -5,10 +5,14 @@ package testing
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/corazawaf/coraza/v3"
+	"github.com/corazawaf/coraza/v3/experimental"
+	"github.com/corazawaf/coraza/v3/internal/environment"
 )
 
 func TestRawRequests(t *testing.T) {
@@ -95,6 +99,222 @@ func TestResponse(t *testing.T) {
 	*/
 }
 
+func TestWebserverErrorLog(t *testing.T) {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().WithDirectives(`
+SecRuleEngine On
+SecRule WEBSERVER_ERROR_LOG "@contains upstream timed out" "id:1,phase:5,log,pass"
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	tx := waf.NewTransaction()
+	tx.ProcessRequestHeaders()
+	eTx, ok := tx.(experimental.TransactionWithWebserverErrorLog)
+	if !ok {
+		t.Fatal("transaction does not implement TransactionWithWebserverErrorLog")
+	}
+	eTx.AddWebserverErrorLog("[error] 123#0: *1 connect() failed (111: Connection refused)")
+	eTx.AddWebserverErrorLog("[error] 123#0: *1 upstream timed out (110: Connection timed out)")
+	tx.ProcessLogging()
+
+	matched := tx.MatchedRules()
+	if want, have := 1, len(matched); want != have {
+		t.Fatalf("unexpected number of matched rules, want %d, have %d", want, have)
+	}
+	if want, have := 1, matched[0].Rule().ID(); want != have {
+		t.Errorf("unexpected matched rule, want %d, have %d", want, have)
+	}
+	if err := tx.Close(); err != nil {
+		t.Fatalf("failed to close transaction: %s", err.Error())
+	}
+}
+
+func TestRequestBodyRawBinaryMatch(t *testing.T) {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().WithDirectives(`
+SecRuleEngine On
+SecRequestBodyAccess On
+SecRule REQUEST_BODY_RAW "@rx \x00\xff\xfe" "id:1,phase:2,deny,status:403"
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	tx := waf.NewTransaction()
+	tx.AddRequestHeader("Content-Type", "application/octet-stream")
+	tx.ProcessRequestHeaders()
+	if _, _, err := tx.WriteRequestBody([]byte("header\x00\xff\xfetrailer")); err != nil {
+		t.Fatal(err)
+	}
+	it, err := tx.ProcessRequestBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it == nil || it.RuleID != 1 {
+		t.Errorf("expected interruption by rule 1, have %v", it)
+	}
+	if err := tx.Close(); err != nil {
+		t.Fatalf("failed to close transaction: %s", err.Error())
+	}
+}
+
+func TestTmpDir(t *testing.T) {
+	if !environment.HasAccessToFS {
+		return // t.Skip doesn't work on TinyGo
+	}
+
+	tmpDir := t.TempDir()
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().WithDirectives(fmt.Sprintf(`
+SecRuleEngine On
+SecRequestBodyAccess On
+SecRequestBodyInMemoryLimit 16
+SecTmpDir %s
+SecRule FILES "@streq payload.bin" "id:1,phase:2,deny,status:403"
+`, tmpDir)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	tmpFiles := func() []string {
+		t.Helper()
+		entries, err := os.ReadDir(tmpDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		return names
+	}
+
+	tx := waf.NewTransaction()
+	tx.AddRequestHeader("Content-Type", "multipart/form-data; boundary=xxx")
+	tx.ProcessRequestHeaders()
+	body := strings.Join([]string{
+		"--xxx",
+		`Content-Disposition: form-data; name="file"; filename="payload.bin"`,
+		"",
+		strings.Repeat("A", 64),
+		"--xxx--",
+		"",
+	}, "\r\n")
+	if _, _, err := tx.WriteRequestBody([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	it, err := tx.ProcessRequestBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it == nil || it.RuleID != 1 {
+		t.Errorf("expected interruption by rule 1, have %v", it)
+	}
+
+	// the spilled over body and the uploaded file
+	if want, have := 2, len(tmpFiles()); want != have {
+		t.Errorf("unexpected number of temporary files, want %d, have %d", want, have)
+	}
+	for _, name := range tmpFiles() {
+		info, err := os.Stat(filepath.Join(tmpDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm&0o077 != 0 {
+			t.Errorf("unexpected permissions %o for temporary file %s", perm, name)
+		}
+	}
+
+	if err := tx.Close(); err != nil {
+		t.Fatalf("failed to close transaction: %s", err.Error())
+	}
+	if files := tmpFiles(); len(files) != 0 {
+		t.Errorf("expected temporary files to be removed, found %v", files)
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().WithDirectives(`
+SecRuleEngine On
+SecRule REQUEST_FILENAME "@and (@beginsWith /admin) (!@endsWith .css)" "id:1,phase:1,log,pass"
+SecRule ARGS:id "@or (@and (@rx ^[0-9]+$) (@gt 1000)) (!@rx ^[0-9]+$)" "id:2,phase:1,log,pass"
+SecRule REQUEST_HEADERS:User-Agent "@or (@contains sqlmap) (@contains nikto)" "id:3,phase:1,log,pass"
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		uri       string
+		userAgent string
+		matched   []int
+	}{
+		{uri: "/admin/users?id=1", userAgent: "curl", matched: []int{1}},
+		{uri: "/admin/style.css?id=5000", userAgent: "curl", matched: []int{2}},
+		{uri: "/?id=abc", userAgent: "nikto/2.1", matched: []int{2, 3}},
+		{uri: "/?id=10", userAgent: "Mozilla", matched: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			tx := waf.NewTransaction()
+			defer tx.Close()
+			tx.ProcessURI(tt.uri, "GET", "HTTP/1.1")
+			tx.AddRequestHeader("User-Agent", tt.userAgent)
+			tx.ProcessRequestHeaders()
+
+			var matched []int
+			for _, mr := range tx.MatchedRules() {
+				matched = append(matched, mr.Rule().ID())
+			}
+			if want, have := fmt.Sprint(tt.matched), fmt.Sprint(matched); want != have {
+				t.Errorf("unexpected matched rules, want %s, have %s", want, have)
+			}
+		})
+	}
+}
+
+func TestTrailers(t *testing.T) {
+	waf, err := coraza.NewWAF(coraza.NewWAFConfig().WithDirectives(`
+SecRuleEngine On
+SecRule REQUEST_TRAILERS:x-checksum "@streq abc" "id:1,phase:5,log,pass"
+SecRule RESPONSE_TRAILERS:grpc-status "!@eq 0" "id:2,phase:5,log,pass"
+SecRule RESPONSE_TRAILERS:grpc-message "@contains ok" "id:3,phase:5,log,pass"
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	tx := waf.NewTransaction()
+	tx.ProcessRequestHeaders()
+	if _, err := tx.ProcessRequestBody(); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	eTx, ok := tx.(experimental.TransactionWithTrailers)
+	if !ok {
+		t.Fatal("transaction does not implement TransactionWithTrailers")
+	}
+	eTx.AddRequestTrailer("X-Checksum", "abc")
+	tx.ProcessResponseHeaders(200, "HTTP/2.0")
+	if _, err := tx.ProcessResponseBody(); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	eTx.AddResponseTrailer("Grpc-Status", "13")
+	tx.ProcessLogging()
+
+	matched := tx.MatchedRules()
+	if want, have := 2, len(matched); want != have {
+		t.Fatalf("unexpected number of matched rules, want %d, have %d", want, have)
+	}
+	for i, id := range []int{1, 2} {
+		if want, have := id, matched[i].Rule().ID(); want != have {
+			t.Errorf("unexpected matched rule, want %d, have %d", want, have)
+		}
+	}
+	if err := tx.Close(); err != nil {
+		t.Fatalf("failed to close transaction: %s", err.Error())
+	}
+}
+
 func buildRequest(method, uri string) string {
 	return strings.Join([]string{
 		method + " " + uri + " HTTP/1.1",
@@ -20,6 +20,9 @@ type Transaction interface {
 	// ProcessConnection should be called at very beginning of a request process, it is
 	// expected to be executed prior to the virtual host resolution, when the
 	// connection arrives on the server.
+	//
+	// It populates REMOTE_ADDR, REMOTE_HOST, REMOTE_PORT, SERVER_ADDR and SERVER_PORT,
+	// SERVER_NAME is set with SetServerName.
 	ProcessConnection(client string, cPort int, server string, sPort int)
 
 	// ProcessURI Performs the analysis on the URI and all the query string variables.
@@ -31,6 +34,9 @@ type Transaction interface {
 	// phase 1 and 2.
 	//
 	// note: This function won't add GET arguments, they must be added with AddArgument
+	//
+	// The uri is the request-target and httpVersion the protocol as found in the request
+	// line, e.g. HTTP/1.1, REQUEST_LINE is rebuilt from them and the method.
 	ProcessURI(uri string, method string, httpVersion string)
 
 	// SetServerName allows to set server name details.
@@ -31,6 +31,15 @@ const (
 	// InboundDataError will be set to 1 when the request body size
 	// is above the setting configured by SecRequesteBodyLimit
 	InboundDataError = variables.InboundDataError
+	// ArgsLimitExceeded will be set to 1 when request arguments were
+	// skipped because of the limit configured by SecArgumentsLimit
+	ArgsLimitExceeded = variables.ArgsLimitExceeded
+	// RequestHeadersLimitExceeded will be set to 1 when request headers
+	// were skipped because of the limit configured by SecRequestHeadersLimit
+	RequestHeadersLimitExceeded = variables.RequestHeadersLimitExceeded
+	// RequestCookiesLimitExceeded will be set to 1 when request cookies
+	// were skipped because of the limit configured by SecRequestCookiesLimit
+	RequestCookiesLimitExceeded = variables.RequestCookiesLimitExceeded
 	// MatchedVar is the value of the matched variable
 	MatchedVar = variables.MatchedVar
 	// MatchedVarName is the name of the matched variable
@@ -44,7 +53,7 @@ const (
 	QueryString = variables.QueryString
 	// RemoteAddr is the remote address of the connection
 	RemoteAddr = variables.RemoteAddr
-	// RemoteHost is the remote host of the connection, not implemented
+	// RemoteHost is the hostname of the client, resolved with SecHostnameLookups On, or the client address otherwise
 	RemoteHost = variables.RemoteHost
 	// RemotePort is the remote port of the connection
 	RemotePort = variables.RemotePort
@@ -58,7 +67,7 @@ const (
 	// ReqbodyProcessorErrorMsg is the same as ReqbodyErrorMsg ?
 	ReqbodyProcessorErrorMsg = variables.ReqbodyProcessorErrorMsg
 	// ReqbodyProcessor contains the name of the request body processor used, default
-	// ones are: URLENCODED, MULTIPART, and XML. They can be extended using plugins.
+	// ones are: URLENCODED, MULTIPART, JSON and XML. They can be extended using plugins.
 	ReqbodyProcessor = variables.ReqbodyProcessor
 	// RequestBasename contains the name after the last slash in the request URI
 	// It does not pass through any anti-evasion, use with transformations
@@ -67,6 +76,10 @@ const (
 	// For urlencoded requests. It is possible to force it's presence by using
 	// the ctl:forceRequestBodyVariable action
 	RequestBody = variables.RequestBody
+	// RequestBodyRaw contains the request body bytes as received, up to the
+	// request body limit, regardless of the body processor and transformations.
+	// It is only available if requestBodyAccess is set to on
+	RequestBodyRaw = variables.RequestBodyRaw
 	// RequestBodyLength contains the length of the request body in bytes calculated from
 	// the BodyBuffer, not from the content-type header
 	RequestBodyLength = variables.RequestBodyLength
@@ -81,15 +94,19 @@ const (
 	// RequestProtocol is the protocol used in the request
 	RequestProtocol = variables.RequestProtocol
 	// RequestURI holds the full request URL including the query string data without
-	// the domain name
+	// the domain name and the fragment, the percent-encoding is not decoded
 	RequestURI = variables.RequestURI
-	// RequestURIRaw is the same as RequestURI but with the domain name in case
-	// it was provided in the request line
+	// RequestURIRaw is the request target exactly as received, with the domain name
+	// in case it was provided in the request line
 	RequestURIRaw = variables.RequestURIRaw
 	// ResponseBody contains the full response body, it will only be available if
 	// responseBodyAccess is set to on and the response mime matches the configured
 	// processable mime types
 	ResponseBody = variables.ResponseBody
+	// ResponseBodyRaw contains the response body bytes as received, up to the
+	// response body limit, regardless of the body processor. It is only available
+	// if responseBodyAccess is set to on and the response mime is processable
+	ResponseBodyRaw = variables.ResponseBodyRaw
 	// ResponseContentLength contains the length of the response body in bytes calculated from
 	// the BodyBuffer, not from the content-type header
 	ResponseContentLength = variables.ResponseContentLength
@@ -105,6 +122,9 @@ const (
 	ServerName = variables.ServerName
 	// ServerPort is the port of the server
 	ServerPort = variables.ServerPort
+	// WebserverErrorLog contains the error log entries of the web server
+	// for the transaction, fed through experimental.TransactionWithWebserverErrorLog
+	WebserverErrorLog = variables.WebserverErrorLog
 	// HighestSeverity is the highest severity from all matched rules
 	HighestSeverity = variables.HighestSeverity
 	// StatusLine is the status line of the response, including the request method
@@ -153,8 +173,29 @@ const (
 	// ResponseHeaders can be used as either a collection of all of the response
 	// headers or can be used to inspect selected headers
 	ResponseHeaders = variables.ResponseHeaders
-	// Geo contains the location information of the client
+	// RequestTrailers contains the trailer fields sent after the request body,
+	// fed through experimental.TransactionWithTrailers
+	RequestTrailers = variables.RequestTrailers
+	// ResponseTrailers contains the trailer fields sent after the response body,
+	// fed through experimental.TransactionWithTrailers
+	ResponseTrailers = variables.ResponseTrailers
+	// Geo contains the location information of the client, populated by @geoLookup
+	// with the COUNTRY_CODE, COUNTRY_NAME, CONTINENT_CODE, CITY, LATITUDE and LONGITUDE keys
 	Geo = variables.Geo
+	// IP is the persistent collection of the client, initialized with initcol:ip=%{REMOTE_ADDR}
+	IP = variables.IP
+	// Global is the persistent collection shared by all the transactions, initialized with initcol:global
+	Global = variables.Global
+	// Session is the persistent collection of the session, initialized with initcol:session
+	Session = variables.Session
+	// User is the persistent collection of the user, initialized with initcol:user
+	User = variables.User
+	// Resource is the persistent collection of the resource, initialized with initcol:resource
+	Resource = variables.Resource
+	// AuthType is the authentication scheme of the Authorization request header, e.g. Basic or Bearer
+	AuthType = variables.AuthType
+	// RemoteUser is the username of the Authorization request header using the Basic scheme
+	RemoteUser = variables.RemoteUser
 	// RequestCookiesNames contains the names of the request cookies
 	RequestCookiesNames = variables.RequestCookiesNames
 	// FilesTmpNames contains the names of the uploaded temporal files
@@ -171,7 +212,7 @@ const (
 	Rule = variables.Rule
 	// JSON does not provide any data, might be removed
 	JSON = variables.JSON
-	// Env contains the process environment variables
+	// Env contains the environment variables set by the setenv action
 	Env = variables.Env
 	// UrlencodedError equals 1 if we failed to parse de URL
 	// It applies for URL query part and urlencoded post body
@@ -182,10 +223,38 @@ const (
 	ResponseXML = variables.ResponseXML
 	// RequestXML contains the request parsed XML
 	RequestXML = variables.RequestXML
-	// XML is a pointer to ResponseXML
+	// XML is a pointer to RequestXML, besides /* and //@* keys are evaluated
+	// as XPath expressions, e.g. XML:/order/item/text()
 	XML = variables.XML
 	// MultipartPartHeaders contains the multipart headers
 	MultipartPartHeaders = variables.MultipartPartHeaders
+	// MultipartBoundaryQuoted will be set to 1 when the multipart boundary is quoted
+	MultipartBoundaryQuoted = variables.MultipartBoundaryQuoted
+	// MultipartBoundaryWhitespace will be set to 1 when the multipart boundary contains whitespace
+	MultipartBoundaryWhitespace = variables.MultipartBoundaryWhitespace
+	// MultipartCrlfLfLines will be set to 1 when the multipart body mixes CRLF and LF line endings
+	MultipartCrlfLfLines = variables.MultipartCrlfLfLines
+	// MultipartDataBefore will be set to 1 when there is data before the first multipart boundary
+	MultipartDataBefore = variables.MultipartDataBefore
+	// MultipartHeaderFolding will be set to 1 when a multipart part header is folded
+	MultipartHeaderFolding = variables.MultipartHeaderFolding
+	// MultipartInvalidHeaderFolding will be set to 1 when a multipart part header is folded
+	// with characters other than space or horizontal tab
+	MultipartInvalidHeaderFolding = variables.MultipartInvalidHeaderFolding
+	// MultipartInvalidPart will be set to 1 when a multipart part has invalid headers
+	MultipartInvalidPart = variables.MultipartInvalidPart
+	// MultipartInvalidQuoting will be set to 1 when a Content-Disposition parameter is badly quoted
+	MultipartInvalidQuoting = variables.MultipartInvalidQuoting
+	// MultipartLfLine will be set to 1 when the multipart body uses LF line endings
+	MultipartLfLine = variables.MultipartLfLine
+	// MultipartMissingSemicolon will be set to 1 when a Content-Disposition parameter is not
+	// preceded by a semicolon
+	MultipartMissingSemicolon = variables.MultipartMissingSemicolon
+	// MultipartStrictError will be set to 1 when any of the multipart strict parsing flags is set
+	// or the multipart body can't be parsed
+	MultipartStrictError = variables.MultipartStrictError
+	// MultipartUnmatchedBoundary will be set to 1 when a line looks like a boundary but doesn't match it
+	MultipartUnmatchedBoundary = variables.MultipartUnmatchedBoundary
 	// ResBodyError is 1 if the response body processor failed
 	ResBodyError = variables.ResBodyError
 	// ResBodyErrorMsg contains the error message if the response body processor failed
@@ -194,8 +263,6 @@ const (
 	ResBodyProcessorError = variables.ResBodyProcessorError
 	// ResBodyProcessorErrorMsg contains the error message if the response body processor failed
 	ResBodyProcessorErrorMsg = variables.ResBodyProcessorErrorMsg
-	// MultipartStrictError will be set to 1 when there is an error parsing multipart
-	MultipartStrictError = variables.MultipartStrictError
 	// Time holds a formatted string representing the time (hour:minute:second).
 	Time = variables.Time
 	// TimeDay holds the current day of the month (1-31)
@@ -7,8 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
+// ErrRuleEvaluationTimeout is the reason of the evaluations aborted because a
+// phase exceeded SecRuleEvaluationTimeout.
+var ErrRuleEvaluationTimeout = errors.New("rule evaluation timeout")
+
 // AuditEngineStatus represents the functionality
 // of the audit engine.
 type AuditEngineStatus int
@@ -178,8 +183,23 @@ type Interruption struct {
 	// Force this status code
 	Status int
 
-	// Parameters used by proxy and redirect
+	// Parameters used by proxy and redirect. For the interruptions of the
+	// evaluations aborted because the context of the transaction is done or a
+	// phase exceeded SecRuleEvaluationTimeout, RuleID is 0, Status is 503 and
+	// Data is the reason, the error is available with
+	// experimental.TransactionWithEvaluationError.
 	Data string
+
+	// Body is the response body configured with SecDefaultBlockPage to be
+	// returned for deny interruptions, empty if none has been configured
+	Body string
+
+	// ContentType is the content type of Body
+	ContentType string
+
+	// Pause is the delay requested by the pause action. Coraza does not
+	// apply it, the integrator is expected to delay the response accordingly.
+	Pause time.Duration
 }
 
 // BodyBufferOptions is used to feed a coraza.BodyBuffer with parameters
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/corazawaf/coraza/v3/experimental"
+	"github.com/corazawaf/coraza/v3/experimental/plugins/plugintypes"
 	"github.com/corazawaf/coraza/v3/internal/corazawaf"
 	"github.com/corazawaf/coraza/v3/internal/environment"
 	"github.com/corazawaf/coraza/v3/internal/seclang"
@@ -44,6 +45,15 @@ func NewWAF(config WAFConfig) (WAF, error) {
 		waf.Logger = c.debugLogger
 	}
 
+	// the database is set before parsing the rules as the geoLookup operators
+	// are initialized with it
+	if c.geoDB != nil {
+		waf.GeoDB = c.geoDB
+	}
+	// same for the resolver of the rbl operators
+	waf.DNSResolver = c.dnsResolver
+	waf.DNSTimeout = c.dnsTimeout
+
 	parser := seclang.NewParser(waf)
 
 	if c.fsRoot != nil {
@@ -151,3 +161,38 @@ func (w wafWrapper) NewTransactionWithID(id string) types.Transaction {
 func (w wafWrapper) NewTransactionWithOptions(opts experimental.Options) types.Transaction {
 	return w.waf.NewTransactionWithOptions(opts)
 }
+
+// Subscribe implements the same method on experimental.WAFWithMatchEvents.
+func (w wafWrapper) Subscribe(size int) (<-chan experimental.MatchEvent, func()) {
+	return w.waf.Subscribe(size)
+}
+
+// SetRuleMatchCallback implements the same method on experimental.WAFWithRuleMatchCallback.
+func (w wafWrapper) SetRuleMatchCallback(cb func(experimental.MatchEvent)) {
+	w.waf.SetRuleMatchCallback(cb)
+}
+
+// SetTracer implements the same method on experimental.WAFWithTracing.
+func (w wafWrapper) SetTracer(tracer plugintypes.Tracer) {
+	w.waf.SetTracer(tracer)
+}
+
+// SetRuleEnabled implements the same method on experimental.WAFWithRuleToggle.
+func (w wafWrapper) SetRuleEnabled(id int, enabled bool) error {
+	return w.waf.SetRuleEnabled(id, enabled)
+}
+
+// IsRuleEnabled implements the same method on experimental.WAFWithRuleToggle.
+func (w wafWrapper) IsRuleEnabled(id int) bool {
+	return w.waf.IsRuleEnabled(id)
+}
+
+// RuleStats implements the same method on experimental.WAFWithRuleStats.
+func (w wafWrapper) RuleStats() map[int]uint64 {
+	return w.waf.RuleStats()
+}
+
+// SetSampleSeed implements the same method on experimental.WAFWithSampleSeed.
+func (w wafWrapper) SetSampleSeed(seed int64) {
+	w.waf.SetSampleSeed(seed)
+}